@@ -0,0 +1,27 @@
+use std::env;
+use std::process::Command;
+
+fn main() {
+	// Embed the commit which this build was made from
+	let commit = output("git", &["rev-parse", "--short", "HEAD"]);
+	println!("cargo:rustc-env=SURREAL_BUILD_COMMIT={}", commit);
+	// Embed the compiler which this build was made with
+	let rustc = env::var("RUSTC").unwrap_or_else(|_| String::from("rustc"));
+	let rustc = output(&rustc, &["--version"]);
+	println!("cargo:rustc-env=SURREAL_BUILD_RUSTC={}", rustc);
+	// Rebuild when the checked out commit changes
+	println!("cargo:rerun-if-changed=.git/HEAD");
+	println!("cargo:rerun-if-changed=.git/refs/heads");
+}
+
+fn output(cmd: &str, args: &[&str]) -> String {
+	Command::new(cmd)
+		.args(args)
+		.output()
+		.ok()
+		.filter(|v| v.status.success())
+		.and_then(|v| String::from_utf8(v.stdout).ok())
+		.map(|v| v.trim().to_owned())
+		.filter(|v| !v.is_empty())
+		.unwrap_or_else(|| String::from("unknown"))
+}
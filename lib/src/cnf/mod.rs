@@ -1,3 +1,5 @@
+use std::time::Duration;
+
 #[cfg(feature = "parallel")]
 // Specifies how many concurrent jobs can be buffered in the worker channel.
 pub const MAX_CONCURRENT_TASKS: usize = 64;
@@ -5,6 +7,30 @@ pub const MAX_CONCURRENT_TASKS: usize = 64;
 // Specifies how many subqueries will be processed recursively before the query fails.
 pub const MAX_RECURSIVE_QUERIES: usize = 16;
 
+// Specifies how many graph edges will be traversed in a single expression before the query fails.
+pub const MAX_GRAPH_DEPTH: usize = 16;
+
+// Specifies how many times the body of a FOR loop will be run before the query fails.
+pub const MAX_LOOP_ITERATIONS: usize = 100_000;
+
+// Specifies how many values an uncorrelated subquery in a WHERE clause can return before the query fails.
+pub const MAX_SUBQUERY_VALUES: usize = 100_000;
+
+// Specifies how long to wait before first retrying a conflicting transaction, doubling on each retry.
+pub const TX_RETRY_BACKOFF: Duration = Duration::from_millis(10);
+
+// Specifies how many compiled regular expressions are cached for string functions.
+pub const MAX_CACHED_REGEXES: usize = 1000;
+
+// Specifies how many query results are cached when the query cache is enabled.
+pub const MAX_CACHED_QUERIES: usize = 1000;
+
+// Specifies how many expired records are deleted in a single transaction.
+pub const EXPIRED_BATCH_SIZE: u32 = 1000;
+
+// Specifies the query parameters which are set from the session and can not be overridden.
+pub const PROTECTED_PARAM_NAMES: &[&str] = &["auth", "scope", "token", "session"];
+
 // The characters which are supported in server record IDs.
 pub const ID_CHARS: [char; 36] = [
 	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i',
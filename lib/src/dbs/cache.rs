@@ -0,0 +1,169 @@
+use crate::cnf::MAX_CACHED_QUERIES;
+use crate::dbs::Response;
+use crate::dbs::Session;
+use crate::dbs::Variables;
+use crate::sql::query::Query;
+use crate::sql::statement::Statement;
+use crate::sql::value::Value;
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::Duration;
+use trice::Instant;
+
+/// A cache of query results, shared by all queries on a datastore.
+///
+/// Results are keyed by the normalised query text, the query variables,
+/// and the full session, so that results computed for one user or scope
+/// are never returned to another. Because subqueries, graph traversals,
+/// and record links can read from any table in a database, a committed
+/// write invalidates all cached results for the database it was made in.
+/// Queries which call non-deterministic functions, or which depend on the
+/// session parameters, are never cached.
+pub(crate) struct QueryCache {
+	ttl: Duration,
+	inner: Mutex<Inner>,
+}
+
+#[derive(Default)]
+struct Inner {
+	// The cached query results
+	entries: HashMap<String, Entry>,
+	// The write version of each database
+	versions: HashMap<(String, String), u64>,
+}
+
+struct Entry {
+	ns: String,
+	db: String,
+	time: Instant,
+	version: u64,
+	results: Vec<(Option<String>, Duration, Value, Option<String>, bool)>,
+}
+
+impl QueryCache {
+	/// Create a new cache where results expire after the specified duration
+	pub(crate) fn new(ttl: Duration) -> QueryCache {
+		QueryCache {
+			ttl,
+			inner: Mutex::new(Inner::default()),
+		}
+	}
+
+	/// Generate the cache key for a query, if the query can be cached
+	pub(crate) fn key(
+		ast: &Query,
+		sess: &Session,
+		vars: &Variables,
+		strict: bool,
+	) -> Option<String> {
+		// Only cache queries against a selected database
+		if sess.ns.is_none() || sess.db.is_none() {
+			return None;
+		}
+		// Only cache repeatable queries which only read data
+		let cacheable = ast.iter().all(|stm| {
+			!stm.writeable()
+				&& !stm.volatile()
+				&& matches!(
+					stm,
+					Statement::Set(_)
+						| Statement::Select(_)
+						| Statement::Ifelse(_)
+						| Statement::Output(_)
+						| Statement::Begin(_)
+						| Statement::Commit(_)
+				)
+		});
+		// Key the query by its identity
+		match cacheable {
+			true => Some(format!("{:?}\n{:?}\n{}\n{}", sess, vars, strict, ast)),
+			false => None,
+		}
+	}
+
+	/// Get the current write version of a database
+	pub(crate) fn version(&self, ns: &str, db: &str) -> u64 {
+		// Claim the cache
+		let inner = self.inner.lock().unwrap();
+		// Fetch the database version
+		inner.versions.get(&(ns.to_owned(), db.to_owned())).copied().unwrap_or_default()
+	}
+
+	/// Fetch the cached results for a query, if they are still valid
+	pub(crate) fn get(&self, key: &str) -> Option<Vec<Response>> {
+		// Claim the cache
+		let mut inner = self.inner.lock().unwrap();
+		// Check if the query is cached
+		let entry = inner.entries.get(key)?;
+		// Get the current database version
+		let version = inner
+			.versions
+			.get(&(entry.ns.to_owned(), entry.db.to_owned()))
+			.copied()
+			.unwrap_or_default();
+		// Remove the results if they are stale
+		if entry.version != version || entry.time.elapsed() >= self.ttl {
+			inner.entries.remove(key);
+			return None;
+		}
+		// Return the cached results
+		Some(
+			entry
+				.results
+				.iter()
+				.map(|(sql, time, val, cursor, truncated)| Response {
+					sql: sql.clone(),
+					time: *time,
+					result: Ok(val.clone()),
+					cursor: cursor.clone(),
+					truncated: *truncated,
+				})
+				.collect(),
+		)
+	}
+
+	/// Store the results of a query, which were computed at a database version
+	pub(crate) fn set(&self, key: String, ns: &str, db: &str, version: u64, res: &[Response]) {
+		// Only cache queries which succeeded
+		let results = match res
+			.iter()
+			.map(|v| match &v.result {
+				Ok(val) => {
+					Some((v.sql.clone(), v.time, val.clone(), v.cursor.clone(), v.truncated))
+				}
+				Err(_) => None,
+			})
+			.collect::<Option<Vec<_>>>()
+		{
+			Some(v) => v,
+			None => return,
+		};
+		// Claim the cache
+		let mut inner = self.inner.lock().unwrap();
+		// Clear the cache if it is full
+		if inner.entries.len() >= MAX_CACHED_QUERIES {
+			inner.entries.clear();
+		}
+		// Store the query results
+		inner.entries.insert(
+			key,
+			Entry {
+				ns: ns.to_owned(),
+				db: db.to_owned(),
+				time: Instant::now(),
+				version,
+				results,
+			},
+		);
+	}
+
+	/// Invalidate all cached results for a database
+	pub(crate) fn invalidate(&self, ns: &str, db: &str) {
+		// Claim the cache
+		let mut inner = self.inner.lock().unwrap();
+		// Increment the database version
+		*inner.versions.entry((ns.to_owned(), db.to_owned())).or_default() += 1;
+		// Remove the stale results
+		inner.entries.retain(|_, v| v.ns != ns || v.db != db);
+	}
+}
@@ -37,7 +37,13 @@ impl Iterable {
 					let val = txn.clone().lock().await.get(key).await?;
 					// Parse the data from the store
 					let val = Operable::Value(match val {
-						Some(v) => Value::from(v),
+						Some(v) => match Value::from(v) {
+							// Ignore records which have expired
+							v if !opt.expired && v.is_expired() => Value::None,
+							// Ignore records which have been soft deleted
+							v if !opt.deleted && v.is_deleted() => Value::None,
+							v => v,
+						},
 						None => Value::None,
 					});
 					// Process the document record
@@ -51,7 +57,13 @@ impl Iterable {
 					let val = txn.clone().lock().await.get(key).await?;
 					// Parse the data from the store
 					let x = match val {
-						Some(v) => Value::from(v),
+						Some(v) => match Value::from(v) {
+							// Ignore records which have expired
+							v if !opt.expired && v.is_expired() => Value::None,
+							// Ignore records which have been soft deleted
+							v if !opt.deleted && v.is_deleted() => Value::None,
+							v => v,
+						},
 						None => Value::None,
 					};
 					// Create a new operable value
@@ -67,7 +79,13 @@ impl Iterable {
 					let val = txn.clone().lock().await.get(key).await?;
 					// Parse the data from the store
 					let x = match val {
-						Some(v) => Value::from(v),
+						Some(v) => match Value::from(v) {
+							// Ignore records which have expired
+							v if !opt.expired && v.is_expired() => Value::None,
+							// Ignore records which have been soft deleted
+							v if !opt.deleted && v.is_deleted() => Value::None,
+							v => v,
+						},
 						None => Value::None,
 					};
 					// Create a new operable value
@@ -124,6 +142,14 @@ impl Iterable {
 								// Parse the data from the store
 								let key: crate::key::thing::Thing = (&k).into();
 								let val: crate::sql::value::Value = (&v).into();
+								// Skip records which have expired
+								if !opt.expired && val.is_expired() {
+									continue;
+								}
+								// Skip records which have been soft deleted
+								if !opt.deleted && val.is_deleted() {
+									continue;
+								}
 								let rid = Thing::from((key.tb, key.id));
 								// Create a new operable value
 								let val = Operable::Value(val);
@@ -184,6 +210,83 @@ impl Iterable {
 								// Parse the data from the store
 								let key: crate::key::thing::Thing = (&k).into();
 								let val: crate::sql::value::Value = (&v).into();
+								// Skip records which have expired
+								if !opt.expired && val.is_expired() {
+									continue;
+								}
+								// Skip records which have been soft deleted
+								if !opt.deleted && val.is_deleted() {
+									continue;
+								}
+								let rid = Thing::from((key.tb, key.id));
+								// Create a new operable value
+								let val = Operable::Value(val);
+								// Process the record
+								chn.send((Some(rid), val)).await?;
+							}
+							continue;
+						}
+						break;
+					}
+				}
+				Iterable::After(v) => {
+					// Check that the table exists
+					txn.lock().await.check_ns_db_tb(opt.ns(), opt.db(), &v.tb, opt.strict).await?;
+					// Start the scan just after the cursor record
+					let mut beg = thing::new(opt.ns(), opt.db(), &v.tb, &v.id).encode().unwrap();
+					beg.push(0x00);
+					let end = thing::suffix(opt.ns(), opt.db(), &v.tb);
+					// Prepare the next holder key
+					let mut nxt: Option<Vec<u8>> = None;
+					// Loop until no more keys
+					loop {
+						// Check if the context is finished
+						if ctx.is_done() {
+							break;
+						}
+						// Get the next 1000 key-value entries
+						let res = match nxt {
+							None => {
+								let min = beg.clone();
+								let max = end.clone();
+								txn.clone().lock().await.scan(min..max, 1000).await?
+							}
+							Some(ref mut beg) => {
+								beg.push(0x00);
+								let min = beg.clone();
+								let max = end.clone();
+								txn.clone().lock().await.scan(min..max, 1000).await?
+							}
+						};
+						// If there are key-value entries then fetch them
+						if !res.is_empty() {
+							// Get total results
+							let n = res.len();
+							// Exit when settled
+							if n == 0 {
+								break;
+							}
+							// Loop over results
+							for (i, (k, v)) in res.into_iter().enumerate() {
+								// Check the context
+								if ctx.is_done() {
+									break;
+								}
+								// Ready the next
+								if n == i + 1 {
+									nxt = Some(k.clone());
+								}
+								// Parse the data from the store
+								let key: crate::key::thing::Thing = (&k).into();
+								let val: crate::sql::value::Value = (&v).into();
+								// Skip records which have expired
+								if !opt.expired && val.is_expired() {
+									continue;
+								}
+								// Skip records which have been soft deleted
+								if !opt.deleted && val.is_deleted() {
+									continue;
+								}
 								let rid = Thing::from((key.tb, key.id));
 								// Create a new operable value
 								let val = Operable::Value(val);
@@ -314,7 +417,13 @@ impl Iterable {
 									let rid = Thing::from((gra.ft, gra.fk));
 									// Parse the data from the store
 									let val = Operable::Value(match val {
-										Some(v) => Value::from(v),
+										Some(v) => match Value::from(v) {
+											// Ignore records which have expired
+											v if !opt.expired && v.is_expired() => Value::None,
+											// Ignore records which have been soft deleted
+											v if !opt.deleted && v.is_deleted() => Value::None,
+											v => v,
+										},
 										None => Value::None,
 									});
 									// Process the record
@@ -0,0 +1,99 @@
+use crate::err::Error;
+use crate::sql::thing::Thing;
+use once_cell::sync::Lazy;
+use sha2::{Digest, Sha256};
+use std::fmt::Write;
+
+// The key which signs pagination cursors. Cursors are
+// only valid for the lifetime of the current process.
+static KEY: Lazy<[u8; 32]> = Lazy::new(rand::random);
+
+/// Create an opaque cursor which resumes a query after the specified record
+pub fn encode(rid: &Thing) -> String {
+	// The record key is the position in the table
+	let pos = rid.to_string();
+	// Sign the position so it can't be altered
+	let sig = sign(pos.as_bytes());
+	// Output the position and signature
+	format!("{}.{}", hex(pos.as_bytes()), hex(&sig))
+}
+
+/// Check the signature of a cursor, and return the record it points to
+pub fn decode(cursor: &str) -> Result<Thing, Error> {
+	// Split the position from the signature
+	let (pos, sig) = match cursor.split_once('.') {
+		Some(v) => v,
+		None => return Err(invalid("The cursor is malformed")),
+	};
+	// Decode the position and signature
+	let pos = match (unhex(pos), unhex(sig)) {
+		(Some(pos), Some(sig)) if equal(&sign(&pos), &sig) => pos,
+		_ => return Err(invalid("The cursor has been altered, or has expired")),
+	};
+	// Parse the record id from the position
+	match String::from_utf8(pos).ok().and_then(|v| crate::sql::thing(&v).ok()) {
+		Some(v) => Ok(v),
+		None => Err(invalid("The cursor is malformed")),
+	}
+}
+
+fn invalid(message: &str) -> Error {
+	Error::InvalidCursor {
+		message: message.to_owned(),
+	}
+}
+
+// Compute the HMAC-SHA256 of the message
+fn sign(msg: &[u8]) -> Vec<u8> {
+	let mut ipad = [0x36u8; 64];
+	let mut opad = [0x5cu8; 64];
+	for (i, k) in KEY.iter().enumerate() {
+		ipad[i] ^= k;
+		opad[i] ^= k;
+	}
+	let inner = Sha256::new().chain_update(ipad).chain_update(msg).finalize();
+	Sha256::new().chain_update(opad).chain_update(inner).finalize().to_vec()
+}
+
+// Compare two signatures in constant time
+fn equal(a: &[u8], b: &[u8]) -> bool {
+	a.len() == b.len() && a.iter().zip(b).fold(0, |acc, (x, y)| acc | (x ^ y)) == 0
+}
+
+fn hex(v: &[u8]) -> String {
+	v.iter().fold(String::with_capacity(v.len() * 2), |mut out, b| {
+		let _ = write!(out, "{:02x}", b);
+		out
+	})
+}
+
+fn unhex(v: &str) -> Option<Vec<u8>> {
+	if v.len() % 2 != 0 {
+		return None;
+	}
+	(0..v.len()).step_by(2).map(|i| u8::from_str_radix(v.get(i..i + 2)?, 16).ok()).collect()
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn cursor_round_trip() {
+		let rid = crate::sql::thing("person:tobie").unwrap();
+		let cur = encode(&rid);
+		assert_eq!(decode(&cur).unwrap(), rid);
+	}
+
+	#[test]
+	fn cursor_tampered() {
+		let rid = crate::sql::thing("person:tobie").unwrap();
+		let cur = encode(&rid);
+		let (_, sig) = cur.split_once('.').unwrap();
+		let cur = format!("{}.{}", hex(b"person:jaime"), sig);
+		assert!(decode(&cur).is_err());
+		assert!(decode("nonsense").is_err());
+		assert!(decode("zz.zz").is_err());
+	}
+}
@@ -1,18 +1,37 @@
+use crate::cnf::PROTECTED_PARAM_NAMES;
+use crate::cnf::TX_RETRY_BACKOFF;
 use crate::ctx::Context;
+use crate::dbs::recover;
+use crate::dbs::response::Chunk;
 use crate::dbs::response::Response;
 use crate::dbs::Auth;
 use crate::dbs::Level;
 use crate::dbs::Options;
 use crate::dbs::Transaction;
 use crate::dbs::LOG;
+#[cfg(feature = "telemetry")]
+use crate::dbs::TRACER;
 use crate::err::Error;
 use crate::kvs::Datastore;
 use crate::sql::paths::DB;
+use crate::sql::paths::ID;
 use crate::sql::paths::NS;
 use crate::sql::query::Query;
+use crate::sql::role::Role;
 use crate::sql::statement::Statement;
 use crate::sql::value::Value;
+use channel::Sender;
 use futures::lock::Mutex;
+use futures::FutureExt;
+use futures_timer::Delay;
+#[cfg(feature = "telemetry")]
+use opentelemetry::global;
+#[cfg(feature = "telemetry")]
+use opentelemetry::trace::{Span, TraceContextExt, Tracer};
+#[cfg(feature = "telemetry")]
+use opentelemetry::KeyValue;
+use std::collections::BTreeMap;
+use std::panic::AssertUnwindSafe;
 use std::sync::Arc;
 use trice::Instant;
 
@@ -20,6 +39,19 @@ pub struct Executor<'a> {
 	err: bool,
 	kvs: &'a Datastore,
 	txn: Option<Transaction>,
+	chn: Option<Sender<Chunk>>,
+	buf: Vec<Response>,
+	vars: BTreeMap<String, Value>,
+	ns: Option<String>,
+	db: Option<String>,
+	readonly: bool,
+	restrict: bool,
+	results: Option<usize>,
+	retries: usize,
+	writes: Vec<(String, String)>,
+	savepoints: Vec<(String, usize)>,
+	recover: bool,
+	roles: Vec<Role>,
 }
 
 impl<'a> Executor<'a> {
@@ -28,9 +60,69 @@ impl<'a> Executor<'a> {
 			kvs,
 			txn: None,
 			err: false,
+			chn: None,
+			buf: vec![],
+			vars: BTreeMap::new(),
+			ns: None,
+			db: None,
+			readonly: false,
+			restrict: false,
+			results: None,
+			retries: 0,
+			writes: vec![],
+			savepoints: vec![],
+			recover: false,
+			roles: vec![],
 		}
 	}
 
+	/// Send each response, and the records of any select statement
+	/// which is not in a transaction, as soon as they are ready
+	pub fn with_channel(mut self, chn: Sender<Chunk>) -> Executor<'a> {
+		self.chn = Some(chn);
+		self
+	}
+
+	/// Only allow read transactions, so that no data can be written
+	pub fn with_readonly(mut self, v: bool) -> Executor<'a> {
+		self.restrict = v;
+		self
+	}
+
+	/// Only allow the statements which are permitted by any of the roles
+	pub fn with_roles(mut self, v: Vec<Role>) -> Executor<'a> {
+		self.roles = v;
+		self
+	}
+
+	/// Limit the number of records which each statement can return
+	pub fn with_max_results(mut self, v: Option<usize>) -> Executor<'a> {
+		self.results = v;
+		self
+	}
+
+	/// Retry statements which fail because of a transaction conflict
+	pub fn with_max_retries(mut self, v: usize) -> Executor<'a> {
+		self.retries = v;
+		self
+	}
+
+	/// Return an error instead of panicking, cancelling any running transaction
+	pub fn with_panic_recovery(mut self, v: bool) -> Executor<'a> {
+		self.recover = v;
+		self
+	}
+
+	/// Take the params defined by LET statements in the query
+	pub fn params(&mut self) -> BTreeMap<String, Value> {
+		std::mem::take(&mut self.vars)
+	}
+
+	/// Take the NS and DB selected by USE statements in the query
+	pub fn selected(&mut self) -> (Option<String>, Option<String>) {
+		(self.ns.take(), self.db.take())
+	}
+
 	fn txn(&self) -> Transaction {
 		match self.txn.as_ref() {
 			Some(txn) => txn.clone(),
@@ -38,12 +130,20 @@ impl<'a> Executor<'a> {
 		}
 	}
 
+	fn allowed(&self, stm: &Statement) -> bool {
+		self.roles.is_empty() || self.roles.iter().any(|v| v.allows(stm))
+	}
+
 	async fn begin(&mut self, write: bool) -> bool {
+		// Writes are never allowed when restricted
+		let write = write && !self.restrict;
 		match self.txn.as_ref() {
 			Some(_) => false,
 			None => match self.kvs.transaction(write, false).await {
 				Ok(v) => {
 					self.txn = Some(Arc::new(Mutex::new(v)));
+					self.readonly = !write;
+					self.savepoints.clear();
 					true
 				}
 				Err(_) => {
@@ -54,7 +154,7 @@ impl<'a> Executor<'a> {
 		}
 	}
 
-	async fn commit(&mut self, local: bool) {
+	async fn commit(&mut self, local: bool) -> Result<(), Error> {
 		if local {
 			if let Some(txn) = self.txn.as_ref() {
 				match &self.err {
@@ -64,19 +164,36 @@ impl<'a> Executor<'a> {
 						if txn.cancel().await.is_err() {
 							self.err = true;
 						}
+						self.writes.clear();
 						self.txn = None;
 					}
 					false => {
 						let txn = txn.clone();
 						let mut txn = txn.lock().await;
-						if txn.commit().await.is_err() {
-							self.err = true;
-						}
+						// Read only transactions have nothing to commit
+						let res = match self.readonly {
+							true => txn.cancel().await,
+							false => txn.commit().await,
+						};
 						self.txn = None;
+						match res {
+							// Invalidate any cached results for the written databases
+							Ok(_) => {
+								for (ns, db) in self.writes.drain(..) {
+									self.kvs.invalidate(&ns, &db);
+								}
+							}
+							Err(e) => {
+								self.writes.clear();
+								self.err = true;
+								return Err(e);
+							}
+						}
 					}
 				}
 			}
 		}
+		Ok(())
 	}
 
 	async fn cancel(&mut self, local: bool) {
@@ -87,16 +204,26 @@ impl<'a> Executor<'a> {
 				if txn.cancel().await.is_err() {
 					self.err = true;
 				}
+				self.writes.clear();
 				self.txn = None;
 			}
 		}
 	}
 
+	/// Record that the current database has been written to
+	fn written(&mut self, opt: &Options) {
+		if let (Some(ns), Some(db)) = (opt.ns.as_deref(), opt.db.as_deref()) {
+			self.writes.push((ns.to_owned(), db.to_owned()));
+		}
+	}
+
 	fn buf_cancel(&self, v: Response) -> Response {
 		Response {
 			sql: v.sql,
 			time: v.time,
 			result: Err(Error::QueryCancelled),
+			cursor: None,
+			truncated: false,
 		}
 	}
 
@@ -109,47 +236,140 @@ impl<'a> Executor<'a> {
 					Ok(_) => Err(Error::QueryNotExecuted),
 					Err(e) => Err(e),
 				},
+				cursor: None,
+				truncated: false,
 			},
 			_ => v,
 		}
 	}
 
-	async fn set_ns(&self, ctx: &mut Context<'_>, opt: &mut Options, ns: &str) {
+	async fn output(&self, out: &mut Vec<Response>, res: Response) {
+		match &self.chn {
+			// Send the response as soon as it is ready
+			Some(chn) => {
+				let _ = chn.send(Chunk::Response(res)).await;
+			}
+			// Otherwise store the response for later
+			None => out.push(res),
+		}
+	}
+
+	/// Check if the records of a statement are sent as soon as they are ready
+	fn streams(&self, stm: &Statement, opt: &Options, local: bool) -> bool {
+		match (stm, &self.chn) {
+			(Statement::Select(v), Some(_)) => local && self.results.is_none() && v.streamable(opt),
+			_ => false,
+		}
+	}
+
+	async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement,
+		streams: bool,
+	) -> Result<Value, Error> {
+		match (stm, &self.chn) {
+			// Send the records to the channel
+			(Statement::Select(v), Some(chn)) if streams => {
+				v.stream(ctx, opt, &self.txn(), chn.clone()).await
+			}
+			// Return the records in the response
+			_ => stm.compute(ctx, opt, &self.txn(), None).await,
+		}
+	}
+
+	async fn set_ns(&mut self, ctx: &mut Context<'_>, opt: &mut Options, ns: &str) {
 		let mut session = ctx.value("session").unwrap_or(&Value::None).clone();
 		session.put(NS.as_ref(), ns.to_owned().into());
 		ctx.add_value(String::from("session"), session);
 		opt.ns = Some(ns.into());
+		self.ns = Some(ns.to_owned());
 	}
 
-	async fn set_db(&self, ctx: &mut Context<'_>, opt: &mut Options, db: &str) {
+	async fn set_db(&mut self, ctx: &mut Context<'_>, opt: &mut Options, db: &str) {
 		let mut session = ctx.value("session").unwrap_or(&Value::None).clone();
 		session.put(DB.as_ref(), db.to_owned().into());
 		ctx.add_value(String::from("session"), session);
 		opt.db = Some(db.into());
+		self.db = Some(db.to_owned());
 	}
 
 	pub async fn execute(
+		&mut self,
+		ctx: Context<'_>,
+		opt: Options,
+		qry: Query,
+	) -> Result<Vec<Response>, Error> {
+		// Panics are not recovered by default
+		if !self.recover {
+			return self.process(ctx, opt, qry).await;
+		}
+		// Get the request id for the logs
+		let id = match ctx.value("session").map(|v| v.pick(ID.as_ref())) {
+			Some(Value::Strand(v)) => v.0,
+			_ => String::from("-"),
+		};
+		// Process the statements, catching any panic
+		match AssertUnwindSafe(self.process(ctx, opt, qry)).catch_unwind().await {
+			Ok(res) => res,
+			Err(err) => {
+				// Get the details of the panic
+				let msg = recover::message(err.as_ref());
+				let trace = recover::trace().unwrap_or_default();
+				error!(target: LOG, "Query for request {} panicked: {}\n{}", id, msg, trace);
+				// Cancel any running transaction
+				self.cancel(true).await;
+				// Return an internal error
+				Err(Error::Panic(msg))
+			}
+		}
+	}
+
+	async fn process(
 		&mut self,
 		mut ctx: Context<'_>,
 		mut opt: Options,
 		qry: Query,
 	) -> Result<Vec<Response>, Error> {
-		// Initialise buffer of responses
-		let mut buf: Vec<Response> = vec![];
 		// Initialise array of responses
 		let mut out: Vec<Response> = vec![];
+		// Start a trace span for the query
+		#[cfg(feature = "telemetry")]
+		let tracer = global::tracer(TRACER);
+		#[cfg(feature = "telemetry")]
+		let trc = opentelemetry::Context::current_with_span(tracer.start("query"));
 		// Process all statements in query
 		for stm in qry.iter() {
 			// Log the statement
 			debug!(target: LOG, "Executing: {}", stm);
+			// Start a trace span for the statement
+			#[cfg(feature = "telemetry")]
+			let mut span = {
+				let mut span = tracer.start_with_context("statement", &trc);
+				span.set_attribute(KeyValue::new("db.statement.type", stm.kind()));
+				span.set_attribute(KeyValue::new(
+					"db.namespace",
+					opt.ns.as_deref().unwrap_or("").to_owned(),
+				));
+				span.set_attribute(KeyValue::new(
+					"db.database",
+					opt.db.as_deref().unwrap_or("").to_owned(),
+				));
+				span
+			};
 			// Reset errors
 			if self.txn.is_none() {
 				self.err = false;
 			}
 			// Get the statement start time
 			let now = Instant::now();
+			// Check if the records are sent separately
+			let mut streamed = false;
 			// Process a single statement
 			let res = match stm {
+				// Check the statement is allowed by the user roles
+				stm if !self.allowed(stm) => Err(Error::QueryPermissions),
 				// Specify runtime options
 				Statement::Option(stm) => {
 					// Selected DB?
@@ -163,41 +383,111 @@ impl<'a> Executor<'a> {
 						"TABLES" => opt = opt.tables(stm.what),
 						"IMPORT" => opt = opt.import(stm.what),
 						"FORCE" => opt = opt.force(stm.what),
+						"CASCADE" => opt = opt.cascade(stm.what),
+						"DELETED" => opt = opt.deleted(stm.what),
 						"DEBUG" => opt = opt.debug(stm.what),
+						"ORDERED" => opt = opt.ordered(stm.what),
+						"DEPTH" => match stm.size {
+							Some(v) => opt = opt.depth(v),
+							None => break,
+						},
 						_ => break,
 					}
 					// Continue
 					continue;
 				}
 				// Begin a new transaction
-				Statement::Begin(_) => {
-					self.begin(true).await;
+				Statement::Begin(stm) => {
+					self.begin(!stm.readonly).await;
 					continue;
 				}
 				// Cancel a running transaction
 				Statement::Cancel(_) => {
 					self.cancel(true).await;
-					buf = buf.into_iter().map(|v| self.buf_cancel(v)).collect();
-					out.append(&mut buf);
+					let res: Vec<_> = self.buf.drain(..).collect();
+					let res: Vec<_> = res.into_iter().map(|v| self.buf_cancel(v)).collect();
+					for v in res {
+						self.output(&mut out, v).await;
+					}
 					self.txn = None;
 					continue;
 				}
 				// Commit a running transaction
 				Statement::Commit(_) => {
-					self.commit(true).await;
-					buf = buf.into_iter().map(|v| self.buf_commit(v)).collect();
-					out.append(&mut buf);
+					// Any failure is reported on the buffered responses
+					let _ = self.commit(true).await;
+					let res: Vec<_> = self.buf.drain(..).collect();
+					let res: Vec<_> = res.into_iter().map(|v| self.buf_commit(v)).collect();
+					for v in res {
+						self.output(&mut out, v).await;
+					}
 					self.txn = None;
 					continue;
 				}
+				// Create a savepoint in a running transaction
+				Statement::Savepoint(stm) => match (&self.txn, self.err) {
+					(None, _) => Err(Error::SavepointNotAllowed),
+					(Some(_), true) => Err(Error::QueryNotExecuted),
+					(Some(txn), false) => {
+						txn.lock().await.savepoint();
+						self.savepoints.push((stm.name.to_raw(), self.buf.len()));
+						Ok(Value::None)
+					}
+				},
+				// Revert the changes made since a savepoint
+				Statement::Rollback(stm) => {
+					match self.savepoints.iter().rposition(|(v, _)| v == &stm.name.0) {
+						None => Err(Error::SavepointNotFound {
+							name: stm.name.to_raw(),
+						}),
+						Some(pos) => {
+							let txn = self.txn();
+							let mut txn = txn.lock().await;
+							match txn.rollback_to(pos).await {
+								Ok(_) => {
+									// The statements since the savepoint were rolled back
+									for v in self.buf.iter_mut().skip(self.savepoints[pos].1) {
+										if v.result.is_ok() {
+											v.result = Err(Error::QueryCancelled);
+										}
+									}
+									self.savepoints.truncate(pos + 1);
+									// The transaction can continue
+									self.err = false;
+									Ok(Value::None)
+								}
+								Err(e) => Err(e),
+							}
+						}
+					}
+				}
+				// Remove a savepoint, keeping its changes
+				Statement::Release(stm) => {
+					match self.savepoints.iter().rposition(|(v, _)| v == &stm.name.0) {
+						None => Err(Error::SavepointNotFound {
+							name: stm.name.to_raw(),
+						}),
+						Some(_) if self.err => Err(Error::QueryNotExecuted),
+						Some(pos) => {
+							self.txn().lock().await.release(pos);
+							self.savepoints.truncate(pos);
+							Ok(Value::None)
+						}
+					}
+				}
 				// Switch to a different NS or DB
 				Statement::Use(stm) => {
 					if let Some(ref ns) = stm.ns {
+						let ns = &opt.name(ns);
 						match &*opt.auth {
 							Auth::No => self.set_ns(&mut ctx, &mut opt, ns).await,
 							Auth::Kv => self.set_ns(&mut ctx, &mut opt, ns).await,
-							Auth::Ns(v) if v == ns => self.set_ns(&mut ctx, &mut opt, ns).await,
-							Auth::Db(v, _) if v == ns => self.set_ns(&mut ctx, &mut opt, ns).await,
+							Auth::Ns(v) if &opt.name(v) == ns => {
+								self.set_ns(&mut ctx, &mut opt, ns).await
+							}
+							Auth::Db(v, _) if &opt.name(v) == ns => {
+								self.set_ns(&mut ctx, &mut opt, ns).await
+							}
 							_ => {
 								opt.ns = None;
 								return Err(Error::NsNotAllowed {
@@ -207,11 +497,14 @@ impl<'a> Executor<'a> {
 						}
 					}
 					if let Some(ref db) = stm.db {
+						let db = &opt.name(db);
 						match &*opt.auth {
 							Auth::No => self.set_db(&mut ctx, &mut opt, db).await,
 							Auth::Kv => self.set_db(&mut ctx, &mut opt, db).await,
 							Auth::Ns(_) => self.set_db(&mut ctx, &mut opt, db).await,
-							Auth::Db(_, v) if v == db => self.set_db(&mut ctx, &mut opt, db).await,
+							Auth::Db(_, v) if &opt.name(v) == db => {
+								self.set_db(&mut ctx, &mut opt, db).await
+							}
 							_ => {
 								opt.db = None;
 								return Err(Error::DbNotAllowed {
@@ -222,6 +515,14 @@ impl<'a> Executor<'a> {
 					}
 					Ok(Value::None)
 				}
+				// Variable types are checked before execution
+				Statement::Declare(_) => Ok(Value::None),
+				// Prevent overriding the session params
+				Statement::Set(stm) if PROTECTED_PARAM_NAMES.contains(&stm.name.as_str()) => {
+					Err(Error::InvalidParam {
+						name: stm.name.to_owned(),
+					})
+				}
 				// Process param definition statements
 				Statement::Set(stm) => {
 					// Create a transaction
@@ -237,15 +538,24 @@ impl<'a> Executor<'a> {
 							//
 							match res {
 								Ok(val) => {
-									// Set the parameter
-									ctx.add_value(stm.name.to_owned(), val);
 									// Finalise transaction
-									match stm.writeable() {
-										true => self.commit(loc).await,
-										false => self.cancel(loc).await,
+									let res = match stm.writeable() {
+										true => {
+											self.written(&opt);
+											self.commit(loc).await
+										}
+										false => {
+											self.cancel(loc).await;
+											Ok(())
+										}
+									};
+									// Record and set the parameter
+									if res.is_ok() {
+										self.vars.insert(stm.name.to_owned(), val.clone());
+										ctx.add_value(stm.name.to_owned(), val);
 									}
 									// Return nothing
-									Ok(Value::None)
+									res.map(|_| Value::None)
 								}
 								Err(err) => {
 									// Cancel transaction
@@ -257,14 +567,16 @@ impl<'a> Executor<'a> {
 						}
 					}
 				}
-				// Process all other normal statements
-				_ => match self.err {
-					// This transaction has failed
-					true => Err(Error::QueryNotExecuted),
-					// Compute the statement normally
-					false => {
+				// Process dry run statements
+				Statement::Dry(stm) => match self.txn {
+					// The changes can't be rolled back separately
+					Some(_) => Err(Error::DryRunNotAllowed {
+						message: String::from("Dry runs can not be used within a transaction"),
+					}),
+					// Compute the statement and roll it back
+					None => {
 						// Create a transaction
-						let loc = self.begin(stm.writeable()).await;
+						let loc = self.begin(true).await;
 						// Check the transaction
 						match self.err {
 							// We failed to create a transaction
@@ -289,34 +601,141 @@ impl<'a> Executor<'a> {
 									// There is no timeout clause
 									None => stm.compute(&ctx, &opt, &self.txn(), None).await,
 								};
-								// Finalise transaction
-								match &res {
-									Ok(_) => match stm.writeable() {
-										true => self.commit(loc).await,
-										false => self.cancel(loc).await,
-									},
-									Err(_) => self.cancel(loc).await,
-								};
+								// Never commit the changes
+								self.cancel(loc).await;
 								// Return the result
 								res
 							}
 						}
 					}
 				},
+				// Process all other normal statements
+				_ => match self.err {
+					// This transaction has failed
+					true => Err(Error::QueryNotExecuted),
+					// Compute the statement normally
+					false => {
+						// Count the attempts to run the statement
+						let mut attempt = 0;
+						loop {
+							// Create a transaction
+							let loc = self.begin(stm.writeable()).await;
+							// Check if the records can be sent separately
+							streamed = self.streams(stm, &opt, loc);
+							// Check the transaction
+							let res = match self.err {
+								// We failed to create a transaction
+								true => Err(Error::TxFailure),
+								// The transaction began successfully
+								false => {
+									// Process the statement
+									let res = match stm.timeout() {
+										// There is a timeout clause
+										Some(timeout) => {
+											// Set statement timeout
+											let mut ctx = Context::new(&ctx);
+											ctx.add_timeout(timeout);
+											// Process the statement
+											let res = self.compute(&ctx, &opt, stm, streamed).await;
+											// Catch statement timeout
+											match ctx.is_timedout() {
+												true => Err(Error::QueryTimedout),
+												false => res,
+											}
+										}
+										// There is no timeout clause
+										None => self.compute(&ctx, &opt, stm, streamed).await,
+									};
+									// Finalise transaction
+									match res {
+										Ok(v) => match stm.writeable() {
+											true => {
+												self.written(&opt);
+												self.commit(loc).await.map(|_| v)
+											}
+											false => {
+												self.cancel(loc).await;
+												Ok(v)
+											}
+										},
+										Err(e) => {
+											self.cancel(loc).await;
+											Err(e)
+										}
+									}
+								}
+							};
+							// Retry the statement if the transaction conflicted
+							match res {
+								Err(e)
+									if loc
+										&& !streamed && e.is_retriable()
+										&& attempt < self.retries =>
+								{
+									trace!(target: LOG, "Retrying statement after a transaction conflict");
+									// Back off before each retry
+									Delay::new(TX_RETRY_BACKOFF * (1 << attempt.min(10))).await;
+									// Run the statement in a new transaction
+									attempt += 1;
+									self.err = false;
+								}
+								// Return the result
+								res => break res,
+							}
+						}
+					}
+				},
 			};
 			// Get the statement end time
 			let dur = now.elapsed();
 			// Produce the response
 			let res = match res {
+				// The result exceeds the maximum number of records
+				Ok(Value::Array(mut v)) if self.results.map_or(false, |max| v.len() > max) => {
+					// Drop the records over the limit
+					v.truncate(self.results.unwrap_or_default());
+					// Produce the response
+					Response {
+						sql: match opt.debug {
+							true => Some(format!("{}", stm)),
+							false => None,
+						},
+						time: dur,
+						cursor: match &stm {
+							Statement::Select(stm) => stm.resume(&v),
+							_ => None,
+						},
+						result: Ok(Value::Array(v)),
+						truncated: true,
+					}
+				}
 				Ok(v) => Response {
 					sql: match opt.debug {
 						true => Some(format!("{}", stm)),
 						false => None,
 					},
 					time: dur,
+					cursor: match &stm {
+						Statement::Select(stm) => stm.next(&v),
+						_ => None,
+					},
 					result: Ok(v),
+					truncated: false,
 				},
 				Err(e) => {
+					// Don't reveal which namespaces and databases exist to anonymous users
+					let e = match e {
+						Error::NsNotFound {
+							..
+						} if opt.auth.is_no() => Error::QueryPermissions,
+						Error::DbNotFound {
+							..
+						} if opt.auth.is_no() => Error::QueryPermissions,
+						e => e,
+					};
+					// Record the error in the trace
+					#[cfg(feature = "telemetry")]
+					span.record_error(&e);
 					// Produce the response
 					let res = Response {
 						sql: match opt.debug {
@@ -325,6 +744,8 @@ impl<'a> Executor<'a> {
 						},
 						time: dur,
 						result: Err(e),
+						cursor: None,
+						truncated: false,
 					};
 					// Mark the error
 					self.err = true;
@@ -336,15 +757,117 @@ impl<'a> Executor<'a> {
 			match self.txn {
 				Some(_) => match stm {
 					Statement::Output(_) => {
-						buf.clear();
-						buf.push(res);
+						self.buf.clear();
+						self.buf.push(res);
 					}
-					_ => buf.push(res),
+					_ => self.buf.push(res),
 				},
-				None => out.push(res),
+				// The records were already sent
+				None if streamed && res.result.is_ok() => {
+					if let Some(chn) = &self.chn {
+						let _ = chn.send(Chunk::Done(res)).await;
+					}
+				}
+				None => self.output(&mut out, res).await,
 			}
 		}
 		// Return responses
 		Ok(out)
 	}
 }
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::kvs::Faults;
+	use crate::sql::parse;
+	use crate::sql::test::Parse;
+	use std::sync::atomic::Ordering;
+	use std::sync::Arc;
+
+	fn options() -> Options {
+		let mut opt = Options::new(Auth::Kv);
+		opt.ns = Some("test".into());
+		opt.db = Some("test".into());
+		opt
+	}
+
+	fn faults(conflicts: usize, panics: usize) -> Arc<Faults> {
+		let faults = Faults::default();
+		faults.conflicts.store(conflicts, Ordering::SeqCst);
+		faults.panics.store(panics, Ordering::SeqCst);
+		Arc::new(faults)
+	}
+
+	#[tokio::test]
+	async fn conflict_is_not_retried_by_default() {
+		let flt = faults(1, 0);
+		let kvs = Datastore::with_faults(flt.clone()).await.unwrap();
+		let ast = parse("CREATE person:test").unwrap();
+		let res = Executor::new(&kvs).execute(Context::default(), options(), ast).await.unwrap();
+		assert!(matches!(res[0].result, Err(Error::TxConflict)));
+		assert_eq!(flt.conflicts.load(Ordering::SeqCst), 0);
+	}
+
+	#[tokio::test]
+	async fn conflict_succeeds_on_retry() {
+		let flt = faults(2, 0);
+		let kvs = Datastore::with_faults(flt.clone()).await.unwrap();
+		let mut exe = Executor::new(&kvs).with_max_retries(3);
+		let ast = parse("CREATE person:test").unwrap();
+		let res = exe.execute(Context::default(), options(), ast).await.unwrap();
+		assert!(res[0].result.is_ok());
+		assert_eq!(flt.conflicts.load(Ordering::SeqCst), 0);
+		// The record was committed
+		let ast = parse("SELECT * FROM person:test").unwrap();
+		let res = Executor::new(&kvs).execute(Context::default(), options(), ast).await.unwrap();
+		let val = Value::parse("[{ id: person:test }]");
+		assert_eq!(res[0].output().unwrap(), &val);
+	}
+
+	#[tokio::test]
+	async fn conflict_exhausts_retries() {
+		let flt = faults(5, 0);
+		let kvs = Datastore::with_faults(flt.clone()).await.unwrap();
+		let mut exe = Executor::new(&kvs).with_max_retries(2);
+		let ast = parse("CREATE person:test").unwrap();
+		let res = exe.execute(Context::default(), options(), ast).await.unwrap();
+		assert!(matches!(res[0].result, Err(Error::TxConflict)));
+		// The statement was run once, and retried twice
+		assert_eq!(flt.conflicts.load(Ordering::SeqCst), 2);
+		// The record was never committed
+		flt.conflicts.store(0, Ordering::SeqCst);
+		let ast = parse("SELECT * FROM person:test").unwrap();
+		let res = Executor::new(&kvs).execute(Context::default(), options(), ast).await.unwrap();
+		let val = Value::parse("[]");
+		assert_eq!(res[0].output().unwrap(), &val);
+	}
+
+	#[tokio::test]
+	async fn panic_is_recovered_and_rolled_back() {
+		let flt = faults(0, 1);
+		let kvs = Datastore::with_faults(flt.clone()).await.unwrap();
+		let mut exe = Executor::new(&kvs).with_panic_recovery(true);
+		let ast = parse("BEGIN; CREATE person:test; SELECT * FROM person; COMMIT;").unwrap();
+		let res = exe.execute(Context::default(), options(), ast).await;
+		assert!(matches!(res, Err(Error::Panic(ref v)) if v == "Simulated panic"));
+		assert!(exe.txn.is_none());
+		// The record was never committed
+		let ast = parse("SELECT * FROM person:test").unwrap();
+		let res = Executor::new(&kvs).execute(Context::default(), options(), ast).await.unwrap();
+		let val = Value::parse("[]");
+		assert_eq!(res[0].output().unwrap(), &val);
+	}
+
+	#[tokio::test]
+	async fn conflict_in_transaction_is_not_retried() {
+		let flt = faults(1, 0);
+		let kvs = Datastore::with_faults(flt.clone()).await.unwrap();
+		let mut exe = Executor::new(&kvs).with_max_retries(3);
+		let ast = parse("BEGIN; CREATE person:test; COMMIT;").unwrap();
+		let res = exe.execute(Context::default(), options(), ast).await.unwrap();
+		assert!(matches!(res[0].result, Err(Error::QueryNotExecuted)));
+		assert_eq!(flt.conflicts.load(Ordering::SeqCst), 0);
+	}
+}
@@ -1,5 +1,6 @@
 use crate::ctx::Canceller;
 use crate::ctx::Context;
+use crate::dbs::Chunk;
 use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::dbs::Transaction;
@@ -9,14 +10,15 @@ use crate::err::Error;
 use crate::sql::array::Array;
 use crate::sql::edges::Edges;
 use crate::sql::field::Field;
-use crate::sql::part::Part;
+use crate::sql::paths::ID;
 use crate::sql::range::Range;
 use crate::sql::table::Table;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
 use async_recursion::async_recursion;
+use channel::Sender;
 use std::cmp::Ordering;
-use std::collections::BTreeMap;
+use std::collections::{BTreeMap, BTreeSet};
 use std::mem;
 
 pub enum Iterable {
@@ -24,6 +26,7 @@ pub enum Iterable {
 	Table(Table),
 	Thing(Thing),
 	Range(Range),
+	After(Thing),
 	Edges(Edges),
 	Mergeable(Thing, Value),
 	Relatable(Thing, Thing, Thing),
@@ -51,6 +54,10 @@ pub struct Iterator {
 	results: Vec<Value>,
 	// Iterator input values
 	entries: Vec<Iterable>,
+	// Iterator output channel
+	chn: Option<Sender<Chunk>>,
+	// Iterator streamed records
+	sent: usize,
 }
 
 impl Iterator {
@@ -59,6 +66,12 @@ impl Iterator {
 		Self::default()
 	}
 
+	// Sends each record to a channel as soon as it is ready
+	pub fn with_channel(mut self, chn: Sender<Chunk>) -> Self {
+		self.chn = Some(chn);
+		self
+	}
+
 	// Prepares a value for processing
 	pub fn ingest(&mut self, val: Iterable) {
 		self.entries.push(val)
@@ -87,6 +100,8 @@ impl Iterator {
 		self.output_split(&ctx, opt, txn, stm).await?;
 		// Process any GROUP clause
 		self.output_group(&ctx, opt, txn, stm).await?;
+		// Process any DISTINCT clause
+		self.output_distinct(&ctx, opt, txn, stm).await?;
 		// Process any ORDER clause
 		self.output_order(&ctx, opt, txn, stm).await?;
 		// Process any START clause
@@ -95,6 +110,8 @@ impl Iterator {
 		self.output_limit(&ctx, opt, txn, stm).await?;
 		// Process any FETCH clause
 		self.output_fetch(&ctx, opt, txn, stm).await?;
+		// Process any VALUE clause
+		self.output_value(&ctx, opt, txn, stm).await?;
 		// Output the results
 		Ok(mem::take(&mut self.results).into())
 	}
@@ -230,12 +247,28 @@ impl Iterator {
 	}
 
 	#[inline]
-	async fn output_order(
+	async fn output_distinct(
 		&mut self,
 		_ctx: &Context<'_>,
 		_opt: &Options,
 		_txn: &Transaction,
 		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		if stm.distinct() {
+			// Keep the first of each identical result
+			let mut seen = BTreeSet::new();
+			self.results.retain(|v| seen.insert(v.clone()));
+		}
+		Ok(())
+	}
+
+	#[inline]
+	async fn output_order(
+		&mut self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		_txn: &Transaction,
+		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		if let Some(orders) = stm.order() {
 			// Sort the full result set
@@ -264,6 +297,11 @@ impl Iterator {
 				}
 				Ordering::Equal
 			})
+		} else if opt.ordered && matches!(stm, Statement::Select(_)) {
+			// Sort the full result set by record id
+			self.results.sort_by(|a, b| {
+				a.pick(ID.as_ref()).partial_cmp(&b.pick(ID.as_ref())).unwrap_or(Ordering::Equal)
+			})
 		}
 		Ok(())
 	}
@@ -308,27 +346,27 @@ impl Iterator {
 			for fetch in &fetchs.0 {
 				// Loop over each value
 				for obj in &mut self.results {
-					// Get the value at the path
-					let val = obj.get(ctx, opt, txn, fetch).await?;
-					// Set the value at the path
-					match val {
-						Value::Array(v) => {
-							// Fetch all remote records
-							let val = Value::Array(v).get(ctx, opt, txn, &[Part::Any]).await?;
-							// Set the value at the path
-							obj.set(ctx, opt, txn, fetch, val).await?;
-						}
-						Value::Thing(v) => {
-							// Fetch all remote records
-							let val = Value::Thing(v).get(ctx, opt, txn, &[Part::All]).await?;
-							// Set the value at the path
-							obj.set(ctx, opt, txn, fetch, val).await?;
-						}
-						_ => {
-							// Set the value at the path
-							obj.set(ctx, opt, txn, fetch, val).await?;
-						}
-					}
+					// Fetch the linked records at the path
+					obj.fetch(ctx, opt, txn, fetch).await?;
+				}
+			}
+		}
+		Ok(())
+	}
+
+	#[inline]
+	async fn output_value(
+		&mut self,
+		_ctx: &Context<'_>,
+		_opt: &Options,
+		_txn: &Transaction,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		if stm.value() {
+			if let Some(idiom) = stm.expr().and_then(|v| v.single()) {
+				// Replace each object with the selected field value
+				for obj in &mut self.results {
+					*obj = obj.pick(&idiom);
 				}
 			}
 		}
@@ -411,7 +449,7 @@ impl Iterator {
 				let aproc = async {
 					// Process all processed values
 					while let Ok(r) = vals.recv().await {
-						self.result(r, stm);
+						self.result(ctx, opt, txn, r, stm).await;
 					}
 					// Shutdown the executor
 					let _ = end.send(()).await;
@@ -450,21 +488,32 @@ impl Iterator {
 		};
 		// Setup a new document
 		let mut doc = Document::new(thg, &val.0, val.1);
-		// Process the document
-		let res = match stm {
-			Statement::Select(_) => doc.select(ctx, opt, txn, stm).await,
-			Statement::Create(_) => doc.create(ctx, opt, txn, stm).await,
-			Statement::Update(_) => doc.update(ctx, opt, txn, stm).await,
-			Statement::Relate(_) => doc.relate(ctx, opt, txn, stm).await,
-			Statement::Delete(_) => doc.delete(ctx, opt, txn, stm).await,
-			Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
+		// Decrypt the encrypted fields
+		let res = match doc.decrypt(opt, txn).await {
+			Err(e) => Err(e),
+			// Process the document
+			Ok(_) => match stm {
+				Statement::Select(_) => doc.select(ctx, opt, txn, stm).await,
+				Statement::Create(_) => doc.create(ctx, opt, txn, stm).await,
+				Statement::Update(_) => doc.update(ctx, opt, txn, stm).await,
+				Statement::Relate(_) => doc.relate(ctx, opt, txn, stm).await,
+				Statement::Delete(_) => doc.delete(ctx, opt, txn, stm).await,
+				Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
+			},
 		};
 		// Process the result
-		self.result(res, stm);
+		self.result(ctx, opt, txn, res, stm).await;
 	}
 
 	// Accept a processed record result
-	fn result(&mut self, res: Result<Value, Error>, stm: &Statement<'_>) {
+	async fn result(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		res: Result<Value, Error>,
+		stm: &Statement<'_>,
+	) {
 		// Process the result
 		match res {
 			Err(Error::Ignore) => {
@@ -475,10 +524,13 @@ impl Iterator {
 				self.run.cancel();
 				return;
 			}
+			Ok(v) if self.chn.is_some() => {
+				return self.stream(ctx, opt, txn, v, stm).await;
+			}
 			Ok(v) => self.results.push(v),
 		}
 		// Check if we can exit
-		if stm.group().is_none() && stm.order().is_none() {
+		if stm.group().is_none() && stm.order().is_none() && !stm.distinct() {
 			if let Some(l) = stm.limit() {
 				if let Some(s) = stm.start() {
 					if self.results.len() == l.0 + s.0 {
@@ -490,4 +542,48 @@ impl Iterator {
 			}
 		}
 	}
+
+	// Send a processed record to the channel
+	async fn stream(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		val: Value,
+		stm: &Statement<'_>,
+	) {
+		// Count the records which have been processed
+		self.sent += 1;
+		// Skip the records before any START clause
+		let start = stm.start().map_or(0, |v| v.0);
+		if self.sent <= start {
+			return;
+		}
+		// Drop the records after any LIMIT clause
+		if let Some(l) = stm.limit() {
+			if self.sent > start + l.0 {
+				return;
+			}
+			if self.sent == start + l.0 {
+				self.run.cancel();
+			}
+		}
+		// Process any FETCH and VALUE clauses on the record
+		self.results = vec![val];
+		let res = match self.output_fetch(ctx, opt, txn, stm).await {
+			Ok(_) => self.output_value(ctx, opt, txn, stm).await,
+			Err(e) => Err(e),
+		};
+		if let Err(e) = res {
+			self.error = Some(e);
+			self.run.cancel();
+			return;
+		}
+		// Send the record as soon as it is ready
+		if let (Some(chn), Some(v)) = (&self.chn, self.results.pop()) {
+			if chn.send(Chunk::Record(v)).await.is_err() {
+				self.run.cancel();
+			}
+		}
+	}
 }
@@ -0,0 +1,294 @@
+use crate::dbs::Session;
+use crate::kvs::Datastore;
+use crate::sql::data::Data;
+use crate::sql::number::Number;
+use crate::sql::output::Output;
+use crate::sql::query::Query;
+use crate::sql::statement::{Statement, Statements};
+use crate::sql::statements::InsertStatement;
+use crate::sql::table::Table;
+use crate::sql::value::Value;
+use crate::sql::Object;
+use std::str::FromStr;
+use std::time::Duration;
+use trice::Instant;
+
+/// The format of the rows which are imported by a [`Loader`].
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub enum Format {
+	/// Each line is a JSON object
+	Ndjson,
+	/// Each line is a comma-separated row, after a header line of field names
+	Csv,
+}
+
+/// The result of importing a single batch of rows.
+#[derive(Debug)]
+pub struct Batch {
+	/// The number of this batch, starting from 1
+	pub batch: usize,
+	/// The first line of the input in this batch
+	pub start: usize,
+	/// The last line of the input in this batch
+	pub end: usize,
+	/// The number of rows in this batch
+	pub rows: usize,
+	/// The time taken to process this batch
+	pub time: Duration,
+	/// Any error which prevented this batch from being imported
+	pub error: Option<String>,
+}
+
+impl From<Batch> for Value {
+	fn from(v: Batch) -> Value {
+		let mut out = map! {
+			String::from("batch") => v.batch.into(),
+			String::from("start") => v.start.into(),
+			String::from("end") => v.end.into(),
+			String::from("rows") => v.rows.into(),
+			String::from("time") => format!("{:?}", v.time).into(),
+		};
+		match v.error {
+			Some(e) => {
+				out.insert(String::from("status"), "ERR".into());
+				out.insert(String::from("detail"), e.into());
+			}
+			None => {
+				out.insert(String::from("status"), "OK".into());
+			}
+		}
+		Value::Object(Object(out))
+	}
+}
+
+/// Imports lines of NDJSON or CSV data into a table, committing the rows in batches.
+///
+/// Each batch is inserted in its own transaction, using the authentication of the current
+/// session, so that table and field permissions, and schema validation, are checked as for
+/// an `INSERT` statement. If any row in a batch is malformed, or can not be inserted, then
+/// the whole batch is skipped, and the error is reported in the [`Batch`] result, but later
+/// batches are still imported.
+pub struct Loader<'a> {
+	kvs: &'a Datastore,
+	sess: Session,
+	tb: String,
+	fmt: Format,
+	size: usize,
+	strict: bool,
+	head: Option<Vec<String>>,
+	line: usize,
+	batch: usize,
+	start: usize,
+	rows: Vec<Value>,
+	error: Option<String>,
+}
+
+impl<'a> Loader<'a> {
+	pub(crate) fn new(
+		kvs: &'a Datastore,
+		sess: &Session,
+		tb: &str,
+		fmt: Format,
+		size: usize,
+		strict: bool,
+	) -> Loader<'a> {
+		Loader {
+			kvs,
+			sess: sess.clone(),
+			tb: tb.to_owned(),
+			fmt,
+			size: size.max(1),
+			strict,
+			head: None,
+			line: 0,
+			batch: 0,
+			start: 1,
+			rows: vec![],
+			error: None,
+		}
+	}
+
+	/// Process a single line of input, returning the result of
+	/// the batch if this line completed a batch of rows
+	pub async fn push(&mut self, line: &str) -> Option<Batch> {
+		// Increment the line number
+		self.line += 1;
+		// Ignore any trailing carriage return
+		let line = line.strip_suffix('\r').unwrap_or(line);
+		// Ignore any empty lines
+		if line.trim().is_empty() {
+			return None;
+		}
+		// The first line of CSV specifies the field names
+		if self.fmt == Format::Csv && self.head.is_none() {
+			match csv(line) {
+				Ok(v) => self.head = Some(v),
+				Err(e) => self.fail(e),
+			}
+			self.start = self.line + 1;
+			return None;
+		}
+		// Parse the line as a row
+		let row = match &self.head {
+			Some(head) => csv(line).and_then(|v| row(head, v)),
+			None => ndjson(line),
+		};
+		// Store the row, or the first error
+		match row {
+			Ok(v) => self.rows.push(v),
+			Err(e) => {
+				self.rows.push(Value::None);
+				self.fail(e);
+			}
+		}
+		// Import the batch once it is full
+		match self.rows.len() >= self.size {
+			true => Some(self.flush().await),
+			false => None,
+		}
+	}
+
+	/// Import any remaining rows, returning the result of the final batch
+	pub async fn finish(mut self) -> Option<Batch> {
+		match self.rows.is_empty() && self.error.is_none() {
+			true => None,
+			false => Some(self.flush().await),
+		}
+	}
+
+	fn fail(&mut self, e: String) {
+		if self.error.is_none() {
+			self.error = Some(format!("Line {}: {}", self.line, e));
+		}
+	}
+
+	async fn flush(&mut self) -> Batch {
+		// Take the rows for this batch
+		let rows = std::mem::take(&mut self.rows);
+		let error = self.error.take();
+		// Track the batch details
+		let now = Instant::now();
+		self.batch += 1;
+		let start = std::mem::replace(&mut self.start, self.line + 1);
+		let count = rows.len();
+		// Insert the rows unless a row was malformed
+		let error = match error {
+			Some(e) => Some(e),
+			None => self.insert(rows).await.err(),
+		};
+		// Return the batch result
+		Batch {
+			batch: self.batch,
+			start,
+			end: self.line,
+			rows: count,
+			time: now.elapsed(),
+			error,
+		}
+	}
+
+	async fn insert(&self, rows: Vec<Value>) -> Result<(), String> {
+		// Insert all rows in a single statement
+		let stm = InsertStatement {
+			into: Table(self.tb.to_owned()),
+			data: Data::SingleExpression(Value::from(rows)),
+			output: Some(Output::None),
+			..InsertStatement::default()
+		};
+		// Process the statement as a query
+		let ast = Query(Statements(vec![Statement::Insert(stm)]));
+		let mut res = self.kvs.process(ast, &self.sess, None, self.strict).await;
+		// Check the result of the statement
+		match res.as_mut().map(|v| v.remove(0).result) {
+			Ok(Ok(_)) => Ok(()),
+			Ok(Err(e)) => Err(e.to_string()),
+			Err(e) => Err(e.to_string()),
+		}
+	}
+}
+
+// Parse a line of NDJSON as an object
+fn ndjson(line: &str) -> Result<Value, String> {
+	match crate::sql::json(line) {
+		Ok(v @ Value::Object(_)) => Ok(v),
+		Ok(_) => Err(String::from("Each row must be a JSON object")),
+		Err(e) => Err(e.to_string()),
+	}
+}
+
+// Combine a row of CSV cells with the field names
+fn row(head: &[String], cells: Vec<String>) -> Result<Value, String> {
+	// Check the number of cells in the row
+	if cells.len() != head.len() {
+		return Err(format!("Expected {} columns but found {}", head.len(), cells.len()));
+	}
+	// Empty cells are left unset
+	let mut out = Object::default();
+	for (k, v) in head.iter().zip(cells) {
+		if !v.is_empty() {
+			out.insert(k.to_owned(), cell(v));
+		}
+	}
+	Ok(Value::Object(out))
+}
+
+// Convert a CSV cell into a number where possible
+fn cell(v: String) -> Value {
+	match v.starts_with(|c: char| c.is_ascii_digit() || c == '-') {
+		true => match (i64::from_str(&v), f64::from_str(&v)) {
+			(Ok(n), _) => Value::Number(Number::Int(n)),
+			(_, Ok(n)) if n.is_finite() => Value::Number(Number::Float(n)),
+			_ => Value::from(v),
+		},
+		false => Value::from(v),
+	}
+}
+
+// Split a line of CSV into cells, allowing quoted cells
+fn csv(line: &str) -> Result<Vec<String>, String> {
+	let mut out = vec![];
+	let mut cur = String::new();
+	let mut chars = line.chars().peekable();
+	let mut quoted = false;
+	while let Some(c) = chars.next() {
+		match (quoted, c) {
+			// An escaped quote within a quoted cell
+			(true, '"') if chars.peek() == Some(&'"') => {
+				chars.next();
+				cur.push('"');
+			}
+			// The end of a quoted cell
+			(true, '"') => quoted = false,
+			// The start of a quoted cell
+			(false, '"') if cur.is_empty() => quoted = true,
+			// The end of a cell
+			(false, ',') => out.push(std::mem::take(&mut cur)),
+			// Any other character
+			(_, c) => cur.push(c),
+		}
+	}
+	// Check that all quotes were closed
+	if quoted {
+		return Err(String::from("Found an unterminated quoted value"));
+	}
+	out.push(cur);
+	Ok(out)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn csv_cells() {
+		let res = csv(r#"1,"Tobie, Morgan","say ""hello""",,3.5"#).unwrap();
+		assert_eq!(res, vec!["1", "Tobie, Morgan", r#"say "hello""#, "", "3.5"]);
+	}
+
+	#[test]
+	fn csv_unterminated() {
+		let res = csv(r#"1,"Tobie"#);
+		assert!(res.is_err());
+	}
+}
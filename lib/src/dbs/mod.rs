@@ -1,20 +1,28 @@
 mod auth;
+mod cache;
+pub(crate) mod cursor;
 mod executor;
 mod iterate;
 mod iterator;
+mod loader;
 mod options;
+pub(crate) mod recover;
 mod response;
 mod session;
+mod slow;
 mod statement;
 mod transaction;
 mod variables;
 
 pub use self::auth::*;
+pub(crate) use self::cache::*;
 pub use self::executor::*;
 pub use self::iterator::*;
+pub use self::loader::*;
 pub use self::options::*;
 pub use self::response::*;
 pub use self::session::*;
+pub(crate) use self::slow::*;
 pub use self::statement::*;
 pub use self::transaction::*;
 pub use self::variables::*;
@@ -29,3 +37,6 @@ pub use self::channel::*;
 pub(crate) mod test;
 
 pub const LOG: &str = "surrealdb::dbs";
+
+#[cfg(feature = "telemetry")]
+pub(crate) const TRACER: &str = "surrealdb";
@@ -21,6 +21,12 @@ pub struct Options {
 	pub auth: Arc<Auth>,
 	// How many subqueries have we gone into?
 	pub dive: usize,
+	// How many graph edges have we traversed?
+	pub hops: usize,
+	// How many graph edges can we traverse?
+	pub depth: usize,
+	// How many loop iterations can we run?
+	pub iterations: usize,
 	// Whether live queries are allowed?
 	pub live: bool,
 	// Should we debug query response SQL?
@@ -41,6 +47,18 @@ pub struct Options {
 	pub indexes: bool,
 	// Should we process function futures?
 	pub futures: bool,
+	// Should we delete the edges of deleted records?
+	pub cascade: bool,
+	// Should we process expired records?
+	pub expired: bool,
+	// Should we process soft deleted records?
+	pub deleted: bool,
+	// Is this a dry run which is never committed?
+	pub dry: bool,
+	// Are NS and DB names case-insensitive?
+	pub fold: bool,
+	// Should results be ordered by id by default?
+	pub ordered: bool,
 }
 
 impl Default for Options {
@@ -56,6 +74,9 @@ impl Options {
 			ns: None,
 			db: None,
 			dive: 0,
+			hops: 0,
+			depth: cnf::MAX_GRAPH_DEPTH,
+			iterations: cnf::MAX_LOOP_ITERATIONS,
 			live: false,
 			perms: true,
 			debug: false,
@@ -66,6 +87,12 @@ impl Options {
 			tables: true,
 			indexes: true,
 			futures: false,
+			cascade: true,
+			expired: false,
+			deleted: false,
+			dry: false,
+			fold: false,
+			ordered: false,
 			auth: Arc::new(auth),
 		}
 	}
@@ -80,6 +107,15 @@ impl Options {
 		self.db.as_ref().unwrap()
 	}
 
+	/// Get a NS or DB name as it is stored, which is
+	/// lowercase when names are case-insensitive
+	pub fn name(&self, v: &str) -> String {
+		match self.fold {
+			true => v.to_lowercase(),
+			false => v.to_owned(),
+		}
+	}
+
 	/// Create a new Options object for a subquery
 	pub fn dive(&self) -> Result<Options, Error> {
 		if self.dive < cnf::MAX_RECURSIVE_QUERIES {
@@ -95,6 +131,34 @@ impl Options {
 		}
 	}
 
+	/// Create a new Options object for a graph traversal
+	pub fn hop(&self) -> Result<Options, Error> {
+		if self.hops < self.depth {
+			Ok(Options {
+				auth: self.auth.clone(),
+				ns: self.ns.clone(),
+				db: self.db.clone(),
+				hops: self.hops + 1,
+				..*self
+			})
+		} else {
+			Err(Error::TooManyTraversals {
+				depth: self.depth,
+			})
+		}
+	}
+
+	/// Create a new Options object for a subquery
+	pub fn depth(&self, v: usize) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			depth: v,
+			..*self
+		}
+	}
+
 	/// Create a new Options object for a subquery
 	pub fn debug(&self, v: bool) -> Options {
 		Options {
@@ -172,6 +236,50 @@ impl Options {
 		}
 	}
 
+	/// Create a new Options object for a subquery
+	pub fn expired(&self, v: bool) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			expired: v,
+			..*self
+		}
+	}
+
+	/// Create a new Options object for a subquery
+	pub fn deleted(&self, v: bool) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			deleted: v,
+			..*self
+		}
+	}
+
+	/// Create a new Options object for a subquery
+	pub fn ordered(&self, v: bool) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			ordered: v,
+			..*self
+		}
+	}
+
+	/// Create a new Options object for a dry run
+	pub fn dry(&self, v: bool) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			dry: v,
+			..*self
+		}
+	}
+
 	/// Create a new Options object for a subquery
 	pub fn import(&self, v: bool) -> Options {
 		Options {
@@ -207,6 +315,17 @@ impl Options {
 		}
 	}
 
+	/// Create a new Options object for a subquery
+	pub fn cascade(&self, v: bool) -> Options {
+		Options {
+			auth: self.auth.clone(),
+			ns: self.ns.clone(),
+			db: self.db.clone(),
+			cascade: v,
+			..*self
+		}
+	}
+
 	/// Check whether realtime queries are supported
 	pub fn realtime(&self) -> Result<(), Error> {
 		if !self.live {
@@ -223,6 +342,27 @@ impl Options {
 		Ok(())
 	}
 
+	/// Check whether the authentication is for the selected NS / DB
+	pub fn owns(&self, level: Level) -> Result<(), Error> {
+		let ns = self.ns.as_deref();
+		let db = self.db.as_deref();
+		match &*self.auth {
+			Auth::Ns(v) | Auth::Db(v, _) | Auth::Sc(v, _, _) if ns != Some(&*self.name(v)) => {
+				Err(Error::NsNotAllowed {
+					ns: ns.unwrap_or_default().to_owned(),
+				})
+			}
+			Auth::Db(_, v) | Auth::Sc(_, v, _)
+				if matches!(level, Level::Db) && db != Some(&*self.name(v)) =>
+			{
+				Err(Error::DbNotAllowed {
+					db: db.unwrap_or_default().to_owned(),
+				})
+			}
+			_ => Ok(()),
+		}
+	}
+
 	/// Check whether the necessary NS / DB options have been set
 	pub fn needs(&self, level: Level) -> Result<(), Error> {
 		if self.ns.is_none() && matches!(level, Level::Ns | Level::Db) {
@@ -0,0 +1,41 @@
+use std::any::Any;
+use std::backtrace::Backtrace;
+use std::cell::RefCell;
+use std::sync::Once;
+
+// Ensures the panic hook is only installed once
+static HOOK: Once = Once::new();
+
+thread_local! {
+	// The stack trace of the last panic on this thread
+	static TRACE: RefCell<Option<String>> = RefCell::new(None);
+}
+
+/// Install a panic hook which records the stack trace of each panic
+///
+/// The previous panic hook is still run after the stack trace is recorded.
+pub(crate) fn install() {
+	HOOK.call_once(|| {
+		let prev = std::panic::take_hook();
+		std::panic::set_hook(Box::new(move |info| {
+			TRACE.with(|v| *v.borrow_mut() = Some(Backtrace::force_capture().to_string()));
+			prev(info);
+		}));
+	});
+}
+
+/// Take the stack trace of the last panic on this thread
+pub(crate) fn trace() -> Option<String> {
+	TRACE.with(|v| v.borrow_mut().take())
+}
+
+/// Get the message of a caught panic
+pub(crate) fn message(err: &(dyn Any + Send)) -> String {
+	if let Some(v) = err.downcast_ref::<&str>() {
+		return v.to_string();
+	}
+	if let Some(v) = err.downcast_ref::<String>() {
+		return v.to_owned();
+	}
+	String::from("Unknown panic")
+}
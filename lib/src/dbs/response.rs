@@ -11,6 +11,21 @@ pub struct Response {
 	pub sql: Option<String>,
 	pub time: Duration,
 	pub result: Result<Value, Error>,
+	/// A cursor which fetches the next page of results
+	pub cursor: Option<String>,
+	/// Whether records were dropped over the maximum result size
+	pub truncated: bool,
+}
+
+/// A part of a query response which is sent as soon as it is ready
+#[derive(Debug)]
+pub enum Chunk {
+	/// A single record which was produced by a statement
+	Record(Value),
+	/// The end of a statement which has sent each of its records
+	Done(Response),
+	/// The complete response of a statement
+	Response(Response),
 }
 
 impl Response {
@@ -33,8 +48,12 @@ impl From<Response> for Value {
 		let time = v.speed();
 		// Get the response status
 		let status = v.output().map_or_else(|_| "ERR", |_| "OK");
+		// Get the response cursor
+		let cursor = v.cursor;
+		// Get the response truncation
+		let truncated = v.truncated;
 		// Convert the response
-		match v.result {
+		let mut out = match v.result {
 			Ok(val) => match v.sql {
 				Some(sql) => Value::Object(Object(map! {
 					String::from("sql") => sql.into(),
@@ -54,14 +73,25 @@ impl From<Response> for Value {
 					String::from("time") => time.into(),
 					String::from("status") => status.into(),
 					String::from("detail") => err.to_string().into(),
+					String::from("code") => err.code().into(),
 				})),
 				None => Value::Object(Object(map! {
 					String::from("time") => time.into(),
 					String::from("status") => status.into(),
 					String::from("detail") => err.to_string().into(),
+					String::from("code") => err.code().into(),
 				})),
 			},
+		};
+		// Add the cursor for the next page
+		if let (Value::Object(out), Some(cursor)) = (&mut out, cursor) {
+			out.insert(String::from("cursor"), cursor.into());
+		}
+		// Mark whether the result was truncated
+		if let (Value::Object(out), true) = (&mut out, truncated) {
+			out.insert(String::from("truncated"), Value::True);
 		}
+		out
 	}
 }
 
@@ -70,41 +100,88 @@ impl Serialize for Response {
 	where
 		S: serde::Serializer,
 	{
+		// Count the cursor and truncated fields if present
+		let c = self.cursor.is_some() as usize + self.truncated as usize;
 		match &self.result {
 			Ok(v) => match &self.sql {
 				Some(s) => {
-					let mut val = serializer.serialize_struct("Response", 4)?;
+					let mut val = serializer.serialize_struct("Response", 4 + c)?;
 					val.serialize_field("sql", s.as_str())?;
 					val.serialize_field("time", self.speed().as_str())?;
 					val.serialize_field("status", "OK")?;
 					val.serialize_field("result", v)?;
+					if let Some(cursor) = &self.cursor {
+						val.serialize_field("cursor", cursor)?;
+					}
+					if self.truncated {
+						val.serialize_field("truncated", &true)?;
+					}
 					val.end()
 				}
 				None => {
-					let mut val = serializer.serialize_struct("Response", 3)?;
+					let mut val = serializer.serialize_struct("Response", 3 + c)?;
 					val.serialize_field("time", self.speed().as_str())?;
 					val.serialize_field("status", "OK")?;
 					val.serialize_field("result", v)?;
+					if let Some(cursor) = &self.cursor {
+						val.serialize_field("cursor", cursor)?;
+					}
+					if self.truncated {
+						val.serialize_field("truncated", &true)?;
+					}
 					val.end()
 				}
 			},
 			Err(e) => match &self.sql {
 				Some(s) => {
-					let mut val = serializer.serialize_struct("Response", 4)?;
+					let mut val = serializer.serialize_struct("Response", 5)?;
 					val.serialize_field("sql", s.as_str())?;
 					val.serialize_field("time", self.speed().as_str())?;
 					val.serialize_field("status", "ERR")?;
 					val.serialize_field("detail", e)?;
+					val.serialize_field("code", e.code())?;
 					val.end()
 				}
 				None => {
-					let mut val = serializer.serialize_struct("Response", 3)?;
+					let mut val = serializer.serialize_struct("Response", 4)?;
 					val.serialize_field("time", self.speed().as_str())?;
 					val.serialize_field("status", "ERR")?;
 					val.serialize_field("detail", e)?;
+					val.serialize_field("code", e.code())?;
+					val.end()
+				}
+			},
+		}
+	}
+}
+
+impl Serialize for Chunk {
+	fn serialize<S>(&self, serializer: S) -> Result<S::Ok, S::Error>
+	where
+		S: serde::Serializer,
+	{
+		match self {
+			Chunk::Record(v) => {
+				let mut val = serializer.serialize_struct("Chunk", 1)?;
+				val.serialize_field("record", v)?;
+				val.end()
+			}
+			Chunk::Done(v) => match &v.sql {
+				Some(s) => {
+					let mut val = serializer.serialize_struct("Response", 3)?;
+					val.serialize_field("sql", s.as_str())?;
+					val.serialize_field("time", v.speed().as_str())?;
+					val.serialize_field("status", "OK")?;
+					val.end()
+				}
+				None => {
+					let mut val = serializer.serialize_struct("Response", 2)?;
+					val.serialize_field("time", v.speed().as_str())?;
+					val.serialize_field("status", "OK")?;
 					val.end()
 				}
 			},
+			Chunk::Response(v) => v.serialize(serializer),
 		}
 	}
 }
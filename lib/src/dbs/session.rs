@@ -1,5 +1,6 @@
 use crate::ctx::Context;
 use crate::dbs::Auth;
+use crate::sql::role::Role;
 use crate::sql::value::Value;
 use std::sync::Arc;
 
@@ -26,6 +27,10 @@ pub struct Session {
 	pub tk: Option<Value>,
 	/// The current scope authentication data
 	pub sd: Option<Value>,
+	/// Whether unauthenticated access is limited to reading data
+	pub ro: bool,
+	/// The roles granted to the authenticated user
+	pub rl: Vec<Role>,
 }
 
 impl Session {
@@ -72,6 +77,18 @@ impl Session {
 			..Session::default()
 		}
 	}
+	/// Create an unauthenticated session which can only read data
+	pub fn for_public<S>(ns: S, db: S) -> Session
+	where
+		S: Into<String>,
+	{
+		Session {
+			ns: Some(ns.into()),
+			db: Some(db.into()),
+			ro: true,
+			..Session::default()
+		}
+	}
 	/// Set the selected namespace for the session
 	pub fn with_ns(mut self, ns: &str) -> Session {
 		self.ns = Some(ns.to_owned());
@@ -82,6 +99,10 @@ impl Session {
 		self.db = Some(db.to_owned());
 		self
 	}
+	/// Checks whether the session can only read data
+	pub(crate) fn readonly(&self) -> bool {
+		self.ro && matches!(*self.au, Auth::No)
+	}
 	/// Retrieves the selected namespace
 	pub(crate) fn ns(&self) -> Option<Arc<str>> {
 		self.ns.as_deref().map(Into::into)
@@ -0,0 +1,139 @@
+use crate::dbs::Auth;
+use crate::dbs::Session;
+use crate::dbs::Variables;
+use crate::sql::query::Query;
+use std::time::Duration;
+
+/// A log of queries which take longer than a threshold to run.
+///
+/// Slow queries are logged with the query text, the names of any query
+/// variables, the selected namespace and database, the kind of the
+/// authenticated user, and the query duration. To avoid writing sensitive
+/// data to the logs, all string literals in the query text are redacted,
+/// and the values of the query variables are never logged.
+pub(crate) struct SlowLog {
+	threshold: Duration,
+}
+
+impl SlowLog {
+	/// Create a new log for queries which exceed the specified duration
+	pub(crate) fn new(threshold: Duration) -> SlowLog {
+		SlowLog {
+			threshold,
+		}
+	}
+
+	/// Generate a log entry for a query, if the query was slow
+	pub(crate) fn check(
+		&self,
+		ast: &Query,
+		sess: &Session,
+		vars: &Variables,
+		time: Duration,
+	) -> Option<String> {
+		// Only log queries which exceeded the threshold
+		if time < self.threshold {
+			return None;
+		}
+		// Get the names of the query variables
+		let vars = match vars {
+			Some(v) => v.keys().cloned().collect::<Vec<_>>().join(","),
+			None => String::new(),
+		};
+		// Get the kind of the authenticated user
+		let auth = match sess.au.as_ref() {
+			Auth::No => "no",
+			Auth::Kv => "kv",
+			Auth::Ns(_) => "ns",
+			Auth::Db(_, _) => "db",
+			Auth::Sc(_, _, _) => "sc",
+		};
+		// Generate the log entry
+		Some(format!(
+			"duration={:?} ns={} db={} auth={} vars=[{}] query={}",
+			time,
+			sess.ns.as_deref().unwrap_or_default(),
+			sess.db.as_deref().unwrap_or_default(),
+			auth,
+			vars,
+			redact(&ast.to_string()).replace('\n', " "),
+		))
+	}
+}
+
+/// Replace the contents of all string literals in a query with a placeholder
+fn redact(sql: &str) -> String {
+	let mut out = String::with_capacity(sql.len());
+	let mut chars = sql.chars();
+	while let Some(c) = chars.next() {
+		out.push(c);
+		// Check if this is the start of a string
+		if c != '"' && c != '\'' {
+			continue;
+		}
+		// Skip the string contents
+		while let Some(v) = chars.next() {
+			match v {
+				'\\' => {
+					chars.next();
+				}
+				v if v == c => break,
+				_ => (),
+			}
+		}
+		// Output the redacted string
+		out.push('?');
+		out.push(c);
+	}
+	out
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::parse;
+	use crate::sql::Value;
+	use std::collections::BTreeMap;
+
+	#[test]
+	fn slow_log_fast_query() {
+		let log = SlowLog::new(Duration::from_millis(100));
+		let ast = parse("SELECT * FROM person").unwrap();
+		let ses = Session::for_kv().with_ns("test").with_db("test");
+		let res = log.check(&ast, &ses, &None, Duration::from_millis(10));
+		assert!(res.is_none());
+	}
+
+	#[test]
+	fn slow_log_slow_query() {
+		let log = SlowLog::new(Duration::from_millis(100));
+		let ast = parse("SELECT * FROM person WHERE age > 18").unwrap();
+		let ses = Session::for_db("test", "test");
+		let res = log.check(&ast, &ses, &None, Duration::from_millis(250));
+		assert_eq!(
+			res,
+			Some(String::from(
+				"duration=250ms ns=test db=test auth=db vars=[] query=SELECT * FROM person WHERE age > 18;"
+			))
+		);
+	}
+
+	#[test]
+	fn slow_log_redacted_query() {
+		let log = SlowLog::new(Duration::from_millis(100));
+		let ast = parse(
+			r#"CREATE user SET email = "tobie@surrealdb.com", pass = $pass, note = "a \"quoted\" value""#,
+		)
+		.unwrap();
+		let ses = Session::for_sc("test", "test", "user");
+		let mut vars = BTreeMap::new();
+		vars.insert(String::from("pass"), Value::from("secret"));
+		let res = log.check(&ast, &ses, &Some(vars), Duration::from_secs(1)).unwrap();
+		assert!(!res.contains("tobie@surrealdb.com"));
+		assert!(!res.contains("quoted"));
+		assert!(!res.contains("secret"));
+		assert!(res.contains("auth=sc vars=[pass]"));
+		assert!(res.contains(r#"email = "?", pass = $pass, note = "?""#));
+	}
+}
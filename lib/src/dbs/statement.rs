@@ -3,6 +3,7 @@ use crate::sql::data::Data;
 use crate::sql::fetch::Fetchs;
 use crate::sql::field::Fields;
 use crate::sql::group::Groups;
+use crate::sql::guard::Guard;
 use crate::sql::limit::Limit;
 use crate::sql::order::Orders;
 use crate::sql::output::Output;
@@ -95,6 +96,22 @@ impl<'a> Statement<'a> {
 			_ => None,
 		}
 	}
+	// Returns whether duplicate results are removed
+	#[inline]
+	pub fn distinct(&self) -> bool {
+		match self {
+			Statement::Select(v) => v.distinct,
+			_ => false,
+		}
+	}
+	// Returns whether field values are output without their names
+	#[inline]
+	pub fn value(&self) -> bool {
+		match self {
+			Statement::Select(v) => v.value,
+			_ => false,
+		}
+	}
 	// Returns any SET clause if specified
 	#[inline]
 	pub fn data(&self) -> Option<&Data> {
@@ -116,6 +133,14 @@ impl<'a> Statement<'a> {
 			_ => None,
 		}
 	}
+	// Returns any IF clause if specified
+	#[inline]
+	pub fn guard(&self) -> Option<&Guard> {
+		match self {
+			Statement::Update(v) => v.guard.as_ref(),
+			_ => None,
+		}
+	}
 	// Returns any SPLIT clause if specified
 	#[inline]
 	pub fn split(&self) -> Option<&Splits> {
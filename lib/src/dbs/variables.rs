@@ -1,23 +1,54 @@
+use crate::cnf::PROTECTED_PARAM_NAMES;
 use crate::ctx::Context;
+use crate::err::Error;
+use crate::sql::query::Query;
+use crate::sql::statement::Statement;
 use crate::sql::value::Value;
 use std::collections::BTreeMap;
 
 pub type Variables = Option<BTreeMap<String, Value>>;
 
 pub(crate) trait Attach {
-	fn attach(self, ctx: Context) -> Context;
+	fn attach(self, ctx: Context) -> Result<Context, Error>;
 }
 
 impl Attach for Variables {
-	fn attach(self, mut ctx: Context) -> Context {
+	fn attach(self, mut ctx: Context) -> Result<Context, Error> {
 		match self {
 			Some(m) => {
 				for (key, val) in m {
+					// Check if the variable is a protected variable
+					if PROTECTED_PARAM_NAMES.contains(&key.as_str()) {
+						return Err(Error::InvalidParam {
+							name: key,
+						});
+					}
+					// The variable isn't protected and can be stored
 					ctx.add_value(key, val);
 				}
-				ctx
+				Ok(ctx)
 			}
-			None => ctx,
+			None => Ok(ctx),
 		}
 	}
 }
+
+pub(crate) trait Validate {
+	fn validate(self, ast: &Query) -> Result<Variables, Error>;
+}
+
+impl Validate for Variables {
+	fn validate(mut self, ast: &Query) -> Result<Variables, Error> {
+		for stm in ast.iter() {
+			if let Statement::Declare(v) = stm {
+				// Get the variables, even if none were passed
+				let m = self.get_or_insert_with(BTreeMap::new);
+				// Missing variables are checked as NONE
+				let val = m.remove(&v.name).unwrap_or_default();
+				// Check and convert the variable
+				m.insert(v.name.to_owned(), v.check(val)?);
+			}
+		}
+		Ok(self)
+	}
+}
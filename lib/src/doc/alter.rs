@@ -2,6 +2,7 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::dbs::Transaction;
+use crate::dbs::Workable;
 use crate::doc::Document;
 use crate::err::Error;
 use crate::sql::data::Data;
@@ -76,7 +77,12 @@ impl<'a> Document<'a> {
 					self.current.to_mut().replace(ctx, opt, txn, data).await?
 				}
 				Data::ContentExpression(data) => {
-					let data = data.compute(ctx, opt, txn, Some(&self.current)).await?;
+					let data = match &self.extras {
+						// This is a record from a batched CREATE statement
+						Workable::Insert(v) => v.to_owned(),
+						// Otherwise compute the content clause
+						_ => data.compute(ctx, opt, txn, Some(&self.current)).await?,
+					};
 					self.current.to_mut().replace(ctx, opt, txn, data).await?
 				}
 				_ => unreachable!(),
@@ -0,0 +1,68 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::datetime::Datetime;
+use crate::sql::value::Value;
+
+impl<'a> Document<'a> {
+	pub async fn changefeed(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Check if forced
+		if !opt.force && !self.changed() {
+			return Ok(());
+		}
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
+		// Check if the table is a view
+		if tb.drop {
+			return Ok(());
+		}
+		// Get the database
+		let db = txn.clone().lock().await.get_and_cache_db(opt.ns(), opt.db()).await?;
+		// Check if the change feed is enabled
+		if !db.changefeed {
+			return Ok(());
+		}
+		// Encrypt the encrypted fields
+		let doc = self.sealed(opt, txn).await?;
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Get the record id
+		let rid = self.id.as_ref().unwrap();
+		// Get the change action
+		let met = if stm.is_delete() {
+			Value::from("DELETE")
+		} else if self.is_new() {
+			Value::from("CREATE")
+		} else {
+			Value::from("UPDATE")
+		};
+		// Get the next sequence number
+		let seq = run.next_cs(opt.ns(), opt.db()).await?;
+		// Create the change event
+		let val = Value::from(map! {
+			String::from("seq") => Value::from(seq as i64),
+			String::from("time") => Value::from(Datetime::default()),
+			String::from("op") => met,
+			String::from("tb") => Value::from(rid.tb.to_owned()),
+			String::from("id") => Value::from(rid.to_owned()),
+			String::from("before") => self.stored.to_owned(),
+			String::from("after") => doc.into_owned(),
+		});
+		// Store the change event
+		let key = crate::key::cf::new(opt.ns(), opt.db(), seq);
+		run.set(key, &val).await?;
+		// Carry on
+		Ok(())
+	}
+}
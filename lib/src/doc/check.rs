@@ -15,12 +15,25 @@ impl<'a> Document<'a> {
 	) -> Result<(), Error> {
 		// Check where condition
 		if let Some(cond) = stm.conds() {
+			// Randomly encrypted fields can not be matched
+			let doc = self.hidden(opt, txn).await?;
 			// Check if the expression is truthy
-			if !cond.compute(ctx, opt, txn, Some(&self.current)).await?.is_truthy() {
+			if !cond.compute(ctx, opt, txn, Some(&doc)).await?.is_truthy() {
 				// Ignore this document
 				return Err(Error::Ignore);
 			}
 		}
+		// Check if clause
+		if let Some(guard) = stm.guard() {
+			// Check if the expression is truthy
+			if !guard.compute(ctx, opt, txn, Some(&self.current)).await?.is_truthy() {
+				// Reject this document
+				return Err(Error::GuardFailed {
+					thing: self.id.as_ref().unwrap().to_string(),
+					check: guard.to_string(),
+				});
+			}
+		}
 		// Carry on
 		Ok(())
 	}
@@ -28,14 +28,18 @@ impl<'a> Document<'a> {
 		};
 		// Setup a new document
 		let mut doc = Document::new(thg, &ins.0, ins.1);
-		// Process the statement
-		let res = match stm {
-			Statement::Select(_) => doc.select(ctx, opt, txn, stm).await,
-			Statement::Create(_) => doc.create(ctx, opt, txn, stm).await,
-			Statement::Update(_) => doc.update(ctx, opt, txn, stm).await,
-			Statement::Relate(_) => doc.relate(ctx, opt, txn, stm).await,
-			Statement::Delete(_) => doc.delete(ctx, opt, txn, stm).await,
-			Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
+		// Decrypt the encrypted fields
+		let res = match doc.decrypt(opt, txn).await {
+			Err(e) => Err(e),
+			// Process the statement
+			Ok(_) => match stm {
+				Statement::Select(_) => doc.select(ctx, opt, txn, stm).await,
+				Statement::Create(_) => doc.create(ctx, opt, txn, stm).await,
+				Statement::Update(_) => doc.update(ctx, opt, txn, stm).await,
+				Statement::Relate(_) => doc.relate(ctx, opt, txn, stm).await,
+				Statement::Delete(_) => doc.delete(ctx, opt, txn, stm).await,
+				Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
+			},
 		};
 		// Send back the result
 		let _ = chn.send(res).await;
@@ -24,6 +24,8 @@ impl<'a> Document<'a> {
 		self.index(ctx, opt, txn, stm).await?;
 		// Purge record data
 		self.purge(ctx, opt, txn, stm).await?;
+		// Store change feed data
+		self.changefeed(ctx, opt, txn, stm).await?;
 		// Run table queries
 		self.table(ctx, opt, txn, stm).await?;
 		// Run lives queries
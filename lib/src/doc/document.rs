@@ -17,6 +17,7 @@ pub struct Document<'a> {
 	pub(super) extras: Workable,
 	pub(super) current: Cow<'a, Value>,
 	pub(super) initial: Cow<'a, Value>,
+	pub(super) stored: &'a Value,
 }
 
 impl<'a> From<&Document<'a>> for Vec<u8> {
@@ -32,6 +33,7 @@ impl<'a> Document<'a> {
 			extras: ext,
 			current: Cow::Borrowed(val),
 			initial: Cow::Borrowed(val),
+			stored: val,
 		}
 	}
 }
@@ -0,0 +1,100 @@
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::statements::Encryption;
+use crate::sql::value::Value;
+use std::borrow::Cow;
+
+impl<'a> Document<'a> {
+	// Decrypt the encrypted fields of the stored record
+	pub async fn decrypt(&mut self, opt: &Options, txn: &Transaction) -> Result<(), Error> {
+		// Check if the record exists
+		if self.id.is_none() || self.initial.is_none() {
+			return Ok(());
+		}
+		// Get the field definitions
+		let fds = self.fd(opt, txn).await?;
+		// Check if any fields are encrypted
+		if fds.iter().all(|fd| fd.encrypted.is_none()) {
+			return Ok(());
+		}
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let run = run.lock().await;
+		// Loop through all encrypted fields
+		for fd in fds.iter().filter(|fd| fd.encrypted.is_some()) {
+			// Loop over each field in document
+			for (k, val) in self.initial.walk(&fd.name).into_iter() {
+				// Values which are not encrypted are left as they are
+				if let Some(val) = run.open_field(&val) {
+					self.initial.to_mut().put(&k, val);
+				}
+			}
+		}
+		// Start from the decrypted record
+		self.current = self.initial.clone();
+		// Carry on
+		Ok(())
+	}
+	// Get the record with its encrypted fields as they are to be stored
+	pub async fn sealed(&self, opt: &Options, txn: &Transaction) -> Result<Cow<'_, Value>, Error> {
+		// Get the field definitions
+		let fds = self.fd(opt, txn).await?;
+		// Check if any fields are encrypted
+		if fds.iter().all(|fd| fd.encrypted.is_none()) {
+			return Ok(Cow::Borrowed(self.current.as_ref()));
+		}
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let run = run.lock().await;
+		// Copy the current record
+		let mut doc = self.current.as_ref().clone();
+		// Loop through all encrypted fields
+		for fd in fds.iter().filter(|fd| fd.encrypted.is_some()) {
+			// Loop over each field in document
+			for (k, val) in self.current.walk(&fd.name).into_iter() {
+				let val = match val {
+					// Empty values are not encrypted
+					Value::None | Value::Null => continue,
+					// Unchanged values keep their stored ciphertext
+					v if v == self.initial.pick(&k) && v != self.stored.pick(&k) => {
+						self.stored.pick(&k)
+					}
+					// Otherwise encrypt the value
+					v => run.seal_field(fd, &v)?,
+				};
+				doc.put(&k, val);
+			}
+		}
+		// Return the encrypted record
+		Ok(Cow::Owned(doc))
+	}
+	// Get the record with its randomly encrypted fields hidden
+	pub async fn hidden(&self, opt: &Options, txn: &Transaction) -> Result<Cow<'_, Value>, Error> {
+		// Check if this is a record
+		if self.id.is_none() {
+			return Ok(Cow::Borrowed(self.current.as_ref()));
+		}
+		// Get the field definitions
+		let fds = self.fd(opt, txn).await?;
+		// Check if any fields are randomly encrypted
+		if !fds.iter().any(|fd| fd.encrypted == Some(Encryption::Random)) {
+			return Ok(Cow::Borrowed(self.current.as_ref()));
+		}
+		// Copy the current record
+		let mut doc = self.current.as_ref().clone();
+		// Loop through all randomly encrypted fields
+		for fd in fds.iter().filter(|fd| fd.encrypted == Some(Encryption::Random)) {
+			// Loop over each field in document
+			for (k, _) in self.current.walk(&fd.name).into_iter() {
+				// Replace the value with its stored ciphertext
+				doc.put(&k, self.stored.pick(&k));
+			}
+		}
+		// Return the hidden record
+		Ok(Cow::Owned(doc))
+	}
+}
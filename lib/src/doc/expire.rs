@@ -0,0 +1,43 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::datetime::Datetime;
+use crate::sql::paths::EXPIRES;
+use crate::sql::value::Value;
+use chrono::Utc;
+
+impl<'a> Document<'a> {
+	pub async fn expire(
+		&mut self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
+		// This table has an expiry time
+		if let Some(ttl) = &tb.ttl {
+			// Get the record expiry time
+			let val = match self.initial.pick(EXPIRES.as_ref()) {
+				// Keep the previous expiry unless touched
+				Value::Datetime(v) if !tb.touch => Value::Datetime(v),
+				// Otherwise calculate a new expiry
+				_ => match chrono::Duration::from_std(ttl.0) {
+					Ok(d) => match Utc::now().checked_add_signed(d) {
+						Some(v) => Value::from(Datetime::from(v)),
+						None => Value::None,
+					},
+					Err(_) => Value::None,
+				},
+			};
+			// Set the record expiry time
+			self.current.to_mut().put(EXPIRES.as_ref(), val);
+		}
+		// Carry on
+		Ok(())
+	}
+}
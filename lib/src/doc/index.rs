@@ -5,6 +5,7 @@ use crate::dbs::Transaction;
 use crate::doc::Document;
 use crate::err::Error;
 use crate::sql::array::Array;
+use crate::sql::statements::Encryption;
 
 impl<'a> Document<'a> {
 	pub async fn index(
@@ -12,7 +13,7 @@ impl<'a> Document<'a> {
 		ctx: &Context<'_>,
 		opt: &Options,
 		txn: &Transaction,
-		_stm: &Statement<'_>,
+		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		// Check events
 		if !opt.indexes {
@@ -22,24 +23,44 @@ impl<'a> Document<'a> {
 		if !opt.force && !self.changed() {
 			return Ok(());
 		}
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
 		// Check if the table is a view
-		if self.tb(opt, txn).await?.drop {
+		if tb.drop {
+			return Ok(());
+		}
+		// Soft deleted records keep their index data
+		if tb.soft && !opt.deleted && stm.is_delete() {
 			return Ok(());
 		}
 		// Get the record id
 		let rid = self.id.as_ref().unwrap();
+		// Get the randomly encrypted fields
+		let fds = self.fd(opt, txn).await?;
+		let enc = fds.iter().filter(|fd| fd.encrypted == Some(Encryption::Random));
+		// Encrypted fields are indexed by their ciphertext
+		let doc = self.sealed(opt, txn).await?;
 		// Loop through all index statements
 		for ix in self.ix(opt, txn).await?.iter() {
+			// Randomly encrypted fields can not be indexed
+			for fd in enc.clone() {
+				if ix.cols.iter().any(|i| i.starts_with(&fd.name) || fd.name.starts_with(i)) {
+					return Err(Error::EncryptedIndex {
+						index: ix.name.to_string(),
+						field: fd.name.clone(),
+					});
+				}
+			}
 			// Calculate old values
 			let mut o = Array::with_capacity(ix.cols.len());
 			for i in ix.cols.iter() {
-				let v = i.compute(ctx, opt, txn, Some(&self.initial)).await?;
+				let v = i.compute(ctx, opt, txn, Some(self.stored)).await?;
 				o.push(v);
 			}
 			// Calculate new values
 			let mut n = Array::with_capacity(ix.cols.len());
 			for i in ix.cols.iter() {
-				let v = i.compute(ctx, opt, txn, Some(&self.current)).await?;
+				let v = i.compute(ctx, opt, txn, Some(&doc)).await?;
 				n.push(v);
 			}
 			// Clone transaction
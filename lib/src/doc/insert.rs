@@ -26,12 +26,18 @@ impl<'a> Document<'a> {
 				self.field(ctx, opt, txn, stm).await?;
 				// Clean fields data
 				self.clean(ctx, opt, txn, stm).await?;
+				// Set record version
+				self.version(ctx, opt, txn, stm).await?;
+				// Set record expiry
+				self.expire(ctx, opt, txn, stm).await?;
 				// Check if allowed
 				self.allow(ctx, opt, txn, stm).await?;
 				// Store index data
 				self.index(ctx, opt, txn, stm).await?;
 				// Store record data
 				self.store(ctx, opt, txn, stm).await?;
+				// Store change feed data
+				self.changefeed(ctx, opt, txn, stm).await?;
 				// Run table queries
 				self.table(ctx, opt, txn, stm).await?;
 				// Run lives queries
@@ -51,12 +57,18 @@ impl<'a> Document<'a> {
 				self.field(ctx, opt, txn, stm).await?;
 				// Clean fields data
 				self.clean(ctx, opt, txn, stm).await?;
+				// Set record version
+				self.version(ctx, opt, txn, stm).await?;
+				// Set record expiry
+				self.expire(ctx, opt, txn, stm).await?;
 				// Check if allowed
 				self.allow(ctx, opt, txn, stm).await?;
 				// Store index data
 				self.index(ctx, opt, txn, stm).await?;
 				// Store record data
 				self.store(ctx, opt, txn, stm).await?;
+				// Store change feed data
+				self.changefeed(ctx, opt, txn, stm).await?;
 				// Run table queries
 				self.table(ctx, opt, txn, stm).await?;
 				// Run lives queries
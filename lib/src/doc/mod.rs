@@ -5,6 +5,7 @@ mod compute;
 
 mod allow;
 mod alter;
+mod changefeed;
 mod check;
 mod clean;
 mod create;
@@ -12,9 +13,11 @@ mod delete;
 mod document;
 mod edges;
 mod empty;
+mod encrypt;
 mod erase;
 mod event;
 mod exist;
+mod expire;
 mod field;
 mod index;
 mod insert;
@@ -23,7 +26,9 @@ mod merge;
 mod pluck;
 mod purge;
 mod relate;
+mod restore;
 mod select;
 mod store;
 mod table;
 mod update;
+mod version;
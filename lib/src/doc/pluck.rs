@@ -51,23 +51,32 @@ impl<'a> Document<'a> {
 		}?;
 		// Check if this record exists
 		if self.id.is_some() {
-			// Loop through all field statements
-			for fd in self.fd(opt, txn).await?.iter() {
-				// Loop over each field in document
-				for k in out.each(&fd.name).iter() {
-					// Process field permissions
-					match &fd.permissions.select {
-						Permission::Full => (),
-						Permission::None => out.del(ctx, opt, txn, k).await?,
-						Permission::Specific(e) => {
-							// Get the current value
-							let val = self.current.pick(k);
-							// Configure the context
-							let mut ctx = Context::new(ctx);
-							ctx.add_value("value".into(), &val);
-							// Process the PERMISSION clause
-							if !e.compute(&ctx, opt, txn, Some(&self.current)).await?.is_truthy() {
-								out.del(&ctx, opt, txn, k).await?
+			// Should we run permissions checks?
+			if opt.perms && opt.auth.perms() {
+				// Loop through all field statements
+				for fd in self.fd(opt, txn).await?.iter() {
+					// Loop over each field in document
+					for k in out.each(&fd.name).iter() {
+						// Process field permissions
+						match &fd.permissions.select {
+							Permission::Full => (),
+							Permission::None => out.del(ctx, opt, txn, k).await?,
+							Permission::Specific(e) => {
+								// Ensure permissions are disabled
+								let opt = &opt.perms(false);
+								// Get the current value
+								let val = self.current.pick(k);
+								// Configure the context
+								let mut ctx = Context::new(ctx);
+								ctx.add_value("value".into(), &val);
+								// Process the PERMISSION clause
+								if !e
+									.compute(&ctx, opt, txn, Some(&self.current))
+									.await?
+									.is_truthy()
+								{
+									out.del(&ctx, opt, txn, k).await?
+								}
 							}
 						}
 					}
@@ -4,8 +4,10 @@ use crate::dbs::Statement;
 use crate::dbs::Transaction;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::sql::datetime::Datetime;
 use crate::sql::dir::Dir;
 use crate::sql::edges::Edges;
+use crate::sql::paths::DELETED;
 use crate::sql::paths::IN;
 use crate::sql::paths::OUT;
 use crate::sql::statements::DeleteStatement;
@@ -24,8 +26,10 @@ impl<'a> Document<'a> {
 		if !opt.force && !self.changed() {
 			return Ok(());
 		}
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
 		// Check if the table is a view
-		if self.tb(opt, txn).await?.drop {
+		if tb.drop {
 			return Ok(());
 		}
 		// Clone transaction
@@ -34,6 +38,14 @@ impl<'a> Document<'a> {
 		let mut run = run.lock().await;
 		// Get the record id
 		let rid = self.id.as_ref().unwrap();
+		// Keep soft deleted records and their edges
+		if tb.soft && !opt.deleted {
+			let mut val = self.stored.clone();
+			val.put(DELETED.as_ref(), Value::from(Datetime::default()));
+			let key = crate::key::thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
+			run.set(key, &val).await?;
+			return Ok(());
+		}
 		// Purge the record data
 		let key = crate::key::thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
 		run.del(key).await?;
@@ -55,6 +67,13 @@ impl<'a> Document<'a> {
 				let key = crate::key::graph::new(opt.ns(), opt.db(), &r.tb, &r.id, i, rid);
 				run.del(key).await?;
 			}
+			// Leave the edges of this record in place
+			_ if !opt.cascade => {
+				// Purge the record pointer edges
+				let beg = crate::key::graph::prefix(opt.ns(), opt.db(), &rid.tb, &rid.id);
+				let end = crate::key::graph::suffix(opt.ns(), opt.db(), &rid.tb, &rid.id);
+				run.delr(beg..end, u32::MAX).await?;
+			}
 			_ => {
 				// Release the transaction
 				drop(run);
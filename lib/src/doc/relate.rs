@@ -22,6 +22,10 @@ impl<'a> Document<'a> {
 		self.field(ctx, opt, txn, stm).await?;
 		// Clean fields data
 		self.clean(ctx, opt, txn, stm).await?;
+		// Set record version
+		self.version(ctx, opt, txn, stm).await?;
+		// Set record expiry
+		self.expire(ctx, opt, txn, stm).await?;
 		// Check if allowed
 		self.allow(ctx, opt, txn, stm).await?;
 		// Store record edges
@@ -30,6 +34,8 @@ impl<'a> Document<'a> {
 		self.index(ctx, opt, txn, stm).await?;
 		// Store record data
 		self.store(ctx, opt, txn, stm).await?;
+		// Store change feed data
+		self.changefeed(ctx, opt, txn, stm).await?;
 		// Run table queries
 		self.table(ctx, opt, txn, stm).await?;
 		// Run lives queries
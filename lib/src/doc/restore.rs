@@ -0,0 +1,24 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::paths::DELETED;
+
+impl<'a> Document<'a> {
+	pub async fn restore(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Updating a soft deleted record restores it
+		if self.current.is_deleted() {
+			self.current.to_mut().del(ctx, opt, txn, DELETED.as_ref()).await?;
+		}
+		// Carry on
+		Ok(())
+	}
+}
@@ -4,6 +4,7 @@ use crate::dbs::Statement;
 use crate::dbs::Transaction;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::kvs::Val;
 
 impl<'a> Document<'a> {
 	pub async fn store(
@@ -17,10 +18,14 @@ impl<'a> Document<'a> {
 		if !opt.force && !self.changed() {
 			return Ok(());
 		}
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
 		// Check if the table is a view
-		if self.tb(opt, txn).await?.drop {
+		if tb.drop {
 			return Ok(());
 		}
+		// Encrypt the encrypted fields
+		let val = self.sealed(opt, txn).await?;
 		// Clone transaction
 		let run = txn.clone();
 		// Claim transaction
@@ -29,7 +34,16 @@ impl<'a> Document<'a> {
 		let rid = self.id.as_ref().unwrap();
 		// Store the record data
 		let key = crate::key::thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
-		run.set(key, self).await?;
+		match tb.vers && !self.is_new() {
+			// Ensure the record is unchanged since it was read
+			true => {
+				let val: Val = val.as_ref().into();
+				let chk: Val = self.stored.into();
+				run.putc(key, val, Some(chk)).await?
+			}
+			// Otherwise overwrite the record
+			false => run.set(key, val.as_ref()).await?,
+		};
 		// Carry on
 		Ok(())
 	}
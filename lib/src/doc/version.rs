@@ -0,0 +1,38 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::number::Number;
+use crate::sql::paths::VERSION;
+use crate::sql::value::Value;
+
+impl<'a> Document<'a> {
+	pub async fn version(
+		&mut self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Check if the record has changed
+		if !self.changed() {
+			return Ok(());
+		}
+		// Get the table
+		let tb = self.tb(opt, txn).await?;
+		// This table is versioned
+		if tb.vers {
+			// Get the previous record version
+			let val = match self.initial.pick(VERSION.as_ref()) {
+				Value::Number(v) => v + Number::from(1),
+				_ => Number::from(1),
+			};
+			// Set the new record version
+			self.current.to_mut().put(VERSION.as_ref(), Value::from(val));
+		}
+		// Carry on
+		Ok(())
+	}
+}
@@ -37,10 +37,39 @@ pub enum Error {
 	#[error("Value being checked was not correct")]
 	TxConditionNotMet,
 
+	/// The transaction conflicted with another concurrent transaction
+	#[error("The transaction conflicted with another transaction, and can be retried")]
+	TxConflict,
+
 	/// The key being inserted in the transaction already exists
 	#[error("The key being inserted already exists")]
 	TxKeyAlreadyExists,
 
+	/// The specified encryption key is not a valid 256-bit key
+	#[error("The encryption key must be 32 bytes in length")]
+	InvalidKey,
+
+	/// There was an error when encrypting a stored value
+	#[error("There was an error when encrypting a stored value")]
+	Encrypt,
+
+	/// A stored value could not be decrypted with the available keys
+	#[error("A stored value could not be decrypted with the specified encryption keys")]
+	Decrypt,
+
+	/// A field is defined as ENCRYPTED, but no field encryption keys have been specified
+	#[error("Unable to store field '{field}' as no field encryption keys have been specified")]
+	EncryptionDisabled {
+		field: Idiom,
+	},
+
+	/// A field which is encrypted with random nonces can not be indexed
+	#[error("Unable to index field '{field}' in index '{index}' as it is not encrypted deterministically")]
+	EncryptedIndex {
+		index: String,
+		field: Idiom,
+	},
+
 	/// No namespace has been selected
 	#[error("Specify a namespace to use")]
 	NsEmpty,
@@ -67,10 +96,47 @@ pub enum Error {
 		message: String,
 	},
 
+	/// A JSON Patch test operation did not match the current value
+	#[error("The JSON Patch test operation failed. Expected '{expected}', but found '{found}'")]
+	PatchTest {
+		expected: String,
+		found: String,
+	},
+
+	/// A transactional batch contained its own transaction statements
+	#[error("The batch contains invalid statements. {message}")]
+	InvalidBatch {
+		message: String,
+	},
+
+	/// The query attempted to set a protected session parameter
+	#[error("'{name}' is a protected variable and cannot be set")]
+	InvalidParam {
+		name: String,
+	},
+
 	/// Remote HTTP request functions are not enabled
 	#[error("Remote HTTP request functions are not enabled")]
 	HttpDisabled,
 
+	/// A query variable did not match its declared type
+	#[error("The variable ${name} expected {expected}, got {found}")]
+	InvalidVariable {
+		name: String,
+		expected: String,
+		found: String,
+	},
+
+	/// The statement can not be previewed without side effects
+	#[error("The statement can not be run as a dry run. {message}")]
+	DryRunNotAllowed {
+		message: String,
+	},
+
+	/// The VALUE clause was used without selecting a single field
+	#[error("A SELECT VALUE statement must select a single field")]
+	SelectValueMultiple,
+
 	/// There was an error with the provided JavaScript code
 	#[error("Problem with embedded script function. {message}")]
 	InvalidScript {
@@ -84,6 +150,13 @@ pub enum Error {
 		message: String,
 	},
 
+	/// A value could not be converted to the specified type
+	#[error("Expected a {into} but cannot convert {from} into a {into}")]
+	ConvertTo {
+		from: String,
+		into: String,
+	},
+
 	/// The query timedout
 	#[error("The query was not executed because it exceeded the timeout")]
 	QueryTimedout,
@@ -96,6 +169,27 @@ pub enum Error {
 	#[error("The query was not executed due to a failed transaction")]
 	QueryNotExecuted,
 
+	/// The query panicked, and the panic was recovered
+	#[error("The query could not be completed because of an unexpected internal error: {0}")]
+	Panic(String),
+
+	/// The requested change feed events have been pruned
+	#[error("The change feed events from sequence {seq} have expired, the oldest available sequence is {oldest}")]
+	ChangefeedExpired {
+		seq: u64,
+		oldest: u64,
+	},
+
+	/// A savepoint was created outside of a transaction
+	#[error("Savepoints can only be created within a transaction")]
+	SavepointNotAllowed,
+
+	/// The specified savepoint does not exist in the current transaction
+	#[error("The savepoint '{name}' does not exist in the current transaction")]
+	SavepointNotFound {
+		name: String,
+	},
+
 	/// The permissions do not allow for performing the specified query
 	#[error("You don't have permission to perform this query type")]
 	QueryPermissions,
@@ -113,8 +207,10 @@ pub enum Error {
 	},
 
 	/// The requested namespace does not exist
-	#[error("The namespace does not exist")]
-	NsNotFound,
+	#[error("The namespace '{value}' does not exist")]
+	NsNotFound {
+		value: String,
+	},
 
 	/// The requested namespace token does not exist
 	#[error("The namespace token does not exist")]
@@ -125,8 +221,10 @@ pub enum Error {
 	NlNotFound,
 
 	/// The requested database does not exist
-	#[error("The database does not exist")]
-	DbNotFound,
+	#[error("The database '{value}' does not exist")]
+	DbNotFound {
+		value: String,
+	},
 
 	/// The requested database token does not exist
 	#[error("The database token does not exist")]
@@ -148,6 +246,18 @@ pub enum Error {
 	#[error("The table does not exist")]
 	TbNotFound,
 
+	/// The requested function does not exist
+	#[error("The function 'fn::{value}' does not exist")]
+	FcNotFound {
+		value: String,
+	},
+
+	/// The requested param does not exist
+	#[error("The param '${value}' does not exist")]
+	PaNotFound {
+		value: String,
+	},
+
 	/// Unable to perform the realtime query
 	#[error("Unable to perform the realtime query")]
 	RealtimeDisabled,
@@ -156,18 +266,69 @@ pub enum Error {
 	#[error("Too many recursive subqueries have been processed")]
 	TooManySubqueries,
 
+	/// Too many graph edges have been traversed in a single expression
+	#[error("Too many graph edges have been traversed, the maximum traversal depth is {depth}")]
+	TooManyTraversals {
+		depth: usize,
+	},
+
+	/// Too many iterations have been processed in a single FOR loop
+	#[error("Too many loop iterations have been processed, the maximum is {limit}")]
+	TooManyIterations {
+		limit: usize,
+	},
+
+	/// Too many values have been returned by a subquery in a WHERE clause
+	#[error("Too many values have been returned by a subquery, the maximum is {limit}")]
+	TooManySubqueryValues {
+		limit: usize,
+	},
+
+	/// The query is too deeply nested to be executed
+	#[error("The query has a complexity of {score}, but the maximum is {limit}")]
+	QueryTooComplex {
+		score: usize,
+		limit: usize,
+	},
+
+	/// The query contains too many statements to be executed
+	#[error("The query contains {count} statements, but the maximum is {limit}")]
+	QueryTooLong {
+		count: usize,
+		limit: usize,
+	},
+
+	/// The pagination cursor could not be used
+	#[error("The pagination cursor is invalid. {message}")]
+	InvalidCursor {
+		message: String,
+	},
+
 	/// Can not execute CREATE query using the specified value
 	#[error("Can not execute CREATE query using value '{value}'")]
 	CreateStatement {
 		value: String,
 	},
 
+	/// The record did not match the IF clause of the UPDATE query
+	#[error("Found record '{thing}' which does not satisfy the clause '{check}'")]
+	GuardFailed {
+		thing: String,
+		check: String,
+	},
+
 	/// Can not execute UPDATE query using the specified value
 	#[error("Can not execute UPDATE query using value '{value}'")]
 	UpdateStatement {
 		value: String,
 	},
 
+	/// A query with the ONLY keyword did not output exactly one record
+	#[error("Expected a single result output when using the ONLY keyword, but found {count}")]
+	SingleOnlyOutput {
+		count: usize,
+	},
+
 	/// Can not execute RELATE query using the specified value
 	#[error("Can not execute RELATE query using value '{value}'")]
 	RelateStatement {
@@ -192,6 +353,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// Can not execute FOR query using the specified value
+	#[error("Can not execute FOR query using value '{value}'")]
+	ForeachStatement {
+		value: String,
+	},
+
 	/// Can not execute KILL query using the specified id
 	#[error("Can not execute KILL query using id '{value}'")]
 	KillStatement {
@@ -204,6 +371,12 @@ pub enum Error {
 		table: String,
 	},
 
+	/// The permissions do not allow this function to be run
+	#[error("You don't have permission to run the `fn::{name}` function")]
+	FunctionPermissions {
+		name: String,
+	},
+
 	/// The specified table can not be written as it is setup as a foreign table view
 	#[error("Unable to write to the `{table}` table while setup as a view")]
 	TableIsView {
@@ -260,6 +433,185 @@ pub enum Error {
 	Decode(#[from] DecodeError),
 }
 
+impl Error {
+	/// Whether the failed operation can be run again in a new transaction
+	pub fn is_retriable(&self) -> bool {
+		matches!(self, Error::TxConflict)
+	}
+
+	/// A stable code which identifies the kind of error
+	pub fn code(&self) -> &'static str {
+		match self {
+			Error::Ignore => "IGNORED",
+			Error::Ds(_) => "DATASTORE",
+			Error::Tx(_) => "TRANSACTION",
+			Error::TxFailure => "TRANSACTION_FAILED",
+			Error::TxFinished => "TRANSACTION_FINISHED",
+			Error::TxReadonly => "TRANSACTION_READONLY",
+			Error::TxConditionNotMet => "TRANSACTION_CONDITION",
+			Error::TxKeyAlreadyExists => "TRANSACTION_KEY_EXISTS",
+			Error::TxConflict => "TRANSACTION_CONFLICT",
+			Error::InvalidKey => "INVALID_KEY",
+			Error::Encrypt => "ENCRYPTION",
+			Error::Decrypt => "DECRYPTION",
+			Error::EncryptionDisabled {
+				..
+			} => "ENCRYPTION_DISABLED",
+			Error::EncryptedIndex {
+				..
+			} => "ENCRYPTED_INDEX",
+			Error::NsEmpty => "NS_EMPTY",
+			Error::DbEmpty => "DB_EMPTY",
+			Error::QueryEmpty => "QUERY_EMPTY",
+			Error::InvalidQuery {
+				..
+			} => "QUERY_PARSE",
+			Error::InvalidPatch {
+				..
+			} => "PATCH_INVALID",
+			Error::PatchTest {
+				..
+			} => "PATCH_TEST",
+			Error::InvalidBatch {
+				..
+			} => "BATCH_INVALID",
+			Error::InvalidParam {
+				..
+			} => "PARAM_INVALID",
+			Error::HttpDisabled => "HTTP_DISABLED",
+			Error::InvalidVariable {
+				..
+			} => "VARIABLE_INVALID",
+			Error::DryRunNotAllowed {
+				..
+			} => "DRY_RUN_NOT_ALLOWED",
+			Error::SelectValueMultiple => "SELECT_VALUE_MULTIPLE",
+			Error::InvalidScript {
+				..
+			} => "SCRIPT_INVALID",
+			Error::ConvertTo {
+				..
+			} => "CONVERT_TO",
+			Error::InvalidArguments {
+				..
+			} => "ARGUMENTS_INVALID",
+			Error::QueryTimedout => "QUERY_TIMEOUT",
+			Error::QueryCancelled => "QUERY_CANCELLED",
+			Error::QueryNotExecuted => "QUERY_NOT_EXECUTED",
+			Error::Panic(_) => "INTERNAL_PANIC",
+			Error::ChangefeedExpired {
+				..
+			} => "CHANGEFEED_EXPIRED",
+			Error::SavepointNotAllowed => "SAVEPOINT_NOT_ALLOWED",
+			Error::SavepointNotFound {
+				..
+			} => "SAVEPOINT_NOT_FOUND",
+			Error::QueryPermissions => "QUERY_PERMISSIONS",
+			Error::NsNotAllowed {
+				..
+			} => "NS_NOT_ALLOWED",
+			Error::DbNotAllowed {
+				..
+			} => "DB_NOT_ALLOWED",
+			Error::NsNotFound {
+				..
+			} => "NS_NOT_FOUND",
+			Error::NtNotFound => "NT_NOT_FOUND",
+			Error::NlNotFound => "NL_NOT_FOUND",
+			Error::DbNotFound {
+				..
+			} => "DB_NOT_FOUND",
+			Error::DtNotFound => "DT_NOT_FOUND",
+			Error::DlNotFound => "DL_NOT_FOUND",
+			Error::ScNotFound => "SC_NOT_FOUND",
+			Error::StNotFound => "ST_NOT_FOUND",
+			Error::TbNotFound => "TB_NOT_FOUND",
+			Error::FcNotFound {
+				..
+			} => "FC_NOT_FOUND",
+			Error::PaNotFound {
+				..
+			} => "PA_NOT_FOUND",
+			Error::RealtimeDisabled => "REALTIME_DISABLED",
+			Error::TooManySubqueries => "TOO_MANY_SUBQUERIES",
+			Error::TooManyTraversals {
+				..
+			} => "TOO_MANY_TRAVERSALS",
+			Error::TooManyIterations {
+				..
+			} => "TOO_MANY_ITERATIONS",
+			Error::TooManySubqueryValues {
+				..
+			} => "TOO_MANY_SUBQUERY_VALUES",
+			Error::QueryTooComplex {
+				..
+			} => "QUERY_TOO_COMPLEX",
+			Error::QueryTooLong {
+				..
+			} => "QUERY_TOO_LONG",
+			Error::InvalidCursor {
+				..
+			} => "CURSOR_INVALID",
+			Error::CreateStatement {
+				..
+			} => "CREATE_STATEMENT",
+			Error::GuardFailed {
+				..
+			} => "GUARD_FAILED",
+			Error::UpdateStatement {
+				..
+			} => "UPDATE_STATEMENT",
+			Error::SingleOnlyOutput {
+				..
+			} => "SINGLE_ONLY_OUTPUT",
+			Error::RelateStatement {
+				..
+			} => "RELATE_STATEMENT",
+			Error::DeleteStatement {
+				..
+			} => "DELETE_STATEMENT",
+			Error::InsertStatement {
+				..
+			} => "INSERT_STATEMENT",
+			Error::LiveStatement {
+				..
+			} => "LIVE_STATEMENT",
+			Error::ForeachStatement {
+				..
+			} => "FOREACH_STATEMENT",
+			Error::KillStatement {
+				..
+			} => "KILL_STATEMENT",
+			Error::TablePermissions {
+				..
+			} => "TABLE_PERMISSIONS",
+			Error::FunctionPermissions {
+				..
+			} => "FUNCTION_PERMISSIONS",
+			Error::TableIsView {
+				..
+			} => "TABLE_IS_VIEW",
+			Error::RecordExists {
+				..
+			} => "RECORD_EXISTS",
+			Error::IndexExists {
+				..
+			} => "INDEX_EXISTS",
+			Error::FieldValue {
+				..
+			} => "FIELD_VALUE",
+			Error::IdInvalid {
+				..
+			} => "ID_INVALID",
+			Error::Http(_) => "HTTP",
+			Error::Channel(_) => "CHANNEL",
+			Error::Serde(_) => "SERIALIZATION",
+			Error::Encode(_) => "ENCODING",
+			Error::Decode(_) => "DECODING",
+		}
+	}
+}
+
 impl From<Error> for String {
 	fn from(e: Error) -> String {
 		e.to_string()
@@ -299,7 +651,10 @@ impl From<tikv::Error> for Error {
 #[cfg(feature = "kv-rocksdb")]
 impl From<rocksdb::Error> for Error {
 	fn from(e: rocksdb::Error) -> Error {
-		Error::Tx(e.to_string())
+		match e.kind() {
+			rocksdb::ErrorKind::Busy | rocksdb::ErrorKind::TryAgain => Error::TxConflict,
+			_ => Error::Tx(e.to_string()),
+		}
 	}
 }
 
@@ -35,6 +35,13 @@ pub fn distinct((arg,): (Value,)) -> Result<Value, Error> {
 	}
 }
 
+pub fn flatten((arg,): (Value,)) -> Result<Value, Error> {
+	match arg {
+		Value::Array(v) => Ok(Value::Array(v).flatten()),
+		_ => Ok(Value::None),
+	}
+}
+
 pub fn intersect(arrays: (Value, Value)) -> Result<Value, Error> {
 	Ok(match arrays {
 		(Value::Array(v), Value::Array(w)) => v.intersect(w).into(),
@@ -49,6 +56,34 @@ pub fn len((arg,): (Value,)) -> Result<Value, Error> {
 	}
 }
 
+pub fn push((array, value): (Value, Value)) -> Result<Value, Error> {
+	match array {
+		Value::Array(mut v) => {
+			v.push(value);
+			Ok(v.into())
+		}
+		_ => Ok(Value::None),
+	}
+}
+
+pub fn remove((array, index): (Value, i64)) -> Result<Value, Error> {
+	match array {
+		Value::Array(mut v) => {
+			// Negative indexes count from the end
+			let index = match index {
+				i if i < 0 => v.len() as i64 + i,
+				i => i,
+			};
+			// Ignore indexes which are out of bounds
+			if index >= 0 && (index as usize) < v.len() {
+				v.remove(index as usize);
+			}
+			Ok(v.into())
+		}
+		_ => Ok(Value::None),
+	}
+}
+
 pub fn sort((array, order): (Value, Option<Value>)) -> Result<Value, Error> {
 	match array {
 		Value::Array(mut v) => match order {
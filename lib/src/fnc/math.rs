@@ -101,7 +101,10 @@ pub fn min((array,): (Value,)) -> Result<Value, Error> {
 
 pub fn mode((array,): (Value,)) -> Result<Value, Error> {
 	Ok(match array {
-		Value::Array(v) => v.as_numbers().mode().into(),
+		Value::Array(v) => match v.is_empty() {
+			true => Value::None,
+			false => v.as_numbers().mode().into(),
+		},
 		_ => Value::None,
 	})
 }
@@ -115,7 +118,10 @@ pub fn nearestrank((array, n): (Value, Number)) -> Result<Value, Error> {
 
 pub fn percentile((array, n): (Value, Number)) -> Result<Value, Error> {
 	Ok(match array {
-		Value::Array(v) => v.as_numbers().sorted().percentile(n).into(),
+		Value::Array(v) => match v.is_empty() {
+			true => Value::None,
+			false => v.as_numbers().sorted().percentile(n).into(),
+		},
 		_ => Value::None,
 	})
 }
@@ -147,7 +153,10 @@ pub fn sqrt((arg,): (Number,)) -> Result<Value, Error> {
 
 pub fn stddev((array,): (Value,)) -> Result<Value, Error> {
 	Ok(match array {
-		Value::Array(v) => v.as_numbers().deviation(true).into(),
+		Value::Array(v) => match v.len() {
+			0 | 1 => Value::None,
+			_ => v.as_numbers().deviation(true).into(),
+		},
 		_ => Value::None,
 	})
 }
@@ -175,7 +184,10 @@ pub fn trimean((array,): (Value,)) -> Result<Value, Error> {
 
 pub fn variance((array,): (Value,)) -> Result<Value, Error> {
 	Ok(match array {
-		Value::Array(v) => v.as_numbers().variance(true).into(),
+		Value::Array(v) => match v.len() {
+			0 | 1 => Value::None,
+			_ => v.as_numbers().variance(true).into(),
+		},
 		_ => Value::None,
 	})
 }
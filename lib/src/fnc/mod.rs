@@ -35,6 +35,16 @@ pub async fn run(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Valu
 	}
 }
 
+/// Checks if a function can return different results for the same arguments.
+pub fn volatile(name: &str) -> bool {
+	name == "time::now"
+		|| name == "rand"
+		|| name.starts_with("rand::")
+		|| name.starts_with("session::")
+		|| name.starts_with("http::")
+		|| (name.starts_with("crypto") && name.ends_with("generate"))
+}
+
 // Each function is specified by its name (a string literal) followed by its path. The path
 // may be followed by one parenthesized argument, e.g. ctx, which is passed to the function
 // before the remainder of the arguments. The path may be followed by `.await` to signify that
@@ -64,8 +74,11 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"array::concat" => array::concat,
 		"array::difference" => array::difference,
 		"array::distinct" => array::distinct,
+		"array::flatten" => array::flatten,
 		"array::intersect" => array::intersect,
 		"array::len" => array::len,
+		"array::push" => array::push,
+		"array::remove" => array::remove,
 		"array::sort" => array::sort,
 		"array::union" => array::union,
 		"array::sort::asc" => array::sort::asc,
@@ -165,6 +178,7 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"string::join" => string::join,
 		"string::length" => string::length,
 		"string::lowercase" => string::lowercase,
+		"string::matches" => string::matches,
 		"string::repeat" => string::repeat,
 		"string::replace" => string::replace,
 		"string::reverse" => string::reverse,
@@ -176,8 +190,10 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"string::uppercase" => string::uppercase,
 		"string::words" => string::words,
 		//
+		"time::add" => time::add,
 		"time::day" => time::day,
 		"time::floor" => time::floor,
+		"time::format" => time::format,
 		"time::group" => time::group,
 		"time::hour" => time::hour,
 		"time::mins" => time::mins,
@@ -200,6 +216,7 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"type::int" => r#type::int,
 		"type::number" => r#type::number,
 		"type::point" => r#type::point,
+		"type::record" => r#type::record,
 		"type::regex" => r#type::regex,
 		"type::string" => r#type::string,
 		"type::table" => r#type::table,
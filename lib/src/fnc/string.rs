@@ -31,6 +31,16 @@ pub fn lowercase((string,): (String,)) -> Result<Value, Error> {
 	Ok(string.to_lowercase().into())
 }
 
+pub fn matches((val, pattern): (String, String)) -> Result<Value, Error> {
+	match string::regex(&pattern) {
+		Ok(re) => Ok(re.is_match(&val).into()),
+		Err(e) => Err(Error::InvalidArguments {
+			name: String::from("string::matches"),
+			message: format!("The regular expression is invalid. {}", e),
+		}),
+	}
+}
+
 pub fn repeat((val, num): (String, usize)) -> Result<Value, Error> {
 	const LIMIT: usize = 2usize.pow(20);
 	if val.len().saturating_mul(num) > LIMIT {
@@ -60,7 +70,12 @@ pub fn slug((string,): (String,)) -> Result<Value, Error> {
 }
 
 pub fn split((val, chr): (String, String)) -> Result<Value, Error> {
-	Ok(val.split(&chr).collect::<Vec<&str>>().into())
+	match chr.is_empty() {
+		// Split the string into characters
+		true => Ok(val.chars().map(|c| Value::from(c.to_string())).collect::<Vec<Value>>().into()),
+		// Split the string by the separator
+		false => Ok(val.split(&chr).collect::<Vec<&str>>().into()),
+	}
 }
 
 pub fn starts_with((val, chr): (String, String)) -> Result<Value, Error> {
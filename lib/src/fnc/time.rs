@@ -1,11 +1,26 @@
 use crate::err::Error;
 use crate::sql::datetime::Datetime;
 use crate::sql::value::Value;
+use chrono::format::{Item, StrftimeItems};
 use chrono::prelude::*;
 use chrono::Datelike;
 use chrono::DurationRound;
 use chrono::Timelike;
 use chrono::Utc;
+use chrono_tz::Tz;
+
+pub fn add((datetime, duration): (Value, Value)) -> Result<Value, Error> {
+	Ok(match (datetime, duration) {
+		(Value::Datetime(v), Value::Duration(w)) => match chrono::Duration::from_std(*w) {
+			Ok(d) => match v.checked_add_signed(d) {
+				Some(v) => v.into(),
+				_ => Value::None,
+			},
+			_ => Value::None,
+		},
+		_ => Value::None,
+	})
+}
 
 pub fn day((datetime,): (Option<Value>,)) -> Result<Value, Error> {
 	let date = match datetime {
@@ -30,6 +45,38 @@ pub fn floor((datetime, duration): (Value, Value)) -> Result<Value, Error> {
 	})
 }
 
+pub fn format(
+	(datetime, layout, timezone): (Value, Option<String>, Option<String>),
+) -> Result<Value, Error> {
+	let date = match datetime {
+		Value::Datetime(v) => v,
+		_ => return Ok(Value::None),
+	};
+	// Default to the ISO 8601 format
+	let layout = layout.unwrap_or_else(|| String::from("%+"));
+	// Check that the format layout is valid
+	let items = StrftimeItems::new(&layout).collect::<Vec<_>>();
+	if items.iter().any(|v| matches!(v, Item::Error)) {
+		return Err(Error::InvalidArguments {
+			name: String::from("time::format"),
+			message: format!("The format layout '{}' is invalid.", layout),
+		});
+	}
+	// Convert to the specified timezone, defaulting to UTC
+	match timezone {
+		None => Ok(date.format_with_items(items.into_iter()).to_string().into()),
+		Some(tz) => match tz.parse::<Tz>() {
+			Ok(tz) => {
+				Ok(date.with_timezone(&tz).format_with_items(items.into_iter()).to_string().into())
+			}
+			Err(_) => Err(Error::InvalidArguments {
+				name: String::from("time::format"),
+				message: format!("The timezone '{}' is not a valid IANA timezone.", tz),
+			}),
+		},
+	}
+}
+
 pub fn group((datetime, strand): (Value, Value)) -> Result<Value, Error> {
 	match datetime {
 		Value::Datetime(v) => match strand {
@@ -1,54 +1,76 @@
 use crate::err::Error;
+use crate::sql::datetime::datetime_raw;
 use crate::sql::geometry::Geometry;
 use crate::sql::number::Number;
 use crate::sql::table::Table;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
-use crate::sql::Strand;
+use bigdecimal::BigDecimal;
+use std::str::FromStr;
 
 pub fn bool((arg,): (Value,)) -> Result<Value, Error> {
 	Ok(arg.is_truthy().into())
 }
 
 pub fn datetime((arg,): (Value,)) -> Result<Value, Error> {
-	Ok(match arg {
-		Value::Datetime(_) => arg,
-		_ => Value::Datetime(arg.as_datetime()),
-	})
+	match arg {
+		Value::Datetime(_) => Ok(arg),
+		Value::Strand(ref v) => match datetime_raw(v) {
+			Ok(("", v)) => Ok(Value::Datetime(v)),
+			_ => Err(fail(&arg, "datetime")),
+		},
+		_ => Err(fail(&arg, "datetime")),
+	}
 }
 
 pub fn decimal((arg,): (Value,)) -> Result<Value, Error> {
-	Ok(match arg {
-		Value::Number(Number::Decimal(_)) => arg,
-		_ => Value::Number(Number::Decimal(arg.as_decimal())),
-	})
+	match arg {
+		Value::Number(Number::Decimal(_)) => Ok(arg),
+		_ => match numeric(&arg) {
+			Some(v) => Ok(Value::Number(Number::Decimal(v.as_decimal()))),
+			None => Err(fail(&arg, "decimal")),
+		},
+	}
 }
 
 pub fn duration((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
 		Value::Duration(_) => Ok(arg),
-		_ => Ok(Value::Duration(arg.as_duration())),
+		Value::Strand(ref v) => match crate::sql::duration::duration(v) {
+			Ok(("", v)) => Ok(Value::Duration(v)),
+			_ => Err(fail(&arg, "duration")),
+		},
+		_ => Err(fail(&arg, "duration")),
 	}
 }
 
 pub fn float((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
 		Value::Number(Number::Float(_)) => Ok(arg),
-		_ => Ok(Value::Number(Number::Float(arg.as_float()))),
+		_ => match numeric(&arg) {
+			Some(v) => Ok(Value::Number(Number::Float(v.as_float()))),
+			None => Err(fail(&arg, "float")),
+		},
 	}
 }
 
 pub fn int((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
 		Value::Number(Number::Int(_)) => Ok(arg),
-		_ => Ok(Value::Number(Number::Int(arg.as_int()))),
+		_ => match numeric(&arg) {
+			Some(v) => Ok(Value::Number(Number::Int(v.as_int()))),
+			None => Err(fail(&arg, "int")),
+		},
 	}
 }
 
 pub fn number((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
 		Value::Number(_) => Ok(arg),
-		_ => Ok(Value::Number(arg.as_number())),
+		_ => match numeric(&arg) {
+			Some(v) => Ok(Value::Number(v)),
+			None => Err(fail(&arg, "number")),
+		},
 	}
 }
 
@@ -65,6 +87,36 @@ pub fn point((arg1, arg2): (Value, Option<Value>)) -> Result<Value, Error> {
 	})
 }
 
+pub fn record((arg1, arg2): (Value, Option<Value>)) -> Result<Value, Error> {
+	match arg2 {
+		// Create a record from a table and an id
+		Some(arg2) => Ok(Value::Thing(Thing {
+			tb: match arg1 {
+				Value::Strand(v) if !v.is_empty() => v.as_string(),
+				Value::Table(v) => v.0,
+				v => return Err(fail(&v, "table")),
+			},
+			id: match arg2 {
+				Value::Thing(v) => v.id,
+				Value::Array(v) => v.into(),
+				Value::Object(v) => v.into(),
+				Value::Number(Number::Int(v)) => v.into(),
+				Value::Strand(v) if !v.is_empty() => v.as_string().into(),
+				v => return Err(fail(&v, "record id")),
+			},
+		})),
+		// Otherwise parse the record
+		None => match arg1 {
+			Value::Thing(_) => Ok(arg1),
+			Value::Strand(ref v) => match crate::sql::thing::thing(v) {
+				Ok(("", v)) => Ok(Value::Thing(v)),
+				_ => Err(fail(&arg1, "record")),
+			},
+			_ => Err(fail(&arg1, "record")),
+		},
+	}
+}
+
 pub fn regex((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
 		Value::Strand(v) => Ok(Value::Regex(v.as_str().into())),
@@ -72,8 +124,12 @@ pub fn regex((arg,): (Value,)) -> Result<Value, Error> {
 	}
 }
 
-pub fn string((arg,): (Strand,)) -> Result<Value, Error> {
-	Ok(arg.into())
+pub fn string((arg,): (Value,)) -> Result<Value, Error> {
+	match arg {
+		// Datetimes are output without quotes
+		Value::Datetime(v) => Ok(format!("{:?}", v.0).into()),
+		v => Ok(v.as_strand().into()),
+	}
 }
 
 pub fn table((arg,): (Value,)) -> Result<Value, Error> {
@@ -102,3 +158,27 @@ pub fn thing((arg1, arg2): (Value, Option<Value>)) -> Result<Value, Error> {
 		}
 	})
 }
+
+// Get the number which a value represents, if any
+fn numeric(val: &Value) -> Option<Number> {
+	match val {
+		Value::True => Some(Number::Int(1)),
+		Value::False => Some(Number::Int(0)),
+		Value::Number(v) => Some(v.clone()),
+		Value::Strand(v) => match v.trim().parse::<i64>() {
+			Ok(v) => Some(Number::Int(v)),
+			Err(_) => BigDecimal::from_str(v.trim()).ok().map(Number::Decimal),
+		},
+		Value::Duration(v) => Some(v.as_secs().into()),
+		Value::Datetime(v) => Some(v.timestamp().into()),
+		_ => None,
+	}
+}
+
+// The error for a value which can not be converted
+fn fail(val: &Value, into: &str) -> Error {
+	Error::ConvertTo {
+		from: val.to_string(),
+		into: into.to_owned(),
+	}
+}
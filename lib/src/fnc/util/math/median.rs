@@ -6,6 +6,14 @@ pub trait Median {
 
 impl Median for Sorted<&Vec<Number>> {
 	fn median(self) -> Number {
-		self.0.get(self.0.len() / 2).unwrap_or(&Number::NAN).clone()
+		let len = self.0.len();
+		match len {
+			// If an empty set, then return NaN
+			0 => Number::NAN,
+			// If an odd length, then return the middle value
+			_ if len % 2 == 1 => self.0[len / 2].clone(),
+			// Otherwise average the two middle values
+			_ => (&self.0[len / 2 - 1] + &self.0[len / 2]) / Number::from(2),
+		}
 	}
 }
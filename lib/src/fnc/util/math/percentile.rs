@@ -1,7 +1,7 @@
 use crate::sql::number::{Number, Sorted};
 
 pub trait Percentile {
-	/// Gets the N percentile, averaging neighboring records if non-exact
+	/// Gets the N percentile, interpolating linearly between neighboring records if non-exact
 	fn percentile(&self, perc: Number) -> Number;
 }
 
@@ -12,23 +12,19 @@ impl Percentile for Sorted<&Vec<Number>> {
 			return Number::NAN;
 		}
 		// If an invalid percentile, then return NaN
-		if (perc <= Number::from(0)) | (perc > Number::from(100)) {
+		if (perc < Number::from(0)) | (perc > Number::from(100)) {
 			return Number::NAN;
 		}
-		// Get the index of the specified percentile
-		let n_percent_idx = Number::from(self.0.len()) * perc / Number::from(100);
-		// Calculate the N percentile for the index
-		if n_percent_idx.to_float().fract().abs() < 1e-10 {
-			let idx = n_percent_idx.as_usize();
-			let val = self.0.get(idx - 1).unwrap_or(&Number::NAN).clone();
-			val
-		} else if n_percent_idx > Number::from(1) {
-			let idx = n_percent_idx.as_usize();
-			let val = self.0.get(idx - 1).unwrap_or(&Number::NAN);
-			let val = val + self.0.get(idx).unwrap_or(&Number::NAN);
-			val / Number::from(2)
-		} else {
-			Number::NAN
+		// Get the position of the specified percentile
+		let pos = perc.to_float() / 100.0 * (self.0.len() - 1) as f64;
+		let idx = pos.floor() as usize;
+		let frac = pos - pos.floor();
+		// Get the value at or below the position
+		let val = self.0[idx].clone();
+		// Interpolate towards the next value
+		match self.0.get(idx + 1) {
+			Some(next) if frac > 1e-10 => &val + &((next - &val) * Number::from(frac)),
+			_ => val,
 		}
 	}
 }
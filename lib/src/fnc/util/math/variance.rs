@@ -9,6 +9,10 @@ pub trait Variance {
 
 impl Variance for Vec<Number> {
 	fn variance(self, sample: bool) -> Number {
+		// If too few numbers, then return NaN
+		if self.len() <= sample as usize {
+			return Number::NAN;
+		}
 		let mean = self.mean();
 		let len = Number::from(self.len() - sample as usize);
 		let out = self.iter().map(|x| (x - &mean) * (x - &mean)).sum::<Number>() / len;
@@ -1,7 +1,11 @@
+use crate::cnf::MAX_CACHED_REGEXES;
 use deunicode::deunicode;
 use once_cell::sync::Lazy;
 use regex::Regex;
+use std::collections::HashMap;
+use std::sync::Mutex;
 
+static REGEXES: Lazy<Mutex<HashMap<String, Regex>>> = Lazy::new(|| Mutex::new(HashMap::new()));
 static SIMPLES: Lazy<Regex> = Lazy::new(|| Regex::new("[^a-z0-9-_]").unwrap());
 static HYPHENS: Lazy<Regex> = Lazy::new(|| Regex::new("-+").unwrap());
 
@@ -21,3 +25,22 @@ pub fn slug<S: AsRef<str>>(s: S) -> String {
 	// Return the string
 	s.to_owned()
 }
+
+pub fn regex(pattern: &str) -> Result<Regex, regex::Error> {
+	// Claim the regex cache
+	let mut cache = REGEXES.lock().unwrap();
+	// Check if the regex is already compiled
+	if let Some(re) = cache.get(pattern) {
+		return Ok(re.clone());
+	}
+	// Compile the regular expression
+	let re = Regex::new(pattern)?;
+	// Clear the cache if it is full
+	if cache.len() >= MAX_CACHED_REGEXES {
+		cache.clear();
+	}
+	// Store the compiled regex
+	cache.insert(pattern.to_owned(), re.clone());
+	// Return the regex
+	Ok(re)
+}
@@ -0,0 +1,60 @@
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Cf {
+	__: u8,
+	_a: u8,
+	pub ns: String,
+	_b: u8,
+	pub db: String,
+	_c: u8,
+	pub cf: u64,
+}
+
+pub fn new(ns: &str, db: &str, cf: u64) -> Cf {
+	Cf::new(ns.to_string(), db.to_string(), cf)
+}
+
+pub fn prefix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::database::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[0x23, 0x00]);
+	k
+}
+
+pub fn suffix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::database::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[0x23, 0xff]);
+	k
+}
+
+impl Cf {
+	pub fn new(ns: String, db: String, cf: u64) -> Cf {
+		Cf {
+			__: 0x2f, // /
+			_a: 0x2a, // *
+			ns,
+			_b: 0x2a, // *
+			db,
+			_c: 0x23, // #
+			cf,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Cf::new(
+			"test".to_string(),
+			"test".to_string(),
+			1,
+		);
+		let enc = Cf::encode(&val).unwrap();
+		let dec = Cf::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -0,0 +1,49 @@
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Cp {
+	__: u8,
+	_a: u8,
+	pub ns: String,
+	_b: u8,
+	pub db: String,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+}
+
+pub fn new(ns: &str, db: &str) -> Cp {
+	Cp::new(ns.to_string(), db.to_string())
+}
+
+impl Cp {
+	pub fn new(ns: String, db: String) -> Cp {
+		Cp {
+			__: 0x2f, // /
+			_a: 0x2a, // *
+			ns,
+			_b: 0x2a, // *
+			db,
+			_c: 0x21, // !
+			_d: 0x63, // c
+			_e: 0x70, // p
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Cp::new(
+			"test".to_string(),
+			"test".to_string(),
+		);
+		let enc = Cp::encode(&val).unwrap();
+		let dec = Cp::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -32,6 +32,37 @@ impl Prefix {
 	}
 }
 
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+struct PrefixIds {
+	__: u8,
+	_a: u8,
+	pub ns: String,
+	_b: u8,
+	pub db: String,
+	_c: u8,
+	pub tb: String,
+	_d: u8,
+	pub ix: String,
+	pub fd: Array,
+}
+
+impl PrefixIds {
+	fn new(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> PrefixIds {
+		PrefixIds {
+			__: 0x2f, // /
+			_a: 0x2a, // *
+			ns: ns.to_string(),
+			_b: 0x2a, // *
+			db: db.to_string(),
+			_c: 0x2a, // *
+			tb: tb.to_string(),
+			_d: 0xa4, // ¤
+			ix: ix.to_string(),
+			fd: fd.to_owned(),
+		}
+	}
+}
+
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
 pub struct Index {
 	__: u8,
@@ -70,6 +101,18 @@ pub fn suffix(ns: &str, db: &str, tb: &str, ix: &str) -> Vec<u8> {
 	k
 }
 
+pub fn prefix_ids(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> Vec<u8> {
+	let mut k = PrefixIds::new(ns, db, tb, ix, fd).encode().unwrap();
+	k.extend_from_slice(&[0x00]);
+	k
+}
+
+pub fn suffix_ids(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> Vec<u8> {
+	let mut k = PrefixIds::new(ns, db, tb, ix, fd).encode().unwrap();
+	k.extend_from_slice(&[0xff]);
+	k
+}
+
 impl Index {
 	pub fn new(ns: String, db: String, tb: String, ix: String, fd: Array, id: Option<Id>) -> Index {
 		Index {
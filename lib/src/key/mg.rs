@@ -0,0 +1,64 @@
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Mg {
+	__: u8,
+	_a: u8,
+	pub ns: String,
+	_b: u8,
+	pub db: String,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub mg: String,
+}
+
+pub fn new(ns: &str, db: &str, mg: &str) -> Mg {
+	Mg::new(ns.to_string(), db.to_string(), mg.to_string())
+}
+
+pub fn prefix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::database::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[0x21, 0x6d, 0x67, 0x00]);
+	k
+}
+
+pub fn suffix(ns: &str, db: &str) -> Vec<u8> {
+	let mut k = super::database::new(ns, db).encode().unwrap();
+	k.extend_from_slice(&[0x21, 0x6d, 0x67, 0xff]);
+	k
+}
+
+impl Mg {
+	pub fn new(ns: String, db: String, mg: String) -> Mg {
+		Mg {
+			__: 0x2f, // /
+			_a: 0x2a, // *
+			ns,
+			_b: 0x2a, // *
+			db,
+			_c: 0x21, // !
+			_d: 0x6d, // m
+			_e: 0x67, // g
+			mg,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Mg::new(
+			"test".to_string(),
+			"test".to_string(),
+			"test".to_string(),
+		);
+		let enc = Mg::encode(&val).unwrap();
+		let dec = Mg::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
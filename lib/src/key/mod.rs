@@ -10,8 +10,15 @@
 /// DL              /*{ns}*{db}!dl{us}
 /// DT              /*{ns}*{db}!dt{tk}
 /// SC              /*{ns}*{db}!sc{sc}
+/// FC              /*{ns}*{db}!fn{fc}
+/// PA              /*{ns}*{db}!pa{pa}
+/// MG              /*{ns}*{db}!mg{mg}
 /// TB              /*{ns}*{db}!tb{tb}
 /// LQ              /*{ns}*{db}!lq{lq}
+/// CS              /*{ns}*{db}!cs
+/// CP              /*{ns}*{db}!cp
+///
+/// Change          /*{ns}*{db}#{cf}
 ///
 /// Scope           /*{ns}*{db}±{sc}
 /// ST              /*{ns}*{db}±{sc}!st{tk}
@@ -29,11 +36,15 @@
 ///
 /// Index           /*{ns}*{db}*{tb}¤{ix}{fd}{id}
 ///
+pub mod cf;
+pub mod cp;
+pub mod cs;
 pub mod database;
 pub mod db;
 pub mod dl;
 pub mod dt;
 pub mod ev;
+pub mod fc;
 pub mod fd;
 pub mod ft;
 pub mod graph;
@@ -42,10 +53,12 @@ pub mod ix;
 pub mod kv;
 pub mod lq;
 pub mod lv;
+pub mod mg;
 pub mod namespace;
 pub mod nl;
 pub mod ns;
 pub mod nt;
+pub mod pa;
 pub mod sc;
 pub mod scope;
 pub mod st;
@@ -2,13 +2,16 @@ use crate::kvs::kv::Key;
 use crate::sql::statements::DefineDatabaseStatement;
 use crate::sql::statements::DefineEventStatement;
 use crate::sql::statements::DefineFieldStatement;
+use crate::sql::statements::DefineFunctionStatement;
 use crate::sql::statements::DefineIndexStatement;
 use crate::sql::statements::DefineLoginStatement;
 use crate::sql::statements::DefineNamespaceStatement;
+use crate::sql::statements::DefineParamStatement;
 use crate::sql::statements::DefineScopeStatement;
 use crate::sql::statements::DefineTableStatement;
 use crate::sql::statements::DefineTokenStatement;
 use crate::sql::statements::LiveStatement;
+use crate::sql::statements::MigrateStatement;
 use std::collections::HashMap;
 use std::sync::Arc;
 
@@ -17,6 +20,8 @@ pub enum Entry {
 	Ns(Arc<DefineNamespaceStatement>),
 	Db(Arc<DefineDatabaseStatement>),
 	Tb(Arc<DefineTableStatement>),
+	Fc(Arc<DefineFunctionStatement>),
+	Pa(Arc<DefineParamStatement>),
 	Nss(Arc<[DefineNamespaceStatement]>),
 	Nls(Arc<[DefineLoginStatement]>),
 	Nts(Arc<[DefineTokenStatement]>),
@@ -24,6 +29,9 @@ pub enum Entry {
 	Dls(Arc<[DefineLoginStatement]>),
 	Dts(Arc<[DefineTokenStatement]>),
 	Scs(Arc<[DefineScopeStatement]>),
+	Fcs(Arc<[DefineFunctionStatement]>),
+	Pas(Arc<[DefineParamStatement]>),
+	Mgs(Arc<[MigrateStatement]>),
 	Sts(Arc<[DefineTokenStatement]>),
 	Tbs(Arc<[DefineTableStatement]>),
 	Evs(Arc<[DefineEventStatement]>),
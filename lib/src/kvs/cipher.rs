@@ -0,0 +1,165 @@
+use crate::err::Error;
+use crate::kvs::Val;
+use aes_gcm::aead::Aead;
+use aes_gcm::Aes256Gcm;
+use aes_gcm::KeyInit;
+use aes_gcm::Nonce;
+use sha2::{Digest, Sha256};
+use std::collections::BTreeMap;
+
+// The length in bytes of an encryption key
+const KEY_LEN: usize = 32;
+
+// The length in bytes of a value nonce
+const NONCE_LEN: usize = 12;
+
+/// A set of versioned keys used to encrypt stored values.
+///
+/// Each encrypted value is prefixed with the version of the key
+/// which was used to encrypt it, followed by a random nonce. New
+/// values are always encrypted with the highest key version, while
+/// older key versions remain available for decryption, so that
+/// values can be re-encrypted incrementally as they are rewritten.
+#[derive(Clone, Default)]
+pub struct Cipher {
+	keys: BTreeMap<u8, Aes256Gcm>,
+	// The raw keys, used to derive deterministic nonces
+	seeds: BTreeMap<u8, Vec<u8>>,
+}
+
+impl Cipher {
+	/// Create a new empty key set
+	pub fn new() -> Cipher {
+		Cipher::default()
+	}
+
+	/// Add a 256-bit encryption key with the specified version
+	///
+	/// ```rust
+	/// # use surrealdb::Cipher;
+	/// # use surrealdb::Error;
+	/// # fn main() -> Result<(), Error> {
+	/// let cipher = Cipher::new().with_key(1, &[0; 32])?.with_key(2, &[1; 32])?;
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_key(mut self, version: u8, key: &[u8]) -> Result<Cipher, Error> {
+		// Check the length of the key
+		if key.len() != KEY_LEN {
+			return Err(Error::InvalidKey);
+		}
+		// Store the key for this version
+		self.seeds.insert(version, key.to_vec());
+		let key = Aes256Gcm::new_from_slice(key).map_err(|_| Error::InvalidKey)?;
+		self.keys.insert(version, key);
+		Ok(self)
+	}
+
+	/// Encrypt a value using the latest key version
+	pub(crate) fn encrypt(&self, val: &[u8]) -> Result<Val, Error> {
+		// Get the latest key version
+		let (version, key) = self.keys.iter().next_back().ok_or(Error::InvalidKey)?;
+		// Generate a random nonce
+		let nonce: [u8; NONCE_LEN] = rand::random();
+		// Encrypt the value
+		seal(*version, key, &nonce, val)
+	}
+
+	/// Encrypt a value using the latest key version, so that equal values
+	/// are always encrypted to equal ciphertext
+	pub(crate) fn encrypt_deterministic(&self, val: &[u8]) -> Result<Val, Error> {
+		// Get the latest key version
+		let (version, key) = self.keys.iter().next_back().ok_or(Error::InvalidKey)?;
+		// Derive the nonce from the key and the value
+		let hash = Sha256::new().chain_update(&self.seeds[version]).chain_update(val).finalize();
+		// Encrypt the value
+		seal(*version, key, &hash[..NONCE_LEN], val)
+	}
+
+	/// Decrypt a value using the key version it was encrypted with
+	pub(crate) fn decrypt(&self, val: &[u8]) -> Result<Val, Error> {
+		// Check the length of the value
+		if val.len() < 1 + NONCE_LEN {
+			return Err(Error::Decrypt);
+		}
+		// Get the key for this version
+		let key = self.keys.get(&val[0]).ok_or(Error::Decrypt)?;
+		// Decrypt the value
+		let nonce = Nonce::from_slice(&val[1..1 + NONCE_LEN]);
+		key.decrypt(nonce, &val[1 + NONCE_LEN..]).map_err(|_| Error::Decrypt)
+	}
+}
+
+// Encrypt a value, prefixed with the key version and nonce
+fn seal(version: u8, key: &Aes256Gcm, nonce: &[u8], val: &[u8]) -> Result<Val, Error> {
+	// Encrypt the value
+	let out = key.encrypt(Nonce::from_slice(nonce), val).map_err(|_| Error::Encrypt)?;
+	// Prefix the key version and nonce
+	let mut res = Vec::with_capacity(1 + NONCE_LEN + out.len());
+	res.push(version);
+	res.extend_from_slice(nonce);
+	res.extend(out);
+	Ok(res)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn cipher_round_trip() {
+		let cipher = Cipher::new().with_key(1, &[7; 32]).unwrap();
+		let val = b"{ name: 'Tobie' }".to_vec();
+		let enc = cipher.encrypt(&val).unwrap();
+		assert_eq!(enc[0], 1);
+		assert_ne!(enc, val);
+		assert!(!enc.windows(5).any(|v| v == b"Tobie"));
+		assert_eq!(cipher.decrypt(&enc).unwrap(), val);
+	}
+
+	#[test]
+	fn cipher_uses_random_nonces() {
+		let cipher = Cipher::new().with_key(1, &[7; 32]).unwrap();
+		let one = cipher.encrypt(b"test").unwrap();
+		let two = cipher.encrypt(b"test").unwrap();
+		assert_ne!(one, two);
+	}
+
+	#[test]
+	fn cipher_deterministic_nonces() {
+		let cipher = Cipher::new().with_key(1, &[7; 32]).unwrap();
+		let one = cipher.encrypt_deterministic(b"test").unwrap();
+		let two = cipher.encrypt_deterministic(b"test").unwrap();
+		let three = cipher.encrypt_deterministic(b"other").unwrap();
+		assert_eq!(one, two);
+		assert_ne!(one, three);
+		assert_eq!(cipher.decrypt(&one).unwrap(), b"test".to_vec());
+		// The ciphertext depends on the key
+		let other = Cipher::new().with_key(1, &[8; 32]).unwrap();
+		assert_ne!(other.encrypt_deterministic(b"test").unwrap(), one);
+	}
+
+	#[test]
+	fn cipher_rotated_keys() {
+		let old = Cipher::new().with_key(1, &[7; 32]).unwrap();
+		let enc = old.encrypt(b"test").unwrap();
+		let new = old.with_key(2, &[8; 32]).unwrap();
+		assert_eq!(new.decrypt(&enc).unwrap(), b"test".to_vec());
+		assert_eq!(new.encrypt(b"test").unwrap()[0], 2);
+	}
+
+	#[test]
+	fn cipher_wrong_key() {
+		let one = Cipher::new().with_key(1, &[7; 32]).unwrap();
+		let two = Cipher::new().with_key(1, &[8; 32]).unwrap();
+		let enc = one.encrypt(b"test").unwrap();
+		assert!(matches!(two.decrypt(&enc), Err(Error::Decrypt)));
+		assert!(matches!(two.decrypt(b"test"), Err(Error::Decrypt)));
+	}
+
+	#[test]
+	fn cipher_invalid_key() {
+		assert!(matches!(Cipher::new().with_key(1, &[7; 16]), Err(Error::InvalidKey)));
+	}
+}
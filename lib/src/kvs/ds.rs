@@ -1,29 +1,82 @@
 use super::tx::Transaction;
+use super::Cipher;
+use crate::cnf;
 use crate::ctx::Context;
 use crate::dbs::Attach;
+use crate::dbs::Auth;
+use crate::dbs::Chunk;
 use crate::dbs::Executor;
+use crate::dbs::Format;
+use crate::dbs::Loader;
 use crate::dbs::Options;
+use crate::dbs::QueryCache;
 use crate::dbs::Response;
 use crate::dbs::Session;
+use crate::dbs::SlowLog;
+use crate::dbs::Validate;
 use crate::dbs::Variables;
+#[cfg(feature = "telemetry")]
+use crate::dbs::TRACER;
 use crate::err::Error;
+use crate::key::thing;
 use crate::kvs::LOG;
 use crate::sql;
+use crate::sql::statements::BeginStatement;
+use crate::sql::statements::CommitStatement;
 use crate::sql::Query;
+use crate::sql::Statement;
+use crate::sql::Statements;
+use crate::sql::Thing;
 use crate::sql::Value;
 use channel::Sender;
+use chrono::Utc;
 use futures::lock::Mutex;
+#[cfg(feature = "telemetry")]
+use opentelemetry::global;
+#[cfg(feature = "telemetry")]
+use opentelemetry::trace::Tracer;
+use std::collections::BTreeMap;
 use std::sync::Arc;
+use std::time::Duration;
+use trice::Instant;
 
 /// The underlying datastore instance which stores the dataset.
 pub struct Datastore {
 	pub(super) inner: Inner,
+	// The maximum depth of graph traversals
+	pub(super) depth: usize,
+	// The maximum number of loop iterations
+	pub(super) iterations: usize,
+	// The maximum complexity of a query
+	pub(super) complexity: Option<usize>,
+	// The keys used to encrypt stored values
+	pub(super) cipher: Option<Arc<Cipher>>,
+	// The keys used to encrypt ENCRYPTED fields
+	pub(super) fields: Option<Arc<Cipher>>,
+	// The cache of read only query results
+	pub(super) queries: Option<QueryCache>,
+	// The log of slow running queries
+	pub(super) slow: Option<SlowLog>,
+	// The maximum number of records returned by a statement
+	pub(super) results: Option<usize>,
+	// The maximum number of statements in a query
+	pub(super) statements: Option<usize>,
+	// The maximum number of retries of a conflicting transaction
+	pub(super) retries: usize,
+	// Whether panics during query execution are recovered
+	pub(super) recover: bool,
+	// Whether namespace and database names are case-insensitive
+	pub(super) fold: bool,
+	// Whether results are ordered by record id by default
+	pub(super) ordered: bool,
 }
 
 #[allow(clippy::large_enum_variant)]
 pub(super) enum Inner {
 	#[cfg(feature = "kv-mem")]
 	Mem(super::mem::Datastore),
+	#[cfg(all(test, feature = "kv-mem"))]
+	Fault(super::fault::Datastore),
 	#[cfg(feature = "kv-rocksdb")]
 	RocksDB(super::rocksdb::Datastore),
 	#[cfg(feature = "kv-indxdb")]
@@ -73,13 +126,12 @@ impl Datastore {
 	/// # }
 	/// ```
 	pub async fn new(path: &str) -> Result<Datastore, Error> {
-		match path {
+		// Initiate the desired datastore
+		let inner = match path {
 			#[cfg(feature = "kv-mem")]
 			"memory" => {
 				info!(target: LOG, "Starting kvs store in {}", path);
-				let v = super::mem::Datastore::new().await.map(|v| Datastore {
-					inner: Inner::Mem(v),
-				});
+				let v = super::mem::Datastore::new().await.map(Inner::Mem);
 				info!(target: LOG, "Started kvs store in {}", path);
 				v
 			}
@@ -89,9 +141,7 @@ impl Datastore {
 				info!(target: LOG, "Starting kvs store at {}", path);
 				let s = s.trim_start_matches("file://");
 				let s = s.trim_start_matches("file:");
-				let v = super::rocksdb::Datastore::new(s).await.map(|v| Datastore {
-					inner: Inner::RocksDB(v),
-				});
+				let v = super::rocksdb::Datastore::new(s).await.map(Inner::RocksDB);
 				info!(target: LOG, "Started kvs store at {}", path);
 				v
 			}
@@ -101,9 +151,7 @@ impl Datastore {
 				info!(target: LOG, "Starting kvs store at {}", path);
 				let s = s.trim_start_matches("rocksdb://");
 				let s = s.trim_start_matches("rocksdb:");
-				let v = super::rocksdb::Datastore::new(s).await.map(|v| Datastore {
-					inner: Inner::RocksDB(v),
-				});
+				let v = super::rocksdb::Datastore::new(s).await.map(Inner::RocksDB);
 				info!(target: LOG, "Started kvs store at {}", path);
 				v
 			}
@@ -113,9 +161,7 @@ impl Datastore {
 				info!(target: LOG, "Starting kvs store at {}", path);
 				let s = s.trim_start_matches("indxdb://");
 				let s = s.trim_start_matches("indxdb:");
-				let v = super::indxdb::Datastore::new(s).await.map(|v| Datastore {
-					inner: Inner::IndxDB(v),
-				});
+				let v = super::indxdb::Datastore::new(s).await.map(Inner::IndxDB);
 				info!(target: LOG, "Started kvs store at {}", path);
 				v
 			}
@@ -125,9 +171,7 @@ impl Datastore {
 				info!(target: LOG, "Connecting to kvs store at {}", path);
 				let s = s.trim_start_matches("tikv://");
 				let s = s.trim_start_matches("tikv:");
-				let v = super::tikv::Datastore::new(s).await.map(|v| Datastore {
-					inner: Inner::TiKV(v),
-				});
+				let v = super::tikv::Datastore::new(s).await.map(Inner::TiKV);
 				info!(target: LOG, "Connected to kvs store at {}", path);
 				v
 			}
@@ -137,15 +181,328 @@ impl Datastore {
 				info!(target: LOG, "Connecting to kvs store at {}", path);
 				let s = s.trim_start_matches("fdb://");
 				let s = s.trim_start_matches("fdb:");
-				let v = super::fdb::Datastore::new(s).await.map(|v| Datastore {
-					inner: Inner::FDB(v),
-				});
+				let v = super::fdb::Datastore::new(s).await.map(Inner::FDB);
 				info!(target: LOG, "Connected to kvs store at {}", path);
 				v
 			}
 			// The datastore path is not valid
 			_ => Err(Error::Ds("Unable to load the specified datastore".into())),
+		}?;
+		// Set the default datastore options
+		Ok(Datastore {
+			inner,
+			depth: cnf::MAX_GRAPH_DEPTH,
+			iterations: cnf::MAX_LOOP_ITERATIONS,
+			complexity: None,
+			cipher: None,
+			fields: None,
+			queries: None,
+			slow: None,
+			results: None,
+			statements: None,
+			retries: 0,
+			recover: false,
+			fold: false,
+			ordered: false,
+		})
+	}
+
+	// Create a memory datastore which injects faults into its transactions
+	#[cfg(all(test, feature = "kv-mem"))]
+	pub(crate) async fn with_faults(faults: Arc<super::Faults>) -> Result<Datastore, Error> {
+		let mut ds = Datastore::new("memory").await?;
+		ds.inner = Inner::Fault(super::fault::Datastore::new(faults).await?);
+		Ok(ds)
+	}
+
+	/// Specify the maximum depth of graph traversals in a single expression
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_depth(8);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_depth(mut self, depth: usize) -> Self {
+		self.depth = depth;
+		self
+	}
+
+	/// Specify the maximum number of iterations of a single FOR loop
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_iterations(1000);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_iterations(mut self, iterations: usize) -> Self {
+		self.iterations = iterations;
+		self
+	}
+
+	/// Specify the maximum complexity of a query, accounting for the nesting
+	/// of subqueries, functions, and values, and the graph edges traversed.
+	/// By default the complexity of a query is not limited.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_complexity(32);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_complexity(mut self, complexity: usize) -> Self {
+		self.complexity = Some(complexity);
+		self
+	}
+
+	/// Encrypt all stored values with the specified keys
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Cipher;
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let cipher = Cipher::new().with_key(1, &[0; 32])?;
+	/// let ds = Datastore::new("file://temp.db").await?.with_encryption(cipher);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_encryption(mut self, cipher: Cipher) -> Self {
+		self.cipher = Some(Arc::new(cipher));
+		self
+	}
+
+	/// Encrypt fields defined as ENCRYPTED with the specified keys
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Cipher;
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let cipher = Cipher::new().with_key(1, &[0; 32])?;
+	/// let ds = Datastore::new("file://temp.db").await?.with_field_encryption(cipher);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_field_encryption(mut self, cipher: Cipher) -> Self {
+		self.fields = Some(Arc::new(cipher));
+		self
+	}
+
+	/// Cache the results of read only queries for the specified duration
+	///
+	/// Cached results are invalidated whenever a write is committed to the
+	/// database which the query was run against, and are never shared
+	/// between sessions with different authentication or scope data.
+	///
+	/// ```rust,no_run
+	/// # use std::time::Duration;
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(10));
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_query_cache(mut self, ttl: Duration) -> Self {
+		self.queries = Some(QueryCache::new(ttl));
+		self
+	}
+
+	/// Log any queries which take longer than the specified duration to run
+	///
+	/// ```rust,no_run
+	/// # use std::time::Duration;
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_slow_query_log(Duration::from_millis(500));
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_slow_query_log(mut self, threshold: Duration) -> Self {
+		self.slow = Some(SlowLog::new(threshold));
+		self
+	}
+
+	/// Specify the maximum number of records which a statement can return
+	///
+	/// Any further records are dropped from the response, which is marked
+	/// as truncated, and which includes a cursor for fetching the remaining
+	/// records, when the statement supports `AFTER` pagination.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_max_results(10000);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_max_results(mut self, results: usize) -> Self {
+		self.results = Some(results);
+		self
+	}
+
+	/// Specify the maximum number of statements which a single query can contain
+	///
+	/// Queries with more statements are rejected before any statement is
+	/// executed. Queries run with root authentication are not limited.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_max_statements(100);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_max_statements(mut self, statements: usize) -> Self {
+		self.statements = Some(statements);
+		self
+	}
+
+	/// Specify how many times a statement is retried when its transaction conflicts
+	///
+	/// Only statements which are run outside of an explicit transaction are
+	/// retried, and only when the datastore reports a retriable conflict.
+	/// By default statements are not retried.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_max_retries(5);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_max_retries(mut self, retries: usize) -> Self {
+		self.retries = retries;
+		self
+	}
+
+	/// Specify whether panics during query execution are recovered
+	///
+	/// A recovered panic is logged with its stack trace, any running
+	/// transaction is cancelled, and the query returns an error.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_panic_recovery(true);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_panic_recovery(mut self, enabled: bool) -> Self {
+		if enabled {
+			crate::dbs::recover::install();
+		}
+		self.recover = enabled;
+		self
+	}
+
+	/// Specify whether namespace and database names are case-insensitive
+	///
+	/// Names are then stored and looked up in lowercase, so that a session
+	/// which selects `MyNS` uses the same namespace as one which selects
+	/// `myns`. Names are case-sensitive by default.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_case_insensitive_names(true);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_case_insensitive_names(mut self, enabled: bool) -> Self {
+		self.fold = enabled;
+		self
+	}
+
+	/// Specify whether results are ordered by record id by default
+	///
+	/// Without an ORDER BY clause, the order of the results of a SELECT
+	/// statement depends on how the records are stored and iterated. When
+	/// enabled, these results are sorted by record id instead. This can be
+	/// turned off for a query with `OPTION ORDERED = false`.
+	///
+	/// ```rust,no_run
+	/// # use surrealdb::Datastore;
+	/// # use surrealdb::Error;
+	/// # #[tokio::main]
+	/// # async fn main() -> Result<(), Error> {
+	/// let ds = Datastore::new("memory").await?.with_default_ordering(true);
+	/// # Ok(())
+	/// # }
+	/// ```
+	pub fn with_default_ordering(mut self, enabled: bool) -> Self {
+		self.ordered = enabled;
+		self
+	}
+
+	/// Get a namespace or database name as it is stored
+	pub fn name(&self, v: &str) -> String {
+		match self.fold {
+			true => v.to_lowercase(),
+			false => v.to_owned(),
+		}
+	}
+
+	/// Invalidate any cached query results for a database
+	pub(crate) fn invalidate(&self, ns: &str, db: &str) {
+		if let Some(cache) = &self.queries {
+			cache.invalidate(ns, db);
+		}
+	}
+
+	/// Parse a SQL query, unless it is too complex or too long to be executed
+	fn parse(&self, txt: &str, sess: &Session) -> Result<Query, Error> {
+		// Parse the SQL query text
+		#[cfg(feature = "telemetry")]
+		let ast = global::tracer(TRACER).in_span("parse", |_| sql::parse(txt))?;
+		#[cfg(not(feature = "telemetry"))]
+		let ast = sql::parse(txt)?;
+		// Check the complexity of the parsed query
+		if let Some(limit) = self.complexity {
+			let score = ast.complexity();
+			if score > limit {
+				return Err(Error::QueryTooComplex {
+					score,
+					limit,
+				});
+			}
+		}
+		// Check the number of statements in the query
+		if let Some(limit) = self.statements {
+			if !sess.au.is_kv() && ast.len() > limit {
+				return Err(Error::QueryTooLong {
+					count: ast.len(),
+					limit,
+				});
+			}
 		}
+		Ok(ast)
 	}
 
 	/// Create a new transaction on this datastore
@@ -172,6 +529,20 @@ impl Datastore {
 				Ok(Transaction {
 					inner: super::tx::Inner::Mem(tx),
 					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
+				})
+			}
+			#[cfg(all(test, feature = "kv-mem"))]
+			Inner::Fault(v) => {
+				let tx = v.transaction(write, lock).await?;
+				Ok(Transaction {
+					inner: super::tx::Inner::Fault(tx),
+					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
 				})
 			}
 			#[cfg(feature = "kv-rocksdb")]
@@ -180,6 +551,9 @@ impl Datastore {
 				Ok(Transaction {
 					inner: super::tx::Inner::RocksDB(tx),
 					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
 				})
 			}
 			#[cfg(feature = "kv-indxdb")]
@@ -188,6 +562,9 @@ impl Datastore {
 				Ok(Transaction {
 					inner: super::tx::Inner::IndxDB(tx),
 					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
 				})
 			}
 			#[cfg(feature = "kv-tikv")]
@@ -196,6 +573,9 @@ impl Datastore {
 				Ok(Transaction {
 					inner: super::tx::Inner::TiKV(tx),
 					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
 				})
 			}
 			#[cfg(feature = "kv-fdb")]
@@ -204,6 +584,9 @@ impl Datastore {
 				Ok(Transaction {
 					inner: super::tx::Inner::FDB(tx),
 					cache: super::cache::Cache::default(),
+					cipher: self.cipher.clone(),
+					fields: self.fields.clone(),
+					savepoints: vec![],
 				})
 			}
 		}
@@ -232,29 +615,208 @@ impl Datastore {
 		vars: Variables,
 		strict: bool,
 	) -> Result<Vec<Response>, Error> {
+		// Parse the SQL query text
+		let ast = self.parse(txt, sess)?;
+		// Process all statements
+		self.process(ast, sess, vars, strict).await
+	}
+
+	/// Execute a SQL query, returning the params defined by any LET statements
+	///
+	/// ```rust,no_run
+	/// use surrealdb::Datastore;
+	/// use surrealdb::Error;
+	/// use surrealdb::Session;
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let ses = Session::for_kv().with_ns("test").with_db("test");
+	///     let ast = "LET $name = 'Tobie';";
+	///     let (_, vars) = ds.execute_with_params(ast, &ses, None, false).await?;
+	///     let ast = "CREATE person SET name = $name;";
+	///     let res = ds.execute(ast, &ses, Some(vars), false).await?;
+	///     Ok(())
+	/// }
+	/// ```
+	pub async fn execute_with_params(
+		&self,
+		txt: &str,
+		sess: &Session,
+		vars: Variables,
+		strict: bool,
+	) -> Result<(Vec<Response>, BTreeMap<String, Value>), Error> {
+		self.execute_with_session(txt, &mut sess.clone(), vars, strict).await
+	}
+
+	/// Execute a SQL query, returning the params defined by any LET statements,
+	/// and keeping any NS or DB selected by USE statements on the session
+	///
+	/// ```rust,no_run
+	/// use surrealdb::Datastore;
+	/// use surrealdb::Error;
+	/// use surrealdb::Session;
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let mut ses = Session::for_kv();
+	///     let ast = "USE NS test DB test;";
+	///     ds.execute_with_session(ast, &mut ses, None, false).await?;
+	///     let ast = "CREATE person SET name = 'Tobie';";
+	///     let res = ds.execute(ast, &ses, None, false).await?;
+	///     Ok(())
+	/// }
+	/// ```
+	pub async fn execute_with_session(
+		&self,
+		txt: &str,
+		sess: &mut Session,
+		vars: Variables,
+		strict: bool,
+	) -> Result<(Vec<Response>, BTreeMap<String, Value>), Error> {
 		// Create a new query options
 		let mut opt = Options::default();
 		// Create a new query executor
-		let mut exe = Executor::new(self);
+		let mut exe = Executor::new(self)
+			.with_readonly(sess.readonly())
+			.with_roles(sess.rl.clone())
+			.with_max_results(self.results)
+			.with_max_retries(self.retries)
+			.with_panic_recovery(self.recover);
 		// Create a default context
 		let ctx = Context::default();
 		// Start an execution context
 		let ctx = sess.context(ctx);
+		// Parse the SQL query text
+		let ast = self.parse(txt, sess)?;
+		// Check the declared variable types
+		let vars = vars.validate(&ast)?;
+		// Keep the query details for the slow query log
+		let slow = self.slow.as_ref().map(|log| (log, ast.clone(), vars.clone(), Instant::now()));
 		// Store the query variables
-		let ctx = vars.attach(ctx);
+		let ctx = vars.attach(ctx)?;
+		// Setup the auth options
+		opt.auth = sess.au.clone();
+		// Setup the live options
+		opt.live = sess.rt;
+		// Set case-insensitive names config
+		opt.fold = self.fold;
+		// Set current NS and DB
+		opt.ns = sess.ns().map(|v| opt.name(&v).into());
+		opt.db = sess.db().map(|v| opt.name(&v).into());
+		// Set strict config
+		opt.strict = strict;
+		// Set graph depth config
+		opt.depth = self.depth;
+		// Set loop iterations config
+		opt.iterations = self.iterations;
+		// Set default ordering config
+		opt.ordered = self.ordered;
+		// Process all statements
+		let res = exe.execute(ctx, opt, ast).await?;
+		// Log the query if it was slow
+		if let Some((log, ast, vars, time)) = slow {
+			if let Some(entry) = log.check(&ast, sess, &vars, time.elapsed()) {
+				warn!(target: LOG, "Slow query: {}", entry);
+			}
+		}
+		// Keep the selected NS and DB on the session
+		let (ns, db) = exe.selected();
+		if ns.is_some() {
+			sess.ns = ns;
+		}
+		if db.is_some() {
+			sess.db = db;
+		}
+		// Return the defined params
+		Ok((res, exe.params()))
+	}
+
+	/// Execute a SQL query, sending each response to a channel as soon as it is ready
+	///
+	/// The records of a select statement which is not within a transaction
+	/// are sent one at a time as they are processed, followed by the end of
+	/// the statement response, so that large result sets are never held in
+	/// memory. Other statements send their complete response.
+	///
+	/// ```rust,no_run
+	/// use surrealdb::Datastore;
+	/// use surrealdb::Error;
+	/// use surrealdb::Session;
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let ses = Session::for_kv();
+	///     let ast = "USE NS test DB test; SELECT * FROM person;";
+	///     let (snd, rcv) = surrealdb::channel::new(1);
+	///     tokio::spawn(async move {
+	///         while let Ok(res) = rcv.recv().await {
+	///             println!("{:?}", res);
+	///         }
+	///     });
+	///     ds.stream(ast, &ses, None, false, snd).await?;
+	///     Ok(())
+	/// }
+	/// ```
+	pub async fn stream(
+		&self,
+		txt: &str,
+		sess: &Session,
+		vars: Variables,
+		strict: bool,
+		chn: Sender<Chunk>,
+	) -> Result<(), Error> {
+		// Create a new query options
+		let mut opt = Options::default();
+		// Create a new query executor
+		let mut exe = Executor::new(self)
+			.with_channel(chn)
+			.with_readonly(sess.readonly())
+			.with_roles(sess.rl.clone())
+			.with_max_results(self.results)
+			.with_max_retries(self.retries)
+			.with_panic_recovery(self.recover);
+		// Create a default context
+		let ctx = Context::default();
+		// Start an execution context
+		let ctx = sess.context(ctx);
 		// Parse the SQL query text
-		let ast = sql::parse(txt)?;
+		let ast = self.parse(txt, sess)?;
+		// Check the declared variable types
+		let vars = vars.validate(&ast)?;
+		// Keep the query details for the slow query log
+		let slow = self.slow.as_ref().map(|log| (log, ast.clone(), vars.clone(), Instant::now()));
+		// Store the query variables
+		let ctx = vars.attach(ctx)?;
 		// Setup the auth options
 		opt.auth = sess.au.clone();
 		// Setup the live options
 		opt.live = sess.rt;
+		// Set case-insensitive names config
+		opt.fold = self.fold;
 		// Set current NS and DB
-		opt.ns = sess.ns();
-		opt.db = sess.db();
+		opt.ns = sess.ns().map(|v| opt.name(&v).into());
+		opt.db = sess.db().map(|v| opt.name(&v).into());
 		// Set strict config
 		opt.strict = strict;
+		// Set graph depth config
+		opt.depth = self.depth;
+		// Set loop iterations config
+		opt.iterations = self.iterations;
+		// Set default ordering config
+		opt.ordered = self.ordered;
 		// Process all statements
-		exe.execute(ctx, opt, ast).await
+		exe.execute(ctx, opt, ast).await?;
+		// Log the query if it was slow
+		if let Some((log, ast, vars, time)) = slow {
+			if let Some(entry) = log.check(&ast, sess, &vars, time.elapsed()) {
+				warn!(target: LOG, "Slow query: {}", entry);
+			}
+		}
+		// Everything ok
+		Ok(())
 	}
 
 	/// Execute a pre-parsed SQL query
@@ -281,27 +843,193 @@ impl Datastore {
 		vars: Variables,
 		strict: bool,
 	) -> Result<Vec<Response>, Error> {
+		// Check the declared variable types
+		let vars = vars.validate(&ast)?;
+		// Check if the query results can be cached
+		let cache = match &self.queries {
+			Some(cache) => QueryCache::key(&ast, sess, &vars, strict).map(|key| (cache, key)),
+			None => None,
+		};
+		// Return any valid cached results
+		if let Some((cache, key)) = &cache {
+			if let Some(res) = cache.get(key) {
+				return Ok(res);
+			}
+		}
+		// Get the database version before executing
+		let version = match &cache {
+			Some((cache, _)) => cache.version(
+				&self.name(sess.ns.as_deref().unwrap()),
+				&self.name(sess.db.as_deref().unwrap()),
+			),
+			None => 0,
+		};
+		// Keep the query details for the slow query log
+		let slow = self.slow.as_ref().map(|log| (log, ast.clone(), vars.clone(), Instant::now()));
 		// Create a new query options
 		let mut opt = Options::default();
 		// Create a new query executor
-		let mut exe = Executor::new(self);
+		let mut exe = Executor::new(self)
+			.with_readonly(sess.readonly())
+			.with_roles(sess.rl.clone())
+			.with_max_results(self.results)
+			.with_max_retries(self.retries)
+			.with_panic_recovery(self.recover);
 		// Create a default context
 		let ctx = Context::default();
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
-		let ctx = vars.attach(ctx);
+		let ctx = vars.attach(ctx)?;
 		// Setup the auth options
 		opt.auth = sess.au.clone();
 		// Setup the live options
 		opt.live = sess.rt;
+		// Set case-insensitive names config
+		opt.fold = self.fold;
 		// Set current NS and DB
-		opt.ns = sess.ns();
-		opt.db = sess.db();
+		opt.ns = sess.ns().map(|v| opt.name(&v).into());
+		opt.db = sess.db().map(|v| opt.name(&v).into());
 		// Set strict config
 		opt.strict = strict;
+		// Set graph depth config
+		opt.depth = self.depth;
+		// Set loop iterations config
+		opt.iterations = self.iterations;
+		// Set default ordering config
+		opt.ordered = self.ordered;
 		// Process all statements
-		exe.execute(ctx, opt, ast).await
+		let res = exe.execute(ctx, opt, ast).await?;
+		// Log the query if it was slow
+		if let Some((log, ast, vars, time)) = slow {
+			if let Some(entry) = log.check(&ast, sess, &vars, time.elapsed()) {
+				warn!(target: LOG, "Slow query: {}", entry);
+			}
+		}
+		// Store the results in the cache
+		if let Some((cache, key)) = cache {
+			let ns = self.name(sess.ns.as_deref().unwrap());
+			let db = self.name(sess.db.as_deref().unwrap());
+			cache.set(key, &ns, &db, version, &res);
+		}
+		// Return the results
+		Ok(res)
+	}
+
+	/// Execute a batch of SQL queries, returning the responses for each query in order
+	///
+	/// When `atomic` is true, all of the queries are run within a single
+	/// transaction, so that a failure in any query cancels the whole batch.
+	/// Each query is still only run with its own variables.
+	/// Otherwise each query is executed separately, and a failure in one
+	/// query is reported without affecting the other queries in the batch.
+	///
+	/// ```rust,no_run
+	/// use surrealdb::Datastore;
+	/// use surrealdb::Error;
+	/// use surrealdb::Session;
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let ses = Session::for_kv().with_ns("test").with_db("test");
+	///     let qry = vec![
+	///         (String::from("CREATE person:tobie;"), None),
+	///         (String::from("SELECT * FROM person;"), None),
+	///     ];
+	///     let res = ds.batch(qry, &ses, false, true).await?;
+	///     Ok(())
+	/// }
+	/// ```
+	pub async fn batch(
+		&self,
+		qry: Vec<(String, Variables)>,
+		sess: &Session,
+		strict: bool,
+		atomic: bool,
+	) -> Result<Vec<Result<Vec<Response>, Error>>, Error> {
+		match atomic {
+			// Execute each query separately
+			false => {
+				let mut out = Vec::with_capacity(qry.len());
+				for (txt, vars) in qry.into_iter() {
+					out.push(self.execute(&txt, sess, vars, strict).await);
+				}
+				Ok(out)
+			}
+			// Execute all queries in one transaction
+			true => {
+				// Store the response count for each query
+				let mut len = Vec::with_capacity(qry.len());
+				// Store the parsed queries and their variables
+				let mut all = Vec::with_capacity(qry.len());
+				// Parse each of the SQL queries
+				for (txt, vars) in qry.into_iter() {
+					let ast = self.parse(&txt, sess)?;
+					// Count the statements which produce a response
+					let mut cnt = 0;
+					for v in ast.iter() {
+						match v {
+							Statement::Begin(_) | Statement::Cancel(_) | Statement::Commit(_) => {
+								return Err(Error::InvalidBatch {
+									message: String::from(
+										"Transaction statements are not allowed in a transactional batch",
+									),
+								})
+							}
+							Statement::Option(_) => (),
+							_ => cnt += 1,
+						}
+					}
+					len.push(cnt);
+					// Check the declared variable types
+					let vars = vars.validate(&ast)?;
+					all.push((ast, vars));
+				}
+				// Create a new query options
+				let mut opt = Options::default();
+				// Create a new query executor
+				let mut exe = Executor::new(self)
+					.with_readonly(sess.readonly())
+					.with_roles(sess.rl.clone())
+					.with_max_results(self.results)
+					.with_max_retries(self.retries)
+					.with_panic_recovery(self.recover);
+				// Setup the auth options
+				opt.auth = sess.au.clone();
+				// Setup the live options
+				opt.live = sess.rt;
+				// Set case-insensitive names config
+				opt.fold = self.fold;
+				// Set current NS and DB
+				opt.ns = sess.ns().map(|v| opt.name(&v).into());
+				opt.db = sess.db().map(|v| opt.name(&v).into());
+				// Set strict config
+				opt.strict = strict;
+				// Set graph depth config
+				opt.depth = self.depth;
+				// Set loop iterations config
+				opt.iterations = self.iterations;
+				// Set default ordering config
+				opt.ordered = self.ordered;
+				// Begin the transaction before the first query
+				let ctx = sess.context(Context::default());
+				let ast = Query(Statements(vec![Statement::Begin(BeginStatement::default())]));
+				exe.execute(ctx, opt.clone(), ast).await?;
+				// Process each query with only its own variables
+				for (ast, vars) in all.into_iter() {
+					let ctx = vars.attach(sess.context(Context::default()))?;
+					exe.execute(ctx, opt.clone(), ast).await?;
+				}
+				// Commit the transaction after the last query
+				let ctx = sess.context(Context::default());
+				let ast = Query(Statements(vec![Statement::Commit(CommitStatement)]));
+				let res = exe.execute(ctx, opt, ast).await?;
+				// Split the responses for each query
+				let mut res = res.into_iter();
+				Ok(len.into_iter().map(|n| Ok(res.by_ref().take(n).collect())).collect())
+			}
+		}
 	}
 
 	/// Ensure a SQL [`Value`] is fully computed
@@ -330,7 +1058,7 @@ impl Datastore {
 		strict: bool,
 	) -> Result<Value, Error> {
 		// Start a new transaction
-		let txn = self.transaction(val.writeable(), false).await?;
+		let txn = self.transaction(val.writeable() && !sess.readonly(), false).await?;
 		//
 		let txn = Arc::new(Mutex::new(txn));
 		// Create a new query options
@@ -340,14 +1068,22 @@ impl Datastore {
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
-		let ctx = vars.attach(ctx);
+		let ctx = vars.attach(ctx)?;
 		// Setup the auth options
 		opt.auth = sess.au.clone();
+		// Set case-insensitive names config
+		opt.fold = self.fold;
 		// Set current NS and DB
-		opt.ns = sess.ns();
-		opt.db = sess.db();
+		opt.ns = sess.ns().map(|v| opt.name(&v).into());
+		opt.db = sess.db().map(|v| opt.name(&v).into());
 		// Set strict config
 		opt.strict = strict;
+		// Set graph depth config
+		opt.depth = self.depth;
+		// Set loop iterations config
+		opt.iterations = self.iterations;
+		// Set default ordering config
+		opt.ordered = self.ordered;
 		// Compute the value
 		let res = val.compute(&ctx, &opt, &txn, None).await?;
 		// Store any data
@@ -364,7 +1100,204 @@ impl Datastore {
 		// Start a new transaction
 		let mut txn = self.transaction(false, false).await?;
 		// Process the export
-		txn.export(&ns, &db, chn).await?;
+		txn.export(&self.name(&ns), &self.name(&db), chn).await?;
+		// Everything ok
+		Ok(())
+	}
+
+	/// Creates a loader which imports lines of NDJSON or CSV data into a table in batches
+	///
+	/// ```rust,no_run
+	/// use surrealdb::load::Format;
+	/// use surrealdb::Datastore;
+	/// use surrealdb::Error;
+	/// use surrealdb::Session;
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let ses = Session::for_kv().with_ns("test").with_db("test");
+	///     let mut ldr = ds.loader(&ses, "person", Format::Ndjson, 1000, false);
+	///     ldr.push(r#"{ "name": "Tobie" }"#).await;
+	///     let res = ldr.finish().await;
+	///     Ok(())
+	/// }
+	/// ```
+	pub fn loader(
+		&self,
+		sess: &Session,
+		tb: &str,
+		fmt: Format,
+		size: usize,
+		strict: bool,
+	) -> Loader<'_> {
+		Loader::new(self, sess, tb, fmt, size, strict)
+	}
+
+	/// Retrieves the change feed events for a database, starting at the specified sequence number
+	pub async fn changes(
+		&self,
+		ns: String,
+		db: String,
+		from: u64,
+		limit: u32,
+	) -> Result<Vec<Value>, Error> {
+		// Get the names as they are stored
+		let (ns, db) = (self.name(&ns), self.name(&db));
+		// Start a new transaction
+		let mut txn = self.transaction(false, false).await?;
+		// Check the events have not been pruned
+		let last = txn.last_cp(&ns, &db).await?;
+		if last > 0 && from <= last {
+			txn.cancel().await?;
+			return Err(Error::ChangefeedExpired {
+				seq: from,
+				oldest: last + 1,
+			});
+		}
+		// Fetch the change feed events
+		let res = txn.all_cf(&ns, &db, from, limit).await?;
+		// Cancel the transaction
+		txn.cancel().await?;
+		// Return the events
+		Ok(res)
+	}
+
+	/// Deletes any change feed events which are older than the retention period of their database
+	pub async fn prune(&self) -> Result<(), Error> {
+		// Fetch all databases which have a retention period
+		let mut dbs = vec![];
+		let mut txn = self.transaction(false, false).await?;
+		for ns in txn.all_ns().await?.iter() {
+			for db in txn.all_db(&ns.name).await?.iter() {
+				if let (true, Some(v)) = (db.changefeed, &db.retention) {
+					dbs.push((ns.name.0.clone(), db.name.0.clone(), v.0));
+				}
+			}
+		}
+		txn.cancel().await?;
+		// Process each database in batches
+		for (ns, db, ttl) in dbs.iter() {
+			// Events before this time have expired
+			let exp = match chrono::Duration::from_std(*ttl) {
+				Ok(v) => Utc::now() - v,
+				Err(_) => continue,
+			};
+			loop {
+				// Fetch the next batch of events
+				let mut txn = self.transaction(true, false).await?;
+				let beg = txn.last_cp(ns, db).await?;
+				let res = txn.all_cf(ns, db, beg + 1, cnf::EXPIRED_BATCH_SIZE).await?;
+				// Get total results
+				let n = res.len();
+				// Find the last expired event
+				let mut end = beg;
+				let mut done = n < cnf::EXPIRED_BATCH_SIZE as usize;
+				for v in res.iter() {
+					if let Value::Object(v) = v {
+						// Events are stored in the order they were written
+						if let Some(Value::Datetime(t)) = v.get("time") {
+							if t.0 > exp {
+								done = true;
+								break;
+							}
+						}
+						end = v.get("seq").map_or(end, |v| v.clone().as_int() as u64);
+					}
+				}
+				// Delete the expired events
+				if end > beg {
+					let min = crate::key::cf::new(ns, db, beg + 1);
+					let max = crate::key::cf::new(ns, db, end + 1);
+					txn.delr(min..max, u32::MAX).await?;
+					txn.set(crate::key::cp::new(ns, db), end.to_be_bytes().to_vec()).await?;
+					txn.commit().await?;
+				} else {
+					txn.cancel().await?;
+				}
+				// Exit when settled
+				if done || end == beg {
+					break;
+				}
+			}
+		}
+		// Everything ok
+		Ok(())
+	}
+
+	/// Deletes any records which have passed the expiry time of their table
+	pub async fn reap(&self) -> Result<(), Error> {
+		// Fetch all tables which have an expiry time
+		let mut tbs = vec![];
+		let mut txn = self.transaction(false, false).await?;
+		for ns in txn.all_ns().await?.iter() {
+			for db in txn.all_db(&ns.name).await?.iter() {
+				for tb in txn.all_tb(&ns.name, &db.name).await?.iter() {
+					if tb.ttl.is_some() {
+						tbs.push((ns.name.0.clone(), db.name.0.clone(), tb.name.0.clone()));
+					}
+				}
+			}
+		}
+		txn.cancel().await?;
+		// Process each table in batches
+		for (ns, db, tb) in tbs.iter() {
+			// Prepare the start and end keys
+			let mut beg = thing::prefix(ns, db, tb);
+			let end = thing::suffix(ns, db, tb);
+			loop {
+				// Fetch the next batch of records
+				let mut txn = self.transaction(false, false).await?;
+				let res = txn.scan(beg.clone()..end.clone(), cnf::EXPIRED_BATCH_SIZE).await?;
+				txn.cancel().await?;
+				// Get total results
+				let n = res.len();
+				// Collect the expired record ids
+				let mut ids = vec![];
+				for (i, (k, v)) in res.into_iter().enumerate() {
+					// Ready the next
+					if n == i + 1 {
+						beg = k.clone();
+						beg.push(0x00);
+					}
+					// Parse the data from the store
+					let val: Value = (&v).into();
+					if val.is_expired() {
+						let key: thing::Thing = (&k).into();
+						ids.push(Value::from(Thing::from((key.tb, key.id))));
+					}
+				}
+				// Delete the expired records in a transaction
+				if !ids.is_empty() {
+					// Create a new query options
+					let mut opt = Options::new(Auth::Kv);
+					// Set current NS and DB
+					opt.ns = Some(ns.as_str().into());
+					opt.db = Some(db.as_str().into());
+					// Process expired records
+					let opt = opt.expired(true);
+					// Remove expired records permanently
+					let opt = opt.deleted(true);
+					// Store the expired record ids
+					let mut ctx = Context::default();
+					ctx.add_value(String::from("ids"), Value::from(ids));
+					// Only delete records which are still expired
+					let ast = sql::parse("DELETE $ids WHERE __.expires <= time::now()")?;
+					// Process the statement
+					for res in Executor::new(self)
+						.with_max_retries(self.retries)
+						.execute(ctx, opt, ast)
+						.await?
+					{
+						res.result?;
+					}
+				}
+				// Exit when settled
+				if n < cnf::EXPIRED_BATCH_SIZE as usize {
+					break;
+				}
+			}
+		}
 		// Everything ok
 		Ok(())
 	}
@@ -0,0 +1,137 @@
+#![cfg(all(test, feature = "kv-mem"))]
+
+use crate::err::Error;
+use crate::kvs::Key;
+use crate::kvs::Val;
+use std::ops::Range;
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+
+/// The faults which are injected into the transactions of a datastore
+#[derive(Default)]
+pub struct Faults {
+	// The number of commits which fail with a conflict
+	pub conflicts: AtomicUsize,
+	// The number of scans which panic
+	pub panics: AtomicUsize,
+}
+
+impl Faults {
+	// Use up one of the remaining faults
+	fn take(v: &AtomicUsize) -> bool {
+		v.fetch_update(Ordering::SeqCst, Ordering::SeqCst, |v| v.checked_sub(1)).is_ok()
+	}
+}
+
+pub struct Datastore {
+	// The underlying memory datastore
+	db: super::mem::Datastore,
+	// The faults to inject into transactions
+	faults: Arc<Faults>,
+}
+
+pub struct Transaction {
+	// The underlying memory transaction
+	tx: super::mem::Transaction,
+	// The faults to inject into the transaction
+	faults: Arc<Faults>,
+}
+
+impl Datastore {
+	// Open a new database
+	pub async fn new(faults: Arc<Faults>) -> Result<Datastore, Error> {
+		Ok(Datastore {
+			db: super::mem::Datastore::new().await?,
+			faults,
+		})
+	}
+	// Start a new transaction
+	pub async fn transaction(&self, write: bool, lock: bool) -> Result<Transaction, Error> {
+		Ok(Transaction {
+			tx: self.db.transaction(write, lock).await?,
+			faults: self.faults.clone(),
+		})
+	}
+}
+
+impl Transaction {
+	// Check if closed
+	pub fn closed(&self) -> bool {
+		self.tx.closed()
+	}
+	// Cancel a transaction
+	pub fn cancel(&mut self) -> Result<(), Error> {
+		self.tx.cancel()
+	}
+	// Commit a transaction, unless a conflict is injected
+	pub fn commit(&mut self) -> Result<(), Error> {
+		if Faults::take(&self.faults.conflicts) {
+			self.tx.cancel()?;
+			return Err(Error::TxConflict);
+		}
+		self.tx.commit()
+	}
+	// Check if a key exists
+	pub fn exi<K>(&mut self, key: K) -> Result<bool, Error>
+	where
+		K: Into<Key>,
+	{
+		self.tx.exi(key)
+	}
+	// Fetch a key from the database
+	pub fn get<K>(&mut self, key: K) -> Result<Option<Val>, Error>
+	where
+		K: Into<Key>,
+	{
+		self.tx.get(key)
+	}
+	// Insert or update a key in the database
+	pub fn set<K, V>(&mut self, key: K, val: V) -> Result<(), Error>
+	where
+		K: Into<Key>,
+		V: Into<Val>,
+	{
+		self.tx.set(key, val)
+	}
+	// Insert a key if it doesn't exist in the database
+	pub fn put<K, V>(&mut self, key: K, val: V) -> Result<(), Error>
+	where
+		K: Into<Key>,
+		V: Into<Val>,
+	{
+		self.tx.put(key, val)
+	}
+	// Insert a key if it doesn't exist in the database
+	pub fn putc<K, V>(&mut self, key: K, val: V, chk: Option<V>) -> Result<(), Error>
+	where
+		K: Into<Key>,
+		V: Into<Val>,
+	{
+		self.tx.putc(key, val, chk)
+	}
+	// Delete a key
+	pub fn del<K>(&mut self, key: K) -> Result<(), Error>
+	where
+		K: Into<Key>,
+	{
+		self.tx.del(key)
+	}
+	// Delete a key
+	pub fn delc<K, V>(&mut self, key: K, chk: Option<V>) -> Result<(), Error>
+	where
+		K: Into<Key>,
+		V: Into<Val>,
+	{
+		self.tx.delc(key, chk)
+	}
+	// Retrieve a range of keys from the databases, unless a panic is injected
+	pub fn scan<K>(&mut self, rng: Range<K>, limit: u32) -> Result<Vec<(Key, Val)>, Error>
+	where
+		K: Into<Key>,
+	{
+		if Faults::take(&self.faults.panics) {
+			panic!("Simulated panic");
+		}
+		self.tx.scan(rng, limit)
+	}
+}
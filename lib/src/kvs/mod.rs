@@ -1,5 +1,7 @@
 mod cache;
+mod cipher;
 mod ds;
+mod fault;
 mod fdb;
 mod indxdb;
 mod kv;
@@ -8,8 +10,12 @@ mod rocksdb;
 mod tikv;
 mod tx;
 
+pub use self::cipher::*;
 pub use self::ds::*;
 pub use self::kv::*;
 pub use self::tx::*;
 
+#[cfg(all(test, feature = "kv-mem"))]
+pub(crate) use self::fault::Faults;
+
 pub const LOG: &str = "surrealdb::kvs";
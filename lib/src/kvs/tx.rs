@@ -6,20 +6,26 @@ use crate::err::Error;
 use crate::key::thing;
 use crate::kvs::cache::Cache;
 use crate::kvs::cache::Entry;
+use crate::kvs::Cipher;
 use crate::sql;
 use crate::sql::thing::Thing;
+use crate::sql::value::Value;
 use channel::Sender;
 use sql::permission::Permissions;
 use sql::statements::DefineDatabaseStatement;
 use sql::statements::DefineEventStatement;
 use sql::statements::DefineFieldStatement;
+use sql::statements::DefineFunctionStatement;
 use sql::statements::DefineIndexStatement;
 use sql::statements::DefineLoginStatement;
 use sql::statements::DefineNamespaceStatement;
+use sql::statements::DefineParamStatement;
 use sql::statements::DefineScopeStatement;
 use sql::statements::DefineTableStatement;
 use sql::statements::DefineTokenStatement;
+use sql::statements::Encryption;
 use sql::statements::LiveStatement;
+use sql::statements::MigrateStatement;
 use std::ops::Range;
 use std::sync::Arc;
 
@@ -27,12 +33,17 @@ use std::sync::Arc;
 pub struct Transaction {
 	pub(super) inner: Inner,
 	pub(super) cache: Cache,
+	pub(super) cipher: Option<Arc<Cipher>>,
+	pub(super) fields: Option<Arc<Cipher>>,
+	pub(super) savepoints: Vec<Vec<(Key, Option<Val>)>>,
 }
 
 #[allow(clippy::large_enum_variant)]
 pub(super) enum Inner {
 	#[cfg(feature = "kv-mem")]
 	Mem(super::mem::Transaction),
+	#[cfg(all(test, feature = "kv-mem"))]
+	Fault(super::fault::Transaction),
 	#[cfg(feature = "kv-rocksdb")]
 	RocksDB(super::rocksdb::Transaction),
 	#[cfg(feature = "kv-indxdb")]
@@ -57,6 +68,11 @@ impl Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.closed(),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.closed(),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -89,6 +105,11 @@ impl Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.cancel(),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.cancel(),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -121,6 +142,11 @@ impl Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.commit(),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.commit(),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -148,12 +174,20 @@ impl Transaction {
 	where
 		K: Into<Key>,
 	{
+		// Keep the previous value
+		let key: Key = key.into();
+		self.record(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.del(key),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.del(key),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -187,6 +221,11 @@ impl Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.exi(key),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.exi(key),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -214,12 +253,17 @@ impl Transaction {
 	where
 		K: Into<Key>,
 	{
-		match self {
+		let res = match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.get(key),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.get(key),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -240,6 +284,11 @@ impl Transaction {
 				inner: Inner::FDB(v),
 				..
 			} => v.get(key).await,
+		};
+		// Decrypt the value
+		match res? {
+			Some(v) => self.open(v).map(Some),
+			None => Ok(None),
 		}
 	}
 	/// Insert or update a key in the datastore.
@@ -248,12 +297,22 @@ impl Transaction {
 		K: Into<Key>,
 		V: Into<Val>,
 	{
+		// Keep the previous value
+		let key: Key = key.into();
+		self.record(&key).await?;
+		// Encrypt the value
+		let val = self.seal(val)?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.set(key, val),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.set(key, val),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -282,12 +341,22 @@ impl Transaction {
 		K: Into<Key>,
 		V: Into<Val>,
 	{
+		// Keep the previous value
+		let key: Key = key.into();
+		self.record(&key).await?;
+		// Encrypt the value
+		let val = self.seal(val)?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.put(key, val),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.put(key, val),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -317,12 +386,17 @@ impl Transaction {
 	where
 		K: Into<Key>,
 	{
-		match self {
+		let res = match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.scan(rng, limit),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.scan(rng, limit),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -343,6 +417,11 @@ impl Transaction {
 				inner: Inner::FDB(v),
 				..
 			} => v.scan(rng, limit).await,
+		};
+		// Decrypt the values
+		match self.cipher {
+			Some(_) => res?.into_iter().map(|(k, v)| Ok((k, self.open(v)?))).collect(),
+			None => res,
 		}
 	}
 	/// Update a key in the datastore if the current value matches a condition.
@@ -351,12 +430,29 @@ impl Transaction {
 		K: Into<Key>,
 		V: Into<Val>,
 	{
+		// Encrypted values are checked once decrypted
+		if self.cipher.is_some() {
+			let key: Key = key.into();
+			let chk: Option<Val> = chk.map(Into::into);
+			if self.get(key.clone()).await? != chk {
+				return Err(Error::TxConditionNotMet);
+			}
+			return self.set(key, val).await;
+		}
+		// Keep the previous value
+		let key: Key = key.into();
+		self.record(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.putc(key, val, chk),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.putc(key, val, chk),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -385,12 +481,29 @@ impl Transaction {
 		K: Into<Key>,
 		V: Into<Val>,
 	{
+		// Encrypted values are checked once decrypted
+		if self.cipher.is_some() {
+			let key: Key = key.into();
+			let chk: Option<Val> = chk.map(Into::into);
+			if self.get(key.clone()).await? != chk {
+				return Err(Error::TxConditionNotMet);
+			}
+			return self.del(key).await;
+		}
+		// Keep the previous value
+		let key: Key = key.into();
+		self.record(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
 				..
 			} => v.delc(key, chk),
+			#[cfg(all(test, feature = "kv-mem"))]
+			Transaction {
+				inner: Inner::Fault(v),
+				..
+			} => v.delc(key, chk),
 			#[cfg(feature = "kv-rocksdb")]
 			Transaction {
 				inner: Inner::RocksDB(v),
@@ -413,6 +526,112 @@ impl Transaction {
 			} => v.delc(key, chk).await,
 		}
 	}
+	/// Create a savepoint within the transaction.
+	///
+	/// Any changes made after the savepoint can be reverted, without
+	/// cancelling the changes made before it. Returns the position of
+	/// the savepoint, which is used to roll back to or release it.
+	pub fn savepoint(&mut self) -> usize {
+		self.savepoints.push(vec![]);
+		self.savepoints.len() - 1
+	}
+	/// Revert all changes made since a savepoint.
+	///
+	/// The savepoint is kept, but any savepoints created after it are removed.
+	pub async fn rollback_to(&mut self, savepoint: usize) -> Result<(), Error> {
+		// Take the changes made since the savepoint
+		let mut stack = std::mem::take(&mut self.savepoints);
+		let undo = stack.split_off(savepoint);
+		// Restore the previous values, in reverse order
+		for (key, val) in undo.into_iter().flatten().rev() {
+			match val {
+				Some(v) => self.set(key, v).await?,
+				None => self.del(key).await?,
+			}
+		}
+		// Clear any definitions cached since the savepoint
+		self.cache = Cache::default();
+		// Keep the savepoint
+		stack.push(vec![]);
+		self.savepoints = stack;
+		Ok(())
+	}
+	/// Remove a savepoint, keeping the changes made since it was created.
+	///
+	/// The changes can still be reverted by rolling back to an earlier savepoint.
+	pub fn release(&mut self, savepoint: usize) {
+		let undo = self.savepoints.split_off(savepoint);
+		if let Some(v) = self.savepoints.last_mut() {
+			v.extend(undo.into_iter().flatten());
+		}
+	}
+	/// Store the current value of a key, if there is a savepoint to restore it to.
+	async fn record(&mut self, key: &Key) -> Result<(), Error> {
+		if !self.savepoints.is_empty() {
+			let val = self.get(key.clone()).await?;
+			if let Some(v) = self.savepoints.last_mut() {
+				v.push((key.clone(), val));
+			}
+		}
+		Ok(())
+	}
+	/// Encrypt a value if encryption is enabled.
+	fn seal<V>(&self, val: V) -> Result<Val, Error>
+	where
+		V: Into<Val>,
+	{
+		match &self.cipher {
+			Some(c) => c.encrypt(&val.into()),
+			None => Ok(val.into()),
+		}
+	}
+	/// Decrypt a value if encryption is enabled.
+	fn open(&self, val: Val) -> Result<Val, Error> {
+		match &self.cipher {
+			Some(c) => c.decrypt(&val),
+			None => Ok(val),
+		}
+	}
+	/// Encrypt the value of an ENCRYPTED field.
+	///
+	/// The encrypted value is stored as a hex encoded string.
+	pub fn seal_field(&self, fd: &DefineFieldStatement, val: &Value) -> Result<Value, Error> {
+		// Check that field encryption is enabled
+		let cipher = match &self.fields {
+			Some(c) => c,
+			None => {
+				return Err(Error::EncryptionDisabled {
+					field: fd.name.clone(),
+				})
+			}
+		};
+		// Encrypt the serialized value
+		let bin: Vec<u8> = val.into();
+		let out = match fd.encrypted {
+			Some(Encryption::Deterministic) => cipher.encrypt_deterministic(&bin)?,
+			_ => cipher.encrypt(&bin)?,
+		};
+		// Encode the encrypted value
+		Ok(Value::from(out.iter().map(|v| format!("{:02x}", v)).collect::<String>()))
+	}
+	/// Decrypt the value of an ENCRYPTED field.
+	///
+	/// Returns [`None`] if the value is not a value encrypted with the field encryption keys.
+	pub fn open_field(&self, val: &Value) -> Option<Value> {
+		// Check that field encryption is enabled
+		let cipher = self.fields.as_ref()?;
+		// Decode the encrypted value
+		let txt = match val {
+			Value::Strand(v) if v.len() % 2 == 0 => v.as_str(),
+			_ => return None,
+		};
+		let bin = (0..txt.len())
+			.step_by(2)
+			.map(|i| u8::from_str_radix(txt.get(i..i + 2)?, 16).ok())
+			.collect::<Option<Vec<u8>>>()?;
+		// Decrypt the serialized value
+		cipher.decrypt(&bin).ok().map(Value::from)
+	}
 	/// Retrieve a specific range of keys from the datastore.
 	///
 	/// This function fetches key-value pairs from the underlying datastore in batches of 1000.
@@ -463,6 +682,47 @@ impl Transaction {
 		}
 		Ok(out)
 	}
+	/// Count a range of keys in the datastore.
+	///
+	/// This function fetches key-value pairs from the underlying datastore in batches of 1000,
+	/// without deserializing the values.
+	pub async fn cnt<K>(&mut self, rng: Range<K>) -> Result<usize, Error>
+	where
+		K: Into<Key>,
+	{
+		let beg: Key = rng.start.into();
+		let end: Key = rng.end.into();
+		let mut nxt: Option<Key> = None;
+		let mut out: usize = 0;
+		// Start processing
+		loop {
+			// Get records batch
+			let res = match nxt {
+				None => {
+					let min = beg.clone();
+					let max = end.clone();
+					self.scan(min..max, 1000).await?
+				}
+				Some(ref mut beg) => {
+					beg.push(0x00);
+					let min = beg.clone();
+					let max = end.clone();
+					self.scan(min..max, 1000).await?
+				}
+			};
+			// Get total results
+			let n = res.len();
+			// Exit when settled
+			if n == 0 {
+				break;
+			}
+			// Ready the next
+			nxt = res.into_iter().last().map(|(k, _)| k);
+			// Count
+			out += n;
+		}
+		Ok(out)
+	}
 	/// Delete a range of keys from the datastore.
 	///
 	/// This function fetches key-value pairs from the underlying datastore in batches of 1000.
@@ -749,6 +1009,68 @@ impl Transaction {
 			}
 		}
 	}
+	/// Retrieve all function definitions for a specific database.
+	pub async fn all_fc(
+		&mut self,
+		ns: &str,
+		db: &str,
+	) -> Result<Arc<[DefineFunctionStatement]>, Error> {
+		let key = crate::key::fc::prefix(ns, db);
+		match self.cache.exi(&key) {
+			true => match self.cache.get(&key) {
+				Some(Entry::Fcs(v)) => Ok(v),
+				_ => unreachable!(),
+			},
+			_ => {
+				let beg = crate::key::fc::prefix(ns, db);
+				let end = crate::key::fc::suffix(ns, db);
+				let val = self.getr(beg..end, u32::MAX).await?;
+				let val = val.convert().into();
+				self.cache.set(key, Entry::Fcs(Arc::clone(&val)));
+				Ok(val)
+			}
+		}
+	}
+	/// Retrieve all param definitions for a specific database.
+	pub async fn all_pa(
+		&mut self,
+		ns: &str,
+		db: &str,
+	) -> Result<Arc<[DefineParamStatement]>, Error> {
+		let key = crate::key::pa::prefix(ns, db);
+		match self.cache.exi(&key) {
+			true => match self.cache.get(&key) {
+				Some(Entry::Pas(v)) => Ok(v),
+				_ => unreachable!(),
+			},
+			_ => {
+				let beg = crate::key::pa::prefix(ns, db);
+				let end = crate::key::pa::suffix(ns, db);
+				let val = self.getr(beg..end, u32::MAX).await?;
+				let val = val.convert().into();
+				self.cache.set(key, Entry::Pas(Arc::clone(&val)));
+				Ok(val)
+			}
+		}
+	}
+	/// Retrieve all applied migrations for a specific database.
+	pub async fn all_mg(&mut self, ns: &str, db: &str) -> Result<Arc<[MigrateStatement]>, Error> {
+		let key = crate::key::mg::prefix(ns, db);
+		match self.cache.exi(&key) {
+			true => match self.cache.get(&key) {
+				Some(Entry::Mgs(v)) => Ok(v),
+				_ => unreachable!(),
+			},
+			_ => {
+				let beg = crate::key::mg::prefix(ns, db);
+				let end = crate::key::mg::suffix(ns, db);
+				let val = self.getr(beg..end, u32::MAX).await?;
+				let val = val.convert().into();
+				self.cache.set(key, Entry::Mgs(Arc::clone(&val)));
+				Ok(val)
+			}
+		}
+	}
 	/// Retrieve all scope token definitions for a scope.
 	pub async fn all_st(
 		&mut self,
@@ -912,7 +1234,9 @@ impl Transaction {
 	/// Retrieve a specific namespace definition.
 	pub async fn get_ns(&mut self, ns: &str) -> Result<DefineNamespaceStatement, Error> {
 		let key = crate::key::ns::new(ns);
-		let val = self.get(key).await?.ok_or(Error::NsNotFound)?;
+		let val = self.get(key).await?.ok_or_else(|| Error::NsNotFound {
+			value: ns.to_owned(),
+		})?;
 		Ok(val.into())
 	}
 	/// Retrieve a specific namespace login definition.
@@ -930,7 +1254,9 @@ impl Transaction {
 	/// Retrieve a specific database definition.
 	pub async fn get_db(&mut self, ns: &str, db: &str) -> Result<DefineDatabaseStatement, Error> {
 		let key = crate::key::db::new(ns, db);
-		let val = self.get(key).await?.ok_or(Error::DbNotFound)?;
+		let val = self.get(key).await?.ok_or_else(|| Error::DbNotFound {
+			value: db.to_owned(),
+		})?;
 		Ok(val.into())
 	}
 	/// Retrieve a specific database login definition.
@@ -989,6 +1315,60 @@ impl Transaction {
 		let val = self.get(key).await?.ok_or(Error::TbNotFound)?;
 		Ok(val.into())
 	}
+	/// Retrieve change feed events for a specific database, starting at a sequence number.
+	pub async fn all_cf(
+		&mut self,
+		ns: &str,
+		db: &str,
+		from: u64,
+		limit: u32,
+	) -> Result<Vec<Value>, Error> {
+		let beg = crate::key::cf::new(ns, db, from).encode()?;
+		let end = crate::key::cf::suffix(ns, db);
+		let val = self.getr(beg..end, limit).await?;
+		Ok(val.into_iter().map(|(_, v)| Value::from(v)).collect())
+	}
+	/// Increment and retrieve the change feed sequence number for a specific database.
+	pub async fn next_cs(&mut self, ns: &str, db: &str) -> Result<u64, Error> {
+		let key = crate::key::cs::new(ns, db);
+		let seq = match self.get(key.clone()).await? {
+			Some(v) => <[u8; 8]>::try_from(v.as_slice()).map(u64::from_be_bytes).unwrap_or(0),
+			None => 0,
+		};
+		self.set(key, (seq + 1).to_be_bytes().to_vec()).await?;
+		Ok(seq + 1)
+	}
+	/// Retrieve the last pruned change feed sequence number for a specific database.
+	pub async fn last_cp(&mut self, ns: &str, db: &str) -> Result<u64, Error> {
+		let key = crate::key::cp::new(ns, db);
+		match self.get(key).await? {
+			Some(v) => Ok(<[u8; 8]>::try_from(v.as_slice()).map(u64::from_be_bytes).unwrap_or(0)),
+			None => Ok(0),
+		}
+	}
+	/// Retrieve and cache a specific param definition.
+	pub async fn get_and_cache_pa(
+		&mut self,
+		ns: &str,
+		db: &str,
+		pa: &str,
+	) -> Result<Arc<DefineParamStatement>, Error> {
+		let key = crate::key::pa::new(ns, db, pa).encode()?;
+		match self.cache.exi(&key) {
+			true => match self.cache.get(&key) {
+				Some(Entry::Pa(v)) => Ok(v),
+				_ => unreachable!(),
+			},
+			_ => {
+				let val = self.get(key.clone()).await?.ok_or_else(|| Error::PaNotFound {
+					value: pa.to_owned(),
+				})?;
+				let val: Arc<DefineParamStatement> = Arc::new(val.into());
+				self.cache.set(key, Entry::Pa(Arc::clone(&val)));
+				Ok(val)
+			}
+		}
+	}
 	/// Add a namespace with a default configuration, only if we are in dynamic mode.
 	pub async fn add_ns(
 		&mut self,
@@ -996,7 +1376,9 @@ impl Transaction {
 		strict: bool,
 	) -> Result<DefineNamespaceStatement, Error> {
 		match self.get_ns(ns).await {
-			Err(Error::NsNotFound) => match strict {
+			Err(Error::NsNotFound {
+				..
+			}) => match strict {
 				false => {
 					let key = crate::key::ns::new(ns);
 					let val = DefineNamespaceStatement {
@@ -1005,7 +1387,9 @@ impl Transaction {
 					self.put(key, &val).await?;
 					Ok(val)
 				}
-				true => Err(Error::NsNotFound),
+				true => Err(Error::NsNotFound {
+					value: ns.to_owned(),
+				}),
 			},
 			Err(e) => Err(e),
 			Ok(v) => Ok(v),
@@ -1019,16 +1403,21 @@ impl Transaction {
 		strict: bool,
 	) -> Result<DefineDatabaseStatement, Error> {
 		match self.get_db(ns, db).await {
-			Err(Error::DbNotFound) => match strict {
+			Err(Error::DbNotFound {
+				..
+			}) => match strict {
 				false => {
 					let key = crate::key::db::new(ns, db);
 					let val = DefineDatabaseStatement {
 						name: db.to_owned().into(),
+						..DefineDatabaseStatement::default()
 					};
 					self.put(key, &val).await?;
 					Ok(val)
 				}
-				true => Err(Error::DbNotFound),
+				true => Err(Error::DbNotFound {
+					value: db.to_owned(),
+				}),
 			},
 			Err(e) => Err(e),
 			Ok(v) => Ok(v),
@@ -1097,7 +1486,9 @@ impl Transaction {
 				_ => unreachable!(),
 			},
 			_ => {
-				let val = self.get(key.clone()).await?.ok_or(Error::NsNotFound)?;
+				let val = self.get(key.clone()).await?.ok_or_else(|| Error::NsNotFound {
+					value: ns.to_owned(),
+				})?;
 				let val: Arc<DefineNamespaceStatement> = Arc::new(val.into());
 				self.cache.set(key, Entry::Ns(Arc::clone(&val)));
 				Ok(val)
@@ -1117,7 +1508,9 @@ impl Transaction {
 				_ => unreachable!(),
 			},
 			_ => {
-				let val = self.get(key.clone()).await?.ok_or(Error::DbNotFound)?;
+				let val = self.get(key.clone()).await?.ok_or_else(|| Error::DbNotFound {
+					value: db.to_owned(),
+				})?;
 				let val: Arc<DefineDatabaseStatement> = Arc::new(val.into());
 				self.cache.set(key, Entry::Db(Arc::clone(&val)));
 				Ok(val)
@@ -1145,6 +1538,29 @@ impl Transaction {
 			}
 		}
 	}
+	/// Retrieve and cache a specific function definition.
+	pub async fn get_and_cache_fc(
+		&mut self,
+		ns: &str,
+		db: &str,
+		fc: &str,
+	) -> Result<Arc<DefineFunctionStatement>, Error> {
+		let key = crate::key::fc::new(ns, db, fc).encode()?;
+		match self.cache.exi(&key) {
+			true => match self.cache.get(&key) {
+				Some(Entry::Fc(v)) => Ok(v),
+				_ => unreachable!(),
+			},
+			_ => {
+				let val = self.get(key.clone()).await?.ok_or_else(|| Error::FcNotFound {
+					value: fc.to_owned(),
+				})?;
+				let val: Arc<DefineFunctionStatement> = Arc::new(val.into());
+				self.cache.set(key, Entry::Fc(Arc::clone(&val)));
+				Ok(val)
+			}
+		}
+	}
 	/// Add a namespace with a default configuration, only if we are in dynamic mode.
 	pub async fn add_and_cache_ns(
 		&mut self,
@@ -1152,7 +1568,9 @@ impl Transaction {
 		strict: bool,
 	) -> Result<Arc<DefineNamespaceStatement>, Error> {
 		match self.get_and_cache_ns(ns).await {
-			Err(Error::NsNotFound) => match strict {
+			Err(Error::NsNotFound {
+				..
+			}) => match strict {
 				false => {
 					let key = crate::key::ns::new(ns);
 					let val = DefineNamespaceStatement {
@@ -1161,7 +1579,9 @@ impl Transaction {
 					self.put(key, &val).await?;
 					Ok(Arc::new(val))
 				}
-				true => Err(Error::NsNotFound),
+				true => Err(Error::NsNotFound {
+					value: ns.to_owned(),
+				}),
 			},
 			Err(e) => Err(e),
 			Ok(v) => Ok(v),
@@ -1175,16 +1595,21 @@ impl Transaction {
 		strict: bool,
 	) -> Result<Arc<DefineDatabaseStatement>, Error> {
 		match self.get_and_cache_db(ns, db).await {
-			Err(Error::DbNotFound) => match strict {
+			Err(Error::DbNotFound {
+				..
+			}) => match strict {
 				false => {
 					let key = crate::key::db::new(ns, db);
 					let val = DefineDatabaseStatement {
 						name: db.to_owned().into(),
+						..DefineDatabaseStatement::default()
 					};
 					self.put(key, &val).await?;
 					Ok(Arc::new(val))
 				}
-				true => Err(Error::DbNotFound),
+				true => Err(Error::DbNotFound {
+					value: db.to_owned(),
+				}),
 			},
 			Err(e) => Err(e),
 			Ok(v) => Ok(v),
@@ -1289,6 +1714,34 @@ impl Transaction {
 				chn.send(bytes!("")).await?;
 			}
 		}
+		// Output FUNCTIONS
+		{
+			let fcs = self.all_fc(ns, db).await?;
+			if !fcs.is_empty() {
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("-- FUNCTIONS")).await?;
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("")).await?;
+				for fc in fcs.iter() {
+					chn.send(bytes!(format!("{};", fc))).await?;
+				}
+				chn.send(bytes!("")).await?;
+			}
+		}
+		// Output PARAMS
+		{
+			let pas = self.all_pa(ns, db).await?;
+			if !pas.is_empty() {
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("-- PARAMS")).await?;
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("")).await?;
+				for pa in pas.iter() {
+					chn.send(bytes!(format!("{};", pa))).await?;
+				}
+				chn.send(bytes!("")).await?;
+			}
+		}
 		// Output TABLES
 		{
 			let tbs = self.all_tb(ns, db).await?;
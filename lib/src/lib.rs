@@ -28,14 +28,23 @@ pub mod sql;
 
 // Exports
 pub use dbs::Auth;
+pub use dbs::Chunk;
 pub use dbs::Response;
 pub use dbs::Session;
 pub use err::Error;
+pub use kvs::Cipher;
 pub use kvs::Datastore;
 pub use kvs::Key;
 pub use kvs::Transaction;
 pub use kvs::Val;
 
+// Bulk imports
+pub mod load {
+	pub use crate::dbs::Batch;
+	pub use crate::dbs::Format;
+	pub use crate::dbs::Loader;
+}
+
 // Re-exports
 pub mod channel {
 	pub use channel::bounded as new;
@@ -0,0 +1,51 @@
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::param::param;
+use crate::sql::strand::strand;
+use crate::sql::value::Value;
+use nom::branch::alt;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::map;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize)]
+pub struct After(pub Value);
+
+impl fmt::Display for After {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "AFTER {}", self.0)
+	}
+}
+
+pub fn after(i: &str) -> IResult<&str, After> {
+	let (i, _) = tag_no_case("AFTER")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = alt((map(param, Value::from), map(strand, Value::from)))(i)?;
+	Ok((i, After(v)))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn after_statement_param() {
+		let sql = "AFTER $cursor";
+		let res = after(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("AFTER $cursor", format!("{}", out));
+	}
+
+	#[test]
+	fn after_statement_strand() {
+		let sql = "AFTER 'abc.def'";
+		let res = after(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(After(Value::from("abc.def")), out);
+		assert_eq!("AFTER 'abc.def'", format!("{}", out));
+	}
+}
@@ -14,6 +14,8 @@ pub enum Algorithm {
 	Hs256,
 	Hs384,
 	Hs512,
+	/// The keys are fetched from a JSON Web Key Set URL
+	Jwks,
 	Ps256,
 	Ps384,
 	Ps512,
@@ -38,6 +40,7 @@ impl fmt::Display for Algorithm {
 			Algorithm::Hs256 => "HS256",
 			Algorithm::Hs384 => "HS384",
 			Algorithm::Hs512 => "HS512",
+			Algorithm::Jwks => "JWKS",
 			Algorithm::Ps256 => "PS256",
 			Algorithm::Ps384 => "PS384",
 			Algorithm::Ps512 => "PS512",
@@ -57,6 +60,7 @@ pub fn algorithm(i: &str) -> IResult<&str, Algorithm> {
 		map(tag("HS256"), |_| Algorithm::Hs256),
 		map(tag("HS384"), |_| Algorithm::Hs384),
 		map(tag("HS512"), |_| Algorithm::Hs512),
+		map(tag("JWKS"), |_| Algorithm::Jwks),
 		map(tag("PS256"), |_| Algorithm::Ps256),
 		map(tag("PS384"), |_| Algorithm::Ps384),
 		map(tag("PS512"), |_| Algorithm::Ps512),
@@ -0,0 +1,252 @@
+use crate::cnf::PROTECTED_PARAM_NAMES;
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::comment::mightbespace;
+use crate::sql::common::colons;
+use crate::sql::error::IResult;
+use crate::sql::statements::create::{create, CreateStatement};
+use crate::sql::statements::delete::{delete, DeleteStatement};
+use crate::sql::statements::foreach::{foreach, ForeachStatement};
+use crate::sql::statements::ifelse::{ifelse, IfelseStatement};
+use crate::sql::statements::insert::{insert, InsertStatement};
+use crate::sql::statements::output::{output, OutputStatement};
+use crate::sql::statements::relate::{relate, RelateStatement};
+use crate::sql::statements::select::{select, SelectStatement};
+use crate::sql::statements::set::{set, SetStatement};
+use crate::sql::statements::update::{update, UpdateStatement};
+use crate::sql::value::Value;
+use nom::branch::alt;
+use nom::character::complete::char;
+use nom::combinator::map;
+use nom::multi::many0;
+use nom::multi::separated_list0;
+use nom::sequence::delimited;
+use serde::{Deserialize, Serialize};
+use std::cmp::Ordering;
+use std::fmt;
+use std::ops::Deref;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize)]
+pub struct Block(pub Vec<Entry>);
+
+impl Deref for Block {
+	type Target = Vec<Entry>;
+	fn deref(&self) -> &Self::Target {
+		&self.0
+	}
+}
+
+impl PartialOrd for Block {
+	#[inline]
+	fn partial_cmp(&self, _: &Self) -> Option<Ordering> {
+		None
+	}
+}
+
+impl Block {
+	pub(crate) fn complexity(&self) -> usize {
+		1 + self.iter().map(|v| v.complexity()).max().unwrap_or(0)
+	}
+
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Create a new context for the block
+		let mut ctx = Context::new(ctx);
+		// Process each statement in turn
+		for v in self.iter() {
+			match v {
+				// Prevent overriding the session params
+				Entry::Set(v) if PROTECTED_PARAM_NAMES.contains(&v.name.as_str()) => {
+					return Err(Error::InvalidParam {
+						name: v.name.to_owned(),
+					});
+				}
+				// Store the param for the rest of the block
+				Entry::Set(v) => {
+					let val = v.compute(&ctx, opt, txn, doc).await?;
+					ctx.add_value(v.name.to_owned(), val);
+				}
+				// Return the value, ending the block
+				Entry::Output(v) => return v.compute(&ctx, opt, txn, doc).await,
+				// Process any other statement
+				Entry::Ifelse(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Foreach(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Select(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Create(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Update(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Relate(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Delete(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+				Entry::Insert(v) => {
+					v.compute(&ctx, opt, txn, doc).await?;
+				}
+			}
+		}
+		// The block returned nothing
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for Block {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		match self.is_empty() {
+			true => write!(f, "{{}}"),
+			false => write!(
+				f,
+				"{{ {} }}",
+				self.iter().map(|v| format!("{};", v)).collect::<Vec<_>>().join(" ")
+			),
+		}
+	}
+}
+
+pub fn block(i: &str) -> IResult<&str, Block> {
+	let (i, _) = char('{')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, v) = separated_list0(colons, delimited(mightbespace, entry, mightbespace))(i)?;
+	let (i, _) = many0(colons)(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char('}')(i)?;
+	Ok((i, Block(v)))
+}
+
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize)]
+pub enum Entry {
+	Set(SetStatement),
+	Output(OutputStatement),
+	Ifelse(IfelseStatement),
+	Foreach(ForeachStatement),
+	Select(SelectStatement),
+	Create(CreateStatement),
+	Update(UpdateStatement),
+	Relate(RelateStatement),
+	Delete(DeleteStatement),
+	Insert(InsertStatement),
+}
+
+impl Entry {
+	pub(crate) fn writeable(&self) -> bool {
+		match self {
+			Entry::Set(v) => v.writeable(),
+			Entry::Output(v) => v.writeable(),
+			Entry::Ifelse(v) => v.writeable(),
+			Entry::Foreach(v) => v.writeable(),
+			Entry::Select(v) => v.writeable(),
+			Entry::Create(v) => v.writeable(),
+			Entry::Update(v) => v.writeable(),
+			Entry::Relate(v) => v.writeable(),
+			Entry::Delete(v) => v.writeable(),
+			Entry::Insert(v) => v.writeable(),
+		}
+	}
+
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Entry::Set(v) => v.complexity(),
+			Entry::Output(v) => v.complexity(),
+			Entry::Ifelse(v) => v.complexity(),
+			Entry::Foreach(v) => v.complexity(),
+			Entry::Select(v) => v.complexity(),
+			Entry::Create(v) => v.complexity(),
+			Entry::Update(v) => v.complexity(),
+			Entry::Relate(v) => v.complexity(),
+			Entry::Delete(v) => v.complexity(),
+			Entry::Insert(v) => v.complexity(),
+		}
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Entry::Set(v) => v.volatile(),
+			Entry::Output(v) => v.volatile(),
+			Entry::Ifelse(v) => v.volatile(),
+			Entry::Select(v) => v.volatile(),
+			// Loops and writes are never repeatable
+			_ => true,
+		}
+	}
+}
+
+impl fmt::Display for Entry {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		match self {
+			Entry::Set(v) => write!(f, "{}", v),
+			Entry::Output(v) => write!(f, "{}", v),
+			Entry::Ifelse(v) => write!(f, "{}", v),
+			Entry::Foreach(v) => write!(f, "{}", v),
+			Entry::Select(v) => write!(f, "{}", v),
+			Entry::Create(v) => write!(f, "{}", v),
+			Entry::Update(v) => write!(f, "{}", v),
+			Entry::Relate(v) => write!(f, "{}", v),
+			Entry::Delete(v) => write!(f, "{}", v),
+			Entry::Insert(v) => write!(f, "{}", v),
+		}
+	}
+}
+
+pub fn entry(i: &str) -> IResult<&str, Entry> {
+	alt((
+		map(set, Entry::Set),
+		map(output, Entry::Output),
+		map(ifelse, Entry::Ifelse),
+		map(select, Entry::Select),
+		map(create, Entry::Create),
+		map(update, Entry::Update),
+		map(relate, Entry::Relate),
+		map(delete, Entry::Delete),
+		map(insert, Entry::Insert),
+	))(i)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn block_empty() {
+		let sql = "{}";
+		let res = block(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("{}", format!("{}", out))
+	}
+
+	#[test]
+	fn block_statements() {
+		let sql = r#"{ LET $name = "Tobie"; RETURN "Hello " + $name; }"#;
+		let res = block(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(r#"{ LET $name = "Tobie"; RETURN "Hello " + $name; }"#, format!("{}", out))
+	}
+
+	#[test]
+	fn block_statements_without_semicolon() {
+		let sql = "{ RETURN 1 }";
+		let res = block(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("{ RETURN 1; }", format!("{}", out))
+	}
+}
@@ -34,6 +34,24 @@ impl Default for Data {
 }
 
 impl Data {
+	// Find the most complex value in the data clause
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Data::SetExpression(v) | Data::UpdateExpression(v) => {
+				v.iter().map(|(_, _, v)| v.complexity()).max().unwrap_or(0)
+			}
+			Data::PatchExpression(v)
+			| Data::MergeExpression(v)
+			| Data::ReplaceExpression(v)
+			| Data::ContentExpression(v)
+			| Data::SingleExpression(v) => v.complexity(),
+			Data::ValuesExpression(v) => {
+				v.iter().flatten().map(|(_, v)| v.complexity()).max().unwrap_or(0)
+			}
+			Data::EmptyExpression => 0,
+		}
+	}
+
 	// Fetch
 	pub(crate) fn rid(&self, tb: &Table) -> Result<Thing, Error> {
 		match self {
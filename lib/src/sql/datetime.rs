@@ -88,7 +88,7 @@ pub fn datetime(i: &str) -> IResult<&str, Datetime> {
 	))(i)
 }
 
-fn datetime_raw(i: &str) -> IResult<&str, Datetime> {
+pub(crate) fn datetime_raw(i: &str) -> IResult<&str, Datetime> {
 	alt((nano, time, date))(i)
 }
 
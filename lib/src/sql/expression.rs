@@ -28,6 +28,11 @@ impl Default for Expression {
 }
 
 impl Expression {
+	// Score the most complex side of the expression
+	pub(crate) fn complexity(&self) -> usize {
+		self.l.complexity().max(self.r.complexity())
+	}
+
 	// Create a new expression
 	fn new(l: Value, o: Operator, r: Value) -> Expression {
 		Expression {
@@ -200,4 +205,16 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!("(3 * 3 * 3) = (3 * 3 * 3)", format!("{}", out));
 	}
+
+	#[test]
+	fn expression_in() {
+		let sql = "user IN (SELECT id FROM user) AND age NOT IN [1, 2]";
+		let res = expression(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(
+			"user INSIDE (SELECT id FROM user) AND age NOTINSIDE [1, 2]",
+			format!("{}", out)
+		);
+	}
 }
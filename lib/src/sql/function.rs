@@ -1,17 +1,21 @@
 use crate::ctx::Context;
+use crate::dbs::Level;
 use crate::dbs::Options;
 use crate::dbs::Transaction;
 use crate::err::Error;
 use crate::fnc;
 use crate::sql::comment::mightbespace;
-use crate::sql::common::commas;
+use crate::sql::common::{commas, val_char};
 use crate::sql::error::IResult;
+use crate::sql::permission::Permission;
 use crate::sql::script::{script as func, Script};
 use crate::sql::value::{single, value, Value};
 use nom::branch::alt;
 use nom::bytes::complete::tag;
+use nom::bytes::complete::take_while1;
 use nom::character::complete::char;
 use nom::multi::separated_list0;
+use nom::multi::separated_list1;
 use serde::{Deserialize, Serialize};
 use std::cmp::Ordering;
 use std::fmt;
@@ -22,6 +26,7 @@ pub enum Function {
 	Cast(String, Value),
 	Normal(String, Vec<Value>),
 	Script(Script, Vec<Value>),
+	Custom(String, Vec<Value>),
 }
 
 impl PartialOrd for Function {
@@ -32,6 +37,28 @@ impl PartialOrd for Function {
 }
 
 impl Function {
+	// Score the nesting of the function arguments
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Function::Cast(_, v) => v.complexity(),
+			Function::Future(v) => 1 + v.complexity(),
+			Function::Normal(_, a) | Function::Script(_, a) | Function::Custom(_, a) => {
+				1 + a.iter().map(|v| v.complexity()).max().unwrap_or(0)
+			}
+		}
+	}
+
+	// Check if the function can return a different result each time it runs
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Function::Cast(_, v) => v.volatile(),
+			Function::Future(v) => v.volatile(),
+			Function::Normal(n, a) => fnc::volatile(n) || a.iter().any(|v| v.volatile()),
+			// Scripts and custom functions could do anything
+			Function::Script(_, _) | Function::Custom(_, _) => true,
+		}
+	}
+
 	// Get function name if applicable
 	pub fn name(&self) -> &str {
 		match self {
@@ -43,6 +70,7 @@ impl Function {
 	pub fn args(&self) -> &[Value] {
 		match self {
 			Function::Normal(_, a) => a,
+			Function::Custom(_, a) => a,
 			_ => &[],
 		}
 	}
@@ -124,12 +152,76 @@ impl Function {
 				fnc::cast::run(ctx, s, v)
 			}
 			Function::Normal(s, x) => {
+				// Remote requests can't be rolled back
+				if opt.dry && s.starts_with("http::") {
+					return Err(Error::DryRunNotAllowed {
+						message: String::from("Remote HTTP request functions can not be run"),
+					});
+				}
 				let mut a: Vec<Value> = Vec::with_capacity(x.len());
 				for v in x {
 					a.push(v.compute(ctx, opt, txn, doc).await?);
 				}
 				fnc::run(ctx, s, a).await
 			}
+			Function::Custom(s, x) => {
+				// Check this database is selected
+				opt.needs(Level::Db)?;
+				// Fetch the function definition
+				let val = {
+					// Clone transaction
+					let run = txn.clone();
+					// Claim transaction
+					let mut run = run.lock().await;
+					// Get the function definition
+					run.get_and_cache_fc(opt.ns(), opt.db(), s).await?
+				};
+				// Check the function permissions
+				if opt.perms && opt.auth.perms() {
+					match &val.permissions {
+						Permission::Full => (),
+						Permission::None => {
+							return Err(Error::FunctionPermissions {
+								name: s.to_owned(),
+							})
+						}
+						Permission::Specific(e) => {
+							// Disable permissions
+							let opt = &opt.perms(false);
+							// Process the PERMISSION clause
+							if !e.compute(ctx, opt, txn, doc).await?.is_truthy() {
+								return Err(Error::FunctionPermissions {
+									name: s.to_owned(),
+								});
+							}
+						}
+					}
+				}
+				// Check the number of arguments
+				if x.len() != val.args.len() {
+					return Err(Error::InvalidArguments {
+						name: format!("fn::{}", s),
+						message: match val.args.len() {
+							1 => String::from("The function expects 1 argument."),
+							l => format!("The function expects {} arguments.", l),
+						},
+					});
+				}
+				// Compute the function arguments
+				let mut a: Vec<Value> = Vec::with_capacity(x.len());
+				for v in x {
+					a.push(v.compute(ctx, opt, txn, doc).await?);
+				}
+				// Prevent infinite recursion
+				let opt = &opt.dive()?;
+				// Bind the arguments to the block
+				let mut ctx = Context::new(ctx);
+				for (name, value) in val.args.iter().zip(a) {
+					ctx.add_value(name.to_raw(), value);
+				}
+				// Run the function body
+				val.block.compute(&ctx, opt, txn, doc).await
+			}
 			#[allow(unused_variables)]
 			Function::Script(s, x) => {
 				#[cfg(feature = "scripting")]
@@ -168,12 +260,18 @@ impl fmt::Display for Function {
 				s,
 				e.iter().map(|ref v| format!("{}", v)).collect::<Vec<_>>().join(", ")
 			),
+			Function::Custom(ref s, ref e) => write!(
+				f,
+				"fn::{}({})",
+				s,
+				e.iter().map(|ref v| format!("{}", v)).collect::<Vec<_>>().join(", ")
+			),
 		}
 	}
 }
 
 pub fn function(i: &str) -> IResult<&str, Function> {
-	alt((normal, script, future, cast))(i)
+	alt((normal, script, custom, future, cast))(i)
 }
 
 fn normal(i: &str) -> IResult<&str, Function> {
@@ -199,6 +297,22 @@ fn script(i: &str) -> IResult<&str, Function> {
 	Ok((i, Function::Script(v, a)))
 }
 
+fn custom(i: &str) -> IResult<&str, Function> {
+	let (i, _) = tag("fn::")(i)?;
+	let (i, s) = function_custom_name(i)?;
+	let (i, _) = char('(')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, a) = separated_list0(commas, value)(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char(')')(i)?;
+	Ok((i, Function::Custom(s, a)))
+}
+
+pub fn function_custom_name(i: &str) -> IResult<&str, String> {
+	let (i, v) = separated_list1(tag("::"), take_while1(val_char))(i)?;
+	Ok((i, v.join("::")))
+}
+
 fn future(i: &str) -> IResult<&str, Function> {
 	let (i, _) = char('<')(i)?;
 	let (i, _) = tag("future")(i)?;
@@ -260,8 +374,11 @@ fn function_array(i: &str) -> IResult<&str, &str> {
 		tag("array::concat"),
 		tag("array::difference"),
 		tag("array::distinct"),
+		tag("array::flatten"),
 		tag("array::intersect"),
 		tag("array::len"),
+		tag("array::push"),
+		tag("array::remove"),
 		tag("array::sort::asc"),
 		tag("array::sort::desc"),
 		tag("array::sort"),
@@ -424,6 +541,7 @@ fn function_string(i: &str) -> IResult<&str, &str> {
 		tag("string::join"),
 		tag("string::length"),
 		tag("string::lowercase"),
+		tag("string::matches"),
 		tag("string::repeat"),
 		tag("string::replace"),
 		tag("string::reverse"),
@@ -439,8 +557,10 @@ fn function_string(i: &str) -> IResult<&str, &str> {
 
 fn function_time(i: &str) -> IResult<&str, &str> {
 	alt((
+		tag("time::add"),
 		tag("time::day"),
 		tag("time::floor"),
+		tag("time::format"),
 		tag("time::group"),
 		tag("time::hour"),
 		tag("time::mins"),
@@ -467,6 +587,7 @@ fn function_type(i: &str) -> IResult<&str, &str> {
 		tag("type::int"),
 		tag("type::number"),
 		tag("type::point"),
+		tag("type::record"),
 		tag("type::regex"),
 		tag("type::string"),
 		tag("type::table"),
@@ -559,4 +680,29 @@ mod tests {
 			)
 		);
 	}
+
+	#[test]
+	fn function_custom_expression() {
+		let sql = r#"fn::greet::formal($name, "Tobie")"#;
+		let res = function(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(r#"fn::greet::formal($name, "Tobie")"#, format!("{}", out));
+		assert_eq!(
+			out,
+			Function::Custom(
+				String::from("greet::formal"),
+				vec![Value::parse("$name"), Value::parse(r#""Tobie""#)]
+			)
+		);
+	}
+
+	#[test]
+	fn function_script_not_custom() {
+		let sql = "fn::script() { return 1; }";
+		let res = function(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(matches!(out, Function::Script(_, _)));
+	}
 }
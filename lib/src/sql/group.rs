@@ -1,9 +1,11 @@
 use crate::sql::comment::shouldbespace;
-use crate::sql::common::commas;
+use crate::sql::common::{commas, val_char};
 use crate::sql::error::IResult;
 use crate::sql::idiom::{basic, Idiom};
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
-use nom::combinator::opt;
+use nom::character::complete::satisfy;
+use nom::combinator::{not, opt};
 use nom::multi::separated_list1;
 use nom::sequence::tuple;
 use serde::{Deserialize, Serialize};
@@ -28,8 +30,18 @@ impl IntoIterator for Groups {
 	}
 }
 
+impl Groups {
+	/// Check if all records are grouped together
+	pub fn is_all(&self) -> bool {
+		self.0.is_empty()
+	}
+}
+
 impl fmt::Display for Groups {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		if self.is_all() {
+			return write!(f, "GROUP ALL");
+		}
 		write!(
 			f,
 			"GROUP BY {}",
@@ -56,6 +68,17 @@ impl fmt::Display for Group {
 
 pub fn group(i: &str) -> IResult<&str, Groups> {
 	let (i, _) = tag_no_case("GROUP")(i)?;
+	alt((group_all, group_by))(i)
+}
+
+fn group_all(i: &str) -> IResult<&str, Groups> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("ALL")(i)?;
+	let (i, _) = not(satisfy(val_char))(i)?;
+	Ok((i, Groups(vec![])))
+}
+
+fn group_by(i: &str) -> IResult<&str, Groups> {
 	let (i, _) = opt(tuple((shouldbespace, tag_no_case("BY"))))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, v) = separated_list1(commas, group_raw)(i)?;
@@ -105,4 +128,24 @@ mod tests {
 		);
 		assert_eq!("GROUP BY field, other.field", format!("{}", out));
 	}
+
+	#[test]
+	fn group_statement_all() {
+		let sql = "GROUP ALL";
+		let res = group(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out, Groups(vec![]));
+		assert_eq!("GROUP ALL", format!("{}", out));
+	}
+
+	#[test]
+	fn group_statement_all_field() {
+		let sql = "GROUP allowed";
+		let res = group(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out, Groups(vec![Group(Idiom::parse("allowed"))]));
+		assert_eq!("GROUP BY allowed", format!("{}", out));
+	}
 }
@@ -0,0 +1,45 @@
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::value::{value, Value};
+use nom::bytes::complete::tag_no_case;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::ops::Deref;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize)]
+pub struct Guard(pub Value);
+
+impl Deref for Guard {
+	type Target = Value;
+	fn deref(&self) -> &Self::Target {
+		&self.0
+	}
+}
+
+impl fmt::Display for Guard {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "IF {}", self.0)
+	}
+}
+
+pub fn guard(i: &str) -> IResult<&str, Guard> {
+	let (i, _) = tag_no_case("IF")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = value(i)?;
+	Ok((i, Guard(v)))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn guard_statement() {
+		let sql = "IF __version = 3";
+		let res = guard(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("IF __version = 3", format!("{}", out));
+	}
+}
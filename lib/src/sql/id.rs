@@ -79,6 +79,13 @@ impl Id {
 	pub fn rand() -> Id {
 		Id::String(nanoid!(20, &ID_CHARS))
 	}
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Id::Array(v) => v.iter().any(|v| v.volatile()),
+			Id::Object(v) => v.values().any(|v| v.volatile()),
+			_ => false,
+		}
+	}
 	pub fn to_raw(&self) -> String {
 		match self {
 			Id::Number(v) => v.to_string(),
@@ -62,6 +62,33 @@ impl From<Vec<Part>> for Idiom {
 }
 
 impl Idiom {
+	// Score the graph edges traversed, and the most complex condition
+	pub(crate) fn complexity(&self) -> usize {
+		// Count the graph edges which are traversed
+		let hops = self.iter().filter(|v| matches!(v, Part::Graph(_))).count();
+		// Find the most complex condition in the idiom
+		let deep = self
+			.iter()
+			.map(|v| match v {
+				Part::Where(v) => v.complexity(),
+				Part::Graph(v) => v.cond.as_ref().map_or(0, |v| v.complexity()),
+				_ => 0,
+			})
+			.max()
+			.unwrap_or(0);
+		hops + deep
+	}
+
+	// Check if any condition or record in the idiom is volatile
+	pub(crate) fn volatile(&self) -> bool {
+		self.iter().any(|v| match v {
+			Part::Where(v) => v.volatile(),
+			Part::Graph(v) => v.cond.as_ref().map_or(false, |v| v.volatile()),
+			Part::Thing(v) => v.id.volatile(),
+			_ => false,
+		})
+	}
+
 	// Appends a part to the end of this Idiom
 	pub(crate) fn push(mut self, n: Part) -> Idiom {
 		self.0.push(n);
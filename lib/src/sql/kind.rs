@@ -6,6 +6,7 @@ use nom::branch::alt;
 use nom::bytes::complete::tag;
 use nom::character::complete::char;
 use nom::combinator::map;
+use nom::combinator::opt;
 use nom::multi::separated_list1;
 use serde::{Deserialize, Serialize};
 use std::fmt;
@@ -47,6 +48,7 @@ impl fmt::Display for Kind {
 			Kind::Number => f.write_str("number"),
 			Kind::Object => f.write_str("object"),
 			Kind::String => f.write_str("string"),
+			Kind::Record(v) if v.is_empty() => f.write_str("record"),
 			Kind::Record(v) => write!(
 				f,
 				"record({})",
@@ -81,6 +83,11 @@ pub fn kind(i: &str) -> IResult<&str, Kind> {
 
 fn record(i: &str) -> IResult<&str, Vec<Table>> {
 	let (i, _) = tag("record")(i)?;
+	let (i, v) = opt(tables)(i)?;
+	Ok((i, v.unwrap_or_default()))
+}
+
+fn tables(i: &str) -> IResult<&str, Vec<Table>> {
 	let (i, _) = mightbespace(i)?;
 	let (i, _) = char('(')(i)?;
 	let (i, v) = separated_list1(commas, table)(i)?;
@@ -1,6 +1,8 @@
+pub(crate) mod after;
 pub(crate) mod algorithm;
 pub(crate) mod array;
 pub(crate) mod base;
+pub(crate) mod block;
 pub(crate) mod comment;
 pub(crate) mod common;
 pub(crate) mod cond;
@@ -19,6 +21,7 @@ pub(crate) mod function;
 pub(crate) mod geometry;
 pub(crate) mod graph;
 pub(crate) mod group;
+pub(crate) mod guard;
 pub(crate) mod id;
 pub(crate) mod ident;
 pub(crate) mod idiom;
@@ -39,6 +42,7 @@ pub(crate) mod permission;
 pub(crate) mod query;
 pub(crate) mod range;
 pub(crate) mod regex;
+pub(crate) mod role;
 pub(crate) mod script;
 pub mod serde;
 pub(crate) mod split;
@@ -64,6 +68,7 @@ pub use self::parser::*;
 pub use self::algorithm::Algorithm;
 pub use self::array::Array;
 pub use self::base::Base;
+pub use self::block::Block;
 pub use self::cond::Cond;
 pub use self::data::Data;
 pub use self::datetime::Datetime;
@@ -81,6 +86,7 @@ pub use self::geometry::Geometry;
 pub use self::graph::Graph;
 pub use self::group::Group;
 pub use self::group::Groups;
+pub use self::guard::Guard;
 pub use self::id::Id;
 pub use self::ident::Ident;
 pub use self::idiom::Idiom;
@@ -103,6 +109,7 @@ pub use self::permission::Permissions;
 pub use self::query::Query;
 pub use self::range::Range;
 pub use self::regex::Regex;
+pub use self::role::Role;
 pub use self::script::Script;
 pub use self::split::Split;
 pub use self::split::Splits;
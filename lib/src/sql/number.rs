@@ -1,10 +1,12 @@
 use crate::sql::ending::number as ending;
 use crate::sql::error::IResult;
 use crate::sql::serde::is_internal_serialization;
+use crate::sql::serde::is_safe_integer_serialization;
 use bigdecimal::BigDecimal;
 use bigdecimal::FromPrimitive;
 use bigdecimal::ToPrimitive;
 use nom::branch::alt;
+use nom::bytes::complete::tag;
 use nom::character::complete::i64;
 use nom::combinator::map;
 use nom::number::complete::recognize_float;
@@ -130,6 +132,9 @@ impl fmt::Display for Number {
 	}
 }
 
+// The largest integer which can be exactly represented by a 64-bit float
+const MAX_SAFE_INTEGER: u64 = 9_007_199_254_740_991;
+
 impl Serialize for Number {
 	fn serialize<S>(&self, s: S) -> Result<S::Ok, S::Error>
 	where
@@ -143,6 +148,11 @@ impl Serialize for Number {
 			}
 		} else {
 			match self {
+				Number::Int(v)
+					if v.unsigned_abs() > MAX_SAFE_INTEGER && is_safe_integer_serialization() =>
+				{
+					s.serialize_str(&v.to_string())
+				}
 				Number::Int(v) => s.serialize_i64(*v),
 				Number::Float(v) => s.serialize_f64(*v),
 				Number::Decimal(v) => s.serialize_some(v),
@@ -529,7 +539,9 @@ impl Sort for Vec<Number> {
 }
 
 pub fn number(i: &str) -> IResult<&str, Number> {
-	alt((map(integer, Number::from), map(decimal, Number::from)))(i)
+	alt((map(integer, Number::from), map(decimal, Number::from), map(decimal_suffix, Number::from)))(
+		i,
+	)
 }
 
 pub fn integer(i: &str) -> IResult<&str, i64> {
@@ -544,6 +556,13 @@ pub fn decimal(i: &str) -> IResult<&str, &str> {
 	Ok((i, v))
 }
 
+pub fn decimal_suffix(i: &str) -> IResult<&str, &str> {
+	let (i, v) = recognize_float(i)?;
+	let (i, _) = tag("dec")(i)?;
+	let (i, _) = ending(i)?;
+	Ok((i, v))
+}
+
 #[cfg(test)]
 mod tests {
 
@@ -579,6 +598,33 @@ mod tests {
 		assert_eq!(out, Number::from(123.45));
 	}
 
+	#[test]
+	fn number_decimal_suffix() {
+		let sql = "1.50dec";
+		let res = number(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("1.50", format!("{}", out));
+		assert_eq!(out, Number::Decimal(BigDecimal::from_str("1.50").unwrap()));
+	}
+
+	#[test]
+	fn number_decimal_suffix_integer() {
+		let sql = "150dec";
+		let res = number(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("150", format!("{}", out));
+		assert!(out.is_decimal());
+	}
+
+	#[test]
+	fn number_decimal_suffix_exact() {
+		let one = number("0.1dec").unwrap().1;
+		let two = number("0.2dec").unwrap().1;
+		assert_eq!(one + two, Number::Decimal(BigDecimal::from_str("0.3").unwrap()));
+	}
+
 	#[test]
 	fn number_decimal_neg() {
 		let sql = "-123.45";
@@ -48,17 +48,28 @@ impl From<Option<Object>> for Object {
 
 impl From<Operation> for Object {
 	fn from(v: Operation) -> Self {
-		Object(map! {
-			String::from("op") => match v.op {
-				Op::None => Value::from("none"),
-				Op::Add => Value::from("add"),
-				Op::Remove => Value::from("remove"),
-				Op::Replace => Value::from("replace"),
-				Op::Change => Value::from("change"),
-			},
-			String::from("path") => v.path.to_path().into(),
-			String::from("value") => v.value,
-		})
+		match v.op {
+			Op::Copy | Op::Move => Object(map! {
+				String::from("op") => match v.op {
+					Op::Copy => Value::from("copy"),
+					_ => Value::from("move"),
+				},
+				String::from("from") => v.value.jsonpath().to_path().into(),
+				String::from("path") => v.path.to_path().into(),
+			}),
+			_ => Object(map! {
+				String::from("op") => match v.op {
+					Op::Add => Value::from("add"),
+					Op::Remove => Value::from("remove"),
+					Op::Replace => Value::from("replace"),
+					Op::Change => Value::from("change"),
+					Op::Test => Value::from("test"),
+					_ => Value::from("none"),
+				},
+				String::from("path") => v.path.to_path().into(),
+				String::from("value") => v.value,
+			}),
+		}
 	}
 }
 
@@ -95,14 +106,31 @@ impl Object {
 	pub fn to_operation(&self) -> Result<Operation, Error> {
 		match self.get("op") {
 			Some(o) => match self.get("path") {
-				Some(p) => Ok(Operation {
-					op: o.into(),
-					path: p.jsonpath(),
-					value: match self.get("value") {
-						Some(v) => v.clone(),
-						None => Value::Null,
+				Some(p) => match Op::from(o) {
+					// The operation is not a valid JSON Patch operation
+					Op::None => Err(Error::InvalidPatch {
+						message: format!("'{}' is not a valid operation", o.to_strand().as_str()),
+					}),
+					// The source path is stored as the operation value
+					op @ (Op::Copy | Op::Move) => match self.get("from") {
+						Some(v) => Ok(Operation {
+							op,
+							path: p.jsonpath(),
+							value: v.clone(),
+						}),
+						None => Err(Error::InvalidPatch {
+							message: String::from("'from' key missing"),
+						}),
 					},
-				}),
+					op => Ok(Operation {
+						op,
+						path: p.jsonpath(),
+						value: match self.get("value") {
+							Some(v) => v.clone(),
+							None => Value::Null,
+						},
+					}),
+				},
 				_ => Err(Error::InvalidPatch {
 					message: String::from("'path' key missing"),
 				}),
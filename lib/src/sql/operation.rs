@@ -16,6 +16,9 @@ pub enum Op {
 	Remove,
 	Replace,
 	Change,
+	Copy,
+	Move,
+	Test,
 }
 
 impl Default for Op {
@@ -31,6 +34,9 @@ impl From<&Value> for Op {
 			"remove" => Op::Remove,
 			"replace" => Op::Replace,
 			"change" => Op::Change,
+			"copy" => Op::Copy,
+			"move" => Op::Move,
+			"test" => Op::Test,
 			_ => Op::None,
 		}
 	}
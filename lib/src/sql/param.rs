@@ -31,6 +31,14 @@ impl Deref for Param {
 }
 
 impl Param {
+	// Check if the parameter depends on the session
+	pub(crate) fn volatile(&self) -> bool {
+		match self.first() {
+			Some(Part::Field(v)) => matches!(v.as_str(), "auth" | "scope" | "session" | "token"),
+			_ => false,
+		}
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -65,6 +73,28 @@ impl Param {
 						// Return the desired field
 						res.get(ctx, opt, txn, pth.next()).await
 					}
+					// The base variable may be defined on the database
+					None if opt.ns.is_some() && opt.db.is_some() => {
+						// Fetch the param definition
+						let res = {
+							let mut run = txn.lock().await;
+							run.get_and_cache_pa(opt.ns(), opt.db(), v).await
+						};
+						match res {
+							// The param is defined on the database
+							Ok(res) => {
+								// Get the path parts
+								let pth: &[Part] = self;
+								// Return the desired field
+								res.value.get(ctx, opt, txn, pth.next()).await
+							}
+							// The param is not defined
+							Err(Error::PaNotFound {
+								..
+							}) => Ok(Value::None),
+							Err(e) => Err(e),
+						}
+					}
 					// The base variable does not exist
 					None => Ok(Value::None),
 				},
@@ -104,6 +104,51 @@ mod tests {
 		assert!(res.is_err());
 	}
 
+	#[test]
+	fn complexity_simple() {
+		let sql = "SELECT * FROM person WHERE age > 18; CREATE person SET name = 'Tobie';";
+		assert_eq!(parse(sql).unwrap().complexity(), 0);
+	}
+
+	#[test]
+	fn complexity_nested() {
+		let sql =
+			"SELECT * FROM (SELECT ->likes->post FROM user WHERE tags CONTAINS math::max([1, 2]))";
+		assert_eq!(parse(sql).unwrap().complexity(), 3);
+	}
+
+	#[test]
+	fn complexity_per_statement() {
+		let sql = "SELECT * FROM ((1)); SELECT * FROM (((1))); SELECT ->a->b->c FROM user";
+		assert_eq!(parse(sql).unwrap().complexity(), 3);
+	}
+
+	#[test]
+	fn complexity_ignores_text() {
+		let sql =
+			"SELECT * FROM person WHERE name = '(((->))' -- (((\n/* ((( */ AND `a(b` = \"[[\"";
+		assert_eq!(parse(sql).unwrap().complexity(), 0);
+	}
+
+	#[test]
+	fn complexity_valid_queries() {
+		let sql = "
+			LET $adult = 18;
+			IF $adult > 0 THEN (SELECT * FROM person WHERE age > $adult) END;
+			CREATE person SET tags = ['rust', 'go'], meta = { size: { width: 1, height: 2 } };
+			UPDATE person SET total = array::len(tags) WHERE tags CONTAINS 'rust';
+			RELATE person:tobie->likes->post:one SET time = time::now();
+			INSERT INTO person (id, name) VALUES ('one', 'Tobie'), ('two', 'Jaime');
+			SELECT ->likes->post.title, <-follows<-person AS fans FROM person;
+			DELETE person WHERE age < $adult RETURN NONE;
+		";
+		let res = parse(sql).unwrap();
+		assert_eq!(res.complexity(), 2);
+		// Every statement is scored
+		let score: Vec<usize> = res.iter().map(|v| v.complexity()).collect();
+		assert_eq!(score, vec![0, 1, 2, 1, 1, 0, 2, 0]);
+	}
+
 	#[test]
 	fn parser_try() {
 		let sql = "
@@ -22,3 +22,9 @@ pub static IN: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("in")]);
 pub static OUT: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("out")]);
 
 pub static META: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("__")]);
+
+pub static EXPIRES: Lazy<[Part; 2]> = Lazy::new(|| [Part::from("__"), Part::from("expires")]);
+
+pub static DELETED: Lazy<[Part; 2]> = Lazy::new(|| [Part::from("__"), Part::from("deleted")]);
+
+pub static VERSION: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("__version")]);
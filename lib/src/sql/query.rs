@@ -17,6 +17,17 @@ impl Deref for Query {
 	}
 }
 
+impl Query {
+	/// Scores the complexity of the parsed query. Each statement scores
+	/// the deepest nesting of its subqueries, functions, blocks, arrays,
+	/// and objects, where the graph edges traversed in an idiom are added
+	/// to that idiom's score. The query scores the same as its most complex
+	/// statement.
+	pub fn complexity(&self) -> usize {
+		self.iter().map(|v| v.complexity()).max().unwrap_or(0)
+	}
+}
+
 impl fmt::Display for Query {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
 		write!(f, "{}", self.0)
@@ -0,0 +1,95 @@
+use crate::sql::common::commas;
+use crate::sql::error::IResult;
+use crate::sql::statement::Statement;
+use nom::branch::alt;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::map;
+use nom::multi::separated_list1;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Copy, Debug, Eq, PartialEq, Serialize, Deserialize)]
+pub enum Role {
+	Viewer,
+	Editor,
+	Owner,
+}
+
+impl Role {
+	/// Checks whether this role can run the specified statement
+	pub(crate) fn allows(&self, stm: &Statement) -> bool {
+		match self {
+			// Viewers can only read data
+			Role::Viewer => match stm {
+				Statement::Set(v) => !v.writeable(),
+				Statement::Output(v) => !v.writeable(),
+				Statement::Ifelse(v) => !v.writeable(),
+				Statement::Foreach(v) => !v.writeable(),
+				Statement::Select(v) => !v.writeable(),
+				Statement::Create(_) => false,
+				Statement::Update(_) => false,
+				Statement::Relate(_) => false,
+				Statement::Delete(_) => false,
+				Statement::Insert(_) => false,
+				Statement::Define(_) => false,
+				Statement::Remove(_) => false,
+				Statement::Dry(_) => false,
+				Statement::Migrate(_) => false,
+				_ => true,
+			},
+			// Editors can change data, but not the schema
+			Role::Editor => {
+				!matches!(stm, Statement::Define(_) | Statement::Remove(_) | Statement::Migrate(_))
+			}
+			// Owners can run any statement
+			Role::Owner => true,
+		}
+	}
+}
+
+impl fmt::Display for Role {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		match self {
+			Role::Viewer => f.write_str("VIEWER"),
+			Role::Editor => f.write_str("EDITOR"),
+			Role::Owner => f.write_str("OWNER"),
+		}
+	}
+}
+
+pub fn role(i: &str) -> IResult<&str, Role> {
+	alt((
+		map(tag_no_case("VIEWER"), |_| Role::Viewer),
+		map(tag_no_case("EDITOR"), |_| Role::Editor),
+		map(tag_no_case("OWNER"), |_| Role::Owner),
+	))(i)
+}
+
+pub fn roles(i: &str) -> IResult<&str, Vec<Role>> {
+	separated_list1(commas, role)(i)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn role_single() {
+		let sql = "viewer";
+		let res = role(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(Role::Viewer, out);
+		assert_eq!("VIEWER", format!("{}", out));
+	}
+
+	#[test]
+	fn roles_multiple() {
+		let sql = "VIEWER, EDITOR";
+		let res = roles(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(vec![Role::Viewer, Role::Editor], out);
+	}
+}
@@ -3,6 +3,7 @@ use std::sync::atomic::Ordering;
 
 thread_local! {
 	static INTERNAL_SERIALIZATION: AtomicBool = AtomicBool::new(false);
+	static SAFE_INTEGER_SERIALIZATION: AtomicBool = AtomicBool::new(false);
 }
 
 pub(crate) fn is_internal_serialization() -> bool {
@@ -16,3 +17,15 @@ pub fn beg_internal_serialization() {
 pub fn end_internal_serialization() {
 	INTERNAL_SERIALIZATION.with(|v| v.store(false, Ordering::Relaxed))
 }
+
+pub(crate) fn is_safe_integer_serialization() -> bool {
+	SAFE_INTEGER_SERIALIZATION.with(|v| v.load(Ordering::Relaxed))
+}
+
+pub fn beg_safe_integer_serialization() {
+	SAFE_INTEGER_SERIALIZATION.with(|v| v.store(true, Ordering::Relaxed))
+}
+
+pub fn end_safe_integer_serialization() {
+	SAFE_INTEGER_SERIALIZATION.with(|v| v.store(false, Ordering::Relaxed))
+}
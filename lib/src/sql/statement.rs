@@ -9,19 +9,27 @@ use crate::sql::statements::begin::{begin, BeginStatement};
 use crate::sql::statements::cancel::{cancel, CancelStatement};
 use crate::sql::statements::commit::{commit, CommitStatement};
 use crate::sql::statements::create::{create, CreateStatement};
+use crate::sql::statements::declare::{declare, DeclareStatement};
 use crate::sql::statements::define::{define, DefineStatement};
 use crate::sql::statements::delete::{delete, DeleteStatement};
+use crate::sql::statements::dry::{dry, DryStatement};
+use crate::sql::statements::foreach::{foreach, ForeachStatement};
 use crate::sql::statements::ifelse::{ifelse, IfelseStatement};
 use crate::sql::statements::info::{info, InfoStatement};
 use crate::sql::statements::insert::{insert, InsertStatement};
 use crate::sql::statements::kill::{kill, KillStatement};
 use crate::sql::statements::live::{live, LiveStatement};
+use crate::sql::statements::migrate::{migrate, MigrateStatement};
 use crate::sql::statements::option::{option, OptionStatement};
 use crate::sql::statements::output::{output, OutputStatement};
 use crate::sql::statements::relate::{relate, RelateStatement};
+use crate::sql::statements::release::{release, ReleaseStatement};
 use crate::sql::statements::remove::{remove, RemoveStatement};
+use crate::sql::statements::rollback::{rollback, RollbackStatement};
+use crate::sql::statements::savepoint::{savepoint, SavepointStatement};
 use crate::sql::statements::select::{select, SelectStatement};
 use crate::sql::statements::set::{set, SetStatement};
+use crate::sql::statements::sleep::{sleep, SleepStatement};
 use crate::sql::statements::update::{update, UpdateStatement};
 use crate::sql::statements::yuse::{yuse, UseStatement};
 use crate::sql::value::Value;
@@ -67,8 +75,12 @@ pub enum Statement {
 	Begin(BeginStatement),
 	Cancel(CancelStatement),
 	Commit(CommitStatement),
+	Savepoint(SavepointStatement),
+	Rollback(RollbackStatement),
+	Release(ReleaseStatement),
 	Output(OutputStatement),
 	Ifelse(IfelseStatement),
+	Foreach(ForeachStatement),
 	Select(SelectStatement),
 	Create(CreateStatement),
 	Update(UpdateStatement),
@@ -78,6 +90,10 @@ pub enum Statement {
 	Define(DefineStatement),
 	Remove(RemoveStatement),
 	Option(OptionStatement),
+	Sleep(SleepStatement),
+	Dry(DryStatement),
+	Declare(DeclareStatement),
+	Migrate(MigrateStatement),
 }
 
 impl Statement {
@@ -89,10 +105,45 @@ impl Statement {
 			Statement::Relate(v) => v.timeout.as_ref().map(|v| *v.0),
 			Statement::Delete(v) => v.timeout.as_ref().map(|v| *v.0),
 			Statement::Insert(v) => v.timeout.as_ref().map(|v| *v.0),
+			Statement::Sleep(v) => v.timeout.as_ref().map(|v| *v.0),
+			Statement::Dry(v) => v.timeout(),
 			_ => None,
 		}
 	}
 
+	#[cfg(feature = "telemetry")]
+	pub(crate) fn kind(&self) -> &'static str {
+		match self {
+			Statement::Use(_) => "use",
+			Statement::Set(_) => "let",
+			Statement::Info(_) => "info",
+			Statement::Live(_) => "live",
+			Statement::Kill(_) => "kill",
+			Statement::Begin(_) => "begin",
+			Statement::Cancel(_) => "cancel",
+			Statement::Commit(_) => "commit",
+			Statement::Savepoint(_) => "savepoint",
+			Statement::Rollback(_) => "rollback",
+			Statement::Release(_) => "release",
+			Statement::Output(_) => "return",
+			Statement::Ifelse(_) => "ifelse",
+			Statement::Foreach(_) => "foreach",
+			Statement::Select(_) => "select",
+			Statement::Create(_) => "create",
+			Statement::Update(_) => "update",
+			Statement::Relate(_) => "relate",
+			Statement::Delete(_) => "delete",
+			Statement::Insert(_) => "insert",
+			Statement::Define(_) => "define",
+			Statement::Remove(_) => "remove",
+			Statement::Option(_) => "option",
+			Statement::Sleep(_) => "sleep",
+			Statement::Dry(_) => "dry",
+			Statement::Declare(_) => "declare",
+			Statement::Migrate(_) => "migrate",
+		}
+	}
+
 	pub(crate) fn writeable(&self) -> bool {
 		match self {
 			Statement::Use(_) => false,
@@ -102,6 +153,7 @@ impl Statement {
 			Statement::Kill(_) => true,
 			Statement::Output(v) => v.writeable(),
 			Statement::Ifelse(v) => v.writeable(),
+			Statement::Foreach(v) => v.writeable(),
 			Statement::Select(v) => v.writeable(),
 			Statement::Create(v) => v.writeable(),
 			Statement::Update(v) => v.writeable(),
@@ -111,10 +163,42 @@ impl Statement {
 			Statement::Define(_) => true,
 			Statement::Remove(_) => true,
 			Statement::Option(_) => false,
+			Statement::Sleep(_) => false,
+			Statement::Dry(_) => true,
+			Statement::Declare(_) => false,
+			Statement::Migrate(_) => true,
 			_ => unreachable!(),
 		}
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Statement::Set(v) => v.complexity(),
+			Statement::Output(v) => v.complexity(),
+			Statement::Ifelse(v) => v.complexity(),
+			Statement::Foreach(v) => v.complexity(),
+			Statement::Select(v) => v.complexity(),
+			Statement::Create(v) => v.complexity(),
+			Statement::Update(v) => v.complexity(),
+			Statement::Relate(v) => v.complexity(),
+			Statement::Delete(v) => v.complexity(),
+			Statement::Insert(v) => v.complexity(),
+			Statement::Dry(v) => v.what.complexity(),
+			_ => 0,
+		}
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Statement::Set(v) => v.volatile(),
+			Statement::Output(v) => v.volatile(),
+			Statement::Ifelse(v) => v.volatile(),
+			Statement::Select(v) => v.volatile(),
+			Statement::Begin(_) | Statement::Commit(_) => false,
+			_ => true,
+		}
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -129,6 +213,7 @@ impl Statement {
 			Statement::Kill(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Output(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Ifelse(v) => v.compute(ctx, opt, txn, doc).await,
+			Statement::Foreach(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Select(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Create(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Update(v) => v.compute(ctx, opt, txn, doc).await,
@@ -137,6 +222,9 @@ impl Statement {
 			Statement::Insert(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Define(v) => v.compute(ctx, opt, txn, doc).await,
 			Statement::Remove(v) => v.compute(ctx, opt, txn, doc).await,
+			Statement::Sleep(v) => v.compute(ctx, opt, txn, doc).await,
+			Statement::Dry(v) => v.compute(ctx, opt, txn, doc).await,
+			Statement::Migrate(v) => v.compute(ctx, opt, txn, doc).await,
 			_ => unreachable!(),
 		}
 	}
@@ -153,8 +241,12 @@ impl fmt::Display for Statement {
 			Statement::Begin(v) => write!(f, "{}", v),
 			Statement::Cancel(v) => write!(f, "{}", v),
 			Statement::Commit(v) => write!(f, "{}", v),
+			Statement::Savepoint(v) => write!(f, "{}", v),
+			Statement::Rollback(v) => write!(f, "{}", v),
+			Statement::Release(v) => write!(f, "{}", v),
 			Statement::Output(v) => write!(f, "{}", v),
 			Statement::Ifelse(v) => write!(f, "{}", v),
+			Statement::Foreach(v) => write!(f, "{}", v),
 			Statement::Select(v) => write!(f, "{}", v),
 			Statement::Create(v) => write!(f, "{}", v),
 			Statement::Update(v) => write!(f, "{}", v),
@@ -164,6 +256,10 @@ impl fmt::Display for Statement {
 			Statement::Define(v) => write!(f, "{}", v),
 			Statement::Remove(v) => write!(f, "{}", v),
 			Statement::Option(v) => write!(f, "{}", v),
+			Statement::Sleep(v) => write!(f, "{}", v),
+			Statement::Dry(v) => write!(f, "{}", v),
+			Statement::Declare(v) => write!(f, "{}", v),
+			Statement::Migrate(v) => write!(f, "{}", v),
 		}
 	}
 }
@@ -182,6 +278,7 @@ pub fn statement(i: &str) -> IResult<&str, Statement> {
 			map(commit, Statement::Commit),
 			map(output, Statement::Output),
 			map(ifelse, Statement::Ifelse),
+			map(foreach, Statement::Foreach),
 			map(select, Statement::Select),
 			map(create, Statement::Create),
 			map(update, Statement::Update),
@@ -191,6 +288,15 @@ pub fn statement(i: &str) -> IResult<&str, Statement> {
 			map(define, Statement::Define),
 			map(remove, Statement::Remove),
 			map(option, Statement::Option),
+			alt((
+				map(sleep, Statement::Sleep),
+				map(dry, Statement::Dry),
+				map(declare, Statement::Declare),
+				map(migrate, Statement::Migrate),
+				map(savepoint, Statement::Savepoint),
+				map(rollback, Statement::Rollback),
+				map(release, Statement::Release),
+			)),
 		)),
 		mightbespace,
 	)(i)
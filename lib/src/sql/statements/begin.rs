@@ -9,11 +9,17 @@ use serde::{Deserialize, Serialize};
 use std::fmt;
 
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
-pub struct BeginStatement;
+pub struct BeginStatement {
+	pub readonly: bool,
+}
 
 impl fmt::Display for BeginStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "BEGIN TRANSACTION")
+		write!(f, "BEGIN TRANSACTION")?;
+		if self.readonly {
+			write!(f, " READONLY")?
+		}
+		Ok(())
 	}
 }
 
@@ -23,13 +29,19 @@ pub fn begin(i: &str) -> IResult<&str, BeginStatement> {
 
 fn begin_basic(i: &str) -> IResult<&str, BeginStatement> {
 	let (i, _) = tag_no_case("BEGIN")(i)?;
-	Ok((i, BeginStatement))
+	Ok((i, BeginStatement::default()))
 }
 
 fn begin_query(i: &str) -> IResult<&str, BeginStatement> {
 	let (i, _) = tag_no_case("BEGIN")(i)?;
 	let (i, _) = opt(tuple((shouldbespace, tag_no_case("TRANSACTION"))))(i)?;
-	Ok((i, BeginStatement))
+	let (i, readonly) = opt(tuple((shouldbespace, tag_no_case("READONLY"))))(i)?;
+	Ok((
+		i,
+		BeginStatement {
+			readonly: readonly.is_some(),
+		},
+	))
 }
 
 #[cfg(test)]
@@ -54,4 +66,21 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!("BEGIN TRANSACTION", format!("{}", out))
 	}
+	#[test]
+	fn begin_readonly() {
+		let sql = "BEGIN READONLY";
+		let res = begin(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("BEGIN TRANSACTION READONLY", format!("{}", out))
+	}
+
+	#[test]
+	fn begin_query_readonly() {
+		let sql = "BEGIN TRANSACTION READONLY";
+		let res = begin(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("BEGIN TRANSACTION READONLY", format!("{}", out))
+	}
 }
@@ -15,12 +15,13 @@ use crate::sql::value::{whats, Value, Values};
 use derive::Store;
 use nom::bytes::complete::tag_no_case;
 use nom::combinator::opt;
-use nom::sequence::preceded;
+use nom::sequence::{preceded, terminated};
 use serde::{Deserialize, Serialize};
 use std::fmt;
 
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
 pub struct CreateStatement {
+	pub only: bool,
 	pub what: Values,
 	pub data: Option<Data>,
 	pub output: Option<Output>,
@@ -33,6 +34,12 @@ impl CreateStatement {
 		true
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let what = self.what.iter().map(|v| v.complexity());
+		let data = self.data.iter().map(|v| v.complexity());
+		what.chain(data).max().unwrap_or(0)
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -53,6 +60,24 @@ impl CreateStatement {
 			let v = w.compute(ctx, opt, txn, doc).await?;
 			match v {
 				Value::Table(v) => match &self.data {
+					// There is a content clause which may contain multiple records
+					Some(d @ Data::ContentExpression(c))
+						if matches!(c, Value::Array(_) | Value::Param(_)) =>
+					{
+						match c.compute(ctx, opt, txn, doc).await? {
+							// Create a record for each of the values
+							Value::Array(c) => {
+								for c in c {
+									// Specify the new table record id
+									let id = c.generate(&v, false)?;
+									// Pass the mergeable to the iterator
+									i.ingest(Iterable::Mergeable(id, c));
+								}
+							}
+							// Otherwise check for a record id
+							_ => i.ingest(Iterable::Thing(d.rid(&v)?)),
+						}
+					}
 					// There is a data clause so check for a record id
 					Some(data) => match data.rid(&v) {
 						// There was a problem creating the record id
@@ -113,13 +138,20 @@ impl CreateStatement {
 		// Assign the statement
 		let stm = Statement::from(self);
 		// Output the results
-		i.output(ctx, opt, txn, &stm).await
+		match self.only && !matches!(self.output, Some(Output::None)) {
+			true => i.output(ctx, opt, txn, &stm).await?.only(),
+			false => i.output(ctx, opt, txn, &stm).await,
+		}
 	}
 }
 
 impl fmt::Display for CreateStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "CREATE {}", self.what)?;
+		write!(f, "CREATE")?;
+		if self.only {
+			write!(f, " ONLY")?
+		}
+		write!(f, " {}", self.what)?;
 		if let Some(ref v) = self.data {
 			write!(f, " {}", v)?
 		}
@@ -139,6 +171,7 @@ impl fmt::Display for CreateStatement {
 pub fn create(i: &str) -> IResult<&str, CreateStatement> {
 	let (i, _) = tag_no_case("CREATE")(i)?;
 	let (i, _) = shouldbespace(i)?;
+	let (i, only) = opt(terminated(tag_no_case("ONLY"), shouldbespace))(i)?;
 	let (i, what) = whats(i)?;
 	let (i, data) = opt(preceded(shouldbespace, data))(i)?;
 	let (i, output) = opt(preceded(shouldbespace, output))(i)?;
@@ -147,6 +180,7 @@ pub fn create(i: &str) -> IResult<&str, CreateStatement> {
 	Ok((
 		i,
 		CreateStatement {
+			only: only.is_some(),
 			what,
 			data,
 			output,
@@ -169,4 +203,13 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!("CREATE test", format!("{}", out))
 	}
+
+	#[test]
+	fn create_statement_only() {
+		let sql = "CREATE ONLY test";
+		let res = create(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("CREATE ONLY test", format!("{}", out))
+	}
 }
@@ -0,0 +1,123 @@
+use crate::err::Error;
+use crate::sql::comment::mightbespace;
+use crate::sql::error::IResult;
+use crate::sql::ident::ident_raw;
+use crate::sql::kind::{kind, Kind};
+use crate::sql::number::Number;
+use crate::sql::value::Value;
+use bigdecimal::BigDecimal;
+use derive::Store;
+use nom::character::complete::char;
+use nom::sequence::preceded;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::str::FromStr;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct DeclareStatement {
+	pub name: String,
+	pub kind: Kind,
+}
+
+impl DeclareStatement {
+	/// Check that a query variable matches the declared type,
+	/// converting values from a string only where this is safe
+	pub(crate) fn check(&self, val: Value) -> Result<Value, Error> {
+		// Keep the detected type for any error
+		let found = val.kindof();
+		// Check or convert the value
+		let res = match (&self.kind, val) {
+			(Kind::Any, v) => Some(v),
+			(Kind::Bool, Value::True) => Some(Value::True),
+			(Kind::Bool, Value::False) => Some(Value::False),
+			(Kind::Bool, Value::Strand(v)) => match v.as_str() {
+				"true" => Some(Value::True),
+				"false" => Some(Value::False),
+				_ => None,
+			},
+			(Kind::Int, Value::Number(Number::Int(v))) => Some(v.into()),
+			(Kind::Int, Value::Number(Number::Float(v))) if v.fract() == 0.0 => {
+				Some((v as i64).into())
+			}
+			(Kind::Int, Value::Strand(v)) => i64::from_str(&v).ok().map(Value::from),
+			(Kind::Float, Value::Number(v)) => Some(v.as_float().into()),
+			(Kind::Float, Value::Strand(v)) => f64::from_str(&v).ok().map(Value::from),
+			(Kind::Decimal, Value::Number(v)) => Some(v.as_decimal().into()),
+			(Kind::Decimal, Value::Strand(v)) => BigDecimal::from_str(&v).ok().map(Value::from),
+			(Kind::Number, Value::Number(v)) => Some(v.into()),
+			(Kind::Number, Value::Strand(v)) => BigDecimal::from_str(&v).ok().map(Value::from),
+			(Kind::String, Value::Strand(v)) => Some(v.into()),
+			(Kind::Datetime, Value::Datetime(v)) => Some(v.into()),
+			(Kind::Datetime, Value::Strand(v)) => crate::sql::datetime::datetime_raw(&v)
+				.ok()
+				.filter(|(i, _)| i.is_empty())
+				.map(|(_, v)| v.into()),
+			(Kind::Duration, Value::Duration(v)) => Some(v.into()),
+			(Kind::Duration, Value::Strand(v)) => crate::sql::duration::duration(&v)
+				.ok()
+				.filter(|(i, _)| i.is_empty())
+				.map(|(_, v)| v.into()),
+			(Kind::Array, v @ Value::Array(_)) => Some(v),
+			(Kind::Object, v @ Value::Object(_)) => Some(v),
+			(Kind::Record(t), v @ Value::Thing(_)) => Some(v).filter(|v| v.is_type_record(t)),
+			(Kind::Record(t), Value::Strand(v)) => {
+				crate::sql::thing(&v).ok().map(Value::from).filter(|v| v.is_type_record(t))
+			}
+			(Kind::Geometry(t), v @ Value::Geometry(_)) => {
+				Some(v).filter(|v| v.is_type_geometry(t))
+			}
+			_ => None,
+		};
+		// Return the value, or the type mismatch
+		res.ok_or_else(|| Error::InvalidVariable {
+			name: self.name.to_owned(),
+			expected: self.kind.to_string(),
+			found: found.to_owned(),
+		})
+	}
+}
+
+impl fmt::Display for DeclareStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "${}: {}", self.name, self.kind)
+	}
+}
+
+pub fn declare(i: &str) -> IResult<&str, DeclareStatement> {
+	let (i, n) = preceded(char('$'), ident_raw)(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char(':')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, k) = kind(i)?;
+	Ok((
+		i,
+		DeclareStatement {
+			name: n,
+			kind: k,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn declare_statement() {
+		let sql = "$id: record";
+		let res = declare(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("$id: record", format!("{}", out));
+	}
+
+	#[test]
+	fn declare_statement_tables() {
+		let sql = "$id:record(person, user)";
+		let res = declare(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("$id: record(person, user)", format!("{}", out));
+	}
+}
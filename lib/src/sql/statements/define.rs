@@ -1,3 +1,4 @@
+use crate::cnf::PROTECTED_PARAM_NAMES;
 use crate::ctx::Context;
 use crate::dbs::Level;
 use crate::dbs::Options;
@@ -5,15 +6,19 @@ use crate::dbs::Transaction;
 use crate::err::Error;
 use crate::sql::algorithm::{algorithm, Algorithm};
 use crate::sql::base::{base, base_or_scope, Base};
-use crate::sql::comment::shouldbespace;
+use crate::sql::block::{block, Block};
+use crate::sql::comment::{mightbespace, shouldbespace};
+use crate::sql::common::{commas, take_u64};
 use crate::sql::duration::{duration, Duration};
 use crate::sql::error::IResult;
 use crate::sql::escape::escape_strand;
-use crate::sql::ident::{ident, Ident};
+use crate::sql::function::function_custom_name;
+use crate::sql::ident::{ident, ident_raw, Ident};
 use crate::sql::idiom;
 use crate::sql::idiom::{Idiom, Idioms};
 use crate::sql::kind::{kind, Kind};
-use crate::sql::permission::{permissions, Permissions};
+use crate::sql::permission::{permissions, Permission, Permissions};
+use crate::sql::role::{roles, Role};
 use crate::sql::statements::UpdateStatement;
 use crate::sql::strand::strand_raw;
 use crate::sql::value::{value, values, Value, Values};
@@ -22,10 +27,13 @@ use argon2::password_hash::{PasswordHasher, SaltString};
 use argon2::Argon2;
 use derive::Store;
 use nom::branch::alt;
+use nom::bytes::complete::tag;
 use nom::bytes::complete::tag_no_case;
+use nom::character::complete::char;
 use nom::combinator::{map, opt};
 use nom::multi::many0;
-use nom::sequence::tuple;
+use nom::multi::separated_list0;
+use nom::sequence::{preceded, tuple};
 use rand::distributions::Alphanumeric;
 use rand::rngs::OsRng;
 use rand::Rng;
@@ -43,6 +51,8 @@ pub enum DefineStatement {
 	Event(DefineEventStatement),
 	Field(DefineFieldStatement),
 	Index(DefineIndexStatement),
+	Function(DefineFunctionStatement),
+	Param(DefineParamStatement),
 }
 
 impl DefineStatement {
@@ -63,6 +73,8 @@ impl DefineStatement {
 			DefineStatement::Event(ref v) => v.compute(ctx, opt, txn, doc).await,
 			DefineStatement::Field(ref v) => v.compute(ctx, opt, txn, doc).await,
 			DefineStatement::Index(ref v) => v.compute(ctx, opt, txn, doc).await,
+			DefineStatement::Function(ref v) => v.compute(ctx, opt, txn, doc).await,
+			DefineStatement::Param(ref v) => v.compute(ctx, opt, txn, doc).await,
 		}
 	}
 }
@@ -79,6 +91,8 @@ impl fmt::Display for DefineStatement {
 			DefineStatement::Event(v) => write!(f, "{}", v),
 			DefineStatement::Field(v) => write!(f, "{}", v),
 			DefineStatement::Index(v) => write!(f, "{}", v),
+			DefineStatement::Function(v) => write!(f, "{}", v),
+			DefineStatement::Param(v) => write!(f, "{}", v),
 		}
 	}
 }
@@ -94,6 +108,8 @@ pub fn define(i: &str) -> IResult<&str, DefineStatement> {
 		map(event, DefineStatement::Event),
 		map(field, DefineStatement::Field),
 		map(index, DefineStatement::Index),
+		map(function, DefineStatement::Function),
+		map(param, DefineStatement::Param),
 	))(i)
 }
 
@@ -118,9 +134,13 @@ impl DefineNamespaceStatement {
 		opt.needs(Level::Kv)?;
 		// Allowed to run?
 		opt.check(Level::Kv)?;
+		// Get the name as it is stored
+		let stm = DefineNamespaceStatement {
+			name: opt.name(&self.name).into(),
+		};
 		// Process the statement
-		let key = crate::key::ns::new(&self.name);
-		txn.clone().lock().await.set(key, self).await?;
+		let key = crate::key::ns::new(&stm.name);
+		txn.clone().lock().await.set(key, &stm).await?;
 		// Ok all good
 		Ok(Value::None)
 	}
@@ -153,6 +173,8 @@ fn namespace(i: &str) -> IResult<&str, DefineNamespaceStatement> {
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
 pub struct DefineDatabaseStatement {
 	pub name: Ident,
+	pub changefeed: bool,
+	pub retention: Option<Duration>,
 }
 
 impl DefineDatabaseStatement {
@@ -171,10 +193,15 @@ impl DefineDatabaseStatement {
 		let run = txn.clone();
 		// Claim transaction
 		let mut run = run.lock().await;
+		// Get the name as it is stored
+		let stm = DefineDatabaseStatement {
+			name: opt.name(&self.name).into(),
+			..self.clone()
+		};
 		// Process the statement
-		let key = crate::key::db::new(opt.ns(), &self.name);
+		let key = crate::key::db::new(opt.ns(), &stm.name);
 		run.add_ns(opt.ns(), opt.strict).await?;
-		run.set(key, self).await?;
+		run.set(key, &stm).await?;
 		// Ok all good
 		Ok(Value::None)
 	}
@@ -182,7 +209,14 @@ impl DefineDatabaseStatement {
 
 impl fmt::Display for DefineDatabaseStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "DEFINE DATABASE {}", self.name)
+		write!(f, "DEFINE DATABASE {}", self.name)?;
+		if self.changefeed {
+			write!(f, " CHANGEFEED")?
+		}
+		if let Some(ref v) = self.retention {
+			write!(f, " {}", v)?
+		}
+		Ok(())
 	}
 }
 
@@ -192,10 +226,17 @@ fn database(i: &str) -> IResult<&str, DefineDatabaseStatement> {
 	let (i, _) = alt((tag_no_case("DB"), tag_no_case("DATABASE")))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, name) = ident(i)?;
+	let (i, changefeed) = opt(tuple((shouldbespace, tag_no_case("CHANGEFEED"))))(i)?;
+	let (i, retention) = match changefeed {
+		Some(_) => opt(preceded(shouldbespace, duration))(i)?,
+		None => (i, None),
+	};
 	Ok((
 		i,
 		DefineDatabaseStatement {
 			name,
+			changefeed: changefeed.is_some(),
+			retention,
 		},
 	))
 }
@@ -210,6 +251,7 @@ pub struct DefineLoginStatement {
 	pub base: Base,
 	pub hash: String,
 	pub code: String,
+	pub roles: Vec<Role>,
 }
 
 impl DefineLoginStatement {
@@ -267,14 +309,22 @@ impl fmt::Display for DefineLoginStatement {
 			self.name,
 			self.base,
 			escape_strand(&self.hash)
-		)
+		)?;
+		if !self.roles.is_empty() {
+			write!(
+				f,
+				" ROLES {}",
+				self.roles.iter().map(|v| v.to_string()).collect::<Vec<_>>().join(", ")
+			)?
+		}
+		Ok(())
 	}
 }
 
 fn login(i: &str) -> IResult<&str, DefineLoginStatement> {
 	let (i, _) = tag_no_case("DEFINE")(i)?;
 	let (i, _) = shouldbespace(i)?;
-	let (i, _) = tag_no_case("LOGIN")(i)?;
+	let (i, _) = alt((tag_no_case("LOGIN"), tag_no_case("USER")))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, name) = ident(i)?;
 	let (i, _) = shouldbespace(i)?;
@@ -282,6 +332,7 @@ fn login(i: &str) -> IResult<&str, DefineLoginStatement> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, base) = base(i)?;
 	let (i, opts) = login_opts(i)?;
+	let (i, roles) = opt(login_roles)(i)?;
 	Ok((
 		i,
 		DefineLoginStatement {
@@ -299,6 +350,7 @@ fn login(i: &str) -> IResult<&str, DefineLoginStatement> {
 					.unwrap()
 					.to_string(),
 			},
+			roles: roles.unwrap_or_default(),
 		},
 	))
 }
@@ -329,6 +381,13 @@ fn login_hash(i: &str) -> IResult<&str, DefineLoginOption> {
 	Ok((i, DefineLoginOption::Passhash(v)))
 }
 
+fn login_roles(i: &str) -> IResult<&str, Vec<Role>> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("ROLES")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	roles(i)
+}
+
 // --------------------------------------------------
 // --------------------------------------------------
 // --------------------------------------------------
@@ -459,6 +518,8 @@ pub struct DefineScopeStatement {
 	pub session: Option<Duration>,
 	pub signup: Option<Value>,
 	pub signin: Option<Value>,
+	pub assert: Option<Value>,
+	pub limit: Option<(u64, Duration)>,
 }
 
 impl DefineScopeStatement {
@@ -496,9 +557,15 @@ impl fmt::Display for DefineScopeStatement {
 		if let Some(ref v) = self.signup {
 			write!(f, " SIGNUP {}", v)?
 		}
+		if let Some((n, ref v)) = self.limit {
+			write!(f, " SIGNUP LIMIT {} PER {}", n, v)?
+		}
 		if let Some(ref v) = self.signin {
 			write!(f, " SIGNIN {}", v)?
 		}
+		if let Some(ref v) = self.assert {
+			write!(f, " ASSERT {}", v)?
+		}
 		Ok(())
 	}
 }
@@ -531,6 +598,14 @@ fn scope(i: &str) -> IResult<&str, DefineScopeStatement> {
 				DefineScopeOption::Signin(ref v) => Some(v.to_owned()),
 				_ => None,
 			}),
+			assert: opts.iter().find_map(|x| match x {
+				DefineScopeOption::Assert(ref v) => Some(v.to_owned()),
+				_ => None,
+			}),
+			limit: opts.iter().find_map(|x| match x {
+				DefineScopeOption::Limit(n, ref v) => Some((*n, v.to_owned())),
+				_ => None,
+			}),
 		},
 	))
 }
@@ -540,10 +615,12 @@ pub enum DefineScopeOption {
 	Session(Duration),
 	Signup(Value),
 	Signin(Value),
+	Assert(Value),
+	Limit(u64, Duration),
 }
 
 fn scope_opts(i: &str) -> IResult<&str, DefineScopeOption> {
-	alt((scope_session, scope_signup, scope_signin))(i)
+	alt((scope_session, scope_limit, scope_signup, scope_signin, scope_assert))(i)
 }
 
 fn scope_session(i: &str) -> IResult<&str, DefineScopeOption> {
@@ -554,6 +631,20 @@ fn scope_session(i: &str) -> IResult<&str, DefineScopeOption> {
 	Ok((i, DefineScopeOption::Session(v)))
 }
 
+fn scope_limit(i: &str) -> IResult<&str, DefineScopeOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("SIGNUP")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("LIMIT")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, n) = take_u64(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("PER")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = duration(i)?;
+	Ok((i, DefineScopeOption::Limit(n, v)))
+}
+
 fn scope_signup(i: &str) -> IResult<&str, DefineScopeOption> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("SIGNUP")(i)?;
@@ -570,6 +661,14 @@ fn scope_signin(i: &str) -> IResult<&str, DefineScopeOption> {
 	Ok((i, DefineScopeOption::Signin(v)))
 }
 
+fn scope_assert(i: &str) -> IResult<&str, DefineScopeOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("ASSERT")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = value(i)?;
+	Ok((i, DefineScopeOption::Assert(v)))
+}
+
 // --------------------------------------------------
 // --------------------------------------------------
 // --------------------------------------------------
@@ -579,6 +678,10 @@ pub struct DefineTableStatement {
 	pub name: Ident,
 	pub drop: bool,
 	pub full: bool,
+	pub vers: bool,
+	pub ttl: Option<Duration>,
+	pub touch: bool,
+	pub soft: bool,
 	pub view: Option<View>,
 	pub permissions: Permissions,
 }
@@ -652,6 +755,18 @@ impl fmt::Display for DefineTableStatement {
 		if !self.full {
 			write!(f, " SCHEMALESS")?
 		}
+		if self.vers {
+			write!(f, " VERSIONED")?
+		}
+		if let Some(ref v) = self.ttl {
+			write!(f, " TTL {}", v)?
+		}
+		if self.touch {
+			write!(f, " TOUCH")?
+		}
+		if self.soft {
+			write!(f, " SOFT DELETE")?
+		}
 		if let Some(ref v) = self.view {
 			write!(f, " {}", v)?
 		}
@@ -688,6 +803,31 @@ fn table(i: &str) -> IResult<&str, DefineTableStatement> {
 					_ => None,
 				})
 				.unwrap_or_default(),
+			vers: opts
+				.iter()
+				.find_map(|x| match x {
+					DefineTableOption::Versioned => Some(true),
+					_ => None,
+				})
+				.unwrap_or_default(),
+			ttl: opts.iter().find_map(|x| match x {
+				DefineTableOption::Ttl(ref v, _) => Some(v.to_owned()),
+				_ => None,
+			}),
+			touch: opts
+				.iter()
+				.find_map(|x| match x {
+					DefineTableOption::Ttl(_, v) => Some(*v),
+					_ => None,
+				})
+				.unwrap_or_default(),
+			soft: opts
+				.iter()
+				.find_map(|x| match x {
+					DefineTableOption::SoftDelete => Some(true),
+					_ => None,
+				})
+				.unwrap_or_default(),
 			view: opts.iter().find_map(|x| match x {
 				DefineTableOption::View(ref v) => Some(v.to_owned()),
 				_ => None,
@@ -709,11 +849,23 @@ pub enum DefineTableOption {
 	View(View),
 	Schemaless,
 	Schemafull,
+	Versioned,
+	Ttl(Duration, bool),
+	SoftDelete,
 	Permissions(Permissions),
 }
 
 fn table_opts(i: &str) -> IResult<&str, DefineTableOption> {
-	alt((table_drop, table_view, table_schemaless, table_schemafull, table_permissions))(i)
+	alt((
+		table_drop,
+		table_view,
+		table_schemaless,
+		table_schemafull,
+		table_versioned,
+		table_ttl,
+		table_soft,
+		table_permissions,
+	))(i)
 }
 
 fn table_drop(i: &str) -> IResult<&str, DefineTableOption> {
@@ -740,6 +892,29 @@ fn table_schemafull(i: &str) -> IResult<&str, DefineTableOption> {
 	Ok((i, DefineTableOption::Schemafull))
 }
 
+fn table_versioned(i: &str) -> IResult<&str, DefineTableOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("VERSIONED")(i)?;
+	Ok((i, DefineTableOption::Versioned))
+}
+
+fn table_soft(i: &str) -> IResult<&str, DefineTableOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("SOFT")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("DELETE")(i)?;
+	Ok((i, DefineTableOption::SoftDelete))
+}
+
+fn table_ttl(i: &str) -> IResult<&str, DefineTableOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("TTL")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = duration(i)?;
+	let (i, t) = opt(tuple((shouldbespace, tag_no_case("TOUCH"))))(i)?;
+	Ok((i, DefineTableOption::Ttl(v, t.is_some())))
+}
+
 fn table_permissions(i: &str) -> IResult<&str, DefineTableOption> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, v) = permissions(i)?;
@@ -836,9 +1011,19 @@ pub struct DefineFieldStatement {
 	pub kind: Option<Kind>,
 	pub value: Option<Value>,
 	pub assert: Option<Value>,
+	pub encrypted: Option<Encryption>,
 	pub permissions: Permissions,
 }
 
+/// How the values of an encrypted field are encrypted
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize)]
+pub enum Encryption {
+	/// Each value is encrypted with a random nonce
+	Random,
+	/// Equal values are encrypted to equal ciphertext
+	Deterministic,
+}
+
 impl DefineFieldStatement {
 	pub(crate) async fn compute(
 		&self,
@@ -878,6 +1063,11 @@ impl fmt::Display for DefineFieldStatement {
 		if let Some(ref v) = self.assert {
 			write!(f, " ASSERT {}", v)?
 		}
+		match self.encrypted {
+			Some(Encryption::Random) => write!(f, " ENCRYPTED")?,
+			Some(Encryption::Deterministic) => write!(f, " ENCRYPTED DETERMINISTIC")?,
+			None => (),
+		}
 		if !self.permissions.is_full() {
 			write!(f, " {}", self.permissions)?;
 		}
@@ -914,6 +1104,10 @@ fn field(i: &str) -> IResult<&str, DefineFieldStatement> {
 				DefineFieldOption::Assert(ref v) => Some(v.to_owned()),
 				_ => None,
 			}),
+			encrypted: opts.iter().find_map(|x| match x {
+				DefineFieldOption::Encrypted(ref v) => Some(v.to_owned()),
+				_ => None,
+			}),
 			permissions: opts
 				.iter()
 				.find_map(|x| match x {
@@ -930,11 +1124,12 @@ pub enum DefineFieldOption {
 	Kind(Kind),
 	Value(Value),
 	Assert(Value),
+	Encrypted(Encryption),
 	Permissions(Permissions),
 }
 
 fn field_opts(i: &str) -> IResult<&str, DefineFieldOption> {
-	alt((field_kind, field_value, field_assert, field_permissions))(i)
+	alt((field_kind, field_value, field_assert, field_encrypted, field_permissions))(i)
 }
 
 fn field_kind(i: &str) -> IResult<&str, DefineFieldOption> {
@@ -961,6 +1156,19 @@ fn field_assert(i: &str) -> IResult<&str, DefineFieldOption> {
 	Ok((i, DefineFieldOption::Assert(v)))
 }
 
+fn field_encrypted(i: &str) -> IResult<&str, DefineFieldOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("ENCRYPTED")(i)?;
+	let (i, v) = opt(tuple((shouldbespace, tag_no_case("DETERMINISTIC"))))(i)?;
+	Ok((
+		i,
+		DefineFieldOption::Encrypted(match v {
+			Some(_) => Encryption::Deterministic,
+			None => Encryption::Random,
+		}),
+	))
+}
+
 fn field_permissions(i: &str) -> IResult<&str, DefineFieldOption> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, v) = permissions(i)?;
@@ -1062,3 +1270,173 @@ fn index(i: &str) -> IResult<&str, DefineIndexStatement> {
 		},
 	))
 }
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct DefineFunctionStatement {
+	pub name: String,
+	pub args: Vec<Ident>,
+	pub block: Block,
+	pub permissions: Permission,
+}
+
+impl DefineFunctionStatement {
+	pub(crate) async fn compute(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Process the statement
+		let key = crate::key::fc::new(opt.ns(), opt.db(), &self.name);
+		run.add_ns(opt.ns(), opt.strict).await?;
+		run.add_db(opt.ns(), opt.db(), opt.strict).await?;
+		run.set(key, self).await?;
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for DefineFunctionStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(
+			f,
+			"DEFINE FUNCTION fn::{}({}) {}",
+			self.name,
+			self.args.iter().map(|v| format!("${}", v)).collect::<Vec<_>>().join(", "),
+			self.block
+		)?;
+		if self.permissions != Permission::Full {
+			write!(f, " PERMISSIONS {}", self.permissions)?
+		}
+		Ok(())
+	}
+}
+
+fn function(i: &str) -> IResult<&str, DefineFunctionStatement> {
+	let (i, _) = tag_no_case("DEFINE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("FUNCTION")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag("fn::")(i)?;
+	let (i, name) = function_custom_name(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char('(')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, args) = separated_list0(commas, preceded(char('$'), ident))(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char(')')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, block) = block(i)?;
+	let (i, permissions) = opt(function_permissions)(i)?;
+	Ok((
+		i,
+		DefineFunctionStatement {
+			name,
+			args,
+			block,
+			permissions: permissions.unwrap_or_default(),
+		},
+	))
+}
+
+fn function_permissions(i: &str) -> IResult<&str, Permission> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("PERMISSIONS")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	alt((
+		map(tag_no_case("NONE"), |_| Permission::None),
+		map(tag_no_case("FULL"), |_| Permission::Full),
+		map(tuple((tag_no_case("WHERE"), shouldbespace, value)), |(_, _, v)| {
+			Permission::Specific(v)
+		}),
+	))(i)
+}
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct DefineParamStatement {
+	pub name: String,
+	pub value: Value,
+}
+
+impl DefineParamStatement {
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Prevent overriding the session params
+		if PROTECTED_PARAM_NAMES.contains(&self.name.as_str()) {
+			return Err(Error::InvalidParam {
+				name: self.name.to_owned(),
+			});
+		}
+		// Compute the param value
+		let value = self.value.compute(ctx, opt, txn, doc).await?;
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Process the statement
+		let key = crate::key::pa::new(opt.ns(), opt.db(), &self.name);
+		run.add_ns(opt.ns(), opt.strict).await?;
+		run.add_db(opt.ns(), opt.db(), opt.strict).await?;
+		run.set(
+			key,
+			DefineParamStatement {
+				name: self.name.to_owned(),
+				value,
+			},
+		)
+		.await?;
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for DefineParamStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "DEFINE PARAM ${} = {}", self.name, self.value)
+	}
+}
+
+fn param(i: &str) -> IResult<&str, DefineParamStatement> {
+	let (i, _) = tag_no_case("DEFINE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("PARAM")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = preceded(char('$'), ident_raw)(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char('=')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, value) = value(i)?;
+	Ok((
+		i,
+		DefineParamStatement {
+			name,
+			value,
+		},
+	))
+}
@@ -34,6 +34,12 @@ impl DeleteStatement {
 		true
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let what = self.what.iter().map(|v| v.complexity());
+		let cond = self.cond.iter().map(|v| v.complexity());
+		what.chain(cond).max().unwrap_or(0)
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -0,0 +1,91 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::statement::Statement;
+use crate::sql::statements::create::create;
+use crate::sql::statements::delete::delete;
+use crate::sql::statements::insert::insert;
+use crate::sql::statements::relate::relate;
+use crate::sql::statements::update::update;
+use crate::sql::value::Value;
+use nom::branch::alt;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::map;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::time::Duration;
+
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize)]
+pub struct DryStatement {
+	pub what: Box<Statement>,
+}
+
+impl DryStatement {
+	pub(crate) fn timeout(&self) -> Option<Duration> {
+		self.what.timeout()
+	}
+
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Prevent any effects outside the transaction
+		let opt = &opt.dry(true);
+		// Process the statement
+		self.what.compute(ctx, opt, txn, doc).await
+	}
+}
+
+impl fmt::Display for DryStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "DRY RUN {}", self.what)
+	}
+}
+
+pub fn dry(i: &str) -> IResult<&str, DryStatement> {
+	let (i, _) = tag_no_case("DRY")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("RUN")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, what) = alt((
+		map(create, Statement::Create),
+		map(update, Statement::Update),
+		map(relate, Statement::Relate),
+		map(delete, Statement::Delete),
+		map(insert, Statement::Insert),
+	))(i)?;
+	Ok((
+		i,
+		DryStatement {
+			what: Box::new(what),
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn dry_statement_update() {
+		let sql = "DRY RUN UPDATE person SET active = false";
+		let res = dry(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("DRY RUN UPDATE person SET active = false", format!("{}", out))
+	}
+
+	#[test]
+	fn dry_statement_select() {
+		let sql = "DRY RUN SELECT * FROM person";
+		let res = dry(sql);
+		assert!(res.is_err());
+	}
+}
@@ -0,0 +1,133 @@
+use crate::cnf::PROTECTED_PARAM_NAMES;
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::block::{block, Block};
+use crate::sql::comment::mightbespace;
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::ident::ident_raw;
+use crate::sql::value::{value, Value};
+use derive::Store;
+use nom::bytes::complete::tag_no_case;
+use nom::character::complete::char;
+use nom::sequence::preceded;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct ForeachStatement {
+	pub param: String,
+	pub range: Value,
+	pub block: Block,
+}
+
+impl ForeachStatement {
+	pub(crate) fn writeable(&self) -> bool {
+		self.range.writeable() || self.block.iter().any(|v| v.writeable())
+	}
+
+	pub(crate) fn complexity(&self) -> usize {
+		self.range.complexity().max(self.block.complexity())
+	}
+
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Prevent overriding the session params
+		if PROTECTED_PARAM_NAMES.contains(&self.param.as_str()) {
+			return Err(Error::InvalidParam {
+				name: self.param.to_owned(),
+			});
+		}
+		// Compute the values to iterate over
+		let range = match self.range.compute(ctx, opt, txn, doc).await? {
+			Value::Array(v) => v.0,
+			Value::None | Value::Null => vec![],
+			v => {
+				return Err(Error::ForeachStatement {
+					value: v.to_string(),
+				})
+			}
+		};
+		// Check the loop iteration limit
+		if range.len() > opt.iterations {
+			return Err(Error::TooManyIterations {
+				limit: opt.iterations,
+			});
+		}
+		// Process the block once for each value
+		for v in range.into_iter() {
+			// Check if the context is finished
+			if ctx.is_timedout() {
+				return Err(Error::QueryTimedout);
+			}
+			if ctx.is_cancelled() {
+				return Err(Error::QueryCancelled);
+			}
+			// Set the loop param for this iteration only
+			let mut ctx = Context::new(ctx);
+			ctx.add_value(self.param.to_owned(), v);
+			// Process the loop body
+			self.block.compute(&ctx, opt, txn, doc).await?;
+		}
+		// The loop returns nothing
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for ForeachStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "FOR ${} IN {} {}", self.param, self.range, self.block)
+	}
+}
+
+pub fn foreach(i: &str) -> IResult<&str, ForeachStatement> {
+	let (i, _) = tag_no_case("FOR")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, param) = preceded(char('$'), ident_raw)(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("IN")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, range) = value(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, block) = block(i)?;
+	Ok((
+		i,
+		ForeachStatement {
+			param,
+			range,
+			block,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn foreach_statement() {
+		let sql = "FOR $item IN [1, 2, 3] { CREATE person SET num = $item; }";
+		let res = foreach(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(sql, format!("{}", out));
+		assert!(out.writeable());
+	}
+
+	#[test]
+	fn foreach_statement_subquery() {
+		let sql = "FOR $p IN (SELECT * FROM person) { UPDATE $p SET seen = true; }";
+		let res = foreach(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(sql, format!("{}", out));
+	}
+}
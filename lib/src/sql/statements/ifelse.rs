@@ -2,11 +2,14 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::dbs::Transaction;
 use crate::err::Error;
+use crate::sql::block::block;
 use crate::sql::comment::shouldbespace;
 use crate::sql::error::IResult;
 use crate::sql::value::{value, Value};
 use derive::Store;
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
+use nom::combinator::map;
 use nom::combinator::opt;
 use nom::multi::separated_list0;
 use serde::{Deserialize, Serialize};
@@ -28,6 +31,16 @@ impl IfelseStatement {
 		self.close.as_ref().map_or(false, |v| v.writeable())
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let exprs = self.exprs.iter().map(|(cond, then)| cond.complexity().max(then.complexity()));
+		exprs.chain(self.close.iter().map(|v| v.complexity())).max().unwrap_or(0)
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		self.exprs.iter().any(|(cond, then)| cond.volatile() || then.volatile())
+			|| self.close.as_ref().map_or(false, |v| v.volatile())
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -88,7 +101,7 @@ fn exprs(i: &str) -> IResult<&str, (Value, Value)> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("THEN")(i)?;
 	let (i, _) = shouldbespace(i)?;
-	let (i, then) = value(i)?;
+	let (i, then) = branch(i)?;
 	Ok((i, (cond, then)))
 }
 
@@ -96,10 +109,14 @@ fn close(i: &str) -> IResult<&str, Value> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("ELSE")(i)?;
 	let (i, _) = shouldbespace(i)?;
-	let (i, then) = value(i)?;
+	let (i, then) = branch(i)?;
 	Ok((i, then))
 }
 
+fn branch(i: &str) -> IResult<&str, Value> {
+	alt((value, map(block, Value::from)))(i)
+}
+
 fn split(i: &str) -> IResult<&str, ()> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("ELSE")(i)?;
@@ -147,4 +164,23 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!(sql, format!("{}", out))
 	}
+
+	#[test]
+	fn ifelse_statement_blocks() {
+		let sql = "IF $x > 0 THEN { CREATE person; RETURN true; } ELSE { RETURN false; } END";
+		let res = ifelse(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(sql, format!("{}", out));
+		assert!(out.writeable());
+	}
+
+	#[test]
+	fn ifelse_statement_empty_object() {
+		let sql = "IF this THEN {} END";
+		let res = ifelse(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(matches!(out.exprs[0].1, Value::Object(_)));
+	}
 }
@@ -58,6 +58,8 @@ impl InfoStatement {
 				opt.needs(Level::Ns)?;
 				// Allowed to run?
 				opt.check(Level::Ns)?;
+				// Allowed to view this namespace?
+				opt.owns(Level::Ns)?;
 				// Clone transaction
 				let run = txn.clone();
 				// Claim transaction
@@ -90,6 +92,8 @@ impl InfoStatement {
 				opt.needs(Level::Db)?;
 				// Allowed to run?
 				opt.check(Level::Db)?;
+				// Allowed to view this database?
+				opt.owns(Level::Db)?;
 				// Clone transaction
 				let run = txn.clone();
 				// Claim transaction
@@ -108,6 +112,24 @@ impl InfoStatement {
 					tmp.insert(v.name.to_string(), v.to_string().into());
 				}
 				res.insert("sc".to_owned(), tmp.into());
+				// Process the functions
+				let mut tmp = Object::default();
+				for v in run.all_fc(opt.ns(), opt.db()).await?.iter() {
+					tmp.insert(v.name.to_string(), v.to_string().into());
+				}
+				res.insert("fc".to_owned(), tmp.into());
+				// Process the params
+				let mut tmp = Object::default();
+				for v in run.all_pa(opt.ns(), opt.db()).await?.iter() {
+					tmp.insert(v.name.to_string(), v.to_string().into());
+				}
+				res.insert("pa".to_owned(), tmp.into());
+				// Process the migrations
+				let mut tmp = Object::default();
+				for v in run.all_mg(opt.ns(), opt.db()).await?.iter() {
+					tmp.insert(v.name.to_string(), v.to_string().into());
+				}
+				res.insert("mg".to_owned(), tmp.into());
 				// Process the tokens
 				let mut tmp = Object::default();
 				for v in run.all_dt(opt.ns(), opt.db()).await?.iter() {
@@ -128,6 +150,8 @@ impl InfoStatement {
 				opt.needs(Level::Db)?;
 				// Allowed to run?
 				opt.check(Level::Db)?;
+				// Allowed to view this database?
+				opt.owns(Level::Db)?;
 				// Clone transaction
 				let run = txn.clone();
 				// Claim transaction
@@ -148,6 +172,8 @@ impl InfoStatement {
 				opt.needs(Level::Db)?;
 				// Allowed to run?
 				opt.check(Level::Db)?;
+				// Allowed to view this database?
+				opt.owns(Level::Db)?;
 				// Clone transaction
 				let run = txn.clone();
 				// Claim transaction
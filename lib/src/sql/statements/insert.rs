@@ -37,6 +37,11 @@ impl InsertStatement {
 		true
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let update = self.update.as_ref().map_or(0, |v| v.complexity());
+		self.data.complexity().max(update)
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
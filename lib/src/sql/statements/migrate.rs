@@ -0,0 +1,132 @@
+use crate::ctx::Context;
+use crate::dbs::Level;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::comment::{mightbespace, shouldbespace};
+use crate::sql::common::colons;
+use crate::sql::error::IResult;
+use crate::sql::ident::{ident, Ident};
+use crate::sql::statement::Statement;
+use crate::sql::statements::create::create;
+use crate::sql::statements::define::define;
+use crate::sql::statements::delete::delete;
+use crate::sql::statements::insert::insert;
+use crate::sql::statements::relate::relate;
+use crate::sql::statements::remove::remove;
+use crate::sql::statements::update::update;
+use crate::sql::value::Value;
+use derive::Store;
+use nom::branch::alt;
+use nom::bytes::complete::tag_no_case;
+use nom::character::complete::char;
+use nom::combinator::map;
+use nom::multi::{many0, separated_list1};
+use nom::sequence::delimited;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct MigrateStatement {
+	pub name: Ident,
+	pub what: Vec<Statement>,
+}
+
+impl MigrateStatement {
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Clone transaction
+		let run = txn.clone();
+		// Check if the migration has already been applied
+		let key = crate::key::mg::new(opt.ns(), opt.db(), &self.name);
+		if run.lock().await.exi(key.clone()).await? {
+			return Ok(Value::False);
+		}
+		// Process each statement in turn
+		for v in self.what.iter() {
+			v.compute(ctx, opt, txn, doc).await?;
+		}
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Record the migration as applied
+		run.add_ns(opt.ns(), opt.strict).await?;
+		run.add_db(opt.ns(), opt.db(), opt.strict).await?;
+		run.set(key, self).await?;
+		// The migration was applied
+		Ok(Value::True)
+	}
+}
+
+impl fmt::Display for MigrateStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(
+			f,
+			"MIGRATE {} {{ {} }}",
+			self.name,
+			self.what.iter().map(|v| format!("{};", v)).collect::<Vec<_>>().join(" ")
+		)
+	}
+}
+
+pub fn migrate(i: &str) -> IResult<&str, MigrateStatement> {
+	let (i, _) = tag_no_case("MIGRATE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = ident(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char('{')(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, what) = separated_list1(colons, delimited(mightbespace, change, mightbespace))(i)?;
+	let (i, _) = many0(colons)(i)?;
+	let (i, _) = mightbespace(i)?;
+	let (i, _) = char('}')(i)?;
+	Ok((
+		i,
+		MigrateStatement {
+			name,
+			what,
+		},
+	))
+}
+
+fn change(i: &str) -> IResult<&str, Statement> {
+	alt((
+		map(define, Statement::Define),
+		map(remove, Statement::Remove),
+		map(create, Statement::Create),
+		map(update, Statement::Update),
+		map(relate, Statement::Relate),
+		map(delete, Statement::Delete),
+		map(insert, Statement::Insert),
+	))(i)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn migrate_statement() {
+		let sql = "MIGRATE v1 { DEFINE TABLE person SCHEMAFULL; DEFINE FIELD name ON person TYPE string; }";
+		let res = migrate(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(sql, format!("{}", out))
+	}
+
+	#[test]
+	fn migrate_statement_select() {
+		let sql = "MIGRATE v1 { SELECT * FROM person; }";
+		let res = migrate(sql);
+		assert!(res.is_err());
+	}
+}
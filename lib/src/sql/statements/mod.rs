@@ -2,19 +2,27 @@ pub(crate) mod begin;
 pub(crate) mod cancel;
 pub(crate) mod commit;
 pub(crate) mod create;
+pub(crate) mod declare;
 pub(crate) mod define;
 pub(crate) mod delete;
+pub(crate) mod dry;
+pub(crate) mod foreach;
 pub(crate) mod ifelse;
 pub(crate) mod info;
 pub(crate) mod insert;
 pub(crate) mod kill;
 pub(crate) mod live;
+pub(crate) mod migrate;
 pub(crate) mod option;
 pub(crate) mod output;
 pub(crate) mod relate;
+pub(crate) mod release;
 pub(crate) mod remove;
+pub(crate) mod rollback;
+pub(crate) mod savepoint;
 pub(crate) mod select;
 pub(crate) mod set;
+pub(crate) mod sleep;
 pub(crate) mod update;
 pub(crate) mod yuse;
 
@@ -22,17 +30,25 @@ pub use self::begin::BeginStatement;
 pub use self::cancel::CancelStatement;
 pub use self::commit::CommitStatement;
 pub use self::create::CreateStatement;
+pub use self::declare::DeclareStatement;
 pub use self::delete::DeleteStatement;
+pub use self::dry::DryStatement;
+pub use self::foreach::ForeachStatement;
 pub use self::ifelse::IfelseStatement;
 pub use self::info::InfoStatement;
 pub use self::insert::InsertStatement;
 pub use self::kill::KillStatement;
 pub use self::live::LiveStatement;
+pub use self::migrate::MigrateStatement;
 pub use self::option::OptionStatement;
 pub use self::output::OutputStatement;
 pub use self::relate::RelateStatement;
+pub use self::release::ReleaseStatement;
+pub use self::rollback::RollbackStatement;
+pub use self::savepoint::SavepointStatement;
 pub use self::select::SelectStatement;
 pub use self::set::SetStatement;
+pub use self::sleep::SleepStatement;
 pub use self::update::UpdateStatement;
 pub use self::yuse::UseStatement;
 
@@ -40,23 +56,28 @@ pub use self::define::DefineDatabaseStatement;
 pub use self::define::DefineEventStatement;
 pub use self::define::DefineFieldOption;
 pub use self::define::DefineFieldStatement;
+pub use self::define::DefineFunctionStatement;
 pub use self::define::DefineIndexStatement;
 pub use self::define::DefineLoginOption;
 pub use self::define::DefineLoginStatement;
 pub use self::define::DefineNamespaceStatement;
+pub use self::define::DefineParamStatement;
 pub use self::define::DefineScopeOption;
 pub use self::define::DefineScopeStatement;
 pub use self::define::DefineStatement;
 pub use self::define::DefineTableOption;
 pub use self::define::DefineTableStatement;
 pub use self::define::DefineTokenStatement;
+pub use self::define::Encryption;
 
 pub use self::remove::RemoveDatabaseStatement;
 pub use self::remove::RemoveEventStatement;
 pub use self::remove::RemoveFieldStatement;
+pub use self::remove::RemoveFunctionStatement;
 pub use self::remove::RemoveIndexStatement;
 pub use self::remove::RemoveLoginStatement;
 pub use self::remove::RemoveNamespaceStatement;
+pub use self::remove::RemoveParamStatement;
 pub use self::remove::RemoveScopeStatement;
 pub use self::remove::RemoveStatement;
 pub use self::remove::RemoveTableStatement;
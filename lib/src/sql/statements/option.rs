@@ -1,5 +1,6 @@
 use crate::sql::comment::mightbespace;
 use crate::sql::comment::shouldbespace;
+use crate::sql::common::take_usize;
 use crate::sql::error::IResult;
 use crate::sql::ident::{ident, Ident};
 use derive::Store;
@@ -15,11 +16,14 @@ use std::fmt;
 pub struct OptionStatement {
 	pub name: Ident,
 	pub what: bool,
+	pub size: Option<usize>,
 }
 
 impl fmt::Display for OptionStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		if self.what {
+		if let Some(v) = self.size {
+			write!(f, "OPTION {} = {}", self.name, v)
+		} else if self.what {
 			write!(f, "OPTION {}", self.name)
 		} else {
 			write!(f, "OPTION {} = FALSE", self.name)
@@ -32,14 +36,21 @@ pub fn option(i: &str) -> IResult<&str, OptionStatement> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, n) = ident(i)?;
 	let (i, v) = opt(alt((
-		map(tuple((mightbespace, char('='), mightbespace, tag_no_case("TRUE"))), |_| true),
-		map(tuple((mightbespace, char('='), mightbespace, tag_no_case("FALSE"))), |_| false),
+		map(tuple((mightbespace, char('='), mightbespace, tag_no_case("TRUE"))), |_| (true, None)),
+		map(tuple((mightbespace, char('='), mightbespace, tag_no_case("FALSE"))), |_| {
+			(false, None)
+		}),
+		map(tuple((mightbespace, char('='), mightbespace, take_usize)), |(_, _, _, v)| {
+			(true, Some(v))
+		}),
 	)))(i)?;
+	let (what, size) = v.unwrap_or((true, None));
 	Ok((
 		i,
 		OptionStatement {
 			name: n,
-			what: v.unwrap_or(true),
+			what,
+			size,
 		},
 	))
 }
@@ -75,4 +86,13 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!("OPTION IMPORT = FALSE", format!("{}", out));
 	}
+
+	#[test]
+	fn option_statement_size() {
+		let sql = "OPTION DEPTH = 3";
+		let res = option(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("OPTION DEPTH = 3", format!("{}", out));
+	}
 }
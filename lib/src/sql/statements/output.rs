@@ -20,6 +20,14 @@ impl OutputStatement {
 		self.what.writeable()
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		self.what.complexity()
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		self.what.volatile()
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
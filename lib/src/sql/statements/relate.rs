@@ -45,6 +45,11 @@ impl RelateStatement {
 		true
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let data = self.data.as_ref().map_or(0, |v| v.complexity());
+		self.from.complexity().max(self.with.complexity()).max(data)
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -0,0 +1,57 @@
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::ident::{ident, Ident};
+use derive::Store;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::opt;
+use nom::sequence::terminated;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct ReleaseStatement {
+	pub name: Ident,
+}
+
+impl fmt::Display for ReleaseStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "RELEASE SAVEPOINT {}", self.name)
+	}
+}
+
+pub fn release(i: &str) -> IResult<&str, ReleaseStatement> {
+	let (i, _) = tag_no_case("RELEASE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = opt(terminated(tag_no_case("SAVEPOINT"), shouldbespace))(i)?;
+	let (i, name) = ident(i)?;
+	Ok((
+		i,
+		ReleaseStatement {
+			name,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn release_basic() {
+		let sql = "RELEASE test";
+		let res = release(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("RELEASE SAVEPOINT test", format!("{}", out))
+	}
+
+	#[test]
+	fn release_query() {
+		let sql = "RELEASE SAVEPOINT test";
+		let res = release(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("RELEASE SAVEPOINT test", format!("{}", out))
+	}
+}
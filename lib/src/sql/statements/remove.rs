@@ -6,15 +6,18 @@ use crate::err::Error;
 use crate::sql::base::{base, base_or_scope, Base};
 use crate::sql::comment::shouldbespace;
 use crate::sql::error::IResult;
-use crate::sql::ident::{ident, Ident};
+use crate::sql::function::function_custom_name;
+use crate::sql::ident::{ident, ident_raw, Ident};
 use crate::sql::idiom;
 use crate::sql::idiom::Idiom;
 use crate::sql::value::Value;
 use derive::Store;
 use nom::branch::alt;
+use nom::bytes::complete::tag;
 use nom::bytes::complete::tag_no_case;
+use nom::character::complete::char;
 use nom::combinator::{map, opt};
-use nom::sequence::tuple;
+use nom::sequence::{preceded, tuple};
 use serde::{Deserialize, Serialize};
 use std::fmt;
 
@@ -29,6 +32,8 @@ pub enum RemoveStatement {
 	Event(RemoveEventStatement),
 	Field(RemoveFieldStatement),
 	Index(RemoveIndexStatement),
+	Function(RemoveFunctionStatement),
+	Param(RemoveParamStatement),
 }
 
 impl RemoveStatement {
@@ -49,6 +54,8 @@ impl RemoveStatement {
 			RemoveStatement::Event(ref v) => v.compute(ctx, opt, txn, doc).await,
 			RemoveStatement::Field(ref v) => v.compute(ctx, opt, txn, doc).await,
 			RemoveStatement::Index(ref v) => v.compute(ctx, opt, txn, doc).await,
+			RemoveStatement::Function(ref v) => v.compute(ctx, opt, txn, doc).await,
+			RemoveStatement::Param(ref v) => v.compute(ctx, opt, txn, doc).await,
 		}
 	}
 }
@@ -65,6 +72,8 @@ impl fmt::Display for RemoveStatement {
 			RemoveStatement::Event(v) => write!(f, "{}", v),
 			RemoveStatement::Field(v) => write!(f, "{}", v),
 			RemoveStatement::Index(v) => write!(f, "{}", v),
+			RemoveStatement::Function(v) => write!(f, "{}", v),
+			RemoveStatement::Param(v) => write!(f, "{}", v),
 		}
 	}
 }
@@ -80,6 +89,8 @@ pub fn remove(i: &str) -> IResult<&str, RemoveStatement> {
 		map(event, RemoveStatement::Event),
 		map(field, RemoveStatement::Field),
 		map(index, RemoveStatement::Index),
+		map(function, RemoveStatement::Function),
+		map(param, RemoveStatement::Param),
 	))(i)
 }
 
@@ -108,11 +119,13 @@ impl RemoveNamespaceStatement {
 		let run = txn.clone();
 		// Claim transaction
 		let mut run = run.lock().await;
+		// Get the name as it is stored
+		let name = opt.name(&self.name);
 		// Delete the definition
-		let key = crate::key::ns::new(&self.name);
+		let key = crate::key::ns::new(&name);
 		run.del(key).await?;
 		// Delete the resource data
-		let key = crate::key::namespace::new(&self.name);
+		let key = crate::key::namespace::new(&name);
 		run.delp(key, u32::MAX).await?;
 		// Ok all good
 		Ok(Value::None)
@@ -164,11 +177,13 @@ impl RemoveDatabaseStatement {
 		let run = txn.clone();
 		// Claim transaction
 		let mut run = run.lock().await;
+		// Get the name as it is stored
+		let name = opt.name(&self.name);
 		// Delete the definition
-		let key = crate::key::db::new(opt.ns(), &self.name);
+		let key = crate::key::db::new(opt.ns(), &name);
 		run.del(key).await?;
 		// Delete the resource data
-		let key = crate::key::database::new(opt.ns(), &self.name);
+		let key = crate::key::database::new(opt.ns(), &name);
 		run.delp(key, u32::MAX).await?;
 		// Ok all good
 		Ok(Value::None)
@@ -663,3 +678,110 @@ fn index(i: &str) -> IResult<&str, RemoveIndexStatement> {
 		},
 	))
 }
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct RemoveFunctionStatement {
+	pub name: String,
+}
+
+impl RemoveFunctionStatement {
+	pub(crate) async fn compute(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Delete the definition
+		let key = crate::key::fc::new(opt.ns(), opt.db(), &self.name);
+		run.del(key).await?;
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for RemoveFunctionStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "REMOVE FUNCTION fn::{}", self.name)
+	}
+}
+
+fn function(i: &str) -> IResult<&str, RemoveFunctionStatement> {
+	let (i, _) = tag_no_case("REMOVE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("FUNCTION")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag("fn::")(i)?;
+	let (i, name) = function_custom_name(i)?;
+	Ok((
+		i,
+		RemoveFunctionStatement {
+			name,
+		},
+	))
+}
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct RemoveParamStatement {
+	pub name: String,
+}
+
+impl RemoveParamStatement {
+	pub(crate) async fn compute(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Delete the definition
+		let key = crate::key::pa::new(opt.ns(), opt.db(), &self.name);
+		run.del(key).await?;
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for RemoveParamStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "REMOVE PARAM ${}", self.name)
+	}
+}
+
+fn param(i: &str) -> IResult<&str, RemoveParamStatement> {
+	let (i, _) = tag_no_case("REMOVE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("PARAM")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = preceded(char('$'), ident_raw)(i)?;
+	Ok((
+		i,
+		RemoveParamStatement {
+			name,
+		},
+	))
+}
@@ -0,0 +1,59 @@
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::ident::{ident, Ident};
+use derive::Store;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::opt;
+use nom::sequence::terminated;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct RollbackStatement {
+	pub name: Ident,
+}
+
+impl fmt::Display for RollbackStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "ROLLBACK TO SAVEPOINT {}", self.name)
+	}
+}
+
+pub fn rollback(i: &str) -> IResult<&str, RollbackStatement> {
+	let (i, _) = tag_no_case("ROLLBACK")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("TO")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = opt(terminated(tag_no_case("SAVEPOINT"), shouldbespace))(i)?;
+	let (i, name) = ident(i)?;
+	Ok((
+		i,
+		RollbackStatement {
+			name,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn rollback_basic() {
+		let sql = "ROLLBACK TO test";
+		let res = rollback(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("ROLLBACK TO SAVEPOINT test", format!("{}", out))
+	}
+
+	#[test]
+	fn rollback_query() {
+		let sql = "ROLLBACK TO SAVEPOINT test";
+		let res = rollback(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("ROLLBACK TO SAVEPOINT test", format!("{}", out))
+	}
+}
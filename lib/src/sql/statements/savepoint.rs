@@ -0,0 +1,45 @@
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::ident::{ident, Ident};
+use derive::Store;
+use nom::bytes::complete::tag_no_case;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct SavepointStatement {
+	pub name: Ident,
+}
+
+impl fmt::Display for SavepointStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "SAVEPOINT {}", self.name)
+	}
+}
+
+pub fn savepoint(i: &str) -> IResult<&str, SavepointStatement> {
+	let (i, _) = tag_no_case("SAVEPOINT")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = ident(i)?;
+	Ok((
+		i,
+		SavepointStatement {
+			name,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn savepoint_statement() {
+		let sql = "SAVEPOINT test";
+		let res = savepoint(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("SAVEPOINT test", format!("{}", out))
+	}
+}
@@ -1,4 +1,7 @@
+use crate::cnf::MAX_SUBQUERY_VALUES;
 use crate::ctx::Context;
+use crate::dbs::cursor;
+use crate::dbs::Chunk;
 use crate::dbs::Iterable;
 use crate::dbs::Iterator;
 use crate::dbs::Level;
@@ -6,29 +9,50 @@ use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::dbs::Transaction;
 use crate::err::Error;
+use crate::key::index;
+use crate::key::thing;
+use crate::sql::after::{after, After};
+use crate::sql::array::Array;
 use crate::sql::comment::shouldbespace;
 use crate::sql::cond::{cond, Cond};
 use crate::sql::error::IResult;
+use crate::sql::expression::Expression;
 use crate::sql::fetch::{fetch, Fetchs};
 use crate::sql::field::{fields, Field, Fields};
+use crate::sql::function::Function;
 use crate::sql::group::{group, Groups};
+use crate::sql::idiom::Idiom;
 use crate::sql::limit::{limit, Limit};
+use crate::sql::object::Object;
+use crate::sql::operator::Operator;
 use crate::sql::order::{order, Orders};
+use crate::sql::part::Part;
 use crate::sql::split::{split, Splits};
 use crate::sql::start::{start, Start};
+use crate::sql::subquery::Subquery;
+use crate::sql::table::Table;
+use crate::sql::thing::Thing;
 use crate::sql::timeout::{timeout, Timeout};
 use crate::sql::value::{selects, Value, Values};
 use crate::sql::version::{version, Version};
+use async_recursion::async_recursion;
+use channel::Sender;
 use derive::Store;
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
-use nom::combinator::opt;
-use nom::sequence::preceded;
+use nom::combinator::{map, opt, peek};
+use nom::sequence::{preceded, terminated};
 use serde::{Deserialize, Serialize};
+use std::borrow::Cow;
+use std::collections::BTreeMap;
 use std::fmt;
 
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
 pub struct SelectStatement {
 	pub expr: Fields,
+	pub distinct: bool,
+	pub value: bool,
+	pub only: bool,
 	pub what: Values,
 	pub cond: Option<Cond>,
 	pub split: Option<Splits>,
@@ -36,6 +60,7 @@ pub struct SelectStatement {
 	pub order: Option<Orders>,
 	pub limit: Option<Limit>,
 	pub start: Option<Start>,
+	pub after: Option<After>,
 	pub fetch: Option<Fetchs>,
 	pub version: Option<Version>,
 	pub timeout: Option<Timeout>,
@@ -73,12 +98,354 @@ impl SelectStatement {
 		self.cond.as_ref().map_or(false, |v| v.writeable())
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let expr = self.expr.iter().map(|v| match v {
+			Field::All => 0,
+			Field::Alone(v) => v.complexity(),
+			Field::Alias(v, _) => v.complexity(),
+		});
+		let what = self.what.iter().map(|v| v.complexity());
+		let cond = self.cond.iter().map(|v| v.complexity());
+		expr.chain(what).chain(cond).max().unwrap_or(0)
+	}
+
+	/// Check if the statement can return different results for the same data
+	pub(crate) fn volatile(&self) -> bool {
+		// Check the fields, sources, and conditions
+		let expr = self.expr.iter().any(|v| match v {
+			Field::All => false,
+			Field::Alone(v) => v.volatile(),
+			Field::Alias(v, _) => v.volatile(),
+		});
+		if expr || self.what.iter().any(|v| v.volatile()) {
+			return true;
+		}
+		if self.cond.as_ref().map_or(false, |v| v.volatile()) {
+			return true;
+		}
+		if self.after.as_ref().map_or(false, |v| v.0.volatile()) {
+			return true;
+		}
+		// Check the clauses which are specified as idioms
+		let split = self.split.iter().flat_map(|v| v.iter()).any(|v| v.volatile());
+		let group = self.group.iter().flat_map(|v| v.iter()).any(|v| v.volatile());
+		let fetch = self.fetch.iter().flat_map(|v| v.iter()).any(|v| v.volatile());
+		if split || group || fetch {
+			return true;
+		}
+		// Random ordering is different every time
+		self.order.iter().flat_map(|v| v.iter()).any(|v| v.random || v.volatile())
+	}
+
+	/// Check if the records can be sent as soon as they are processed
+	pub(crate) fn streamable(&self, opt: &Options) -> bool {
+		!self.only
+			&& !self.distinct
+			&& !opt.ordered
+			&& self.split.is_none()
+			&& self.group.is_none()
+			&& self.order.is_none()
+	}
+
+	/// Return the field name if this is a pure `count()` query
+	fn counted(&self) -> Option<Idiom> {
+		// Check for a single count field
+		let field = match self.expr.0.as_slice() {
+			[Field::Alone(v @ Value::Function(f))] => match f.as_ref() {
+				Function::Normal(name, args) if name == "count" && args.is_empty() => v.to_idiom(),
+				_ => return None,
+			},
+			_ => return None,
+		};
+		// Check that all records are grouped
+		match self.group {
+			Some(ref v) if v.is_all() => (),
+			_ => return None,
+		}
+		// Check for any other clauses
+		if self.value
+			|| self.cond.is_some()
+			|| self.split.is_some()
+			|| self.order.is_some()
+			|| self.limit.is_some()
+			|| self.start.is_some()
+			|| self.after.is_some()
+			|| self.fetch.is_some()
+			|| self.version.is_some()
+		{
+			return None;
+		}
+		Some(field)
+	}
+
+	/// Count the table records without processing them, if possible
+	async fn count(
+		&self,
+		opt: &Options,
+		txn: &Transaction,
+		what: &[Value],
+	) -> Result<Option<Value>, Error> {
+		// Check if this is a pure count query
+		let field = match self.counted() {
+			Some(v) => v,
+			None => return Ok(None),
+		};
+		// Permissions must be checked per record
+		if opt.perms && opt.auth.perms() {
+			return Ok(None);
+		}
+		// Check that only tables are selected
+		if !what.iter().all(|v| matches!(v, Value::Table(_))) {
+			return Ok(None);
+		}
+		// Clone transaction
+		let run = txn.clone();
+		// Claim transaction
+		let mut run = run.lock().await;
+		// Loop over the select targets
+		let mut total = 0;
+		for v in what {
+			if let Value::Table(v) = v {
+				// Check that the table exists
+				run.check_ns_db_tb(opt.ns(), opt.db(), v, opt.strict).await?;
+				// Expired records must be filtered
+				match run.get_and_cache_tb(opt.ns(), opt.db(), v).await {
+					Ok(tb) if tb.ttl.is_some() => return Ok(None),
+					Err(Error::TbNotFound) => continue,
+					Err(e) => return Err(e),
+					Ok(_) => (),
+				}
+				// Prepare the start and end keys
+				let beg = thing::prefix(opt.ns(), opt.db(), v);
+				let end = thing::suffix(opt.ns(), opt.db(), v);
+				// Count the table records
+				total += run.cnt(beg..end).await?;
+			}
+		}
+		// Output the grouped count
+		match total {
+			0 => Ok(Some(Value::from(Vec::<Value>::new()))),
+			n => {
+				let mut obj = Object::default();
+				obj.insert(field.to_string(), Value::from(n));
+				Ok(Some(Value::from(vec![Value::from(obj)])))
+			}
+		}
+	}
+
+	/// Find the records matching the WHERE clause using a table index, if possible
+	///
+	/// An index is used when the WHERE clause contains an `=`, `==`, or
+	/// `INSIDE` predicate, which is not part of an `OR` clause, against the
+	/// single field of an index on the table. As the index stores the exact
+	/// values of the field, only predicates comparing strings, record ids,
+	/// uuids, or datetimes are optimised. The WHERE clause is still checked
+	/// against every record which is found through the index.
+	async fn indexed(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		tb: &Table,
+	) -> Result<Option<Vec<Thing>>, Error> {
+		// Check if there is a WHERE clause
+		let cond = match self.cond {
+			Some(ref v) if self.version.is_none() => v,
+			_ => return Ok(None),
+		};
+		// Find the equality predicates
+		let mut preds = Vec::new();
+		predicates(&cond.0, &mut preds);
+		if preds.is_empty() {
+			return Ok(None);
+		}
+		// Get the index definitions
+		let ixs = txn.clone().lock().await.all_ix(opt.ns(), opt.db(), tb).await?;
+		// Loop over the predicates
+		for (field, value) in preds {
+			// Find a single field index on this field
+			let ix = match ixs.iter().find(|ix| ix.cols.len() == 1 && &ix.cols[0] == field) {
+				Some(ix) => ix,
+				None => continue,
+			};
+			// Compute the values to look up
+			let vals = match value.compute(ctx, opt, txn, None).await? {
+				Value::Array(v) => v.0,
+				v => vec![v],
+			};
+			// Only look up values which are stored exactly
+			if !vals.iter().all(|v| {
+				matches!(
+					v,
+					Value::Strand(_) | Value::Thing(_) | Value::Uuid(_) | Value::Datetime(_)
+				)
+			}) {
+				continue;
+			}
+			// Clone transaction
+			let run = txn.clone();
+			// Claim transaction
+			let mut run = run.lock().await;
+			// Find the matching records in the index
+			let mut ids = BTreeMap::new();
+			for v in vals {
+				// Values may also be stored as strings
+				let keys = match v {
+					Value::Strand(_) => vec![v],
+					v => vec![Value::from(v.to_strand()), v],
+				};
+				for v in keys {
+					let fd = Array::from(vec![v]);
+					let res = match ix.uniq {
+						true => {
+							#[rustfmt::skip]
+							let key = crate::key::index::new(opt.ns(), opt.db(), tb, &ix.name, &fd, None);
+							run.get(key).await?.into_iter().collect::<Vec<_>>()
+						}
+						false => {
+							let beg = index::prefix_ids(opt.ns(), opt.db(), tb, &ix.name, &fd);
+							let end = index::suffix_ids(opt.ns(), opt.db(), tb, &ix.name, &fd);
+							run.getr(beg..end, u32::MAX)
+								.await?
+								.into_iter()
+								.map(|(_, v)| v)
+								.collect()
+						}
+					};
+					// Order the records as a table scan would
+					for v in res {
+						let rid: Thing = v.into();
+						ids.insert(
+							thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id).encode().unwrap(),
+							rid,
+						);
+					}
+				}
+			}
+			// Return the matching records
+			return Ok(Some(ids.into_values().collect()));
+		}
+		// No index could be used
+		Ok(None)
+	}
+
+	/// Check if this statement selects a single field, without referring to an outer record
+	fn uncorrelated(&self) -> bool {
+		// Check for a single selected field
+		match self.expr.0.as_slice() {
+			[Field::Alone(v) | Field::Alias(v, _)] if local(v) => (),
+			_ => return false,
+		}
+		// Check that only tables and records are selected
+		if !self.what.iter().all(|v| matches!(v, Value::Table(_) | Value::Thing(_))) {
+			return false;
+		}
+		// Check the clauses which are computed
+		self.cond.as_ref().map_or(true, |v| local(&v.0)) && self.after.is_none()
+	}
+
+	/// Decode the record which an `AFTER` cursor resumes from
+	///
+	/// A cursor resumes a scan of a single table, in the order of the
+	/// record keys, from just after the last record of the previous page.
+	/// Records which are added or removed between pages don't cause
+	/// other records to be skipped or repeated.
+	async fn cursor(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+		what: &[Value],
+	) -> Result<Option<Thing>, Error> {
+		// Check if there is an AFTER clause
+		let v = match self.after {
+			Some(ref v) => v.0.compute(ctx, opt, txn, doc).await?,
+			None => return Ok(None),
+		};
+		// Records must be output in key order
+		if self.order.is_some() || self.group.is_some() || self.split.is_some() {
+			return Err(Error::InvalidCursor {
+				message: String::from(
+					"A cursor can not be used with an ORDER BY, GROUP BY, or SPLIT clause",
+				),
+			});
+		}
+		// Check that the cursor is valid
+		let rid = cursor::decode(&v.as_string())?;
+		// Check that the cursor table is selected
+		if !what.iter().any(|v| matches!(v, Value::Table(tb) if tb.0 == rid.tb)) {
+			return Err(Error::InvalidCursor {
+				message: format!("The cursor is for the `{}` table", rid.tb),
+			});
+		}
+		Ok(Some(rid))
+	}
+
+	/// Create a cursor for the page of results which follows this one
+	///
+	/// A cursor is only returned when the results fill the `LIMIT`,
+	/// as otherwise there are no further records to fetch.
+	pub(crate) fn next(&self, res: &Value) -> Option<String> {
+		// Only limited selects can be paginated
+		if self.limit.is_none() {
+			return None;
+		}
+		// Check if the page of results is full
+		match res {
+			Value::Array(v) if v.len() == self.limit() => self.resume(v),
+			_ => None,
+		}
+	}
+
+	/// Create a cursor which resumes just after the last of these results
+	pub(crate) fn resume(&self, res: &[Value]) -> Option<String> {
+		// Only table selects in key order can be resumed
+		if self.order.is_some()
+			|| self.group.is_some()
+			|| self.split.is_some()
+			|| !self.what.iter().any(|v| matches!(v, Value::Table(_) | Value::Param(_)))
+		{
+			return None;
+		}
+		// Resume from the last record
+		match res.last() {
+			Some(Value::Object(v)) => match v.get("id") {
+				Some(Value::Thing(v)) => Some(cursor::encode(v)),
+				_ => None,
+			},
+			_ => None,
+		}
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
 		opt: &Options,
 		txn: &Transaction,
 		doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		self.process(ctx, opt, txn, doc, None).await
+	}
+
+	/// Process the statement, sending each record to a channel as soon as it is ready
+	pub(crate) async fn stream(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		chn: Sender<Chunk>,
+	) -> Result<Value, Error> {
+		self.process(ctx, opt, txn, None, Some(chn)).await
+	}
+
+	async fn process(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		doc: Option<&Value>,
+		chn: Option<Sender<Chunk>>,
 	) -> Result<Value, Error> {
 		// Selected DB?
 		opt.needs(Level::Db)?;
@@ -86,13 +453,55 @@ impl SelectStatement {
 		opt.check(Level::No)?;
 		// Create a new iterator
 		let mut i = Iterator::new();
+		// Send the records to any channel
+		if let Some(chn) = chn {
+			i = i.with_channel(chn);
+		}
 		// Ensure futures are processed
 		let opt = &opt.futures(true);
-		// Loop over the select targets
+		// Check that VALUE selects a single field
+		if self.value && self.expr.single().is_none() {
+			return Err(Error::SelectValueMultiple);
+		}
+		// Compute the select targets
+		let mut what = Vec::with_capacity(self.what.len());
 		for w in self.what.0.iter() {
-			let v = w.compute(ctx, opt, txn, doc).await?;
+			what.push(w.compute(ctx, opt, txn, doc).await?);
+		}
+		// Check if the records only need counting
+		if let Some(v) = self.count(opt, txn, &what).await? {
+			return match self.only {
+				true => v.only(),
+				false => Ok(v),
+			};
+		}
+		// Compute any uncorrelated subqueries once
+		let this = match self.cond {
+			Some(ref v) => match hoist(ctx, opt, txn, &v.0).await? {
+				Some(v) => Cow::Owned(SelectStatement {
+					cond: Some(Cond(v)),
+					..self.clone()
+				}),
+				None => Cow::Borrowed(self),
+			},
+			None => Cow::Borrowed(self),
+		};
+		// Check if the query resumes from a cursor
+		let after = self.cursor(ctx, opt, txn, doc, &what).await?;
+		// Loop over the select targets
+		for v in what {
 			match v {
-				Value::Table(v) => i.ingest(Iterable::Table(v)),
+				Value::Table(v) if after.as_ref().map_or(false, |rid| rid.tb == v.0) => {
+					i.ingest(Iterable::After(after.clone().unwrap()))
+				}
+				Value::Table(v) => match this.indexed(ctx, opt, txn, &v).await? {
+					Some(ids) => {
+						for v in ids {
+							i.ingest(Iterable::Thing(v));
+						}
+					}
+					None => i.ingest(Iterable::Table(v)),
+				},
 				Value::Thing(v) => i.ingest(Iterable::Thing(v)),
 				Value::Range(v) => i.ingest(Iterable::Range(*v)),
 				Value::Edges(v) => i.ingest(Iterable::Edges(*v)),
@@ -120,15 +529,144 @@ impl SelectStatement {
 			};
 		}
 		// Assign the statement
-		let stm = Statement::from(self);
+		let stm = Statement::from(this.as_ref());
 		// Output the results
-		i.output(ctx, opt, txn, &stm).await
+		match self.only {
+			true => i.output(ctx, opt, txn, &stm).await?.only(),
+			false => i.output(ctx, opt, txn, &stm).await,
+		}
+	}
+}
+
+/// Collect the equality predicates which every matching record must satisfy
+fn predicates<'a>(cond: &'a Value, preds: &mut Vec<(&'a Idiom, &'a Value)>) {
+	match cond {
+		Value::Subquery(v) => {
+			if let Subquery::Value(v) = v.as_ref() {
+				predicates(v, preds)
+			}
+		}
+		Value::Expression(v) => match (&v.l, &v.o, &v.r) {
+			(l, Operator::And, r) => {
+				predicates(l, preds);
+				predicates(r, preds);
+			}
+			(Value::Idiom(l), Operator::Equal | Operator::Exact, r) if constant(r) => {
+				preds.push((l, r))
+			}
+			(l, Operator::Equal | Operator::Exact, Value::Idiom(r)) if constant(l) => {
+				preds.push((r, l))
+			}
+			(Value::Idiom(l), Operator::Inside, r @ Value::Array(v)) => {
+				if v.iter().all(constant) {
+					preds.push((l, r))
+				}
+			}
+			(Value::Idiom(l), Operator::Inside, r @ Value::Param(_)) => preds.push((l, r)),
+			_ => (),
+		},
+		_ => (),
+	}
+}
+
+/// Compute the uncorrelated subqueries of `IN` and `NOT IN` predicates
+///
+/// A subquery which selects a single field from tables or records, and
+/// which only refers to the fields of those records, returns the same
+/// values for every record which is checked. These subqueries are run
+/// once, before any records are processed, and are replaced with their
+/// values, which can also be looked up in a table index.
+#[cfg_attr(feature = "parallel", async_recursion)]
+#[cfg_attr(not(feature = "parallel"), async_recursion(?Send))]
+async fn hoist(
+	ctx: &Context<'_>,
+	opt: &Options,
+	txn: &Transaction,
+	cond: &Value,
+) -> Result<Option<Value>, Error> {
+	match cond {
+		Value::Subquery(v) => match v.as_ref() {
+			Subquery::Value(v) => match hoist(ctx, opt, txn, v).await? {
+				Some(v) => Ok(Some(Value::Subquery(Box::new(Subquery::Value(v))))),
+				None => Ok(None),
+			},
+			_ => Ok(None),
+		},
+		Value::Expression(e) => match (&e.l, &e.o, &e.r) {
+			(l, Operator::And | Operator::Or, r) => {
+				match (hoist(ctx, opt, txn, l).await?, hoist(ctx, opt, txn, r).await?) {
+					(None, None) => Ok(None),
+					(nl, nr) => Ok(Some(Value::from(Expression {
+						l: nl.unwrap_or_else(|| l.clone()),
+						o: e.o.clone(),
+						r: nr.unwrap_or_else(|| r.clone()),
+					}))),
+				}
+			}
+			(l, Operator::Inside | Operator::NotInside, Value::Subquery(v)) => match v.as_ref() {
+				Subquery::Select(s) if s.uncorrelated() => {
+					// Run the subquery once
+					let r = v.compute(ctx, opt, txn, None).await?;
+					// Check the number of values
+					if let Value::Array(v) = &r {
+						if v.len() > MAX_SUBQUERY_VALUES {
+							return Err(Error::TooManySubqueryValues {
+								limit: MAX_SUBQUERY_VALUES,
+							});
+						}
+					}
+					Ok(Some(Value::from(Expression {
+						l: l.clone(),
+						o: e.o.clone(),
+						r,
+					})))
+				}
+				_ => Ok(None),
+			},
+			_ => Ok(None),
+		},
+		_ => Ok(None),
+	}
+}
+
+/// Check if a value only refers to the fields of the current record
+fn local(v: &Value) -> bool {
+	match v {
+		Value::Idiom(v) => v.iter().all(|p| {
+			matches!(p, Part::Field(_) | Part::Index(_) | Part::All | Part::First | Part::Last)
+		}),
+		Value::Expression(v) => local(&v.l) && local(&v.r),
+		Value::Array(v) => v.iter().all(local),
+		Value::Subquery(v) => matches!(v.as_ref(), Subquery::Value(v) if local(v)),
+		Value::None | Value::Null | Value::False | Value::True => true,
+		Value::Number(_) | Value::Strand(_) | Value::Duration(_) => true,
+		Value::Datetime(_) | Value::Uuid(_) | Value::Thing(_) => true,
+		_ => false,
 	}
 }
 
+/// Check if a value is the same for every record
+fn constant(v: &Value) -> bool {
+	matches!(
+		v,
+		Value::Strand(_) | Value::Thing(_) | Value::Uuid(_) | Value::Datetime(_) | Value::Param(_)
+	)
+}
+
 impl fmt::Display for SelectStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "SELECT {} FROM {}", self.expr, self.what)?;
+		write!(f, "SELECT")?;
+		if self.distinct {
+			write!(f, " DISTINCT")?
+		}
+		if self.value {
+			write!(f, " VALUE")?
+		}
+		write!(f, " {} FROM", self.expr)?;
+		if self.only {
+			write!(f, " ONLY")?
+		}
+		write!(f, " {}", self.what)?;
 		if let Some(ref v) = self.cond {
 			write!(f, " {}", v)?
 		}
@@ -147,6 +685,9 @@ impl fmt::Display for SelectStatement {
 		if let Some(ref v) = self.start {
 			write!(f, " {}", v)?
 		}
+		if let Some(ref v) = self.after {
+			write!(f, " {}", v)?
+		}
 		if let Some(ref v) = self.fetch {
 			write!(f, " {}", v)?
 		}
@@ -166,10 +707,21 @@ impl fmt::Display for SelectStatement {
 pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 	let (i, _) = tag_no_case("SELECT")(i)?;
 	let (i, _) = shouldbespace(i)?;
-	let (i, expr) = fields(i)?;
+	let (i, distinct) = opt(terminated(tag_no_case("DISTINCT"), shouldbespace))(i)?;
+	let (i, (value, expr)) = alt((
+		map(
+			preceded(
+				terminated(tag_no_case("VALUE"), shouldbespace),
+				terminated(fields, peek(preceded(shouldbespace, tag_no_case("FROM")))),
+			),
+			|v| (true, v),
+		),
+		map(fields, |v| (false, v)),
+	))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("FROM")(i)?;
 	let (i, _) = shouldbespace(i)?;
+	let (i, only) = opt(terminated(tag_no_case("ONLY"), shouldbespace))(i)?;
 	let (i, what) = selects(i)?;
 	let (i, cond) = opt(preceded(shouldbespace, cond))(i)?;
 	let (i, split) = opt(preceded(shouldbespace, split))(i)?;
@@ -177,6 +729,7 @@ pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 	let (i, order) = opt(preceded(shouldbespace, order))(i)?;
 	let (i, limit) = opt(preceded(shouldbespace, limit))(i)?;
 	let (i, start) = opt(preceded(shouldbespace, start))(i)?;
+	let (i, after) = opt(preceded(shouldbespace, after))(i)?;
 	let (i, fetch) = opt(preceded(shouldbespace, fetch))(i)?;
 	let (i, version) = opt(preceded(shouldbespace, version))(i)?;
 	let (i, timeout) = opt(preceded(shouldbespace, timeout))(i)?;
@@ -185,6 +738,9 @@ pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 		i,
 		SelectStatement {
 			expr,
+			distinct: distinct.is_some(),
+			value,
+			only: only.is_some(),
 			what,
 			cond,
 			split,
@@ -192,6 +748,7 @@ pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 			order,
 			limit,
 			start,
+			after,
 			fetch,
 			version,
 			timeout,
@@ -232,6 +789,46 @@ mod tests {
 		assert_eq!(sql, format!("{}", out))
 	}
 
+	#[test]
+	fn select_statement_only() {
+		let sql = "SELECT * FROM ONLY test:thingy";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(out.only);
+		assert_eq!(sql, format!("{}", out))
+	}
+
+	#[test]
+	fn select_statement_distinct() {
+		let sql = "SELECT DISTINCT name FROM test ORDER BY name LIMIT 10";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(out.distinct);
+		assert_eq!(sql, format!("{}", out))
+	}
+
+	#[test]
+	fn select_statement_value() {
+		let sql = "SELECT DISTINCT VALUE name FROM test";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(out.value);
+		assert_eq!(sql, format!("{}", out))
+	}
+
+	#[test]
+	fn select_statement_value_field() {
+		let sql = "SELECT value FROM test";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(!out.value);
+		assert_eq!(sql, format!("{}", out))
+	}
+
 	#[test]
 	fn select_statement_clash() {
 		let sql = "SELECT * FROM order ORDER BY order";
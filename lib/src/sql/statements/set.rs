@@ -25,6 +25,14 @@ impl SetStatement {
 		self.what.writeable()
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		self.what.complexity()
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		self.what.volatile()
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -0,0 +1,125 @@
+use crate::ctx::Context;
+use crate::dbs::Level;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::comment::shouldbespace;
+use crate::sql::duration::{duration, Duration};
+use crate::sql::error::IResult;
+use crate::sql::timeout::{timeout, Timeout};
+use crate::sql::value::Value;
+use derive::Store;
+use futures_timer::Delay;
+use nom::bytes::complete::tag_no_case;
+use nom::combinator::opt;
+use nom::sequence::preceded;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::time::Instant;
+
+// The longest interval between checks for cancellation while sleeping
+const INTERVAL: std::time::Duration = std::time::Duration::from_millis(10);
+
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+pub struct SleepStatement {
+	pub duration: Duration,
+	pub timeout: Option<Timeout>,
+}
+
+impl SleepStatement {
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		_txn: &Transaction,
+		_doc: Option<&Value>,
+	) -> Result<Value, Error> {
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Calculate the end of the sleep
+		let end = Instant::now() + *self.duration;
+		// Sleep until the end, or until the context is done
+		loop {
+			// Check if the context is finished
+			if ctx.is_timedout() {
+				return Err(Error::QueryTimedout);
+			}
+			if ctx.is_cancelled() {
+				return Err(Error::QueryCancelled);
+			}
+			// Check if the sleep is finished
+			let now = Instant::now();
+			if now >= end {
+				break;
+			}
+			// Sleep for the next interval
+			Delay::new(INTERVAL.min(end - now)).await;
+		}
+		// Return nothing
+		Ok(Value::None)
+	}
+}
+
+impl fmt::Display for SleepStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "SLEEP {}", self.duration)?;
+		if let Some(ref v) = self.timeout {
+			write!(f, " {}", v)?
+		}
+		Ok(())
+	}
+}
+
+pub fn sleep(i: &str) -> IResult<&str, SleepStatement> {
+	let (i, _) = tag_no_case("SLEEP")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, duration) = duration(i)?;
+	let (i, timeout) = opt(preceded(shouldbespace, timeout))(i)?;
+	Ok((
+		i,
+		SleepStatement {
+			duration,
+			timeout,
+		},
+	))
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::dbs::test::mock;
+	use crate::dbs::Auth;
+
+	#[test]
+	fn sleep_statement() {
+		let sql = "SLEEP 2s";
+		let res = sleep(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("SLEEP 2s", format!("{}", out))
+	}
+
+	#[test]
+	fn sleep_statement_timeout() {
+		let sql = "SLEEP 2s TIMEOUT 100ms";
+		let res = sleep(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("SLEEP 2s TIMEOUT 100ms", format!("{}", out))
+	}
+
+	#[tokio::test]
+	async fn sleep_statement_cancelled() {
+		let (ctx, _, txn) = mock().await;
+		let opt = Options::new(Auth::Kv);
+		let mut ctx = Context::new(&ctx);
+		let can = ctx.add_cancel();
+		can.cancel();
+		let stm = sleep("SLEEP 10s").unwrap().1;
+		let now = Instant::now();
+		let res = stm.compute(&ctx, &opt, &txn, None).await;
+		assert!(matches!(res, Err(Error::QueryCancelled)));
+		assert!(now.elapsed() < std::time::Duration::from_secs(1));
+	}
+}
@@ -10,21 +10,24 @@ use crate::sql::comment::shouldbespace;
 use crate::sql::cond::{cond, Cond};
 use crate::sql::data::{data, Data};
 use crate::sql::error::IResult;
+use crate::sql::guard::{guard, Guard};
 use crate::sql::output::{output, Output};
 use crate::sql::timeout::{timeout, Timeout};
 use crate::sql::value::{whats, Value, Values};
 use derive::Store;
 use nom::bytes::complete::tag_no_case;
 use nom::combinator::opt;
-use nom::sequence::preceded;
+use nom::sequence::{preceded, terminated};
 use serde::{Deserialize, Serialize};
 use std::fmt;
 
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
 pub struct UpdateStatement {
+	pub only: bool,
 	pub what: Values,
 	pub data: Option<Data>,
 	pub cond: Option<Cond>,
+	pub guard: Option<Guard>,
 	pub output: Option<Output>,
 	pub timeout: Option<Timeout>,
 	pub parallel: bool,
@@ -35,6 +38,13 @@ impl UpdateStatement {
 		true
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		let what = self.what.iter().map(|v| v.complexity());
+		let data = self.data.iter().map(|v| v.complexity());
+		let cond = self.cond.iter().map(|v| v.complexity());
+		what.chain(data).chain(cond).max().unwrap_or(0)
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -108,19 +118,29 @@ impl UpdateStatement {
 		// Assign the statement
 		let stm = Statement::from(self);
 		// Output the results
-		i.output(ctx, opt, txn, &stm).await
+		match self.only && !matches!(self.output, Some(Output::None)) {
+			true => i.output(ctx, opt, txn, &stm).await?.only(),
+			false => i.output(ctx, opt, txn, &stm).await,
+		}
 	}
 }
 
 impl fmt::Display for UpdateStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "UPDATE {}", self.what)?;
+		write!(f, "UPDATE")?;
+		if self.only {
+			write!(f, " ONLY")?
+		}
+		write!(f, " {}", self.what)?;
 		if let Some(ref v) = self.data {
 			write!(f, " {}", v)?
 		}
 		if let Some(ref v) = self.cond {
 			write!(f, " {}", v)?
 		}
+		if let Some(ref v) = self.guard {
+			write!(f, " {}", v)?
+		}
 		if let Some(ref v) = self.output {
 			write!(f, " {}", v)?
 		}
@@ -137,18 +157,22 @@ impl fmt::Display for UpdateStatement {
 pub fn update(i: &str) -> IResult<&str, UpdateStatement> {
 	let (i, _) = tag_no_case("UPDATE")(i)?;
 	let (i, _) = shouldbespace(i)?;
+	let (i, only) = opt(terminated(tag_no_case("ONLY"), shouldbespace))(i)?;
 	let (i, what) = whats(i)?;
 	let (i, data) = opt(preceded(shouldbespace, data))(i)?;
 	let (i, cond) = opt(preceded(shouldbespace, cond))(i)?;
+	let (i, guard) = opt(preceded(shouldbespace, guard))(i)?;
 	let (i, output) = opt(preceded(shouldbespace, output))(i)?;
 	let (i, timeout) = opt(preceded(shouldbespace, timeout))(i)?;
 	let (i, parallel) = opt(preceded(shouldbespace, tag_no_case("PARALLEL")))(i)?;
 	Ok((
 		i,
 		UpdateStatement {
+			only: only.is_some(),
 			what,
 			data,
 			cond,
+			guard,
 			output,
 			timeout,
 			parallel: parallel.is_some(),
@@ -169,4 +193,25 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!("UPDATE test", format!("{}", out))
 	}
+
+	#[test]
+	fn update_statement_guard() {
+		let sql = "UPDATE person:test SET name = 'Tobie' WHERE age > 18 IF __version = 3";
+		let res = update(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(
+			"UPDATE person:test SET name = 'Tobie' WHERE age > 18 IF __version = 3",
+			format!("{}", out)
+		)
+	}
+
+	#[test]
+	fn update_statement_only() {
+		let sql = "UPDATE ONLY person:test SET name = 'Tobie'";
+		let res = update(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("UPDATE ONLY person:test SET name = 'Tobie'", format!("{}", out))
+	}
 }
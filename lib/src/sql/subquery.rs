@@ -53,6 +53,29 @@ impl Subquery {
 		}
 	}
 
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Subquery::Value(v) => v.complexity(),
+			Subquery::Ifelse(v) => v.complexity(),
+			Subquery::Select(v) => v.complexity(),
+			Subquery::Create(v) => v.complexity(),
+			Subquery::Update(v) => v.complexity(),
+			Subquery::Delete(v) => v.complexity(),
+			Subquery::Relate(v) => v.complexity(),
+			Subquery::Insert(v) => v.complexity(),
+		}
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Subquery::Value(v) => v.volatile(),
+			Subquery::Ifelse(v) => v.volatile(),
+			Subquery::Select(v) => v.volatile(),
+			// Writes are never repeatable
+			_ => true,
+		}
+	}
+
 	pub(crate) async fn compute(
 		&self,
 		ctx: &Context<'_>,
@@ -77,12 +100,12 @@ impl Subquery {
 				// Process result
 				match v.limit() {
 					1 => match v.expr.single() {
-						Some(v) => res.first().get(&ctx, &opt, txn, &v).await,
-						None => res.first().ok(),
+						Some(i) if !v.value => res.first().get(&ctx, &opt, txn, &i).await,
+						_ => res.first().ok(),
 					},
 					_ => match v.expr.single() {
-						Some(v) => res.get(&ctx, &opt, txn, &v).await,
-						None => res.ok(),
+						Some(i) if !v.value => res.get(&ctx, &opt, txn, &i).await,
+						_ => res.ok(),
 					},
 				}
 			}
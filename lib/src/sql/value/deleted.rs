@@ -0,0 +1,28 @@
+use crate::sql::paths::DELETED;
+use crate::sql::value::Value;
+
+impl Value {
+	/// Check if this record has been soft deleted
+	pub fn is_deleted(&self) -> bool {
+		self.pick(DELETED.as_ref()).is_some()
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::test::Parse;
+
+	#[test]
+	fn is_deleted_none() {
+		let val = Value::parse("{ test: true }");
+		assert!(!val.is_deleted());
+	}
+
+	#[test]
+	fn is_deleted_tombstone() {
+		let val = Value::parse("{ test: true, __: { deleted: '2000-01-01T00:00:00Z' } }");
+		assert!(val.is_deleted());
+	}
+}
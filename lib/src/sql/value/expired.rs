@@ -0,0 +1,38 @@
+use crate::sql::paths::EXPIRES;
+use crate::sql::value::Value;
+use chrono::Utc;
+
+impl Value {
+	/// Check if this record has passed its expiry time
+	pub fn is_expired(&self) -> bool {
+		match self.pick(EXPIRES.as_ref()) {
+			Value::Datetime(v) => v.0 <= Utc::now(),
+			_ => false,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::test::Parse;
+
+	#[test]
+	fn is_expired_none() {
+		let val = Value::parse("{ test: true }");
+		assert!(!val.is_expired());
+	}
+
+	#[test]
+	fn is_expired_past() {
+		let val = Value::parse("{ test: true, __: { expires: '2000-01-01T00:00:00Z' } }");
+		assert!(val.is_expired());
+	}
+
+	#[test]
+	fn is_expired_future() {
+		let val = Value::parse("{ test: true, __: { expires: '9999-01-01T00:00:00Z' } }");
+		assert!(!val.is_expired());
+	}
+}
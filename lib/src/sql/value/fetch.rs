@@ -0,0 +1,136 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::part::Next;
+use crate::sql::part::Part;
+use crate::sql::value::Value;
+use async_recursion::async_recursion;
+
+impl Value {
+	/// Replace any record links at the path with the linked records
+	#[cfg_attr(feature = "parallel", async_recursion)]
+	#[cfg_attr(not(feature = "parallel"), async_recursion(?Send))]
+	pub(crate) async fn fetch(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		path: &[Part],
+	) -> Result<(), Error> {
+		match path.first() {
+			// Get the current path part
+			Some(p) => match self {
+				// Current path part is an object
+				Value::Object(v) => match p {
+					Part::Graph(g) => match v.get_mut(g.to_raw().as_str()) {
+						Some(v) => v.fetch(ctx, opt, txn, path.next()).await,
+						None => Ok(()),
+					},
+					Part::Field(f) => match v.get_mut(f as &str) {
+						Some(v) => v.fetch(ctx, opt, txn, path.next()).await,
+						None => Ok(()),
+					},
+					Part::All => self.fetch(ctx, opt, txn, path.next()).await,
+					_ => Ok(()),
+				},
+				// Current path part is an array
+				Value::Array(v) => match p {
+					Part::All | Part::Any => {
+						let path = path.next();
+						for v in v.iter_mut() {
+							v.fetch(ctx, opt, txn, path).await?;
+						}
+						Ok(())
+					}
+					Part::First => match v.first_mut() {
+						Some(v) => v.fetch(ctx, opt, txn, path.next()).await,
+						None => Ok(()),
+					},
+					Part::Last => match v.last_mut() {
+						Some(v) => v.fetch(ctx, opt, txn, path.next()).await,
+						None => Ok(()),
+					},
+					Part::Index(i) => match v.get_mut(i.to_usize()) {
+						Some(v) => v.fetch(ctx, opt, txn, path.next()).await,
+						None => Ok(()),
+					},
+					Part::Where(w) => {
+						let path = path.next();
+						for v in v.iter_mut() {
+							if w.compute(ctx, opt, txn, Some(v)).await?.is_truthy() {
+								v.fetch(ctx, opt, txn, path).await?;
+							}
+						}
+						Ok(())
+					}
+					_ => {
+						for v in v.iter_mut() {
+							v.fetch(ctx, opt, txn, path).await?;
+						}
+						Ok(())
+					}
+				},
+				// Current path part is a record link
+				Value::Thing(_) => {
+					// Fetch the linked record
+					self.fetch(ctx, opt, txn, &[]).await?;
+					// Continue along the path
+					match self {
+						Value::Object(_) => self.fetch(ctx, opt, txn, path).await,
+						_ => Ok(()),
+					}
+				}
+				// Ignore everything else
+				_ => Ok(()),
+			},
+			// No more parts so fetch the linked records
+			None => match self {
+				Value::Thing(v) => {
+					// Select the record, respecting its permissions
+					let val = Value::Thing(v.clone()).get(ctx, opt, txn, &[Part::All]).await?;
+					// Replace the link with the record
+					*self = val;
+					Ok(())
+				}
+				Value::Array(v) => {
+					for v in v.iter_mut() {
+						v.fetch(ctx, opt, txn, &[]).await?;
+					}
+					Ok(())
+				}
+				// Ignore everything else
+				_ => Ok(()),
+			},
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::dbs::test::mock;
+	use crate::sql::idiom::Idiom;
+	use crate::sql::test::Parse;
+
+	#[tokio::test]
+	async fn fetch_none() {
+		let (ctx, opt, txn) = mock().await;
+		let idi = Idiom::parse("test.other");
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let res = Value::parse("{ test: { other: null, something: 123 } }");
+		val.fetch(&ctx, &opt, &txn, &idi).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn fetch_missing() {
+		let (ctx, opt, txn) = mock().await;
+		let idi = Idiom::parse("test.missing");
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let res = Value::parse("{ test: { other: null, something: 123 } }");
+		val.fetch(&ctx, &opt, &txn, &idi).await.unwrap();
+		assert_eq!(res, val);
+	}
+}
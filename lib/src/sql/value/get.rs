@@ -89,6 +89,9 @@ impl Value {
 						_ => match p {
 							// This is a graph traversal expression
 							Part::Graph(g) => {
+								// Check the graph traversal depth
+								let opt = &opt.hop()?;
+								// Fetch the connected records
 								let stm = SelectStatement {
 									expr: Fields(vec![Field::All]),
 									what: Values(vec![Value::from(Edges {
@@ -108,14 +111,24 @@ impl Value {
 										.await?
 										.flatten()
 										.ok(),
-									_ => stm
-										.compute(ctx, opt, txn, None)
-										.await?
-										.all()
-										.get(ctx, opt, txn, path.next())
-										.await?
-										.flatten()
-										.ok(),
+									_ => {
+										let res = stm
+											.compute(ctx, opt, txn, None)
+											.await?
+											.all()
+											.get(ctx, opt, txn, path.next())
+											.await?
+											.flatten();
+										// Remove records reached through multiple paths
+										match path
+											.next()
+											.iter()
+											.all(|p| matches!(p, Part::Graph(_)))
+										{
+											true => res.uniq().ok(),
+											false => res.ok(),
+										}
+									}
 								}
 							}
 							// This is a remote field expression
@@ -16,7 +16,9 @@ impl Value {
 		match val {
 			Value::Object(v) => {
 				for (k, v) in v {
-					self.set(ctx, opt, txn, &[Part::from(k)], v).await?;
+					let path = [Part::from(k)];
+					let v = deep(self.pick(&path), v);
+					self.set(ctx, opt, txn, &path, v).await?;
 				}
 				Ok(())
 			}
@@ -24,3 +26,47 @@ impl Value {
 		}
 	}
 }
+
+// Merges nested objects into the existing value, keeping
+// any existing fields which are not specified. All other
+// values replace the existing value entirely.
+fn deep(current: Value, val: Value) -> Value {
+	match (current, val) {
+		(Value::Object(mut a), Value::Object(b)) => {
+			for (k, v) in b {
+				let x = a.remove(&k).unwrap_or(Value::None);
+				a.insert(k, deep(x, v));
+			}
+			Value::Object(a)
+		}
+		(_, v) => v,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::dbs::test::mock;
+	use crate::sql::test::Parse;
+
+	#[tokio::test]
+	async fn merge_basic() {
+		let (ctx, opt, txn) = mock().await;
+		let mut res = Value::parse("{ test: { other: null, something: 123 } }");
+		let mrg = Value::parse("{ name: 'Tobie', test: [1, 2] }");
+		let val = Value::parse("{ name: 'Tobie', test: [1, 2] }");
+		res.merge(&ctx, &opt, &txn, mrg).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn merge_nested() {
+		let (ctx, opt, txn) = mock().await;
+		let mut res = Value::parse("{ test: { other: null, something: 123, deep: { a: 1 } } }");
+		let mrg = Value::parse("{ test: { something: 456, deep: { b: 2 } } }");
+		let val = Value::parse("{ test: { other: null, something: 456, deep: { a: 1, b: 2 } } }");
+		res.merge(&ctx, &opt, &txn, mrg).await.unwrap();
+		assert_eq!(res, val);
+	}
+}
@@ -10,9 +10,12 @@ mod compare;
 mod decrement;
 mod def;
 mod del;
+mod deleted;
 mod diff;
 mod each;
 mod every;
+mod expired;
+mod fetch;
 mod first;
 mod flatten;
 mod generate;
@@ -21,10 +24,12 @@ mod increment;
 mod last;
 mod merge;
 mod object;
+mod only;
 mod patch;
 mod pick;
 mod put;
 mod replace;
 mod set;
 mod single;
+mod uniq;
 mod walk;
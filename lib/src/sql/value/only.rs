@@ -0,0 +1,51 @@
+use crate::err::Error;
+use crate::sql::value::Value;
+
+impl Value {
+	/// Unwrap the single result of a query which uses the ONLY keyword
+	pub(crate) fn only(self) -> Result<Self, Error> {
+		match self {
+			Value::Array(mut v) if v.len() == 1 => Ok(v.0.remove(0)),
+			Value::Array(v) => Err(Error::SingleOnlyOutput {
+				count: v.len(),
+			}),
+			v => Ok(v),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::test::Parse;
+
+	#[test]
+	fn only_single() {
+		let val = Value::parse("[{ test: true }]");
+		let res = Value::parse("{ test: true }");
+		assert_eq!(res, val.only().unwrap());
+	}
+
+	#[test]
+	fn only_empty() {
+		let val = Value::parse("[]");
+		assert!(matches!(
+			val.only(),
+			Err(Error::SingleOnlyOutput {
+				count: 0
+			})
+		));
+	}
+
+	#[test]
+	fn only_multiple() {
+		let val = Value::parse("[1, 2]");
+		assert!(matches!(
+			val.only(),
+			Err(Error::SingleOnlyOutput {
+				count: 2
+			})
+		));
+	}
+}
@@ -3,6 +3,7 @@ use crate::dbs::Options;
 use crate::dbs::Transaction;
 use crate::err::Error;
 use crate::sql::operation::Op;
+use crate::sql::part::Part;
 use crate::sql::value::Value;
 
 impl Value {
@@ -15,9 +16,16 @@ impl Value {
 	) -> Result<(), Error> {
 		for o in val.to_operations()?.into_iter() {
 			match o.op {
-				Op::Add => match self.get(ctx, opt, txn, &o.path).await? {
-					Value::Array(_) => self.increment(ctx, opt, txn, &o.path, o.value).await?,
-					_ => self.set(ctx, opt, txn, &o.path, o.value).await?,
+				Op::Add => match o.path.last() {
+					// Append the value to the end of the array
+					Some(Part::Last) => {
+						let path = &o.path[..o.path.len() - 1];
+						self.increment(ctx, opt, txn, path, Value::from(vec![o.value])).await?
+					}
+					_ => match self.get(ctx, opt, txn, &o.path).await? {
+						Value::Array(_) => self.increment(ctx, opt, txn, &o.path, o.value).await?,
+						_ => self.set(ctx, opt, txn, &o.path, o.value).await?,
+					},
 				},
 				Op::Remove => self.del(ctx, opt, txn, &o.path).await?,
 				Op::Replace => self.set(ctx, opt, txn, &o.path, o.value).await?,
@@ -32,6 +40,34 @@ impl Value {
 						}
 					}
 				}
+				Op::Copy => {
+					let from = o.value.jsonpath();
+					let val = self.get(ctx, opt, txn, &from).await?;
+					// The source location must exist
+					if let Value::None = val {
+						return Err(missing(&o.value));
+					}
+					self.set(ctx, opt, txn, &o.path, val).await?
+				}
+				Op::Move => {
+					let from = o.value.jsonpath();
+					let val = self.get(ctx, opt, txn, &from).await?;
+					// The source location must exist
+					if let Value::None = val {
+						return Err(missing(&o.value));
+					}
+					self.del(ctx, opt, txn, &from).await?;
+					self.set(ctx, opt, txn, &o.path, val).await?
+				}
+				Op::Test => {
+					let val = self.get(ctx, opt, txn, &o.path).await?;
+					if val != o.value {
+						return Err(Error::PatchTest {
+							expected: o.value.to_string(),
+							found: val.to_string(),
+						});
+					}
+				}
 				_ => (),
 			}
 		}
@@ -39,6 +75,13 @@ impl Value {
 	}
 }
 
+// The error for a copy or move from a location which does not exist
+fn missing(from: &Value) -> Error {
+	Error::InvalidPatch {
+		message: format!("'from' location '{}' does not exist", from.to_strand().as_str()),
+	}
+}
+
 #[cfg(test)]
 mod tests {
 
@@ -129,4 +172,96 @@ mod tests {
 		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
 		assert_eq!(res, val);
 	}
+
+	#[tokio::test]
+	async fn patch_add_array_end() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 }, temp: [1, 2] }");
+		let ops = Value::parse("[{ op: 'add', path: '/temp/-', value: [3] }]");
+		let res = Value::parse("{ test: { other: null, something: 123 }, temp: [1, 2, [3]] }");
+		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_copy_simple() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'copy', from: '/test/something', path: '/temp' }]");
+		let res = Value::parse("{ test: { other: null, something: 123 }, temp: 123 }");
+		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_move_simple() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'move', from: '/test/something', path: '/temp' }]");
+		let res = Value::parse("{ test: { other: null }, temp: 123 }");
+		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_move_missing() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'move', from: '/test/missing', path: '/temp' }]");
+		let res = val.patch(&ctx, &opt, &txn, ops).await;
+		assert!(matches!(res, Err(Error::InvalidPatch { .. })));
+		// The value is left unchanged
+		assert_eq!(val, Value::parse("{ test: { other: null, something: 123 } }"));
+	}
+
+	#[tokio::test]
+	async fn patch_copy_missing() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'copy', from: '/test/missing', path: '/temp' }]");
+		let res = val.patch(&ctx, &opt, &txn, ops).await;
+		assert!(matches!(res, Err(Error::InvalidPatch { .. })));
+	}
+
+	#[tokio::test]
+	async fn patch_move_null() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'move', from: '/test/other', path: '/temp' }]");
+		let res = Value::parse("{ test: { something: 123 }, temp: null }");
+		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_test_simple() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse(
+			"[{ op: 'test', path: '/test/something', value: 123 }, { op: 'add', path: '/temp', value: true }]",
+		);
+		let res = Value::parse("{ test: { other: null, something: 123 }, temp: true }");
+		val.patch(&ctx, &opt, &txn, ops).await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_test_failed() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse(
+			"[{ op: 'test', path: '/test/something', value: 456 }, { op: 'add', path: '/temp', value: true }]",
+		);
+		let res = val.patch(&ctx, &opt, &txn, ops).await;
+		assert!(matches!(res, Err(Error::PatchTest { .. })));
+	}
+
+	#[tokio::test]
+	async fn patch_invalid_operation() {
+		let (ctx, opt, txn) = mock().await;
+		let mut val = Value::parse("{ test: { other: null, something: 123 } }");
+		let ops = Value::parse("[{ op: 'unknown', path: '/temp', value: true }]");
+		let res = val.patch(&ctx, &opt, &txn, ops).await;
+		assert!(matches!(res, Err(Error::InvalidPatch { .. })));
+	}
 }
@@ -0,0 +1,11 @@
+use crate::sql::array::Uniq;
+use crate::sql::value::Value;
+
+impl Value {
+	pub fn uniq(self) -> Self {
+		match self {
+			Value::Array(v) => v.uniq().into(),
+			v => v,
+		}
+	}
+}
@@ -6,6 +6,7 @@ use crate::dbs::Response;
 use crate::dbs::Transaction;
 use crate::err::Error;
 use crate::sql::array::{array, Array};
+use crate::sql::block::Block;
 use crate::sql::common::commas;
 use crate::sql::datetime::{datetime, Datetime};
 use crate::sql::duration::{duration, Duration};
@@ -120,6 +121,7 @@ pub enum Value {
 	Function(Box<Function>),
 	Subquery(Box<Subquery>),
 	Expression(Box<Expression>),
+	Block(Box<Block>),
 }
 
 impl Eq for Value {}
@@ -248,6 +250,12 @@ impl From<Function> for Value {
 	}
 }
 
+impl From<Block> for Value {
+	fn from(v: Block) -> Self {
+		Value::Block(Box::new(v))
+	}
+}
+
 impl From<Subquery> for Value {
 	fn from(v: Subquery) -> Self {
 		Value::Subquery(Box::new(v))
@@ -561,8 +569,30 @@ impl Value {
 		matches!(self, Value::Object(_))
 	}
 
+	pub fn kindof(&self) -> &'static str {
+		match self {
+			Value::None => "none",
+			Value::Null => "null",
+			Value::True | Value::False => "bool",
+			Value::Number(Number::Int(_)) => "int",
+			Value::Number(Number::Float(_)) => "float",
+			Value::Number(Number::Decimal(_)) => "decimal",
+			Value::Strand(_) => "string",
+			Value::Duration(_) => "duration",
+			Value::Datetime(_) => "datetime",
+			Value::Uuid(_) => "uuid",
+			Value::Array(_) => "array",
+			Value::Object(_) => "object",
+			Value::Geometry(_) => "geometry",
+			Value::Thing(_) => "record",
+			Value::Table(_) => "table",
+			_ => "expression",
+		}
+	}
+
 	pub fn is_type_record(&self, types: &[Table]) -> bool {
 		match self {
+			Value::Thing(_) if types.is_empty() => true,
 			Value::Thing(v) => types.iter().any(|tb| tb.0 == v.tb),
 			_ => false,
 		}
@@ -721,6 +751,7 @@ impl Value {
 				Function::Future(_) => "fn::future".to_string().into(),
 				Function::Script(_, _) => "fn::script".to_string().into(),
 				Function::Normal(f, _) => f.to_string().into(),
+				Function::Custom(f, _) => format!("fn::{}", f).into(),
 				Function::Cast(_, v) => v.to_idiom(),
 			},
 			_ => self.to_string().into(),
@@ -880,7 +911,10 @@ impl Value {
 			.as_str()
 			.trim_start_matches('/')
 			.split(&['.', '/'][..])
-			.map(Part::from)
+			.map(|v| match v {
+				"-" => Part::Last,
+				v => Part::from(v),
+			})
 			.collect::<Vec<Part>>()
 			.into()
 	}
@@ -1016,23 +1050,19 @@ impl Value {
 	}
 
 	pub fn contains_all(&self, other: &Value) -> bool {
-		match other {
-			Value::Array(v) => v.iter().all(|v| match self {
-				Value::Array(w) => w.iter().any(|w| v.equal(w)),
-				Value::Geometry(_) => self.contains(v),
-				_ => false,
-			}),
+		match (self, other) {
+			(Value::Array(w), Value::Array(v)) => v.iter().all(|v| w.iter().any(|w| v.equal(w))),
+			(Value::Strand(_), Value::Array(v)) => v.iter().all(|v| self.contains(v)),
+			(Value::Geometry(_), Value::Array(v)) => v.iter().all(|v| self.contains(v)),
 			_ => false,
 		}
 	}
 
 	pub fn contains_any(&self, other: &Value) -> bool {
-		match other {
-			Value::Array(v) => v.iter().any(|v| match self {
-				Value::Array(w) => w.iter().any(|w| v.equal(w)),
-				Value::Geometry(_) => self.contains(v),
-				_ => false,
-			}),
+		match (self, other) {
+			(Value::Array(w), Value::Array(v)) => v.iter().any(|v| w.iter().any(|w| v.equal(w))),
+			(Value::Strand(_), Value::Array(v)) => v.iter().any(|v| self.contains(v)),
+			(Value::Geometry(_), Value::Array(v)) => v.iter().any(|v| self.contains(v)),
 			_ => false,
 		}
 	}
@@ -1099,6 +1129,7 @@ impl fmt::Display for Value {
 			Value::Function(v) => write!(f, "{}", v),
 			Value::Subquery(v) => write!(f, "{}", v),
 			Value::Expression(v) => write!(f, "{}", v),
+			Value::Block(v) => write!(f, "{}", v),
 		}
 	}
 }
@@ -1108,9 +1139,42 @@ impl Value {
 		match self {
 			Value::Array(v) => v.iter().any(|v| v.writeable()),
 			Value::Object(v) => v.iter().any(|(_, v)| v.writeable()),
-			Value::Function(v) => v.args().iter().any(|v| v.writeable()),
+			Value::Function(v) => {
+				matches!(v.as_ref(), Function::Custom(_, _))
+					|| v.args().iter().any(|v| v.writeable())
+			}
 			Value::Subquery(v) => v.writeable(),
 			Value::Expression(v) => v.l.writeable() || v.r.writeable(),
+			Value::Block(v) => v.iter().any(|v| v.writeable()),
+			_ => false,
+		}
+	}
+
+	pub(crate) fn complexity(&self) -> usize {
+		match self {
+			Value::Array(v) => 1 + v.iter().map(|v| v.complexity()).max().unwrap_or(0),
+			Value::Object(v) => 1 + v.values().map(|v| v.complexity()).max().unwrap_or(0),
+			Value::Idiom(v) => v.complexity(),
+			Value::Function(v) => v.complexity(),
+			Value::Subquery(v) => 1 + v.complexity(),
+			Value::Expression(v) => v.complexity(),
+			Value::Block(v) => v.complexity(),
+			_ => 0,
+		}
+	}
+
+	pub(crate) fn volatile(&self) -> bool {
+		match self {
+			Value::Array(v) => v.iter().any(|v| v.volatile()),
+			Value::Object(v) => v.values().any(|v| v.volatile()),
+			Value::Param(v) => v.volatile(),
+			Value::Idiom(v) => v.volatile(),
+			Value::Thing(v) => v.id.volatile(),
+			Value::Range(v) => v.beg.volatile() || v.end.volatile(),
+			Value::Function(v) => v.volatile(),
+			Value::Subquery(v) => v.volatile(),
+			Value::Expression(v) => v.l.volatile() || v.r.volatile(),
+			Value::Block(v) => v.iter().any(|v| v.volatile()),
 			_ => false,
 		}
 	}
@@ -1137,6 +1201,7 @@ impl Value {
 			Value::Function(v) => v.compute(ctx, opt, txn, doc).await,
 			Value::Subquery(v) => v.compute(ctx, opt, txn, doc).await,
 			Value::Expression(v) => v.compute(ctx, opt, txn, doc).await,
+			Value::Block(v) => v.compute(ctx, opt, txn, doc).await,
 			_ => Ok(self.to_owned()),
 		}
 	}
@@ -1172,6 +1237,7 @@ impl Serialize for Value {
 				Value::Function(v) => s.serialize_newtype_variant("Value", 20, "Function", v),
 				Value::Subquery(v) => s.serialize_newtype_variant("Value", 21, "Subquery", v),
 				Value::Expression(v) => s.serialize_newtype_variant("Value", 22, "Expression", v),
+				Value::Block(v) => s.serialize_newtype_variant("Value", 23, "Block", v),
 			}
 		} else {
 			match self {
@@ -1262,10 +1328,10 @@ pub fn single(i: &str) -> IResult<&str, Value> {
 			map(subquery, Value::from),
 			map(function, Value::from),
 			map(datetime, Value::from),
+			map(number, Value::from),
 			map(duration, Value::from),
 			map(geometry, Value::from),
 			map(unique, Value::from),
-			map(number, Value::from),
 			map(object, Value::from),
 			map(array, Value::from),
 			map(param, Value::from),
@@ -1291,10 +1357,10 @@ pub fn select(i: &str) -> IResult<&str, Value> {
 			map(subquery, Value::from),
 			map(function, Value::from),
 			map(datetime, Value::from),
+			map(number, Value::from),
 			map(duration, Value::from),
 			map(geometry, Value::from),
 			map(unique, Value::from),
-			map(number, Value::from),
 			map(object, Value::from),
 			map(array, Value::from),
 			map(param, Value::from),
@@ -1328,10 +1394,10 @@ pub fn json(i: &str) -> IResult<&str, Value> {
 		map(tag_no_case("true"), |_| Value::True),
 		map(tag_no_case("false"), |_| Value::False),
 		map(datetime, Value::from),
+		map(number, Value::from),
 		map(duration, Value::from),
 		map(geometry, Value::from),
 		map(unique, Value::from),
-		map(number, Value::from),
 		map(object, Value::from),
 		map(array, Value::from),
 		map(thing, Value::from),
@@ -0,0 +1,127 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn batch_queries() -> Result<(), Error> {
+	let qry = vec![
+		(String::from("CREATE person:one;"), None),
+		(String::from("CREATE person:two SET;"), None),
+		(String::from("CREATE person:three; CREATE person:one;"), None),
+		(String::from("SELECT * FROM person;"), None),
+	];
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut res = dbs.batch(qry, &ses, false, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 1);
+	assert!(tmp[0].result.is_ok());
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp.err(), Some(Error::InvalidQuery { .. })));
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 2);
+	assert!(tmp.remove(0).result.is_ok());
+	assert!(matches!(tmp.remove(0).result.err(), Some(Error::RecordExists { .. })));
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 1);
+	let tmp = tmp.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:one
+			},
+			{
+				id: person:three
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn batch_queries_in_transaction() -> Result<(), Error> {
+	let qry = vec![
+		(String::from("CREATE person:one;"), None),
+		(String::from("CREATE person:two; CREATE person:one;"), None),
+		(String::from("CREATE person:three;"), None),
+	];
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut res = dbs.batch(qry, &ses, false, true).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 1);
+	assert!(matches!(tmp.remove(0).result.err(), Some(Error::QueryNotExecuted)));
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 2);
+	assert!(matches!(tmp.remove(0).result.err(), Some(Error::QueryNotExecuted)));
+	assert!(matches!(tmp.remove(0).result.err(), Some(Error::RecordExists { .. })));
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 1);
+	assert!(matches!(tmp.remove(0).result.err(), Some(Error::QueryNotExecuted)));
+	//
+	let sql = "SELECT * FROM person";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn batch_queries_in_transaction_with_statements() -> Result<(), Error> {
+	let qry = vec![
+		(String::from("CREATE person:one;"), None),
+		(String::from("BEGIN; CREATE person:two; COMMIT;"), None),
+	];
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.batch(qry, &ses, false, true).await;
+	assert!(matches!(res.err(), Some(Error::InvalidBatch { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn batch_queries_in_transaction_with_variables() -> Result<(), Error> {
+	let one = Some([("x".to_owned(), Value::from(1))].into());
+	let two = Some([("x".to_owned(), Value::from(2))].into());
+	let qry = vec![
+		(String::from("CREATE person:one SET x = $x; LET $y = 'one';"), one),
+		(String::from("CREATE person:two SET x = $x, y = $y;"), two),
+	];
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut res = dbs.batch(qry, &ses, false, true).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 2);
+	let tmp = tmp.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, x: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	let mut tmp = res.remove(0)?;
+	assert_eq!(tmp.len(), 1);
+	let tmp = tmp.remove(0).result?;
+	let val = Value::parse("[{ id: person:two, x: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,170 @@
+mod parse;
+use parse::Parse;
+use std::time::Duration;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+const SQL: &str = "SELECT value FROM person;";
+
+async fn select(dbs: &Datastore, ses: &Session, sql: &str) -> Result<Value, Error> {
+	let res = &mut dbs.execute(sql, ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	res.remove(0).result
+}
+
+// Remove all data without running a query, so no cached results are invalidated
+async fn forget(dbs: &Datastore) -> Result<(), Error> {
+	let mut tx = dbs.transaction(true, false).await?;
+	tx.delp("/", u32::MAX).await?;
+	tx.commit().await
+}
+
+#[tokio::test]
+async fn cache_hits_skip_execution() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(60));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	select(&dbs, &ses, "CREATE person:tobie SET value = 1;").await?;
+	let one = select(&dbs, &ses, SQL).await?;
+	assert_eq!(one, Value::parse("[{ value: 1 }]"));
+	// The cached results are returned without reading the data
+	forget(&dbs).await?;
+	let two = select(&dbs, &ses, SQL).await?;
+	assert_eq!(one, two);
+	// Equivalent query text shares the same entry
+	let tmp = select(&dbs, &ses, "select  value from person").await?;
+	assert_eq!(one, tmp);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_disabled_by_default() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	select(&dbs, &ses, "CREATE person:tobie SET value = 1;").await?;
+	let one = select(&dbs, &ses, SQL).await?;
+	// Each query is executed again
+	forget(&dbs).await?;
+	let two = select(&dbs, &ses, SQL).await?;
+	assert_ne!(one, two);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_invalidated_by_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(60));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let oth = Session::for_kv().with_ns("test").with_db("other");
+	select(&dbs, &ses, "CREATE person:tobie SET value = 1;").await?;
+	let one = select(&dbs, &ses, SQL).await?;
+	forget(&dbs).await?;
+	// Writes to another database do not invalidate the results
+	select(&dbs, &oth, "CREATE person:tobie SET value = 2;").await?;
+	let tmp = select(&dbs, &ses, SQL).await?;
+	assert_eq!(one, tmp);
+	// Cancelled writes do not invalidate the results
+	let sql = "
+		BEGIN TRANSACTION;
+		CREATE person:tobie SET value = 3;
+		CANCEL TRANSACTION;
+	";
+	dbs.execute(sql, &ses, None, false).await?;
+	let tmp = select(&dbs, &ses, SQL).await?;
+	assert_eq!(one, tmp);
+	// Committed writes invalidate the results
+	select(&dbs, &ses, "CREATE person:tobie SET value = 4;").await?;
+	let tmp = select(&dbs, &ses, SQL).await?;
+	let val = Value::parse("[{ value: 4 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_entries_expire() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_millis(50));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	select(&dbs, &ses, "CREATE person:tobie SET value = 1;").await?;
+	let one = select(&dbs, &ses, SQL).await?;
+	forget(&dbs).await?;
+	std::thread::sleep(Duration::from_millis(100));
+	let two = select(&dbs, &ses, SQL).await?;
+	assert_ne!(one, two);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_entries_not_shared_between_scopes() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE post SCHEMALESS PERMISSIONS FOR select WHERE author = $auth;
+		CREATE post:one SET author = user:one;
+		CREATE post:two SET author = user:two;
+	";
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(60));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	dbs.execute(sql, &ses, None, false).await?;
+	let one = Session {
+		sd: Some(Value::parse("user:one")),
+		..Session::for_sc("test", "test", "account")
+	};
+	let two = Session {
+		sd: Some(Value::parse("user:two")),
+		..Session::for_sc("test", "test", "account")
+	};
+	// Each identity gets its own results
+	let sql = "SELECT id FROM post;";
+	let res = select(&dbs, &one, sql).await?;
+	assert_eq!(res, Value::parse("[{ id: post:one }]"));
+	let tmp = select(&dbs, &two, sql).await?;
+	assert_eq!(tmp, Value::parse("[{ id: post:two }]"));
+	// Each identity still hits its own entry
+	forget(&dbs).await?;
+	let tmp = select(&dbs, &one, sql).await?;
+	assert_eq!(res, tmp);
+	let tmp = select(&dbs, &two, sql).await?;
+	assert_eq!(tmp, Value::parse("[{ id: post:two }]"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_skips_time_now() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(60));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let one = select(&dbs, &ses, "SELECT * FROM time::now();").await?;
+	std::thread::sleep(Duration::from_millis(10));
+	let two = select(&dbs, &ses, "SELECT * FROM time::now();").await?;
+	assert_ne!(one, two);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn cache_skips_volatile_queries() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_query_cache(Duration::from_secs(60));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let sqls = [
+		"SELECT value, time::now() AS now FROM person;",
+		"SELECT value, rand::uuid() AS uuid FROM person;",
+		"SELECT value FROM person WHERE value > rand();",
+		"SELECT value, session::id() AS id FROM person;",
+		"SELECT value, $session AS session FROM person;",
+		"SELECT value FROM person ORDER BY RAND();",
+		"SELECT value, (SELECT time::now() AS now FROM person) AS sub FROM person;",
+	];
+	for sql in sqls {
+		select(&dbs, &ses, "CREATE person:tobie SET value = 1;").await?;
+		let one = select(&dbs, &ses, sql).await?;
+		assert_eq!(one.pick(&[0.into(), "value".into()]), Value::from(1), "{}", sql);
+		// The query runs again against the current data
+		forget(&dbs).await?;
+		let two = select(&dbs, &ses, sql).await?;
+		assert_eq!(two, Value::parse("[]"), "{}", sql);
+	}
+	//
+	Ok(())
+}
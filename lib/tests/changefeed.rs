@@ -0,0 +1,248 @@
+mod parse;
+use parse::Parse;
+use std::thread::sleep;
+use std::time::Duration;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+// Remove the time of each change event, once it has been checked
+fn untimed(events: Vec<Value>) -> Vec<Value> {
+	events
+		.into_iter()
+		.map(|v| match v {
+			Value::Object(mut v) => {
+				assert!(matches!(v.remove("time"), Some(Value::Datetime(_))));
+				Value::Object(v)
+			}
+			v => v,
+		})
+		.collect()
+}
+
+#[tokio::test]
+async fn changefeed_writes_produce_ordered_events() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED;
+		CREATE person:one SET name = 'Tobie';
+		UPDATE person:one SET name = 'Jaime';
+		DELETE person:one;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	//
+	let tmp = dbs.changes("test".to_owned(), "test".to_owned(), 1, 100).await?;
+	let tmp = Value::from(untimed(tmp));
+	let val = Value::parse(
+		"[
+			{
+				seq: 1,
+				op: 'CREATE',
+				tb: 'person',
+				id: person:one,
+				before: NONE,
+				after: { id: person:one, name: 'Tobie' },
+			},
+			{
+				seq: 2,
+				op: 'UPDATE',
+				tb: 'person',
+				id: person:one,
+				before: { id: person:one, name: 'Tobie' },
+				after: { id: person:one, name: 'Jaime' },
+			},
+			{
+				seq: 3,
+				op: 'DELETE',
+				tb: 'person',
+				id: person:one,
+				before: { id: person:one, name: 'Jaime' },
+				after: NONE,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn changefeed_resume_from_sequence() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED;
+		CREATE person:1;
+		CREATE person:2;
+		CREATE person:3;
+		CREATE person:4;
+		CREATE person:5;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	// Read the feed in batches, resuming after the last seen sequence
+	let mut seen = Vec::new();
+	let mut next = 1;
+	loop {
+		let tmp = dbs.changes("test".to_owned(), "test".to_owned(), next, 2).await?;
+		if tmp.is_empty() {
+			break;
+		}
+		assert!(tmp.len() <= 2);
+		for v in tmp.iter() {
+			let seq = match v {
+				Value::Object(v) => v.get("seq").cloned().unwrap_or_default().as_int(),
+				_ => unreachable!(),
+			};
+			next = seq as u64 + 1;
+			seen.push(seq);
+		}
+		// Write more records part way through consuming the feed
+		if next == 5 {
+			let res = &mut dbs.execute("CREATE person:6;", &ses, None, false).await?;
+			res.remove(0).result?;
+		}
+	}
+	assert_eq!(seen, vec![1, 2, 3, 4, 5, 6]);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn changefeed_events_are_written_atomically() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED;
+		BEGIN TRANSACTION;
+		CREATE person:one;
+		CREATE person:two;
+		CANCEL TRANSACTION;
+		BEGIN TRANSACTION;
+		CREATE person:three;
+		COMMIT TRANSACTION;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = untimed(dbs.changes("test".to_owned(), "test".to_owned(), 1, 100).await?);
+	assert_eq!(tmp.len(), 1);
+	let val = Value::parse(
+		"{
+			seq: 1,
+			op: 'CREATE',
+			tb: 'person',
+			id: person:three,
+			before: NONE,
+			after: { id: person:three },
+		}",
+	);
+	assert_eq!(tmp[0], val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn changefeed_disabled_by_default() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET name = 'Tobie';
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = dbs.changes("test".to_owned(), "test".to_owned(), 1, 100).await?;
+	assert!(tmp.is_empty());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn changefeed_events_are_pruned_after_retention() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED 1s;
+		CREATE person:one;
+		CREATE person:two;
+		INFO FOR NS;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(3).result?;
+	let val = Value::parse(
+		"{
+			db: { test: 'DEFINE DATABASE test CHANGEFEED 1s' },
+			nl: {},
+			nt: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	// Wait for the first events to pass the retention period
+	sleep(Duration::from_millis(1100));
+	let res = &mut dbs.execute("CREATE person:three;", &ses, None, false).await?;
+	res.remove(0).result?;
+	dbs.prune().await?;
+	//
+	let tmp = untimed(dbs.changes("test".to_owned(), "test".to_owned(), 3, 100).await?);
+	let val = Value::parse(
+		"[
+			{
+				seq: 3,
+				op: 'CREATE',
+				tb: 'person',
+				id: person:three,
+				before: NONE,
+				after: { id: person:three },
+			}
+		]",
+	);
+	assert_eq!(Value::from(tmp), val);
+	//
+	let tmp = dbs.changes("test".to_owned(), "test".to_owned(), 1, 100).await;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "CHANGEFEED_EXPIRED"));
+	assert!(matches!(
+		tmp,
+		Err(Error::ChangefeedExpired {
+			seq: 1,
+			oldest: 3
+		})
+	));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn changefeed_events_are_kept_without_retention() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED;
+		CREATE person:one;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	//
+	dbs.prune().await?;
+	let tmp = dbs.changes("test".to_owned(), "test".to_owned(), 1, 100).await?;
+	assert_eq!(tmp.len(), 1);
+	//
+	Ok(())
+}
@@ -0,0 +1,94 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn complexity_normal_query() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET tags = ['rust', 'go'];
+		SELECT * FROM (SELECT id, array::len(tags) AS total FROM person WHERE tags CONTAINS 'rust');
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:tobie,
+				total: 2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn complexity_nested_query_allowed() -> Result<(), Error> {
+	let sql = format!("RETURN {}1{};", "(SELECT * FROM ".repeat(20), ")".repeat(20));
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn complexity_nested_query_rejected() -> Result<(), Error> {
+	let sql = format!("RETURN {}1{};", "(SELECT * FROM ".repeat(20), ")".repeat(20));
+	let dbs = Datastore::new("memory").await?.with_complexity(16);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await;
+	assert!(matches!(
+		res,
+		Err(Error::QueryTooComplex {
+			score: 20,
+			limit: 16
+		})
+	));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn complexity_configurable_limit() -> Result<(), Error> {
+	let sql = "
+		SELECT ->likes->post->tagged->tag FROM user;
+	";
+	let dbs = Datastore::new("memory").await?.with_complexity(3);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await;
+	assert!(matches!(
+		res,
+		Err(Error::QueryTooComplex {
+			score: 4,
+			limit: 3
+		})
+	));
+	//
+	let sql = "
+		SELECT ->likes->post FROM user;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
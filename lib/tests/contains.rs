@@ -0,0 +1,162 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn contains_operators_on_arrays() -> Result<(), Error> {
+	let sql = "
+		RETURN ['a', 'b', 'c'] CONTAINS 'a';
+		RETURN ['a', 'b', 'c'] CONTAINS 'x';
+		RETURN ['a', 'b', 'c'] CONTAINSALL ['a', 'c'];
+		RETURN ['a', 'b', 'c'] CONTAINSALL ['a', 'x'];
+		RETURN ['a', 'b', 'c'] CONTAINSANY ['x', 'c'];
+		RETURN ['a', 'b', 'c'] CONTAINSANY ['x', 'y'];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn contains_operators_on_strings() -> Result<(), Error> {
+	let sql = "
+		RETURN 'surrealdb' CONTAINS 'real';
+		RETURN 'surrealdb' CONTAINS 'fake';
+		RETURN 'surrealdb' CONTAINSALL ['surreal', 'db'];
+		RETURN 'surrealdb' CONTAINSALL ['surreal', 'sql'];
+		RETURN 'surrealdb' CONTAINSANY ['sql', 'db'];
+		RETURN 'surrealdb' CONTAINSANY ['sql', 'ql'];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn contains_operators_on_empty_sets() -> Result<(), Error> {
+	let sql = "
+		RETURN [] CONTAINS 'a';
+		RETURN ['a'] CONTAINSALL [];
+		RETURN ['a'] CONTAINSANY [];
+		RETURN 'surrealdb' CONTAINSALL [];
+		RETURN 'surrealdb' CONTAINSANY [];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn contains_operators_on_missing_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET tags = ['rust'];
+		CREATE person:two;
+		SELECT id FROM person WHERE tags CONTAINS 'rust';
+		SELECT id FROM person WHERE tags CONTAINSALL [];
+		SELECT id FROM person WHERE tags CONTAINSANY ['rust', 'go'];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// Missing fields never contain any values
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,116 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn setup(dbs: &Datastore) -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET age = 15;
+		CREATE person:2 SET age = 25;
+		CREATE person:3 SET age = 35;
+		CREATE person:4 SET age = 45;
+		CREATE animal:1 SET age = 5;
+		DEFINE TABLE empty;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	Ok(())
+}
+
+#[tokio::test]
+async fn count_group_all_matches_full_evaluation() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		SELECT count() FROM person GROUP ALL;
+		SELECT count() FROM person WHERE true GROUP ALL;
+		SELECT count() FROM person, animal GROUP ALL;
+		SELECT count() FROM person, animal WHERE true GROUP ALL;
+		SELECT count() FROM empty GROUP ALL;
+		SELECT count() FROM empty WHERE true GROUP ALL;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 4 }]");
+	assert_eq!(tmp, val);
+	assert_eq!(tmp, res.remove(0).result?);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 5 }]");
+	assert_eq!(tmp, val);
+	assert_eq!(tmp, res.remove(0).result?);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	assert_eq!(tmp, res.remove(0).result?);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn count_group_all_with_filter() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		SELECT count() FROM person WHERE age > 20 GROUP ALL;
+		SELECT count() FROM person WHERE age > 100 GROUP ALL;
+		SELECT count(age > 20) FROM person GROUP ALL;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn count_group_all_with_permissions() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let sql = "
+		DEFINE TABLE post SCHEMALESS PERMISSIONS FOR select WHERE author = $auth;
+		CREATE post:1 SET author = user:one;
+		CREATE post:2 SET author = user:two;
+		CREATE post:3 SET author = user:one;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	//
+	let sql = "SELECT count() FROM post GROUP ALL;";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	let mut ses = Session::for_sc("test", "test", "user");
+	ses.sd = Some(Value::parse("user:one"));
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
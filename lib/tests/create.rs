@@ -0,0 +1,137 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn create_statement_content_multiple() -> Result<(), Error> {
+	let sql = "
+		CREATE person CONTENT [
+			{ id: 'tobie', name: 'Tobie' },
+			{ id: 'jaime', name: 'Jaime' },
+		];
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:tobie, name: 'Tobie' },
+			{ id: person:jaime, name: 'Jaime' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:jaime, name: 'Jaime' },
+			{ id: person:tobie, name: 'Tobie' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn create_statement_content_large_batch() -> Result<(), Error> {
+	let items = (0..1000).map(|v| format!("{{ id: {}, value: {} }}", v, v)).collect::<Vec<_>>();
+	let sql = format!(
+		"
+		CREATE item CONTENT [{}];
+		SELECT count() FROM item GROUP ALL;
+		SELECT * FROM item:999;
+		",
+		items.join(", ")
+	);
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	assert!(matches!(tmp, Value::Array(v) if v.len() == 1000));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 1000 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: item:999, value: 999 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn create_statement_content_param() -> Result<(), Error> {
+	let sql = "
+		CREATE person CONTENT $data;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("data"), Value::parse("[{ id: 1, age: 33 }, { id: 2, age: 28 }]"));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:1, age: 33 },
+			{ id: person:2, age: 28 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn create_statement_content_duplicate_rolls_back() -> Result<(), Error> {
+	let sql = "
+		CREATE person:jaime SET name = 'Jaime';
+		CREATE person CONTENT [
+			{ id: 'tobie', name: 'Tobie' },
+			{ id: 'jaime', name: 'Jaime' },
+		];
+		CREATE person CONTENT [
+			{ id: 'one', name: 'One' },
+			{ id: 'one', name: 'Two' },
+		];
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(Error::RecordExists { thing }) if thing == "person:jaime"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(Error::RecordExists { thing }) if thing == "person:one"
+	));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:jaime, name: 'Jaime' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
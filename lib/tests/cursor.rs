@@ -0,0 +1,108 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn select_after_cursor_is_stable() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3, person:4, person:5;
+		SELECT id FROM person LIMIT 2;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0);
+	let cur = tmp.cursor.clone().unwrap();
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp.result?, val);
+	// Records are added before and after the cursor
+	let sql = "
+		CREATE person:0, person:6;
+		DELETE person:2;
+		SELECT id FROM person LIMIT 2 AFTER $cursor;
+	";
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("cursor"), Value::from(cur));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0);
+	let cur = tmp.cursor.clone().unwrap();
+	let val = Value::parse("[{ id: person:3 }, { id: person:4 }]");
+	assert_eq!(tmp.result?, val);
+	// The last page has no further cursor
+	let sql = "SELECT id FROM person LIMIT 5 AFTER $cursor";
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("cursor"), Value::from(cur));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.cursor.is_none());
+	let val = Value::parse("[{ id: person:5 }, { id: person:6 }]");
+	assert_eq!(tmp.result?, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_after_cursor_is_invalid() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3;
+		CREATE animal:1;
+		SELECT id FROM person LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let cur = res.remove(0).cursor.unwrap();
+	// Change the record position in the cursor
+	let (_, sig) = cur.split_once('.').unwrap();
+	let bad = format!("{}.{}", "706572736f6e3a33", sig);
+	let sql = "
+		SELECT id FROM person LIMIT 1 AFTER $bad;
+		SELECT id FROM person LIMIT 1 AFTER 'nonsense';
+		SELECT id FROM animal LIMIT 1 AFTER $cursor;
+		SELECT id FROM person ORDER BY id LIMIT 1 AFTER $cursor;
+		SELECT id FROM person LIMIT 1 AFTER $cursor;
+	";
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("bad"), Value::from(bad));
+	vars.insert(String::from("cursor"), Value::from(cur));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	for _ in 0..4 {
+		let tmp = res.remove(0).result;
+		assert!(matches!(tmp, Err(Error::InvalidCursor { .. })));
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,65 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn decimal_arithmetic() -> Result<(), Error> {
+	let sql = "
+		RETURN 0.1dec + 0.2dec;
+		RETURN 0.1dec + 0.2dec = 0.3dec;
+		RETURN 10dec / 4dec;
+		RETURN math::sum([0.1dec, 0.2dec, 0.3dec]);
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("0.3");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("2.5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("0.6");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn decimal_storage() -> Result<(), Error> {
+	let sql = "
+		CREATE account:one SET balance = 1234567890.123456789012345678dec;
+		UPDATE account:one SET balance += 0.000000000000000001dec;
+		SELECT * FROM account:one;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: account:one, balance: 1234567890.123456789012345678 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: account:one, balance: 1234567890.123456789012345679 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: account:one, balance: 1234567890.123456789012345679 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,120 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn declare_variable_type_mismatch() -> Result<(), Error> {
+	let sql = "
+		$id: record;
+		CREATE person:test;
+		SELECT * FROM $id;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("id"), Value::from("test"));
+	let res = dbs.execute(&sql, &ses, Some(vars), false).await;
+	assert!(matches!(
+		res,
+		Err(Error::InvalidVariable {
+			ref name,
+			ref expected,
+			ref found,
+		}) if name == "id" && expected == "record" && found == "string"
+	));
+	// Nothing is executed when a variable is invalid
+	let sql = "SELECT * FROM person";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declare_variable_type_missing() -> Result<(), Error> {
+	let sql = "
+		$age: int;
+		RETURN $age;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await;
+	assert!(matches!(
+		res,
+		Err(Error::InvalidVariable {
+			ref found,
+			..
+		}) if found == "none"
+	));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declare_variable_record_table() -> Result<(), Error> {
+	let sql = "
+		$id: record(person);
+		RETURN $id;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("id"), Value::parse("user:tobie"));
+	let res = dbs.execute(&sql, &ses, Some(vars), false).await;
+	assert!(matches!(
+		res,
+		Err(Error::InvalidVariable {
+			ref expected,
+			..
+		}) if expected == "record(person)"
+	));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declare_variable_type_coercion() -> Result<(), Error> {
+	let sql = "
+		$id: record(person);
+		$age: int;
+		$name: string;
+		CREATE $id SET age = $age, name = $name;
+		SELECT * FROM $id;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("id"), Value::from("person:tobie"));
+	vars.insert(String::from("age"), Value::from("30"));
+	vars.insert(String::from("name"), Value::from("Tobie"));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::None);
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:tobie,
+				age: 30,
+				name: 'Tobie'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
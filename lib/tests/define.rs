@@ -57,6 +57,95 @@ async fn define_statement_database() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn define_statement_database_changefeed() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED;
+		INFO FOR NS;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			db: { test: 'DEFINE DATABASE test CHANGEFEED' },
+			nl: {},
+			nt: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn define_statement_scope_assert() -> Result<(), Error> {
+	let sql = "
+		DEFINE SCOPE account SIGNUP (CREATE user SET email = $email) ASSERT string::length($pass) >= 8;
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: { account: 'DEFINE SCOPE account SIGNUP (CREATE user SET email = $email) ASSERT string::length($pass) >= 8' },
+			tb: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn define_statement_scope_signup_limit() -> Result<(), Error> {
+	let sql = "
+		DEFINE SCOPE account SIGNUP (CREATE user SET email = $email) SIGNUP LIMIT 5 PER 1h;
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: { account: 'DEFINE SCOPE account SIGNUP (CREATE user SET email = $email) SIGNUP LIMIT 5 PER 1h' },
+			tb: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn define_statement_table_drop() -> Result<(), Error> {
 	let sql = "
@@ -76,6 +165,9 @@ async fn define_statement_table_drop() -> Result<(), Error> {
 		"{
 			dl: {},
 			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
 			sc: {},
 			tb: { test: 'DEFINE TABLE test DROP SCHEMALESS' },
 		}",
@@ -104,6 +196,9 @@ async fn define_statement_table_schemaless() -> Result<(), Error> {
 		"{
 			dl: {},
 			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
 			sc: {},
 			tb: { test: 'DEFINE TABLE test SCHEMALESS' },
 		}",
@@ -136,6 +231,9 @@ async fn define_statement_table_schemafull() -> Result<(), Error> {
 		"{
 			dl: {},
 			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
 			sc: {},
 			tb: { test: 'DEFINE TABLE test SCHEMAFULL' },
 		}",
@@ -164,6 +262,9 @@ async fn define_statement_table_schemaful() -> Result<(), Error> {
 		"{
 			dl: {},
 			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
 			sc: {},
 			tb: { test: 'DEFINE TABLE test SCHEMAFULL' },
 		}",
@@ -885,3 +986,41 @@ async fn define_statement_index_multiple_unique_existing() -> Result<(), Error>
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn define_statement_user_roles() -> Result<(), Error> {
+	let sql = "
+		DEFINE USER reader ON DATABASE PASSHASH 'hash' ROLES VIEWER;
+		DEFINE USER writer ON DATABASE PASSHASH 'hash' ROLES VIEWER, EDITOR;
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {
+				reader: \"DEFINE LOGIN reader ON DATABASE PASSHASH 'hash' ROLES VIEWER\",
+				writer: \"DEFINE LOGIN writer ON DATABASE PASSHASH 'hash' ROLES VIEWER, EDITOR\",
+			},
+			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: {},
+			tb: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
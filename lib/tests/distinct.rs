@@ -0,0 +1,116 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn select_distinct_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET city = 'London';
+		CREATE person:2 SET city = 'London';
+		CREATE person:3 SET city = 'Paris';
+		CREATE person:4 SET city = 'Tokyo';
+		SELECT DISTINCT city FROM person ORDER BY city;
+		SELECT DISTINCT city FROM person ORDER BY city LIMIT 2;
+		SELECT DISTINCT city FROM person ORDER BY city LIMIT 2 START 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ city: 'London' },
+			{ city: 'Paris' },
+			{ city: 'Tokyo' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ city: 'London' },
+			{ city: 'Paris' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ city: 'Paris' },
+			{ city: 'Tokyo' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_distinct_records() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie';
+		CREATE person:jaime SET name = 'Jaime';
+		SELECT DISTINCT * FROM person:tobie, person:jaime, person:tobie;
+		SELECT * FROM person:tobie, person:jaime, person:tobie;
+		SELECT DISTINCT * FROM [{ name: 'Tobie' }, { name: 'Jaime' }, { name: 'Tobie' }];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:tobie, name: 'Tobie' },
+			{ id: person:jaime, name: 'Jaime' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:tobie, name: 'Tobie' },
+			{ id: person:jaime, name: 'Jaime' },
+			{ id: person:tobie, name: 'Tobie' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ name: 'Tobie' },
+			{ name: 'Jaime' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
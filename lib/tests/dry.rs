@@ -0,0 +1,113 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn dry_run_update_leaves_data_unchanged() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET age = 20;
+		CREATE person:2 SET age = 40;
+		DEFINE EVENT test ON person WHEN $event = 'UPDATE' THEN (CREATE activity SET user = $after.id);
+		DRY RUN UPDATE person SET old = true WHERE age > 30;
+		SELECT * FROM person;
+		SELECT * FROM activity;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:2,
+				age: 40,
+				old: true
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:1,
+				age: 20
+			},
+			{
+				id: person:2,
+				age: 40
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn dry_run_delete_leaves_data_unchanged() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2;
+		DRY RUN DELETE person RETURN BEFORE;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn dry_run_without_side_effects() -> Result<(), Error> {
+	let sql = "
+		DRY RUN CREATE person:1 SET page = http::get('https://surrealdb.com');
+		BEGIN TRANSACTION;
+		DRY RUN CREATE person:2;
+		COMMIT TRANSACTION;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DryRunNotAllowed { .. })));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DryRunNotAllowed { .. })));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,236 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Cipher;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+fn path(name: &str) -> String {
+	let dir = std::env::temp_dir().join(format!("surrealdb-{}-{}", name, std::process::id()));
+	format!("file://{}", dir.display())
+}
+
+#[tokio::test]
+async fn encrypt_values_round_trip() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie';
+		SELECT * FROM person;
+	";
+	let key = Cipher::new().with_key(1, &[7; 32])?;
+	let dbs = Datastore::new("memory").await?.with_encryption(key);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_values_are_stored_as_ciphertext() -> Result<(), Error> {
+	let path = path("encrypt-ciphertext");
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	// Write the data with encryption enabled
+	{
+		let key = Cipher::new().with_key(1, &[7; 32])?;
+		let dbs = Datastore::new(&path).await?.with_encryption(key);
+		let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+		res.remove(0).result?;
+	}
+	// Read the raw data without encryption
+	{
+		let dbs = Datastore::new(&path).await?;
+		let mut tx = dbs.transaction(false, false).await?;
+		let res = tx.scan(vec![0x00]..vec![0xff], 1000).await?;
+		tx.cancel().await?;
+		assert!(!res.is_empty());
+		for (_, v) in res.iter() {
+			assert_eq!(v[0], 1);
+			assert!(!v.windows(5).any(|v| v == b"Tobie"));
+		}
+	}
+	// Read the data with a rotated key set
+	{
+		let key = Cipher::new().with_key(1, &[7; 32])?.with_key(2, &[8; 32])?;
+		let dbs = Datastore::new(&path).await?.with_encryption(key);
+		let res = &mut dbs.execute("SELECT * FROM person;", &ses, None, false).await?;
+		let tmp = res.remove(0).result?;
+		let val = Value::parse("[{ id: person:tobie, name: 'Tobie' }]");
+		assert_eq!(tmp, val);
+	}
+	//
+	let _ = std::fs::remove_dir_all(path.trim_start_matches("file://"));
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_values_with_wrong_key_fail() -> Result<(), Error> {
+	let path = path("encrypt-wrong-key");
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	// Write the data with encryption enabled
+	{
+		let key = Cipher::new().with_key(1, &[7; 32])?;
+		let dbs = Datastore::new(&path).await?.with_encryption(key);
+		let res = &mut dbs.execute("CREATE person:tobie;", &ses, None, false).await?;
+		res.remove(0).result?;
+	}
+	// Read the data with the wrong key
+	{
+		let key = Cipher::new().with_key(1, &[8; 32])?;
+		let dbs = Datastore::new(&path).await?.with_encryption(key);
+		let res = &mut dbs.execute("SELECT * FROM person;", &ses, None, false).await?;
+		let tmp = res.remove(0).result;
+		assert!(matches!(tmp, Err(Error::Decrypt)));
+	}
+	//
+	let _ = std::fs::remove_dir_all(path.trim_start_matches("file://"));
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_fields_round_trip() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD ssn ON person TYPE string ENCRYPTED;
+		CREATE person:tobie SET name = 'Tobie', ssn = '123-45-6789';
+		UPDATE person:tobie SET name = 'Jaime';
+		SELECT * FROM person;
+		INFO FOR TABLE person;
+	";
+	let key = Cipher::new().with_key(1, &[7; 32])?;
+	let dbs = Datastore::new("memory").await?.with_field_encryption(key);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Tobie', ssn: '123-45-6789' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Jaime', ssn: '123-45-6789' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Jaime', ssn: '123-45-6789' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			ev: {},
+			fd: { ssn: 'DEFINE FIELD ssn ON person TYPE string ENCRYPTED' },
+			ft: {},
+			ix: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_fields_are_stored_as_ciphertext() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD ssn ON person TYPE string ENCRYPTED;
+		CREATE person:tobie SET name = 'Tobie', ssn = '123-45-6789';
+	";
+	let key = Cipher::new().with_key(1, &[7; 32])?;
+	let dbs = Datastore::new("memory").await?.with_field_encryption(key);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	res.remove(0).result?;
+	res.remove(0).result?;
+	// Read the raw record data
+	let mut tx = dbs.transaction(false, false).await?;
+	let res = tx.scan(vec![0x00]..vec![0xff], 1000).await?;
+	tx.cancel().await?;
+	// Only the encrypted field is stored as ciphertext
+	assert!(res.iter().any(|(_, v)| v.windows(5).any(|v| v == b"Tobie")));
+	assert!(!res.iter().any(|(_, v)| v.windows(11).any(|v| v == b"123-45-6789")));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_fields_in_where_clauses() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD ssn ON person TYPE string ENCRYPTED;
+		DEFINE FIELD email ON person TYPE string ENCRYPTED DETERMINISTIC;
+		DEFINE INDEX email ON person FIELDS email UNIQUE;
+		CREATE person:tobie SET ssn = '123-45-6789', email = 'tobie@surrealdb.com';
+		SELECT id FROM person WHERE ssn = '123-45-6789';
+		SELECT id FROM person WHERE email = 'tobie@surrealdb.com';
+		CREATE person:jaime SET ssn = '987-65-4321', email = 'tobie@surrealdb.com';
+		DEFINE INDEX ssn ON person FIELDS ssn;
+		CREATE person:jaime SET ssn = '987-65-4321', email = 'jaime@surrealdb.com';
+	";
+	let key = Cipher::new().with_key(1, &[7; 32])?;
+	let dbs = Datastore::new("memory").await?.with_field_encryption(key);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..4 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Randomly encrypted fields can not be matched
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Deterministically encrypted fields can be matched
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie }]");
+	assert_eq!(tmp, val);
+	// Deterministically encrypted fields can be uniquely indexed
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::IndexExists { .. })));
+	// Randomly encrypted fields can not be indexed
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "ENCRYPTED_INDEX"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn encrypt_fields_without_keys_fail() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD ssn ON person TYPE string ENCRYPTED;
+		CREATE person:tobie SET ssn = '123-45-6789';
+		CREATE person:jaime SET name = 'Jaime';
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "ENCRYPTION_DISABLED"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:jaime, name: 'Jaime' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,68 @@
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn error_code_parse() -> Result<(), Error> {
+	let sql = "SELECT * FORM person";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await;
+	assert!(matches!(res, Err(ref e) if e.code() == "QUERY_PARSE"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn error_code_not_found() -> Result<(), Error> {
+	let sql = "
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, true).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "NS_NOT_FOUND"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn error_code_permissions() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_sc("test", "test", "user");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_PERMISSIONS"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn error_code_in_response() -> Result<(), Error> {
+	let sql = "
+		SLEEP 10s TIMEOUT 10ms;
+		RETURN true;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = serde_json::to_value(&res[0]).unwrap();
+	assert_eq!(tmp["status"], "ERR");
+	assert_eq!(tmp["code"], "QUERY_TIMEOUT");
+	//
+	let tmp = serde_json::to_value(&res[1]).unwrap();
+	assert_eq!(tmp["status"], "OK");
+	assert!(tmp.get("code").is_none());
+	//
+	Ok(())
+}
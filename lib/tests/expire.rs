@@ -0,0 +1,190 @@
+mod parse;
+use parse::Parse;
+use std::thread::sleep;
+use std::time::Duration;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn export(dbs: &Datastore) -> Result<String, Error> {
+	// Create a new bounded channel
+	let (snd, rcv) = surrealdb::channel::new(1);
+	// Collect the exported lines
+	let out = async move {
+		let mut out = Vec::new();
+		while let Ok(v) = rcv.recv().await {
+			out.extend(v);
+		}
+		out
+	};
+	// Run the export and receive concurrently
+	let (res, out) = tokio::join!(dbs.export("test".to_owned(), "test".to_owned(), snd), out);
+	res?;
+	Ok(String::from_utf8(out).unwrap())
+}
+
+#[tokio::test]
+async fn expire_define_table_ttl() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE session TTL 1d TOUCH;
+		DEFINE TABLE token TTL 30m;
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: {},
+			tb: {
+				session: 'DEFINE TABLE session SCHEMALESS TTL 1d TOUCH',
+				token: 'DEFINE TABLE token SCHEMALESS TTL 30m',
+			},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn expire_record_is_invisible_and_reaped() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE session TTL 1s;
+		CREATE session:one SET active = true;
+		SELECT * FROM session;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:one, active: true }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:one, active: true }]");
+	assert_eq!(tmp, val);
+	// Wait for the record to expire
+	sleep(Duration::from_millis(1100));
+	//
+	let sql = "
+		SELECT * FROM session;
+		SELECT * FROM session:one;
+		SELECT * FROM session:one..session:two;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// The record remains in storage until reaped
+	assert!(export(&dbs).await?.contains("UPDATE session:one CONTENT"));
+	dbs.reap().await?;
+	assert!(!export(&dbs).await?.contains("UPDATE session:one CONTENT"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn expire_record_can_be_recreated() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE session TTL 1s;
+		CREATE session:one SET version = 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	// Wait for the record to expire
+	sleep(Duration::from_millis(1100));
+	//
+	let sql = "
+		CREATE session:one SET version = 2;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:one, version: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn expire_ttl_updates_on_touch() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE session TTL 2s TOUCH;
+		DEFINE TABLE token TTL 2s;
+		CREATE session:one;
+		CREATE token:one;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	// Touch both records before they expire
+	sleep(Duration::from_millis(1200));
+	let sql = "
+		UPDATE session:one SET seen = true;
+		UPDATE token:one SET seen = true;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	for v in res.drain(..) {
+		v.result?;
+	}
+	// Wait until the original expiry has passed
+	sleep(Duration::from_millis(1200));
+	let sql = "
+		SELECT * FROM session;
+		SELECT * FROM token;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:one, seen: true }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,80 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn export(dbs: &Datastore) -> Result<String, Error> {
+	// Create a new bounded channel
+	let (snd, rcv) = surrealdb::channel::new(1);
+	// Collect the exported lines
+	let out = async move {
+		let mut out = Vec::new();
+		while let Ok(v) = rcv.recv().await {
+			out.extend(v);
+		}
+		out
+	};
+	// Run the export and receive concurrently
+	let (res, out) = tokio::join!(dbs.export("test".to_owned(), "test".to_owned(), snd), out);
+	res?;
+	Ok(String::from_utf8(out).unwrap())
+}
+
+async fn query(dbs: &Datastore, sql: &str) -> Result<Vec<Value>, Error> {
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(sql, &ses, None, false).await?;
+	res.into_iter().map(|v| v.result).collect()
+}
+
+#[tokio::test]
+async fn export_import_round_trip() -> Result<(), Error> {
+	let sql = "
+		DEFINE SCOPE account SESSION 24h SIGNIN (SELECT * FROM user WHERE email = $email);
+		DEFINE TABLE person SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE string;
+		DEFINE FIELD age ON person TYPE number ASSERT $value > 0;
+		DEFINE INDEX name ON person FIELDS name UNIQUE;
+		DEFINE EVENT change ON person WHEN $before != $after THEN (CREATE log SET at = time::now());
+		DEFINE TABLE log SCHEMALESS;
+		CREATE person:tobie SET name = 'Tobie', age = 33;
+		CREATE person:jaime SET name = 'Jaime', age = 28;
+		UPDATE person:jaime SET age = 29;
+	";
+	let src = Datastore::new("memory").await?;
+	query(&src, sql).await?;
+	// Export the source database
+	let dump = export(&src).await?;
+	assert!(dump.contains("DEFINE TABLE person SCHEMAFULL;"));
+	assert!(dump.contains("BEGIN TRANSACTION;"));
+	assert!(dump.contains("COMMIT TRANSACTION;"));
+	// Import into a new database
+	let dst = Datastore::new("memory").await?;
+	query(&dst, &dump).await?;
+	// Check the schema definitions
+	let sql = "
+		INFO FOR DB;
+		INFO FOR TABLE person;
+		INFO FOR TABLE log;
+	";
+	assert_eq!(query(&src, sql).await?, query(&dst, sql).await?);
+	// Check the table records
+	let sql = "
+		SELECT * FROM person;
+		SELECT * FROM log;
+	";
+	let tmp = query(&dst, sql).await?;
+	assert_eq!(tmp, query(&src, sql).await?);
+	let val = Value::parse(
+		"[
+			{ id: person:jaime, age: 29, name: 'Jaime' },
+			{ id: person:tobie, age: 33, name: 'Tobie' }
+		]",
+	);
+	assert_eq!(tmp[0], val);
+	// Check that an export of the import is identical
+	assert_eq!(dump, export(&dst).await?);
+	//
+	Ok(())
+}
@@ -0,0 +1,210 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn fetch_single_field() -> Result<(), Error> {
+	let sql = "
+		CREATE user:tobie SET name = 'Tobie';
+		CREATE post:one SET author = user:tobie, title = 'One';
+		SELECT * FROM post FETCH author;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: {
+					id: user:tobie,
+					name: 'Tobie'
+				},
+				title: 'One'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn fetch_multiple_and_nested_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE company:surreal SET name = 'SurrealDB';
+		CREATE user:tobie SET name = 'Tobie', company = company:surreal;
+		CREATE user:jaime SET name = 'Jaime';
+		CREATE tag:rust SET name = 'Rust';
+		CREATE post:one SET
+			author = user:tobie,
+			tags = [tag:rust],
+			comments = [{ author: user:jaime, text: 'Nice' }];
+		SELECT * FROM post FETCH author, tags, comments.author;
+		SELECT * FROM post FETCH author.company;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: {
+					id: user:tobie,
+					company: company:surreal,
+					name: 'Tobie'
+				},
+				comments: [
+					{
+						author: {
+							id: user:jaime,
+							name: 'Jaime'
+						},
+						text: 'Nice'
+					}
+				],
+				tags: [
+					{
+						id: tag:rust,
+						name: 'Rust'
+					}
+				]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: {
+					id: user:tobie,
+					company: {
+						id: company:surreal,
+						name: 'SurrealDB'
+					},
+					name: 'Tobie'
+				},
+				comments: [
+					{
+						author: user:jaime,
+						text: 'Nice'
+					}
+				],
+				tags: [tag:rust]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn fetch_nil_links() -> Result<(), Error> {
+	let sql = "
+		CREATE post:one SET author = NULL, title = 'One';
+		CREATE post:two SET author = user:missing, title = 'Two';
+		CREATE post:three SET title = 'Three';
+		SELECT * FROM post FETCH author;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: NULL,
+				title: 'One'
+			},
+			{
+				id: post:three,
+				title: 'Three'
+			},
+			{
+				id: post:two,
+				author: NONE,
+				title: 'Two'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn fetch_respects_permissions() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE post SCHEMALESS PERMISSIONS FULL;
+		DEFINE TABLE user SCHEMALESS PERMISSIONS FOR select WHERE id = $auth;
+		CREATE user:one SET name = 'One';
+		CREATE user:two SET name = 'Two';
+		CREATE post:one SET author = user:one;
+		CREATE post:two SET author = user:two;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	//
+	let sql = "SELECT * FROM post FETCH author";
+	let mut ses = Session::for_sc("test", "test", "user");
+	ses.sd = Some(Value::parse("user:one"));
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	// Only the permitted linked record is fetched
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: {
+					id: user:one,
+					name: 'One'
+				}
+			},
+			{
+				id: post:two,
+				author: NONE
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,169 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn foreach_statement_array() -> Result<(), Error> {
+	let sql = "
+		FOR $num IN [1, 2, 3] {
+			LET $double = $num * 2;
+			CREATE type::thing('person', $num) SET double = $double;
+		};
+		SELECT * FROM person;
+		RETURN [$num, $double];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:1,
+				double: 2
+			},
+			{
+				id: person:2,
+				double: 4
+			},
+			{
+				id: person:3,
+				double: 6
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[NONE, NONE]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn foreach_statement_subquery() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET age = 30;
+		CREATE person:jaime SET age = 20;
+		CREATE person:alex SET age = 10;
+		FOR $person IN (SELECT * FROM person WHERE age >= 18) {
+			UPDATE $person.id SET adult = true;
+		};
+		SELECT * FROM person WHERE adult = true;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:jaime,
+				age: 20,
+				adult: true
+			},
+			{
+				id: person:tobie,
+				age: 30,
+				adult: true
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn foreach_statement_iteration_limit() -> Result<(), Error> {
+	let sql = "
+		FOR $num IN [1, 2, 3, 4, 5] {
+			CREATE type::thing('person', $num);
+		};
+		FOR $num IN [1, 2, 3] {
+			CREATE type::thing('person', $num);
+		};
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?.with_iterations(3);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp,
+		Err(Error::TooManyIterations {
+			limit: 3
+		})
+	));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:1
+			},
+			{
+				id: person:2
+			},
+			{
+				id: person:3
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn foreach_statement_transaction() -> Result<(), Error> {
+	let sql = "
+		BEGIN TRANSACTION;
+		FOR $num IN [1, 2, 3] {
+			CREATE type::thing('person', $num);
+		};
+		CANCEL TRANSACTION;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryCancelled)));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,728 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Datetime;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn function_crypto_argon2() -> Result<(), Error> {
+	let sql = r#"
+		LET $hash = crypto::argon2::generate("password");
+		RETURN crypto::argon2::compare($hash, "password");
+		RETURN crypto::argon2::compare($hash, "wrong");
+		RETURN crypto::argon2::compare("invalid", "password");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_crypto_bcrypt() -> Result<(), Error> {
+	let sql = r#"
+		LET $hash = crypto::bcrypt::generate("password");
+		RETURN crypto::bcrypt::compare($hash, "password");
+		RETURN crypto::bcrypt::compare($hash, "wrong");
+		RETURN crypto::bcrypt::compare("invalid", "password");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_crypto_signup_and_signin() -> Result<(), Error> {
+	let sql = r#"
+		CREATE user:test SET pass = crypto::argon2::generate("password");
+		SELECT crypto::argon2::compare(pass, "password") AS valid FROM user:test;
+		SELECT * FROM user WHERE crypto::argon2::compare(pass, "wrong");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ valid: true }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_push() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::push([1, 2], 3);
+		RETURN array::push([], [1, 2]);
+		RETURN array::push(NONE, 3);
+		RETURN array::push("test", 3);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2, 3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[[1, 2]]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_remove() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::remove([1, 2, 3], 1);
+		RETURN array::remove([1, 2, 3], -1);
+		RETURN array::remove([1, 2, 3], 5);
+		RETURN array::remove([], 0);
+		RETURN array::remove(NONE, 0);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2, 3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_distinct() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::distinct([1, 2, 1, [3], [3]]);
+		RETURN array::distinct([]);
+		RETURN array::distinct(NONE);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2, [3]]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_flatten() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::flatten([[1, 2], [3], 4]);
+		RETURN array::flatten([[1, [2]], []]);
+		RETURN array::flatten([]);
+		RETURN array::flatten("test");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2, 3, 4]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, [2]]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_union() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::union([1, 2], [2, 3]);
+		RETURN array::union([], []);
+		RETURN array::union([1], NONE);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[1, 2, 3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_slug() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::slug("This is a test");
+		RETURN string::slug("Ünïcödé strïng — test!");
+		RETURN string::slug("");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("this-is-a-test");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("unicode-string-test");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_split() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::split("a, b, c", ", ");
+		RETURN string::split("añb→c", "→");
+		RETURN string::split("añb", "");
+		RETURN string::split("", ",");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['a', 'b', 'c']");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['añb', 'c']");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['a', 'ñ', 'b']");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['']");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_replace() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::replace("hello world", "world", "there");
+		RETURN string::replace("naïve café", "é", "e");
+		RETURN string::replace("", "a", "b");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("hello there");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("naïve cafe");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_matches() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::matches("test@surrealdb.com", "^[a-z]+@[a-z]+\\.com$");
+		RETURN string::matches("テスト", "^\\p{Katakana}+$");
+		RETURN string::matches("", "^$");
+		RETURN string::matches("test", "[a-z");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::True);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::True);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::True);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp.err(), Some(Error::InvalidArguments { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_time_floor() -> Result<(), Error> {
+	let sql = r#"
+		RETURN time::floor("2021-11-01T08:30:17Z", 1d);
+		RETURN time::floor("2021-11-01T08:30:17Z", 1h);
+		RETURN time::floor("test", 1h);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(Datetime::from("2021-11-01T00:00:00Z"));
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(Datetime::from("2021-11-01T08:00:00Z"));
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_time_add() -> Result<(), Error> {
+	let sql = r#"
+		RETURN time::add("2022-03-27T00:30:00Z", 1h);
+		RETURN time::add("2021-12-31T23:00:00Z", 2h);
+		RETURN time::add("test", 1h);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(Datetime::from("2022-03-27T01:30:00Z"));
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(Datetime::from("2022-01-01T01:00:00Z"));
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_time_format() -> Result<(), Error> {
+	let sql = r#"
+		RETURN time::format("2022-03-27T00:30:00Z", "%Y-%m-%d %H:%M");
+		RETURN time::format("2022-03-27T00:30:00Z", "%H:%M %Z", "Europe/London");
+		RETURN time::format(time::add("2022-03-27T00:30:00Z", 1h), "%H:%M %Z", "Europe/London");
+		RETURN time::format("2022-03-27T00:30:00Z", "%Y-%m-%d %H:%M", "Asia/Tokyo");
+		RETURN time::format("2022-03-27T00:30:00Z", "%H:%M", "Mars/Olympus");
+		RETURN time::format("2022-03-27T00:30:00Z", "%Q");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("2022-03-27 00:30");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("00:30 GMT");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("02:30 BST");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("2022-03-27 09:30");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp.err(), Some(Error::InvalidArguments { .. })));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp.err(), Some(Error::InvalidArguments { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_type_number() -> Result<(), Error> {
+	let sql = r#"
+		RETURN type::int("42");
+		RETURN type::int(3.7);
+		RETURN type::float("1.5");
+		RETURN type::decimal(true);
+		RETURN type::int("abc");
+		RETURN type::float(NONE);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("42");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("3");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1.5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "CONVERT_TO"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "CONVERT_TO"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_type_datetime() -> Result<(), Error> {
+	let sql = r#"
+		RETURN type::datetime("2022-01-01T00:00:00Z");
+		RETURN type::datetime("tomorrow");
+		RETURN type::duration("1h30m");
+		RETURN type::duration("1 hour");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'2022-01-01T00:00:00Z'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "CONVERT_TO"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1h30m");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "CONVERT_TO"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_type_record() -> Result<(), Error> {
+	let sql = r#"
+		RETURN type::record("person", 1);
+		RETURN type::record("person", "tobie");
+		RETURN type::record("person:tobie");
+		RETURN type::record("person", NONE);
+		RETURN type::record("", 1);
+		RETURN type::record("tobie");
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:1");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:tobie");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:tobie");
+	assert_eq!(tmp, val);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(matches!(tmp, Err(ref e) if e.code() == "CONVERT_TO"));
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_type_round_trip() -> Result<(), Error> {
+	let sql = r#"
+		RETURN type::int(type::string(42));
+		RETURN type::float(type::string(1.5));
+		RETURN type::datetime(type::string("2022-01-01T00:00:00Z"));
+		RETURN type::record(type::string(person:tobie));
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("42");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1.5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'2022-01-01T00:00:00Z'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:tobie");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_custom_arguments() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FUNCTION fn::greet($name) { RETURN "Hello " + $name; };
+		DEFINE FUNCTION fn::person::name($first, $last) {
+			LET $full = $first + " " + $last;
+			RETURN $full;
+		};
+		RETURN fn::greet("Tobie");
+		RETURN fn::person::name("Tobie", "Morgan Hitchcock");
+		RETURN fn::greet();
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'Hello Tobie'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'Tobie Morgan Hitchcock'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidArguments { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_custom_nested() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FUNCTION fn::double($num) { RETURN $num * 2; };
+		DEFINE FUNCTION fn::quadruple($num) { RETURN fn::double(fn::double($num)); };
+		DEFINE FUNCTION fn::factorial($num) {
+			RETURN IF $num <= 1 THEN 1 ELSE $num * fn::factorial($num - 1) END;
+		};
+		RETURN fn::quadruple(5);
+		RETURN fn::factorial(5);
+		RETURN fn::missing(5);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("20");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("120");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::FcNotFound { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_custom_recursion_limit() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FUNCTION fn::forever($num) { RETURN fn::forever($num + 1); };
+		RETURN fn::forever(0);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TooManySubqueries)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_custom_permissions() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FUNCTION fn::public() { RETURN "public"; };
+		DEFINE FUNCTION fn::private() { RETURN "private"; } PERMISSIONS NONE;
+		DEFINE FUNCTION fn::owner() { RETURN "owner"; } PERMISSIONS WHERE $auth = user:tobie;
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.drain(..) {
+		v.result?;
+	}
+	//
+	let sql = r#"
+		RETURN fn::public();
+		RETURN fn::private();
+		RETURN fn::owner();
+		DEFINE FUNCTION fn::other() { RETURN "other"; };
+	"#;
+	let mut ses = Session::for_sc("test", "test", "user");
+	ses.sd = Some(Value::parse("user:jaime"));
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'public'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::FunctionPermissions { .. })));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::FunctionPermissions { .. })));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
+	//
+	let sql = "RETURN fn::private();";
+	let ses = Session::for_db("test", "test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'private'");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -227,3 +227,108 @@ async fn geometry_multipolygon() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn geometry_point_inside_polygon() -> Result<(), Error> {
+	let sql = "
+		LET $area = {
+			type: 'Polygon',
+			coordinates: [[
+				[-0.38314819, 51.37692386], [0.1785278, 51.37692386],
+				[0.1785278, 51.61460570], [-0.38314819, 51.61460570],
+				[-0.38314819, 51.37692386]
+			]]
+		};
+		CREATE city:london SET centre = (-0.118092, 51.509865);
+		CREATE city:paris SET centre = (2.352222, 48.856614);
+		SELECT id FROM city WHERE centre INSIDE $area;
+		SELECT id FROM city WHERE centre OUTSIDE $area;
+		SELECT id FROM city WHERE $area CONTAINS centre;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: city:london }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: city:paris }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: city:london }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn geometry_distance() -> Result<(), Error> {
+	let sql = "
+		RETURN geo::distance((-0.118092, 51.509865), (2.352222, 48.856614));
+		RETURN geo::distance((-0.118092, 51.509865), (-0.118092, 51.509865));
+		RETURN geo::distance((-0.118092, 51.509865), 'london');
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	// The haversine distance from London to Paris in meters
+	let tmp = res.remove(0).result?;
+	assert!((tmp.as_float() - 343434.63).abs() < 1.0);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.as_float(), 0.0);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn geometry_invalid_geojson() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD location ON city TYPE geometry(point) ASSERT $value != NONE;
+		CREATE city:london SET location = { type: 'Point', coordinates: [-0.118092, 51.509865] };
+		CREATE city:short SET location = { type: 'Point', coordinates: [-0.118092] };
+		CREATE city:text SET location = { type: 'Point', coordinates: 'london' };
+		CREATE city:area SET location = {
+			type: 'Polygon',
+			coordinates: [[[0.0, 0.0], [1.0, 0.0], [1.0, 1.0], [0.0, 0.0]]]
+		};
+		SELECT id FROM city;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[{ id: city:london, location: { type: 'Point', coordinates: [-0.118092, 51.509865] } }]",
+	);
+	assert_eq!(tmp, val);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(matches!(tmp, Err(Error::FieldValue { .. })));
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: city:london }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
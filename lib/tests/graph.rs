@@ -0,0 +1,109 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn graph_traversal_cyclic() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		CREATE person:jaime;
+		RELATE person:tobie->knows->person:jaime;
+		RELATE person:jaime->knows->person:tobie;
+		RELATE person:jaime->knows->person:tobie;
+		SELECT ->knows->person->knows->person AS people FROM person:tobie;
+		SELECT ->knows->person->knows->person->knows->person AS people FROM person:tobie;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				people: [person:tobie]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				people: [person:jaime]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn graph_traversal_depth() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		CREATE person:jaime;
+		RELATE person:tobie->knows->person:jaime;
+		RELATE person:jaime->knows->person:tobie;
+		OPTION DEPTH = 2;
+		SELECT ->knows->person AS people FROM person:tobie;
+		SELECT ->knows->person->knows->person AS people FROM person:tobie;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				people: [person:jaime]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(Error::TooManyTraversals {
+			depth: 2
+		})
+	));
+	//
+	Ok(())
+}
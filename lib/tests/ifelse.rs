@@ -0,0 +1,140 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn ifelse_statement_branches() -> Result<(), Error> {
+	let sql = "
+		LET $x = 10;
+		IF $x > 0 THEN (CREATE person:one) ELSE (CREATE person:two) END;
+		IF $x < 0 THEN (CREATE person:three) ELSE (CREATE person:four) END;
+		IF $x < 0 THEN (CREATE person:five) END;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:one");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("person:four");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:four
+			},
+			{
+				id: person:one
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn ifelse_statement_blocks() -> Result<(), Error> {
+	let sql = "
+		LET $x = 5;
+		IF $x > 0 THEN {
+			LET $name = 'positive';
+			CREATE person:one SET name = $name;
+			RETURN $name;
+		} ELSE {
+			CREATE person:two;
+			RETURN 'negative';
+		} END;
+		IF $x > 10 THEN {
+			CREATE person:three;
+		} END;
+		SELECT * FROM person;
+		RETURN $name;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("positive");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:one,
+				name: 'positive'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn ifelse_statement_nested() -> Result<(), Error> {
+	let sql = "
+		LET $x = 5;
+		IF $x > 0 THEN {
+			RETURN (IF $x > 10 THEN {
+				RETURN 'large';
+			} ELSE IF $x > 1 THEN {
+				RETURN 'medium';
+			} ELSE {
+				RETURN 'small';
+			} END);
+		} ELSE {
+			RETURN 'negative';
+		} END;
+		IF $x > 0 THEN (IF $x > 3 THEN 'yes' ELSE 'no' END) ELSE 'never' END;
+		IF $x < 0 THEN 'negative' ELSE IF $x = 0 THEN 'zero' END;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("medium");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("yes");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
@@ -0,0 +1,124 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn setup(dbs: &Datastore, ses: &Session) -> Result<(), Error> {
+	let sql = "
+		DEFINE INDEX email ON person FIELDS email UNIQUE;
+		DEFINE INDEX team ON person FIELDS team;
+		CREATE person:one SET email = 'one@surrealdb.com', team = 'red', name = 'One';
+		CREATE person:two SET email = 'two@surrealdb.com', team = 'blue', name = 'Two';
+		CREATE person:three SET email = 'three@surrealdb.com', team = 'red', name = 'Three';
+	";
+	for v in dbs.execute(&sql, ses, None, false).await? {
+		v.result?;
+	}
+	Ok(())
+}
+
+#[tokio::test]
+async fn index_equality_predicates() -> Result<(), Error> {
+	let sql = "
+		SELECT id FROM person WHERE email = 'two@surrealdb.com';
+		SELECT id FROM person WHERE team = 'red';
+		SELECT id FROM person WHERE team INSIDE ['blue', 'green'];
+		SELECT id FROM person WHERE 'red' = team AND name = 'Three';
+		SELECT id FROM person WHERE team = 'green';
+		SELECT id FROM person WHERE team = 'blue' OR name = 'One';
+		SELECT id FROM person WHERE name = 'Three';
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	setup(&dbs, &ses).await?;
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn index_used_for_indexed_fields() -> Result<(), Error> {
+	// Each record which is checked against the
+	// WHERE clause creates a visit record, so the
+	// number of visits shows how many records were
+	// iterated over to find any matching records.
+	let sql = "
+		SELECT id FROM person WHERE (CREATE visit) AND email = 'two@surrealdb.com';
+		SELECT count() FROM visit GROUP BY ALL;
+		DELETE visit;
+		SELECT id FROM person WHERE (CREATE visit) AND team = $team;
+		SELECT count() FROM visit GROUP BY ALL;
+		DELETE visit;
+		SELECT id FROM person WHERE (CREATE visit) AND name = 'Two';
+		SELECT count() FROM visit GROUP BY ALL;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	setup(&dbs, &ses).await?;
+	let vars = Some([("team".to_owned(), Value::from("red"))].into());
+	let res = &mut dbs.execute(&sql, &ses, vars, false).await?;
+	assert_eq!(res.len(), 8);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,159 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn schema(dbs: &Datastore) -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMALESS;
+		DEFINE TABLE post SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE string;
+		DEFINE INDEX email ON person FIELDS email UNIQUE;
+		DEFINE EVENT created ON person WHEN true THEN (CREATE log);
+		DEFINE SCOPE account SESSION 1d;
+		DEFINE SCOPE admin SESSION 1h;
+		DEFINE TOKEN api ON DATABASE TYPE HS512 VALUE 'secret';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	Ok(())
+}
+
+#[tokio::test]
+async fn info_for_db() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	schema(&dbs).await?;
+	//
+	let sql = "INFO FOR DB";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: { api: 'DEFINE TOKEN api ON DATABASE TYPE HS512 VALUE \\'secret\\'' },
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: {
+				account: 'DEFINE SCOPE account SESSION 1d',
+				admin: 'DEFINE SCOPE admin SESSION 1h',
+			},
+			tb: {
+				person: 'DEFINE TABLE person SCHEMALESS',
+				post: 'DEFINE TABLE post SCHEMAFULL',
+			},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn info_for_table() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	schema(&dbs).await?;
+	//
+	let sql = "INFO FOR TABLE person";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			ev: { created: 'DEFINE EVENT created ON person WHEN true THEN (CREATE log)' },
+			fd: { name: 'DEFINE FIELD name ON person TYPE string' },
+			ft: {},
+			ix: { email: 'DEFINE INDEX email ON person FIELDS email UNIQUE' },
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn info_for_own_namespace() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	schema(&dbs).await?;
+	//
+	let sql = "
+		INFO FOR NS;
+		INFO FOR TABLE post;
+	";
+	let ses = Session::for_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			db: { test: 'DEFINE DATABASE test' },
+			nl: {},
+			nt: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			ev: {},
+			fd: {},
+			ft: {},
+			ix: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn info_for_other_namespace_or_database() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	schema(&dbs).await?;
+	//
+	let sql = "
+		INFO FOR NS;
+		INFO FOR DB;
+		INFO FOR TABLE person;
+		INFO FOR SCOPE account;
+	";
+	// Namespace users can not view other namespaces
+	let ses = Session::for_ns("other").with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	for v in res.into_iter() {
+		assert!(matches!(v.result, Err(Error::NsNotAllowed { .. })));
+	}
+	// Database users can not view the namespace or other databases
+	let ses = Session::for_db("test", "other").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
+	//
+	for v in res.drain(..) {
+		assert!(matches!(v.result, Err(Error::DbNotAllowed { .. })));
+	}
+	// Scope users can not view the schema
+	let ses = Session::for_sc("test", "test", "account");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	for v in res.into_iter() {
+		assert!(matches!(v.result, Err(Error::QueryPermissions)));
+	}
+	//
+	Ok(())
+}
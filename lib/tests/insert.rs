@@ -120,6 +120,62 @@ async fn insert_statement_on_duplicate_key() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn insert_statement_on_duplicate_key_object() -> Result<(), Error> {
+	let sql = "
+		INSERT INTO counter { id: 'one', count: 1 } ON DUPLICATE KEY UPDATE count += 1;
+		INSERT INTO counter { id: 'one', count: 1 } ON DUPLICATE KEY UPDATE count += 1;
+		INSERT INTO counter { id: 'two', count: 1 } ON DUPLICATE KEY UPDATE count += 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: counter:one, count: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: counter:one, count: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: counter:two, count: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn insert_statement_on_duplicate_key_concurrent() -> Result<(), Error> {
+	let sql = "
+		INSERT INTO counter { id: 'one', count: 1 } ON DUPLICATE KEY UPDATE count += 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	// Run the same upsert concurrently
+	let (one, two, three) = tokio::join!(
+		dbs.execute(&sql, &ses, None, false),
+		dbs.execute(&sql, &ses, None, false),
+		dbs.execute(&sql, &ses, None, false),
+	);
+	for res in [one?, two?, three?] {
+		for v in res.into_iter() {
+			v.result?;
+		}
+	}
+	// Check that each upsert was applied once
+	let res = &mut dbs.execute("SELECT * FROM counter;", &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: counter:one, count: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn insert_statement_output() -> Result<(), Error> {
 	let sql = "
@@ -0,0 +1,72 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::serde::{beg_safe_integer_serialization, end_safe_integer_serialization};
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn json_large_integers_as_numbers() -> Result<(), Error> {
+	let sql = "
+		CREATE person:9007199254740993 SET big = 9007199254740993, small = 42;
+		SELECT * FROM person:9007199254740993;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let out = serde_json::to_string(&tmp).unwrap();
+	assert_eq!(out, r#"[{"big":9007199254740993,"id":"person:9007199254740993","small":42}]"#);
+	// The integers survive a round trip without losing precision
+	let val = surrealdb::sql::json(&out)?;
+	let exp = Value::parse(
+		"[
+			{
+				big: 9007199254740993,
+				id: 'person:9007199254740993',
+				small: 42
+			}
+		]",
+	);
+	assert_eq!(val, exp);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn json_large_integers_as_strings() -> Result<(), Error> {
+	let sql = "
+		CREATE person:9007199254740993 SET big = 9007199254740993, neg = -9007199254740993, safe = 9007199254740991, small = 42, float = <float> 1.5;
+		SELECT * FROM person:9007199254740993;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	beg_safe_integer_serialization();
+	let out = serde_json::to_string(&tmp).unwrap();
+	end_safe_integer_serialization();
+	assert_eq!(
+		out,
+		r#"[{"big":"9007199254740993","float":1.5,"id":"person:9007199254740993","neg":"-9007199254740993","safe":9007199254740991,"small":42}]"#
+	);
+	// The stored values are left as integers
+	let out = serde_json::to_string(&tmp).unwrap();
+	assert_eq!(
+		out,
+		r#"[{"big":9007199254740993,"float":1.5,"id":"person:9007199254740993","neg":-9007199254740993,"safe":9007199254740991,"small":42}]"#
+	);
+	//
+	Ok(())
+}
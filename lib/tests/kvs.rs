@@ -0,0 +1,129 @@
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+fn path(name: &str) -> String {
+	let dir = std::env::temp_dir().join(format!("surrealdb-{}-{}", name, std::process::id()));
+	format!("file://{}", dir.display())
+}
+
+async fn check_transactions(dbs: &Datastore) -> Result<(), Error> {
+	// Check writes and reads in a transaction
+	let mut tx = dbs.transaction(true, false).await?;
+	tx.set("test", "one").await?;
+	assert_eq!(tx.get("test").await?, Some(b"one".to_vec()));
+	assert!(tx.exi("test").await?);
+	assert!(!tx.exi("none").await?);
+	// Check that uncommitted writes are isolated
+	let mut other = dbs.transaction(false, false).await?;
+	assert_eq!(other.get("test").await?, None);
+	other.cancel().await?;
+	tx.commit().await?;
+	// Check that committed writes are visible
+	let mut tx = dbs.transaction(false, false).await?;
+	assert_eq!(tx.get("test").await?, Some(b"one".to_vec()));
+	tx.cancel().await?;
+	// Check that cancelled writes are discarded
+	let mut tx = dbs.transaction(true, false).await?;
+	tx.set("test", "two").await?;
+	tx.cancel().await?;
+	let mut tx = dbs.transaction(false, false).await?;
+	assert_eq!(tx.get("test").await?, Some(b"one".to_vec()));
+	// Check that read only transactions can not write
+	assert!(matches!(tx.set("test", "two").await, Err(Error::TxReadonly)));
+	tx.cancel().await?;
+	// Check that finished transactions can not be used
+	assert!(matches!(tx.cancel().await, Err(Error::TxFinished)));
+	Ok(())
+}
+
+async fn check_conditions(dbs: &Datastore) -> Result<(), Error> {
+	let mut tx = dbs.transaction(true, false).await?;
+	// Check inserting keys which already exist
+	tx.put("cond", "one").await?;
+	assert!(matches!(tx.put("cond", "two").await, Err(Error::TxKeyAlreadyExists)));
+	// Check conditional updates
+	assert!(tx.putc("cond", "two", Some("none")).await.is_err());
+	tx.putc("cond", "two", Some("one")).await?;
+	assert_eq!(tx.get("cond").await?, Some(b"two".to_vec()));
+	// Check conditional deletes
+	assert!(tx.delc("cond", Some("one")).await.is_err());
+	tx.delc("cond", Some("two")).await?;
+	assert_eq!(tx.get("cond").await?, None);
+	tx.cancel().await?;
+	Ok(())
+}
+
+async fn check_iteration(dbs: &Datastore) -> Result<(), Error> {
+	let mut tx = dbs.transaction(true, false).await?;
+	tx.set("scan:3", "3").await?;
+	tx.set("scan:1", "1").await?;
+	tx.set("scan:2", "2").await?;
+	tx.set("scan:4", "4").await?;
+	tx.commit().await?;
+	// Check that keys are returned in order
+	let mut tx = dbs.transaction(false, false).await?;
+	let res = tx.scan("scan:1".."scan:4", 1000).await?;
+	let keys: Vec<Vec<u8>> = res.into_iter().map(|(k, _)| k).collect();
+	assert_eq!(keys, vec![b"scan:1".to_vec(), b"scan:2".to_vec(), b"scan:3".to_vec()]);
+	// Check that the scan limit is respected
+	let res = tx.scan("scan:1".."scan:9", 2).await?;
+	assert_eq!(res.len(), 2);
+	// Check that batched scans return every key
+	let res = tx.getr("scan:1".."scan:9", 1000).await?;
+	assert_eq!(res.len(), 4);
+	tx.cancel().await?;
+	Ok(())
+}
+
+async fn check_queries(dbs: &Datastore) -> Result<Vec<Value>, Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie', age = 33;
+		CREATE person:jaime SET name = 'Jaime', age = 28;
+		UPDATE person:jaime SET age = 29;
+		SELECT name FROM person WHERE age > 30;
+		SELECT count() FROM person GROUP ALL;
+		DELETE person:tobie;
+		SELECT * FROM person;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	res.into_iter().map(|v| v.result).collect()
+}
+
+#[tokio::test]
+async fn kvs_memory_backend() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	check_transactions(&dbs).await?;
+	check_conditions(&dbs).await?;
+	check_iteration(&dbs).await?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn kvs_file_backend() -> Result<(), Error> {
+	let path = path("kvs-file");
+	{
+		let dbs = Datastore::new(&path).await?;
+		check_transactions(&dbs).await?;
+		check_conditions(&dbs).await?;
+		check_iteration(&dbs).await?;
+	}
+	let _ = std::fs::remove_dir_all(path.trim_start_matches("file://"));
+	Ok(())
+}
+
+#[tokio::test]
+async fn kvs_backends_return_equal_query_results() -> Result<(), Error> {
+	let path = path("kvs-parity");
+	let mem = check_queries(&Datastore::new("memory").await?).await?;
+	let file = {
+		let dbs = Datastore::new(&path).await?;
+		check_queries(&dbs).await?
+	};
+	assert_eq!(mem, file);
+	let _ = std::fs::remove_dir_all(path.trim_start_matches("file://"));
+	Ok(())
+}
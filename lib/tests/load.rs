@@ -0,0 +1,141 @@
+mod parse;
+use parse::Parse;
+use surrealdb::load::Format;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn load_ndjson_with_malformed_row() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let mut ldr = dbs.loader(&ses, "person", Format::Ndjson, 1000, false);
+	let mut res = vec![];
+	for i in 1..=5000 {
+		let line = match i {
+			2500 => String::from(r#"{ "id": 2500, "name": "#),
+			i => format!(r#"{{ "id": {}, "name": "Person {}" }}"#, i, i),
+		};
+		if let Some(v) = ldr.push(&line).await {
+			res.push(v);
+		}
+	}
+	if let Some(v) = ldr.finish().await {
+		res.push(v);
+	}
+	assert_eq!(res.len(), 5);
+	//
+	for (i, v) in res.iter().enumerate() {
+		assert_eq!(v.batch, i + 1);
+		assert_eq!(v.start, i * 1000 + 1);
+		assert_eq!(v.end, i * 1000 + 1000);
+		assert_eq!(v.rows, 1000);
+		match i {
+			2 => assert!(v.error.as_ref().unwrap().starts_with("Line 2500:")),
+			_ => assert!(v.error.is_none()),
+		}
+	}
+	// Only the batch with the malformed row is missing
+	let sql = "
+		SELECT count() FROM person GROUP ALL;
+		SELECT * FROM person:2001, person:3001;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 4000 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:3001,
+				name: 'Person 3001'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn load_csv_with_schema_validation() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE string;
+		DEFINE FIELD age ON person TYPE int ASSERT $value >= 0;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	//
+	let mut ldr = dbs.loader(&ses, "person", Format::Csv, 2, false);
+	let mut res = vec![];
+	for line in [
+		"id,name,age,email",
+		"tobie,\"Morgan Hitchcock, Tobie\",30,tobie@surrealdb.com",
+		"jaime,Jaime,20,",
+		"alex,Alex,-1,",
+		"lucy,Lucy,40,",
+		"emma,Emma,25",
+		"kate,Kate,35,",
+		"lily,Lily,28,",
+	] {
+		if let Some(v) = ldr.push(line).await {
+			res.push(v);
+		}
+	}
+	if let Some(v) = ldr.finish().await {
+		res.push(v);
+	}
+	assert_eq!(res.len(), 4);
+	//
+	assert_eq!(res[0].start, 2);
+	assert_eq!(res[0].end, 3);
+	assert!(res[0].error.is_none());
+	// The assertion failed for one of the rows
+	assert_eq!(res[1].start, 4);
+	assert_eq!(res[1].end, 5);
+	assert!(res[1].error.is_some());
+	// The row had the wrong number of columns
+	assert_eq!(res[2].start, 6);
+	assert_eq!(res[2].end, 7);
+	assert!(res[2].error.as_ref().unwrap().starts_with("Line 6:"));
+	// The final batch is imported
+	assert_eq!(res[3].start, 8);
+	assert_eq!(res[3].rows, 1);
+	assert!(res[3].error.is_none());
+	//
+	let sql = "SELECT * FROM person";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:jaime,
+				name: 'Jaime',
+				age: 20
+			},
+			{
+				id: person:lily,
+				name: 'Lily',
+				age: 28
+			},
+			{
+				id: person:tobie,
+				name: 'Morgan Hitchcock, Tobie',
+				age: 30
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
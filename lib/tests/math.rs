@@ -0,0 +1,137 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn math_statistics_known_values() -> Result<(), Error> {
+	let sql = "
+		RETURN math::median([5, 1, 3]);
+		RETURN math::median([4, 1, 3, 2]);
+		RETURN math::mode([1, 2, 2, 3]);
+		RETURN math::variance([1, 3, 5]);
+		RETURN math::stddev([1, 3, 5]);
+		RETURN math::percentile([4, 1, 3, 2], 50);
+		RETURN math::percentile([4, 1, 3, 2], 25);
+		RETURN math::percentile([10, 20, 30, 40, 50], 75);
+		RETURN math::percentile([10, 20, 30, 40, 50], 0);
+		RETURN math::percentile([10, 20, 30, 40, 50], 100);
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 10);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("3");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("2.5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("2");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("4");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("2");
+	assert_eq!(tmp, val);
+	// Percentiles interpolate between neighbouring values
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("2.5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1.75");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("40");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("10");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("50");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn math_statistics_small_inputs() -> Result<(), Error> {
+	let sql = "
+		RETURN math::median([]);
+		RETURN math::mode([]);
+		RETURN math::variance([]);
+		RETURN math::stddev([]);
+		RETURN math::percentile([], 50);
+		RETURN math::variance([7]);
+		RETURN math::stddev([7]);
+		RETURN [math::median([7]), math::mode([7]), math::percentile([7], 50)];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 8);
+	//
+	for _ in 0..7 {
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::None);
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[7, 7, 7]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn math_statistics_group_by() -> Result<(), Error> {
+	let sql = "
+		CREATE score:1 SET team = 'a', value = 1;
+		CREATE score:2 SET team = 'a', value = 3;
+		CREATE score:3 SET team = 'a', value = 5;
+		CREATE score:4 SET team = 'b', value = 2;
+		CREATE score:5 SET team = 'b', value = 4;
+		SELECT team, math::median(value) AS median, math::variance(value) AS variance FROM score GROUP BY team;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				team: 'a',
+				median: 3,
+				variance: 4
+			},
+			{
+				team: 'b',
+				median: 3,
+				variance: 2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,94 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn migrate_statement_applied_once() -> Result<(), Error> {
+	let sql = "
+		MIGRATE v1 { DEFINE TABLE person SCHEMAFULL; DEFINE FIELD name ON person TYPE string; };
+		MIGRATE v1 { DEFINE TABLE person SCHEMALESS; };
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("false");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: { v1: 'MIGRATE v1 { DEFINE TABLE person SCHEMAFULL; DEFINE FIELD name ON person TYPE string; }' },
+			pa: {},
+			sc: {},
+			tb: { person: 'DEFINE TABLE person SCHEMAFULL' },
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn migrate_statement_failed_not_applied() -> Result<(), Error> {
+	let sql = "
+		MIGRATE v1 { DEFINE TABLE person; CREATE person:tobie; CREATE person:tobie; };
+		INFO FOR DB;
+		MIGRATE v1 { DEFINE TABLE person; CREATE person:tobie; };
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_err());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
+			sc: {},
+			tb: {},
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("true");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			dl: {},
+			dt: {},
+			fc: {},
+			mg: { v1: 'MIGRATE v1 { DEFINE TABLE person SCHEMALESS; CREATE person:tobie; }' },
+			pa: {},
+			sc: {},
+			tb: { person: 'DEFINE TABLE person SCHEMALESS' },
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,106 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn only_returns_a_single_record() -> Result<(), Error> {
+	let sql = "
+		CREATE ONLY person:tobie SET name = 'Tobie';
+		UPDATE ONLY person:tobie SET name = 'Jaime';
+		SELECT * FROM ONLY person:tobie;
+		SELECT name FROM ONLY person WHERE name = 'Jaime';
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ id: person:tobie, name: 'Tobie' }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ id: person:tobie, name: 'Jaime' }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ id: person:tobie, name: 'Jaime' }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ name: 'Jaime' }");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn only_fails_without_records() -> Result<(), Error> {
+	let sql = "
+		SELECT * FROM ONLY person:tobie;
+		SELECT * FROM ONLY person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "SINGLE_ONLY_OUTPUT"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "SINGLE_ONLY_OUTPUT"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn only_fails_with_multiple_records() -> Result<(), Error> {
+	let sql = "
+		CREATE ONLY person:tobie, person:jaime;
+		CREATE person:tobie, person:jaime;
+		SELECT * FROM ONLY person;
+		UPDATE ONLY person SET name = 'Test';
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp,
+		Err(Error::SingleOnlyOutput {
+			count: 2
+		})
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp,
+		Err(Error::SingleOnlyOutput {
+			count: 2
+		})
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp,
+		Err(Error::SingleOnlyOutput {
+			count: 2
+		})
+	));
+	// Failed statements make no changes
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:jaime }, { id: person:tobie }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
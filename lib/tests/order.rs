@@ -0,0 +1,101 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn order_by_id_when_enabled() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3;
+		SELECT * FROM person:3, person:1, person:2;
+		SELECT * FROM person:3, person:1, person:2 ORDER BY id DESC;
+	";
+	let dbs = Datastore::new("memory").await?.with_default_ordering(true);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }, { id: person:3 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:3 }, { id: person:2 }, { id: person:1 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn order_by_id_is_stable_with_limits() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3, person:4;
+		SELECT * FROM person:4, person:2, person:3, person:1 LIMIT 2;
+		SELECT * FROM person:4, person:2, person:3, person:1 LIMIT 2 START 2;
+	";
+	let dbs = Datastore::new("memory").await?.with_default_ordering(true);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:3 }, { id: person:4 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn order_by_id_can_be_disabled() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3;
+		OPTION ORDERED = false;
+		SELECT * FROM person:3, person:1, person:2;
+	";
+	let dbs = Datastore::new("memory").await?.with_default_ordering(true);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:3 }, { id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn order_by_id_is_disabled_by_default() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3;
+		SELECT * FROM person:3, person:1, person:2;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:3 }, { id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,203 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn output_create_statements() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET name = 'One' RETURN NONE;
+		CREATE person:two SET name = 'Two' RETURN BEFORE;
+		CREATE person:three SET name = 'Three' RETURN AFTER;
+		CREATE person:four SET name = 'Four';
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[NONE]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three, name: 'Three' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:four, name: 'Four' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:four, name: 'Four' },
+			{ id: person:one, name: 'One' },
+			{ id: person:three, name: 'Three' },
+			{ id: person:two, name: 'Two' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn output_update_statements() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET age = 33;
+		UPDATE person:tobie SET age = 34 RETURN NONE;
+		UPDATE person:tobie SET age = 35 RETURN BEFORE;
+		UPDATE person:tobie SET age = 36 RETURN AFTER;
+		UPDATE person:tobie SET age = 37;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, age: 34 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, age: 36 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, age: 37 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn output_update_statement_diff() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie CONTENT {
+			active: true,
+			info: { age: 33, tags: { admin: true } },
+			scores: [1, 2],
+		};
+		UPDATE person:tobie MERGE {
+			info: { age: 34, tags: { admin: false, staff: true } },
+			scores: [1, 2, 3],
+		} RETURN DIFF;
+		UPDATE person:tobie SET active = true RETURN DIFF;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[[
+			{ op: 'replace', path: '/info/age', value: 34 },
+			{ op: 'replace', path: '/info/tags/admin', value: false },
+			{ op: 'add', path: '/info/tags/staff', value: true },
+			{ op: 'add', path: '/scores/2', value: 3 }
+		]]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[[]]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn output_delete_statements() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one, person:two, person:three SET age = 33;
+		DELETE person:one;
+		DELETE person:two RETURN BEFORE;
+		DELETE person:three RETURN AFTER;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two, age: 33 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[NONE]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn output_return_statements() -> Result<(), Error> {
+	let sql = "
+		LET $radius = 3;
+		RETURN 2 * 3 + 1;
+		RETURN math::sqrt(16) * $radius;
+		RETURN string::uppercase('surreal') + 'DB';
+		RETURN { radius: $radius, sizes: [$radius, $radius * 2] };
+		RETURN $auth;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("7");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("12");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("'SURREALDB'");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ radius: 3, sizes: [3, 6] }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::None;
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,92 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn define_global_param() -> Result<(), Error> {
+	let sql = "
+		DEFINE PARAM $max = 100;
+		RETURN $max;
+		RETURN $max.missing;
+		RETURN $other;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("100");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let sql = "
+		REMOVE PARAM $max;
+		RETURN $max;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn define_global_param_shadowed() -> Result<(), Error> {
+	let sql = "DEFINE PARAM $max = 100;";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// A client variable shadows the param
+	let sql = "
+		RETURN $max;
+		LET $max = 1;
+		RETURN $max;
+	";
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("max"), Value::from(5));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("5");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1");
+	assert_eq!(tmp, val);
+	// The param itself is unchanged
+	let sql = "RETURN $max;";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("100");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
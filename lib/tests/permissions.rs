@@ -0,0 +1,271 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Role;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn setup(dbs: &Datastore) -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE post SCHEMALESS PERMISSIONS
+			FOR select WHERE author = $auth
+			FOR create, update WHERE author = $auth
+			FOR delete NONE;
+		DEFINE FIELD secret ON post PERMISSIONS FOR select NONE;
+		CREATE post:one SET author = user:one, title = 'One', secret = 'one';
+		CREATE post:two SET author = user:two, title = 'Two', secret = 'two';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	Ok(())
+}
+
+fn scope(id: &str) -> Session {
+	let mut ses = Session::for_sc("test", "test", "user");
+	ses.sd = Some(Value::parse(id));
+	ses
+}
+
+#[tokio::test]
+async fn permissions_select_only_permitted_rows() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		SELECT * FROM post;
+		SELECT * FROM post:two;
+	";
+	let ses = scope("user:one");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:one,
+				author: user:one,
+				title: 'One',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn permissions_deny_disallowed_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		CREATE post:three SET author = user:two, title = 'Three';
+		CREATE post:four SET author = $auth, title = 'Four';
+		UPDATE post:two SET title = 'Changed';
+		DELETE post:one;
+	";
+	let ses = scope("user:one");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:four,
+				author: user:one,
+				title: 'Four',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let sql = "
+		SELECT * FROM post;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:four,
+				author: user:one,
+				title: 'Four',
+			},
+			{
+				id: post:one,
+				author: user:one,
+				secret: 'one',
+				title: 'One',
+			},
+			{
+				id: post:two,
+				author: user:two,
+				secret: 'two',
+				title: 'Two',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn permissions_select_omits_restricted_fields() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let sql = "
+		DEFINE TABLE user SCHEMALESS PERMISSIONS FOR select FULL;
+		DEFINE FIELD email ON user PERMISSIONS FOR select WHERE id = $auth;
+		DEFINE FIELD pass ON user PERMISSIONS FOR select NONE;
+		CREATE user:one SET name = 'One', email = 'one@example.com', pass = 'one';
+		CREATE user:two SET name = 'Two', email = 'two@example.com', pass = 'two';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	//
+	let sql = "
+		SELECT * FROM user;
+		SELECT pass FROM user:one;
+	";
+	let ses = scope("user:one");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: user:one,
+				email: 'one@example.com',
+				name: 'One',
+			},
+			{
+				id: user:two,
+				name: 'Two',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{}]");
+	assert_eq!(tmp, val);
+	//
+	let sql = "
+		SELECT * FROM user;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: user:one,
+				email: 'one@example.com',
+				name: 'One',
+				pass: 'one',
+			},
+			{
+				id: user:two,
+				email: 'two@example.com',
+				name: 'Two',
+				pass: 'two',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn permissions_viewer_role_is_denied_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	dbs.execute("CREATE person:one;", &ses, None, false).await?;
+	//
+	let sql = "
+		SELECT * FROM person;
+		CREATE person:two;
+		UPDATE person:one SET name = 'Tobie';
+		DEFINE TABLE other;
+	";
+	let ses = Session {
+		rl: vec![Role::Viewer],
+		..Session::for_db("test", "test")
+	};
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_PERMISSIONS"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_PERMISSIONS"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_PERMISSIONS"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn permissions_editor_role_is_allowed_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let sql = "
+		CREATE person:one;
+		UPDATE person:one SET name = 'Tobie';
+		DEFINE TABLE other;
+	";
+	let ses = Session {
+		rl: vec![Role::Editor],
+		..Session::for_db("test", "test")
+	};
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_PERMISSIONS"));
+	//
+	Ok(())
+}
@@ -0,0 +1,91 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn setup(dbs: &Datastore) -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE article SCHEMALESS PERMISSIONS FULL;
+		DEFINE TABLE account SCHEMALESS PERMISSIONS NONE;
+		CREATE article:one SET title = 'One';
+		CREATE account:one SET email = 'info@surrealdb.com';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	Ok(())
+}
+
+#[tokio::test]
+async fn public_select_permitted_table() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		SELECT * FROM article;
+		SELECT * FROM account;
+	";
+	let ses = Session::for_public("test", "test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: article:one,
+				title: 'One'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn public_deny_all_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		CREATE article:two SET title = 'Two';
+		UPDATE article:one SET title = 'Changed';
+		DELETE article:one;
+		SELECT * FROM (CREATE article:three);
+	";
+	let ses = Session::for_public("test", "test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for _ in 0..4 {
+		let tmp = res.remove(0).result;
+		assert!(matches!(tmp, Err(Error::TxReadonly)));
+	}
+	//
+	let sql = "
+		SELECT * FROM article;
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: article:one,
+				title: 'One'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
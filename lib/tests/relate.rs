@@ -0,0 +1,194 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn relate_with_content() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1;
+		CREATE post:2;
+		RELATE user:1->likes->post:2 CONTENT { since: time::now(), rating: 5 };
+		SELECT in, out, rating, since < time::now() AS past FROM likes;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				in: user:1,
+				out: post:2,
+				rating: 5,
+				past: true
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn relate_and_traverse() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1, user:2;
+		CREATE post:1, post:2;
+		RELATE user:1->likes->post:2 CONTENT { rating: 5 };
+		RELATE user:2->likes->post:1 CONTENT { rating: 1 };
+		RELATE user:2->likes->post:2 CONTENT { rating: 3 };
+		SELECT ->likes->post AS posts FROM user:1;
+		SELECT array::sort(<-likes<-user) AS users FROM post:2;
+		SELECT ->(likes WHERE rating > 2)->post AS posts FROM user:2;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 8);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				posts: [post:2]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				users: [user:1, user:2]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				posts: [post:2]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn relate_delete_cascade() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1;
+		CREATE post:1, post:2;
+		RELATE user:1->likes->post:1;
+		RELATE user:1->likes->post:2;
+		DELETE post:1;
+		SELECT in, out FROM likes;
+		SELECT ->likes->post AS posts FROM user:1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				in: user:1,
+				out: post:2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				posts: [post:2]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn relate_delete_without_cascade() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1;
+		CREATE post:1, post:2;
+		RELATE user:1->likes->post:1;
+		RELATE user:1->likes->post:2;
+		OPTION CASCADE = false;
+		DELETE post:1;
+		SELECT in, out FROM likes ORDER BY out;
+		SELECT <-likes<-user AS users FROM post:2;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				in: user:1,
+				out: post:1
+			},
+			{
+				in: user:1,
+				out: post:2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				users: [user:1]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
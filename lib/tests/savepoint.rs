@@ -0,0 +1,170 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn savepoint_rollback_discards_changes() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:one;
+		SAVEPOINT sub;
+		CREATE person:two;
+		UPDATE person:one SET name = 'Tobie';
+		ROLLBACK TO SAVEPOINT sub;
+		CREATE person:three;
+		COMMIT;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_CANCELLED"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_CANCELLED"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn savepoint_rollback_recovers_from_errors() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:one;
+		SAVEPOINT sub;
+		CREATE person:two;
+		CREATE person:one;
+		ROLLBACK TO sub;
+		CREATE person:three;
+		COMMIT;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_CANCELLED"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "RECORD_EXISTS"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:three }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn savepoint_release_keeps_changes() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		SAVEPOINT outer;
+		CREATE person:one;
+		SAVEPOINT inner;
+		CREATE person:two;
+		RELEASE SAVEPOINT inner;
+		COMMIT;
+		SELECT * FROM person;
+		BEGIN;
+		SAVEPOINT outer;
+		CREATE person:three;
+		SAVEPOINT inner;
+		CREATE person:four;
+		RELEASE SAVEPOINT inner;
+		ROLLBACK TO SAVEPOINT outer;
+		COMMIT;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 13);
+	//
+	let tmp = res.remove(5).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(6).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_CANCELLED"));
+	//
+	let tmp = res.remove(7).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_CANCELLED"));
+	//
+	let tmp = res.remove(9).result?;
+	let val = Value::parse("[{ id: person:one }, { id: person:two }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn savepoint_errors() -> Result<(), Error> {
+	let sql = "
+		SAVEPOINT sub;
+		BEGIN;
+		CREATE person:one;
+		ROLLBACK TO SAVEPOINT sub;
+		COMMIT;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "SAVEPOINT_NOT_ALLOWED"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "QUERY_NOT_EXECUTED"));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(ref e) if e.code() == "SAVEPOINT_NOT_FOUND"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
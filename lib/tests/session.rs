@@ -0,0 +1,106 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+async fn setup(dbs: &Datastore) -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE user SCHEMALESS PERMISSIONS FOR select WHERE id = $auth;
+		CREATE user:one SET name = 'Tobie';
+		CREATE user:two SET name = 'Jaime';
+	";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.into_iter() {
+		v.result?;
+	}
+	Ok(())
+}
+
+fn scope(id: &str) -> Session {
+	let mut ses = Session::for_sc("test", "test", "user");
+	ses.sd = Some(Value::parse(id));
+	ses
+}
+
+#[tokio::test]
+async fn session_params_resolve_authenticated_record() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let sql = "
+		RETURN $auth.id;
+		RETURN $auth.name;
+		RETURN $scope;
+		RETURN $session.sc;
+		SELECT name FROM user WHERE id = $auth.id;
+	";
+	let ses = scope("user:one");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("user:one");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("Tobie");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("user");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("user");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn session_params_cannot_be_overridden() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	setup(&dbs).await?;
+	let ses = scope("user:one");
+	// Check client supplied variables
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("auth"), Value::parse("user:two"));
+	let res = dbs.execute("RETURN $auth;", &ses, Some(vars), false).await;
+	assert!(matches!(
+		res.err(),
+		Some(Error::InvalidParam { name }) if name == "auth"
+	));
+	// Check query defined variables
+	let sql = "
+		LET $auth = user:two;
+		LET $session = NONE;
+		RETURN $auth;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(Error::InvalidParam { name }) if name == "auth"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(Error::InvalidParam { name }) if name == "session"
+	));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("user:one");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,91 @@
+use std::time::Duration;
+use std::time::Instant;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn sleep_statement() -> Result<(), Error> {
+	let sql = "SLEEP 100ms;";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let now = Instant::now();
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert!(now.elapsed() >= Duration::from_millis(100));
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn sleep_statement_timeout() -> Result<(), Error> {
+	let sql = "
+		SLEEP 10s TIMEOUT 100ms;
+		SLEEP 10ms TIMEOUT 10s;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let now = Instant::now();
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert!(now.elapsed() < Duration::from_secs(5));
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryTimedout)));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn sleep_statement_cancelled_transaction() -> Result<(), Error> {
+	let sql = "
+		BEGIN TRANSACTION;
+		CREATE person:tobie;
+		CREATE person:tobie;
+		SLEEP 10s;
+		CANCEL TRANSACTION;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let now = Instant::now();
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert!(now.elapsed() < Duration::from_secs(5));
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryCancelled)));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryCancelled)));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryCancelled)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn sleep_statement_requires_database_auth() -> Result<(), Error> {
+	let sql = "SLEEP 10ms;";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_sc("test", "test", "account");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
+	//
+	let ses = Session::for_db("test", "test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
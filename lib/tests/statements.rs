@@ -0,0 +1,81 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn statements_above_limit_are_rejected() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1;
+		CREATE person:2;
+		CREATE person:3;
+		CREATE person:4;
+	";
+	let dbs = Datastore::new("memory").await?.with_max_statements(3);
+	let ses = Session::for_db("test", "test");
+	let res = dbs.execute(&sql, &ses, None, false).await;
+	assert!(matches!(
+		res,
+		Err(Error::QueryTooLong {
+			count: 4,
+			limit: 3
+		})
+	));
+	// No statements were executed
+	let sql = "SELECT * FROM person";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn statements_at_limit_are_executed() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1;
+		CREATE person:2;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?.with_max_statements(3);
+	let ses = Session::for_db("test", "test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn statements_are_not_limited_for_root() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1;
+		CREATE person:2;
+		CREATE person:3;
+		CREATE person:4;
+	";
+	let dbs = Datastore::new("memory").await?.with_max_statements(3);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for v in res.into_iter() {
+		assert!(v.result.is_ok());
+	}
+	//
+	Ok(())
+}
@@ -0,0 +1,182 @@
+mod parse;
+use parse::Parse;
+use std::sync::atomic::{AtomicBool, Ordering};
+use surrealdb::sql::Value;
+use surrealdb::Chunk;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn stream_responses() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one;
+		CREATE person:one;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let (snd, rcv) = surrealdb::channel::new(1);
+	let run = dbs.stream(&sql, &ses, None, false, snd);
+	let out = async move {
+		let mut res: Vec<Chunk> = vec![];
+		while let Ok(v) = rcv.recv().await {
+			res.push(v);
+		}
+		res
+	};
+	let (tmp, mut res) = tokio::join!(run, out);
+	assert!(tmp.is_ok());
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Response(v) if v.result.is_ok()));
+	//
+	let tmp = res.remove(0);
+	assert!(
+		matches!(tmp, Chunk::Response(v) if matches!(v.result, Err(Error::RecordExists { .. })))
+	);
+	//
+	let tmp = res.remove(0);
+	let val = Value::parse("{ id: person:one }");
+	assert!(matches!(tmp, Chunk::Record(v) if v == val));
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Done(_)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn stream_responses_in_transaction() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:one;
+		CREATE person:one;
+		SELECT * FROM person;
+		COMMIT;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let (snd, rcv) = surrealdb::channel::new(1);
+	let run = dbs.stream(&sql, &ses, None, false, snd);
+	let out = async move {
+		let mut res: Vec<Chunk> = vec![];
+		while let Ok(v) = rcv.recv().await {
+			res.push(v);
+		}
+		res
+	};
+	let (tmp, mut res) = tokio::join!(run, out);
+	assert!(tmp.is_ok());
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Response(v) if matches!(v.result, Err(Error::QueryNotExecuted))));
+	//
+	let tmp = res.remove(0);
+	assert!(
+		matches!(tmp, Chunk::Response(v) if matches!(v.result, Err(Error::RecordExists { .. })))
+	);
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Response(v) if matches!(v.result, Err(Error::QueryNotExecuted))));
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Done(_)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn stream_records_incrementally() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute("CREATE |person:1000|;", &ses, None, false).await?;
+	assert!(res.remove(0).result.is_ok());
+	//
+	let sql = "SELECT * FROM person; SELECT * FROM person LIMIT 10 START 5;";
+	let end = AtomicBool::new(false);
+	let (snd, rcv) = surrealdb::channel::new(1);
+	let run = async {
+		let res = dbs.stream(sql, &ses, None, false, snd).await;
+		end.store(true, Ordering::SeqCst);
+		res
+	};
+	let out = async {
+		let mut res: Vec<Chunk> = vec![];
+		while let Ok(v) = rcv.recv().await {
+			// The first record is received before the query
+			// has finished, as the channel only holds one chunk
+			if res.is_empty() {
+				assert!(!end.load(Ordering::SeqCst));
+			}
+			res.push(v);
+		}
+		res
+	};
+	let (tmp, mut res) = tokio::join!(run, out);
+	assert!(tmp.is_ok());
+	assert_eq!(res.len(), 1012);
+	//
+	let tmp: Vec<_> = res.drain(..1000).collect();
+	assert!(tmp.iter().all(|v| matches!(v, Chunk::Record(_))));
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Done(_)));
+	//
+	let tmp: Vec<_> = res.drain(..10).collect();
+	assert!(tmp.iter().all(|v| matches!(v, Chunk::Record(_))));
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Done(_)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn stream_records_with_error() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET size = 2;
+		CREATE person:2 SET size = 2;
+		CREATE person:3 SET size = 0;
+		SELECT math::fixed(1.2345, size) AS num FROM person;
+		SELECT * FROM person:1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let (snd, rcv) = surrealdb::channel::new(1);
+	let run = dbs.stream(&sql, &ses, None, false, snd);
+	let out = async move {
+		let mut res: Vec<Chunk> = vec![];
+		while let Ok(v) = rcv.recv().await {
+			res.push(v);
+		}
+		res
+	};
+	let (tmp, mut res) = tokio::join!(run, out);
+	assert!(tmp.is_ok());
+	//
+	let tmp: Vec<_> = res.drain(..3).collect();
+	assert!(tmp.iter().all(|v| matches!(v, Chunk::Response(v) if v.result.is_ok())));
+	// Any records sent before the error are followed by the error
+	while matches!(res.first(), Some(Chunk::Record(_))) {
+		res.remove(0);
+	}
+	let tmp = res.remove(0);
+	assert!(
+		matches!(tmp, Chunk::Response(v) if matches!(v.result, Err(Error::InvalidArguments { .. })))
+	);
+	// The following statements still run
+	let tmp = res.remove(0);
+	let val = Value::parse("{ id: person:1, size: 2 }");
+	assert!(matches!(tmp, Chunk::Record(v) if v == val));
+	//
+	let tmp = res.remove(0);
+	assert!(matches!(tmp, Chunk::Done(_)));
+	//
+	assert!(res.is_empty());
+	//
+	Ok(())
+}
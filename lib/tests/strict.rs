@@ -21,19 +21,19 @@ async fn strict_mode_no_namespace() -> Result<(), Error> {
 	assert_eq!(res.len(), 5);
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::NsNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::NsNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::NsNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::NsNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::NsNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::NsNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::NsNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::NsNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::NsNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::NsNotFound { .. })));
 	//
 	Ok(())
 }
@@ -57,16 +57,84 @@ async fn strict_mode_no_database() -> Result<(), Error> {
 	assert!(tmp.is_ok());
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::DbNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::DbNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::DbNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::DbNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::DbNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::DbNotFound { .. })));
 	//
 	let tmp = res.remove(0).result;
-	assert!(matches!(tmp.err(), Some(Error::DbNotFound)));
+	assert!(matches!(tmp.err(), Some(Error::DbNotFound { .. })));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn strict_mode_missing_namespace_message() -> Result<(), Error> {
+	let sql = "
+		SELECT * FROM test;
+		USE NS test DB test;
+		SELECT * FROM test;
+	";
+	let dbs = Datastore::new("memory").await?;
+	// An authenticated user is told which namespace is missing
+	let ses = Session::for_kv().with_ns("other").with_db("other");
+	let res = &mut dbs.execute(&sql, &ses, None, true).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::NsNotFound { ref value }) if value == "other"));
+	assert_eq!(tmp.unwrap_err().to_string(), "The namespace 'other' does not exist");
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::NsNotFound { ref value }) if value == "test"));
+	// An anonymous user is not told whether the namespace exists
+	let ses = Session::default().with_ns("other").with_db("other");
+	let res = &mut dbs.execute(&sql, &ses, None, true).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn strict_mode_missing_database_message() -> Result<(), Error> {
+	let sql = "
+		DEFINE NAMESPACE test;
+		SELECT * FROM test;
+	";
+	let dbs = Datastore::new("memory").await?;
+	// An authenticated user is told which database is missing
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, true).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DbNotFound { ref value }) if value == "test"));
+	assert_eq!(tmp.unwrap_err().to_string(), "The database 'test' does not exist");
+	// An anonymous user is not told whether the database exists
+	let ses = Session::default().with_ns("test").with_db("test");
+	let res = &mut dbs.execute("SELECT * FROM test", &ses, None, true).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryPermissions)));
 	//
 	Ok(())
 }
@@ -192,6 +260,9 @@ async fn loose_mode_all_ok() -> Result<(), Error> {
 		"{
 			dl: {},
 			dt: {},
+			fc: {},
+			mg: {},
+			pa: {},
 			sc: {},
 			tb: { test: 'DEFINE TABLE test SCHEMALESS PERMISSIONS NONE' },
 		}",
@@ -0,0 +1,110 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn subquery_in_membership() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1 SET active = true;
+		CREATE user:2 SET active = false;
+		CREATE user:3 SET active = true;
+		CREATE order:1 SET user = user:1;
+		CREATE order:2 SET user = user:2;
+		CREATE order:3 SET user = user:3;
+		SELECT id FROM order WHERE user IN (SELECT id FROM user WHERE active = true);
+		SELECT id FROM order WHERE user NOT IN (SELECT id FROM user WHERE active = true);
+		SELECT id FROM order WHERE user IN (SELECT id FROM user WHERE id = $parent.user AND active = true);
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 9);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: order:1 },
+			{ id: order:3 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: order:2 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: order:1 },
+			{ id: order:3 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn subquery_in_empty_result() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1 SET active = false;
+		CREATE order:1 SET user = user:1;
+		CREATE order:2 SET user = user:2;
+		SELECT id FROM order WHERE user IN (SELECT id FROM user WHERE active = true);
+		SELECT id FROM order WHERE user NOT IN (SELECT id FROM user WHERE active = true);
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: order:1 },
+			{ id: order:2 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
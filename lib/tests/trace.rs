@@ -0,0 +1,60 @@
+use futures::future::BoxFuture;
+use opentelemetry::global;
+use opentelemetry::sdk::export::trace::{ExportResult, SpanData, SpanExporter};
+use opentelemetry::sdk::trace::TracerProvider;
+use opentelemetry::trace::SpanId;
+use opentelemetry::Key;
+use opentelemetry::Value;
+use std::sync::{Arc, Mutex};
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[derive(Clone, Debug, Default)]
+struct Exporter(Arc<Mutex<Vec<SpanData>>>);
+
+impl SpanExporter for Exporter {
+	fn export(&mut self, batch: Vec<SpanData>) -> BoxFuture<'static, ExportResult> {
+		self.0.lock().unwrap().extend(batch);
+		Box::pin(futures::future::ready(Ok(())))
+	}
+}
+
+#[tokio::test]
+async fn trace_query_spans() -> Result<(), Error> {
+	let exp = Exporter::default();
+	let pro = TracerProvider::builder().with_simple_exporter(exp.clone()).build();
+	global::set_tracer_provider(pro);
+	let sql = "
+		CREATE person:one;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	global::shutdown_tracer_provider();
+	//
+	let spans = exp.0.lock().unwrap();
+	assert_eq!(spans.iter().filter(|v| v.name == "parse").count(), 1);
+	assert_eq!(spans.iter().filter(|v| v.name == "query").count(), 1);
+	assert_eq!(spans.iter().filter(|v| v.name == "statement").count(), 2);
+	//
+	let qry = spans.iter().find(|v| v.name == "query").unwrap();
+	assert_eq!(qry.parent_span_id, SpanId::INVALID);
+	//
+	let stm: Vec<_> = spans.iter().filter(|v| v.name == "statement").collect();
+	for v in stm.iter() {
+		assert_eq!(v.parent_span_id, qry.span_context.span_id());
+		assert_eq!(v.span_context.trace_id(), qry.span_context.trace_id());
+		assert_eq!(v.attributes.get(&Key::new("db.namespace")), Some(&Value::from("test")));
+		assert_eq!(v.attributes.get(&Key::new("db.database")), Some(&Value::from("test")));
+	}
+	//
+	let kinds: Vec<_> =
+		stm.iter().filter_map(|v| v.attributes.get(&Key::new("db.statement.type"))).collect();
+	assert!(kinds.contains(&&Value::from("create")));
+	assert!(kinds.contains(&&Value::from("select")));
+	//
+	Ok(())
+}
@@ -0,0 +1,88 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn transaction_readonly_returns_results() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie';
+		BEGIN TRANSACTION READONLY;
+		SELECT * FROM person;
+		SELECT name FROM person:tobie;
+		COMMIT TRANSACTION;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:tobie, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_readonly_prevents_writes() -> Result<(), Error> {
+	let sql = "
+		BEGIN TRANSACTION READONLY;
+		CREATE person:tobie SET name = 'Tobie';
+		COMMIT TRANSACTION;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TxReadonly)));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_readonly_ignores_concurrent_writes() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute("CREATE person:one SET value = 1;", &ses, None, false).await?;
+	res.remove(0).result?;
+	// Open a read snapshot
+	let mut snap = dbs.transaction(false, false).await?;
+	let before = snap.scan(vec![0x00]..vec![0xff], 1000).await?;
+	// Write concurrently while the snapshot is open
+	let sql = "
+		UPDATE person:one SET value = 2;
+		CREATE person:two SET value = 1;
+	";
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	for v in res.drain(..) {
+		v.result?;
+	}
+	// The snapshot does not observe the writes
+	let after = snap.scan(vec![0x00]..vec![0xff], 1000).await?;
+	assert_eq!(before, after);
+	snap.cancel().await?;
+	// A new snapshot does observe the writes
+	let mut snap = dbs.transaction(false, false).await?;
+	let now = snap.scan(vec![0x00]..vec![0xff], 1000).await?;
+	assert_ne!(before, now);
+	snap.cancel().await?;
+	//
+	Ok(())
+}
@@ -0,0 +1,84 @@
+mod parse;
+use parse::Parse;
+use std::collections::BTreeMap;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn results_are_truncated() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3, person:4, person:5;
+		SELECT id FROM person;
+		SELECT id FROM person LIMIT 2;
+		SELECT id FROM person ORDER BY id DESC;
+		RETURN [1, 2, 3];
+	";
+	let dbs = Datastore::new("memory").await?.with_max_results(2);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	// All five records are created
+	let tmp = res.remove(0);
+	assert!(tmp.truncated);
+	assert!(tmp.result.is_ok());
+	// The results are capped, with a cursor
+	let tmp = res.remove(0);
+	let cur = tmp.cursor.clone().unwrap();
+	assert!(tmp.truncated);
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp.result?, val);
+	// The results fit within the cap
+	let tmp = res.remove(0);
+	assert!(!tmp.truncated);
+	assert!(tmp.cursor.is_some());
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }]");
+	assert_eq!(tmp.result?, val);
+	// Ordered results can not be resumed
+	let tmp = res.remove(0);
+	assert!(tmp.truncated);
+	assert!(tmp.cursor.is_none());
+	let val = Value::parse("[{ id: person:5 }, { id: person:4 }]");
+	assert_eq!(tmp.result?, val);
+	// Arrays of values are capped too
+	let tmp = res.remove(0);
+	assert!(tmp.truncated);
+	let val = Value::parse("[1, 2]");
+	assert_eq!(tmp.result?, val);
+	// The cursor resumes after the truncated results
+	let sql = "SELECT id FROM person AFTER $cursor";
+	let mut vars = BTreeMap::new();
+	vars.insert(String::from("cursor"), Value::from(cur));
+	let res = &mut dbs.execute(&sql, &ses, Some(vars), false).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.truncated);
+	let val = Value::parse("[{ id: person:3 }, { id: person:4 }]");
+	assert_eq!(tmp.result?, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn results_are_not_truncated_by_default() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1, person:2, person:3;
+		SELECT id FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0);
+	assert!(!tmp.truncated);
+	//
+	let tmp = res.remove(0);
+	assert!(!tmp.truncated);
+	let val = Value::parse("[{ id: person:1 }, { id: person:2 }, { id: person:3 }]");
+	assert_eq!(tmp.result?, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,275 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn update_with_patch() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET name = 'Tobie', tags = ['one'], info = { age: 33 };
+		UPDATE person:test PATCH [
+			{ op: 'add', path: '/tags/-', value: 'two' },
+			{ op: 'replace', path: '/name', value: 'Jaime' },
+			{ op: 'move', from: '/info/age', path: '/age' },
+			{ op: 'remove', path: '/info' },
+		];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				age: 33,
+				id: person:test,
+				name: 'Jaime',
+				tags: ['one', 'two'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_with_patch_failed_test() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET name = 'Tobie', tags = ['one'];
+		UPDATE person:test PATCH [
+			{ op: 'test', path: '/name', value: 'Jaime' },
+			{ op: 'add', path: '/tags/-', value: 'two' },
+		];
+		SELECT * FROM person:test;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp.err(), Some(Error::PatchTest { .. })));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				tags: ['one'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_versioned_record() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person VERSIONED;
+		CREATE person:test SET name = 'Tobie';
+		UPDATE person:test SET name = 'Jaime' IF __version = 1;
+		UPDATE person:test SET name = 'Simon' IF __version = 2;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				__version: 1,
+				id: person:test,
+				name: 'Tobie',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				__version: 2,
+				id: person:test,
+				name: 'Jaime',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				__version: 3,
+				id: person:test,
+				name: 'Simon',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_versioned_record_conflict() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person VERSIONED;
+		CREATE person:test SET name = 'Tobie';
+		UPDATE person:test SET name = 'Jaime' IF __version = 1;
+		UPDATE person:test SET name = 'Simon' IF __version = 1;
+		SELECT * FROM person:test;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp.err(), Some(Error::GuardFailed { .. })));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				__version: 2,
+				id: person:test,
+				name: 'Jaime',
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_with_content_replaces_record() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET name = 'Tobie', info = { age: 33, city: 'London' };
+		UPDATE person:test CONTENT { info: { age: 34 } };
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				info: { age: 34 },
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_with_merge_keeps_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET name = 'Tobie', tags = ['one'], info = { age: 33, city: 'London' };
+		UPDATE person:test MERGE { tags: ['two'], info: { age: 34 } };
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				info: { age: 34, city: 'London' },
+				name: 'Tobie',
+				tags: ['two'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_with_compound_operators() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET score = 10, tags = ['one', 'two'];
+		UPDATE person:test SET score += 5, tags += 'three', total += 2;
+		UPDATE person:test SET score -= 20, tags -= 'one', tags += ['four', 'five'];
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				score: 15,
+				tags: ['one', 'two', 'three'],
+				total: 2,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				score: -5,
+				tags: ['two', 'three', 'four', 'five'],
+				total: 2,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,79 @@
+mod parse;
+use parse::Parse;
+use surrealdb::sql::Value;
+use surrealdb::Datastore;
+use surrealdb::Error;
+use surrealdb::Session;
+
+#[tokio::test]
+async fn select_value_field() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1 SET name = 'a', value = 1;
+		CREATE user:2 SET name = 'b', value = 2;
+		SELECT VALUE name FROM user;
+		SELECT VALUE name AS n FROM user LIMIT 1;
+		SELECT name FROM user WHERE id IN (SELECT VALUE id FROM user WHERE name = 'b');
+		SELECT value FROM user;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['a', 'b']");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['a']");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ name: 'b' }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ value: 1 },
+			{ value: 2 }
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_value_multiple_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE user:1 SET name = 'a', age = 1;
+		SELECT VALUE name, age FROM user;
+		SELECT VALUE * FROM user;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::SelectValueMultiple)));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::SelectValueMultiple)));
+	//
+	Ok(())
+}
@@ -82,3 +82,140 @@ async fn use_statement_set_both() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn use_statement_kept_on_session() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let mut ses = Session::for_kv().with_ns("test").with_db("test");
+	// Switch to a different database
+	let sql = "USE NS my_ns DB my_db; CREATE person:test;";
+	let (res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	assert_eq!(ses.ns, Some(String::from("my_ns")));
+	assert_eq!(ses.db, Some(String::from("my_db")));
+	// Later queries use the selected database
+	let sql = "SELECT * FROM person; RETURN [session::ns(), session::db()];";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:test }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['my_ns', 'my_db']");
+	assert_eq!(tmp, val);
+	// The original database is left untouched
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute("SELECT * FROM person;", &ses, None, false).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn use_statement_not_allowed_is_not_kept() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let mut ses = Session::for_db("test", "test");
+	// Switch to a namespace outside of the authentication
+	let sql = "USE NS other_ns;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::NsNotAllowed { .. })));
+	assert_eq!(ses.ns, Some(String::from("test")));
+	// Switch to a database outside of the authentication
+	let sql = "USE DB other_db;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::DbNotAllowed { .. })));
+	assert_eq!(ses.db, Some(String::from("test")));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn use_statement_case_insensitive_names() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?.with_case_insensitive_names(true);
+	let mut ses = Session::for_kv().with_ns("MyNS").with_db("MyDB");
+	// Names are stored in lowercase
+	let sql = "CREATE person:test; DEFINE NAMESPACE OtherNS; INFO FOR KV;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			ns: {
+				myns: 'DEFINE NAMESPACE myns',
+				otherns: 'DEFINE NAMESPACE otherns',
+			},
+		}",
+	);
+	assert_eq!(tmp, val);
+	// Names are matched in any case
+	let sql = "USE NS MYNS DB mydb; SELECT * FROM person; RETURN [session::ns(), session::db()];";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:test }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['myns', 'mydb']");
+	assert_eq!(tmp, val);
+	// Authentication is matched in any case
+	let mut ses = Session::for_db("MyNS", "MyDB");
+	let sql = "USE NS myns DB MYDB; SELECT * FROM person;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:test }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn use_statement_case_sensitive_names_by_default() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let mut ses = Session::for_kv().with_ns("MyNS").with_db("MyDB");
+	// Create a record in the selected database
+	let sql = "CREATE person:test;";
+	let (res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 1);
+	// A name in a different case is a different database
+	let sql = "USE NS myns DB mydb; SELECT * FROM person;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Authentication is matched in the same case
+	let mut ses = Session::for_db("MyNS", "MyDB");
+	let sql = "USE NS myns;";
+	let (mut res, _) = dbs.execute_with_session(&sql, &mut ses, None, false).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::NsNotAllowed { .. })));
+	//
+	Ok(())
+}
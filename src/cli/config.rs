@@ -1,20 +1,150 @@
+use crate::err::Error;
 use once_cell::sync::OnceCell;
 use std::net::SocketAddr;
+use std::time::Duration;
 
 pub static CF: OnceCell<Config> = OnceCell::new();
 
 #[derive(Clone, Debug)]
 pub struct Config {
 	pub strict: bool,
+	pub tracing: bool,
+	pub depth: Option<usize>,
+	pub iterations: Option<usize>,
+	pub complexity: Option<usize>,
+	pub results: Option<usize>,
+	pub statements: Option<usize>,
+	pub retries: Option<usize>,
+	pub recover: bool,
+	pub fold: bool,
+	pub ordered: bool,
+	pub reap: Duration,
+	pub cache: Option<Duration>,
+	pub slow: Option<Duration>,
+	pub keys: Vec<(u8, Vec<u8>)>,
+	pub fields: Vec<(u8, Vec<u8>)>,
 	pub bind: SocketAddr,
 	pub path: String,
 	pub user: String,
 	pub pass: Option<String>,
+	pub deny_insecure: bool,
 	pub crt: Option<String>,
 	pub key: Option<String>,
+	pub tls_min: String,
+	pub tls_ciphers: Vec<String>,
+	pub tls_alpn: Vec<String>,
+	pub http2: bool,
+	pub h2c: bool,
+	pub client_ca: Option<String>,
+	pub client_auth: bool,
+	pub compression: usize,
+	pub safe_integers: bool,
+	pub max_body: u64,
+	pub max_query: usize,
+	pub origins: Vec<String>,
+	pub methods: Vec<String>,
+	pub headers: Vec<String>,
+	pub credentials: bool,
+	pub algorithms: Vec<String>,
+	pub bind_origin: bool,
+	pub cookies: bool,
+	pub cookie_same_site: String,
+	pub cookie_secure: bool,
+	pub public_ns: Option<String>,
+	pub public_db: Option<String>,
+	pub ws_ping: Duration,
+	pub ws_pong: Duration,
+	pub ws_idle: Duration,
+	pub ws_resume: Option<Duration>,
+	pub ws_message: usize,
+	pub ws_frame: Option<usize>,
+	pub ws_calls: usize,
+	pub ws_reject: bool,
+	pub ws_origins: Vec<String>,
+	pub ws_log: bool,
+	pub ws_debug: bool,
+	pub ws_conns: Option<usize>,
+	pub ws_conns_ip: Option<usize>,
+	pub request_timeout: Option<Duration>,
+	pub shutdown_grace: Duration,
+	pub rate: Option<usize>,
+	pub burst: Option<usize>,
+	pub rates: Vec<(String, usize)>,
 }
 
-pub fn init(matches: &clap::ArgMatches) {
+#[cfg(test)]
+impl Default for Config {
+	// A local configuration for unit tests
+	fn default() -> Self {
+		Self {
+			strict: Default::default(),
+			tracing: Default::default(),
+			depth: Default::default(),
+			iterations: Default::default(),
+			complexity: Default::default(),
+			results: Default::default(),
+			statements: Default::default(),
+			retries: Default::default(),
+			recover: Default::default(),
+			fold: Default::default(),
+			ordered: Default::default(),
+			reap: Default::default(),
+			cache: Default::default(),
+			slow: Default::default(),
+			keys: Default::default(),
+			fields: Default::default(),
+			bind: "127.0.0.1:8000".parse().unwrap(),
+			path: Default::default(),
+			user: String::from("root"),
+			pass: Default::default(),
+			deny_insecure: Default::default(),
+			crt: Default::default(),
+			key: Default::default(),
+			tls_min: Default::default(),
+			tls_ciphers: Default::default(),
+			tls_alpn: Default::default(),
+			http2: true,
+			h2c: true,
+			client_ca: Default::default(),
+			client_auth: Default::default(),
+			compression: Default::default(),
+			safe_integers: Default::default(),
+			max_body: 1048576,
+			max_query: 1048576,
+			origins: Default::default(),
+			methods: Default::default(),
+			headers: Default::default(),
+			credentials: Default::default(),
+			algorithms: Default::default(),
+			bind_origin: Default::default(),
+			cookies: Default::default(),
+			cookie_same_site: Default::default(),
+			cookie_secure: Default::default(),
+			public_ns: Default::default(),
+			public_db: Default::default(),
+			ws_ping: Default::default(),
+			ws_pong: Default::default(),
+			ws_idle: Default::default(),
+			ws_resume: Default::default(),
+			ws_message: Default::default(),
+			ws_frame: Default::default(),
+			ws_calls: Default::default(),
+			ws_reject: Default::default(),
+			ws_origins: Default::default(),
+			ws_log: Default::default(),
+			ws_debug: Default::default(),
+			ws_conns: Default::default(),
+			ws_conns_ip: Default::default(),
+			request_timeout: Default::default(),
+			shutdown_grace: Default::default(),
+			rate: Default::default(),
+			burst: Default::default(),
+			rates: Default::default(),
+		}
+	}
+}
+
+pub fn init(matches: &clap::ArgMatches) -> Result<(), Error> {
 	// Parse the server binding address
 	let bind = matches
 		.value_of("bind")
@@ -27,19 +157,209 @@ pub fn init(matches: &clap::ArgMatches) {
 	let user = matches.value_of("user").unwrap().to_owned();
 	// Parse the root password for authentication
 	let pass = matches.value_of("pass").map(|v| v.to_owned());
+	// Parse whether insecure options are denied
+	let deny_insecure = matches.is_present("deny-insecure");
 	// Parse any TLS server security options
 	let crt = matches.value_of("web-crt").map(|v| v.to_owned());
 	let key = matches.value_of("web-key").map(|v| v.to_owned());
+	let tls_min = matches.value_of("web-tls-min-version").unwrap().to_owned();
+	let tls_ciphers =
+		matches.values_of("web-tls-cipher").map_or(vec![], |v| v.map(|v| v.to_owned()).collect());
+	let tls_alpn = matches.values_of("web-tls-alpn").unwrap().map(|v| v.to_owned()).collect();
+	let client_ca = matches.value_of("web-client-ca").map(|v| v.to_owned());
+	let client_auth = matches.is_present("web-client-auth-required");
+	// Parse whether HTTP/2 is served to clients
+	let http2 = matches.value_of("web-http2").unwrap() == "true";
+	let h2c = matches.value_of("web-h2c").unwrap() == "true";
+	// Parse the response compression threshold
+	let compression = matches.value_of("compression-threshold").unwrap().parse::<usize>().unwrap();
+	// Check if large integers are output as strings
+	let safe_integers = matches.is_present("json-safe-integers");
+	// Parse the request size limits
+	let max_body = matches.value_of("max-body-size").unwrap().parse::<u64>().unwrap();
+	let max_query = matches.value_of("max-query-length").unwrap().parse::<usize>().unwrap();
+	// Parse the cross-origin request options
+	let origins: Vec<String> =
+		matches.values_of("allow-origin").unwrap().map(|v| v.to_owned()).collect();
+	let methods = matches.values_of("allow-method").unwrap().map(|v| v.to_uppercase()).collect();
+	let headers =
+		matches.values_of("allow-header").map_or(vec![], |v| v.map(|v| v.to_owned()).collect());
+	let credentials = matches.is_present("allow-credentials");
+	// Check that credentials are not allowed from any origin
+	if credentials && origins.iter().any(|v| v == "*") {
+		return Err(Error::InvalidConfig(
+			"Credentials can not be allowed in cross-origin requests from any origin".to_string(),
+		));
+	}
+	// Parse the allowed token signing algorithms
+	let algorithms =
+		matches.values_of("auth-algorithm").unwrap().map(|v| v.to_uppercase()).collect();
+	// Parse whether scope tokens are bound to the request origin
+	let bind_origin = matches.is_present("auth-bind-origin");
+	// Parse the session cookie options
+	let cookies = matches.is_present("auth-cookies");
+	let cookie_same_site = matches.value_of("auth-cookie-same-site").unwrap().to_owned();
+	let cookie_secure = matches.is_present("auth-cookie-secure");
+	// Parse the public access options
+	let public_ns = matches.value_of("public-ns").map(|v| v.to_owned());
+	let public_db = matches.value_of("public-db").map(|v| v.to_owned());
+	// Parse the WebSocket keepalive options
+	let ws_ping = matches.value_of("ws-ping-interval").unwrap().parse::<u64>().unwrap();
+	let ws_ping = Duration::from_secs(ws_ping);
+	let ws_pong = matches.value_of("ws-pong-wait").unwrap().parse::<u64>().unwrap();
+	let ws_pong = Duration::from_secs(ws_pong);
+	let ws_idle = matches.value_of("ws-idle-timeout").unwrap().parse::<u64>().unwrap();
+	let ws_idle = Duration::from_secs(ws_idle);
+	// Parse the WebSocket message size limits
+	let ws_message = matches
+		.value_of("ws-max-message-size")
+		.map_or(max_body as usize, |v| v.parse::<usize>().unwrap());
+	let ws_frame = matches.value_of("ws-max-frame-size").map(|v| v.parse::<usize>().unwrap());
+	// Parse the WebSocket session resumption options
+	let ws_resume =
+		matches.value_of("ws-resume-grace").map(|v| Duration::from_secs(v.parse::<u64>().unwrap()));
+	// Parse the request timeout
+	let request_timeout =
+		matches.value_of("request-timeout").map(|v| Duration::from_secs(v.parse::<u64>().unwrap()));
+	// Parse the shutdown grace period
+	let shutdown_grace = matches.value_of("shutdown-grace").unwrap().parse::<u64>().unwrap();
+	let shutdown_grace = Duration::from_secs(shutdown_grace);
+	// Parse the WebSocket concurrency options
+	let ws_calls = matches.value_of("ws-max-concurrent").unwrap().parse::<usize>().unwrap();
+	let ws_reject = matches.is_present("ws-reject-concurrent");
+	// Parse the WebSocket upgrade options
+	let ws_origins =
+		matches.values_of("ws-allow-origin").map_or(vec![], |v| v.map(|v| v.to_owned()).collect());
+	let ws_log = matches.is_present("ws-log-upgrade");
+	// Parse the WebSocket debug log options
+	let ws_debug = matches.is_present("ws-debug-log");
+	// Parse the WebSocket connection limits
+	let ws_conns = matches.value_of("ws-max-connections").map(|v| v.parse::<usize>().unwrap());
+	let ws_conns_ip =
+		matches.value_of("ws-max-connections-per-ip").map(|v| v.parse::<usize>().unwrap());
+	// Parse the request rate limit options
+	let rate = matches.value_of("rate-limit").map(|v| v.parse::<usize>().unwrap());
+	let burst = matches.value_of("rate-limit-burst").map(|v| v.parse::<usize>().unwrap());
+	let rates = matches.values_of("rate-limit-ns").map_or(vec![], |v| {
+		v.map(|v| {
+			let (n, r) = v.rsplit_once(':').unwrap();
+			(n.to_owned(), r.parse::<usize>().unwrap())
+		})
+		.collect()
+	});
 	// Check if database strict mode is enabled
 	let strict = matches.is_present("strict");
+	// Check if trace exporting is enabled
+	let tracing = matches.is_present("tracing");
+	// Parse the maximum graph traversal depth
+	let depth = matches.value_of("depth").map(|v| v.parse::<usize>().unwrap());
+	// Parse the maximum loop iterations
+	let iterations = matches.value_of("max-loop-iterations").map(|v| v.parse::<usize>().unwrap());
+	// Parse the maximum query complexity
+	let complexity = matches.value_of("max-query-complexity").map(|v| v.parse::<usize>().unwrap());
+	// Parse the maximum query results
+	let results = matches.value_of("max-query-results").map(|v| v.parse::<usize>().unwrap());
+	// Parse the maximum transaction retries
+	let retries = matches.value_of("max-tx-retries").map(|v| v.parse::<usize>().unwrap());
+	// Parse whether to recover from panics
+	let recover = matches.is_present("panic-recovery");
+	// Parse whether names are case-insensitive
+	let fold = matches.is_present("case-insensitive-names");
+	// Parse whether results are ordered by default
+	let ordered = matches.is_present("default-ordering");
+	// Parse the maximum query statements
+	let statements = matches.value_of("max-query-statements").map(|v| v.parse::<usize>().unwrap());
+	// Parse the expired record reaping interval
+	let reap = matches.value_of("reap-interval").unwrap().parse::<u64>().unwrap();
+	let reap = Duration::from_secs(reap);
+	// Parse the query result cache duration
+	let cache =
+		matches.value_of("query-cache-ttl").map(|v| Duration::from_secs(v.parse::<u64>().unwrap()));
+	// Parse the slow query log threshold
+	let slow = matches
+		.value_of("slow-query-threshold")
+		.map(|v| Duration::from_millis(v.parse::<u64>().unwrap()));
+	// Parse the storage encryption keys
+	let keys = matches.values_of("encryption-key").map_or(vec![], |v| {
+		v.map(|v| {
+			let (n, k) = v.split_once(':').unwrap();
+			(n.parse::<u8>().unwrap(), base64::decode(k).unwrap())
+		})
+		.collect()
+	});
+	// Parse the field encryption keys
+	let fields = matches.values_of("field-encryption-key").map_or(vec![], |v| {
+		v.map(|v| {
+			let (n, k) = v.split_once(':').unwrap();
+			(n.parse::<u8>().unwrap(), base64::decode(k).unwrap())
+		})
+		.collect()
+	});
 	// Store the new config object
 	let _ = CF.set(Config {
 		strict,
+		tracing,
+		depth,
+		iterations,
+		complexity,
+		results,
+		statements,
+		retries,
+		recover,
+		fold,
+		ordered,
+		reap,
+		cache,
+		slow,
+		keys,
+		fields,
 		bind,
 		path,
 		user,
 		pass,
+		deny_insecure,
 		crt,
 		key,
+		tls_min,
+		tls_ciphers,
+		tls_alpn,
+		http2,
+		h2c,
+		client_ca,
+		client_auth,
+		compression,
+		safe_integers,
+		max_body,
+		max_query,
+		origins,
+		methods,
+		headers,
+		credentials,
+		algorithms,
+		bind_origin,
+		cookies,
+		cookie_same_site,
+		cookie_secure,
+		public_ns,
+		public_db,
+		ws_ping,
+		ws_pong,
+		ws_idle,
+		ws_resume,
+		ws_message,
+		ws_frame,
+		ws_calls,
+		ws_reject,
+		ws_origins,
+		ws_log,
+		ws_debug,
+		ws_conns,
+		ws_conns_ip,
+		request_timeout,
+		shutdown_grace,
+		rate,
+		burst,
+		rates,
 	});
+	Ok(())
 }
@@ -3,10 +3,13 @@ mod config;
 mod export;
 mod import;
 mod log;
+mod secure;
 mod sql;
 mod start;
+mod trace;
 mod version;
 
+pub use config::Config;
 pub use config::CF;
 
 use crate::cnf::LOGO;
@@ -108,6 +111,146 @@ fn key_valid(v: &str) -> Result<(), String> {
 	}
 }
 
+fn depth_valid(v: &str) -> Result<(), String> {
+	match v.parse::<usize>() {
+		Ok(v) if v > 0 => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid graph traversal depth greater than zero\
+		",
+		)),
+	}
+}
+
+fn size_valid(v: &str) -> Result<(), String> {
+	match v.parse::<usize>() {
+		Ok(_) => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid size in bytes\
+		",
+		)),
+	}
+}
+
+fn secs_valid(v: &str) -> Result<(), String> {
+	match v.parse::<u64>() {
+		Ok(v) if v > 0 => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid duration in seconds greater than zero\
+		",
+		)),
+	}
+}
+
+fn number_valid(v: &str) -> Result<(), String> {
+	match v.parse::<usize>() {
+		Ok(_) => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid number\
+		",
+		)),
+	}
+}
+
+fn count_valid(v: &str) -> Result<(), String> {
+	match v.parse::<usize>() {
+		Ok(v) if v > 0 => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid number greater than zero\
+		",
+		)),
+	}
+}
+
+fn cipher_valid(v: &str) -> Result<(), String> {
+	match v.split_once(':') {
+		Some((n, k)) if n.parse::<u8>().is_ok() => match base64::decode(k) {
+			Ok(k) if k.len() == 32 => Ok(()),
+			_ => Err(String::from(
+				"\
+				Provide a valid base64 encoded 256-bit encryption key\
+			",
+			)),
+		},
+		_ => Err(String::from(
+			"\
+			Provide a valid encryption key in the form <version>:<key>\
+		",
+		)),
+	}
+}
+
+fn rate_valid(v: &str) -> Result<(), String> {
+	match v.rsplit_once(':') {
+		Some((n, r)) if !n.is_empty() => match r.parse::<usize>() {
+			Ok(r) if r > 0 => Ok(()),
+			_ => Err(String::from(
+				"\
+				Provide a valid rate limit greater than zero\
+			",
+			)),
+		},
+		_ => Err(String::from(
+			"\
+			Provide a valid namespace rate limit in the form <namespace>:<rate>\
+		",
+		)),
+	}
+}
+
+fn origin_valid(v: &str) -> Result<(), String> {
+	// Wildcards are only allowed in place of a subdomain
+	let wild = v.replacen("://*.", "://", 1).contains('*');
+	match v {
+		"*" => Ok(()),
+		v if v.starts_with("http://") && !wild => Ok(()),
+		v if v.starts_with("https://") && !wild => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid origin, such as https://example.com or https://*.example.com, or specify * to allow any origin\
+		",
+		)),
+	}
+}
+
+fn method_valid(v: &str) -> Result<(), String> {
+	match v.to_uppercase().as_str() {
+		"GET" | "PUT" | "POST" | "PATCH" | "DELETE" | "OPTIONS" | "HEAD" => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid HTTP method\
+		",
+		)),
+	}
+}
+
+fn tls_cipher_valid(v: &str) -> Result<(), String> {
+	match rustls::ALL_CIPHER_SUITES.iter().any(|s| format!("{:?}", s.suite()) == v) {
+		true => Ok(()),
+		false => Err(String::from(
+			"\
+			Provide a valid TLS cipher suite, such as TLS13_AES_256_GCM_SHA384\
+		",
+		)),
+	}
+}
+
+fn algorithm_valid(v: &str) -> Result<(), String> {
+	match v.to_uppercase().as_str() {
+		"EDDSA" | "ES256" | "ES384" | "ES512" | "HS256" | "HS384" | "HS512" | "PS256" | "PS384"
+		| "PS512" | "RS256" | "RS384" | "RS512" => Ok(()),
+		_ => Err(String::from(
+			"\
+			Provide a valid token signing algorithm\
+		",
+		)),
+	}
+}
+
 pub fn init() {
 	let setup = Command::new("SurrealDB command-line interface and server")
 		.about(INFO)
@@ -156,6 +299,14 @@ pub fn init() {
 					.default_value("127.0.0.1/32")
 					.help("The allowed networks for master authentication"),
 			)
+			.arg(
+				Arg::new("deny-insecure")
+					.env("DENY_INSECURE")
+					.long("deny-insecure")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to refuse to start with an insecure configuration, rather than only warning"),
+			)
 			.arg(
 				Arg::new("bind")
 					.short('b')
@@ -219,6 +370,398 @@ pub fn init() {
 					.forbid_empty_values(true)
 					.help("Path to the private key file for encrypted client connections"),
 			)
+			.arg(
+				Arg::new("web-tls-min-version")
+					.env("WEB_TLS_MIN_VERSION")
+					.long("web-tls-min-version")
+					.takes_value(true)
+					.default_value("1.2")
+					.help("The minimum TLS protocol version accepted for encrypted client connections")
+					.value_parser(["1.2", "1.3"]),
+			)
+			.arg(
+				Arg::new("web-tls-cipher")
+					.env("WEB_TLS_CIPHER")
+					.long("web-tls-cipher")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.validator(tls_cipher_valid)
+					.help("The TLS cipher suites accepted for encrypted client connections"),
+			)
+			.arg(
+				Arg::new("web-tls-alpn")
+					.env("WEB_TLS_ALPN")
+					.long("web-tls-alpn")
+					.number_of_values(1)
+					.multiple_occurrences(true)
+					.default_values(&["h2", "http/1.1"])
+					.help("The application protocols advertised for encrypted client connections")
+					.value_parser(["h2", "http/1.1"]),
+			)
+			.arg(
+				Arg::new("web-http2")
+					.env("WEB_HTTP2")
+					.long("web-http2")
+					.takes_value(true)
+					.default_value("true")
+					.help("Whether HTTP/2 is negotiated with ALPN for encrypted client connections")
+					.value_parser(["true", "false"]),
+			)
+			.arg(
+				Arg::new("web-h2c")
+					.env("WEB_H2C")
+					.long("web-h2c")
+					.takes_value(true)
+					.default_value("true")
+					.help("Whether HTTP/2 with prior knowledge (h2c) is accepted for unencrypted client connections")
+					.value_parser(["true", "false"]),
+			)
+			.arg(
+				Arg::new("web-client-ca")
+					.env("WEB_CLIENT_CA")
+					.long("web-client-ca")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.help("Path to the certificate authority file for verifying client certificates"),
+			)
+			.arg(
+				Arg::new("web-client-auth-required")
+					.env("WEB_CLIENT_AUTH_REQUIRED")
+					.long("web-client-auth-required")
+					.takes_value(false)
+					.requires("web-client-ca")
+					.help("Whether clients must present a valid certificate when connecting"),
+			)
+			.arg(
+				Arg::new("encryption-key")
+					.env("ENCRYPTION_KEY")
+					.long("encryption-key")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.validator(cipher_valid)
+					.help("A versioned base64 encoded key for encrypting stored data, in the form <version>:<key>"),
+			)
+			.arg(
+				Arg::new("field-encryption-key")
+					.env("FIELD_ENCRYPTION_KEY")
+					.long("field-encryption-key")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.validator(cipher_valid)
+					.help("A versioned base64 encoded key for encrypting ENCRYPTED fields, in the form <version>:<key>"),
+			)
+			.arg(
+				Arg::new("allow-origin")
+					.env("ALLOW_ORIGIN")
+					.long("allow-origin")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.default_value("*")
+					.validator(origin_valid)
+					.help("The origins which are allowed to make cross-origin requests"),
+			)
+			.arg(
+				Arg::new("allow-method")
+					.env("ALLOW_METHOD")
+					.long("allow-method")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.default_values(&["GET", "PUT", "POST", "PATCH", "DELETE", "OPTIONS"])
+					.validator(method_valid)
+					.help("The HTTP methods which are allowed in cross-origin requests"),
+			)
+			.arg(
+				Arg::new("allow-header")
+					.env("ALLOW_HEADER")
+					.long("allow-header")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.help("Additional HTTP headers which are allowed in cross-origin requests"),
+			)
+			.arg(
+				Arg::new("allow-credentials")
+					.env("ALLOW_CREDENTIALS")
+					.long("allow-credentials")
+					.required(false)
+					.takes_value(false)
+					.help("Whether credentials are allowed in cross-origin requests"),
+			)
+			.arg(
+				Arg::new("auth-algorithm")
+					.env("AUTH_ALGORITHM")
+					.long("auth-algorithm")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.default_values(&[
+						"EDDSA", "ES256", "ES384", "ES512", "HS256", "HS384", "HS512", "PS256",
+						"PS384", "PS512", "RS256", "RS384", "RS512",
+					])
+					.validator(algorithm_valid)
+					.help("The token signing algorithms which are accepted for authentication"),
+			)
+			.arg(
+				Arg::new("auth-bind-origin")
+					.env("AUTH_BIND_ORIGIN")
+					.long("auth-bind-origin")
+					.required(false)
+					.takes_value(false)
+					.help("Whether scope tokens are bound to the origin of the signin or signup request"),
+			)
+			.arg(
+				Arg::new("auth-cookies")
+					.env("AUTH_COOKIES")
+					.long("auth-cookies")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to set a signed session cookie after scope signin or signup"),
+			)
+			.arg(
+				Arg::new("auth-cookie-same-site")
+					.env("AUTH_COOKIE_SAME_SITE")
+					.long("auth-cookie-same-site")
+					.takes_value(true)
+					.default_value("Strict")
+					.forbid_empty_values(true)
+					.help("The SameSite policy of the session and CSRF cookies")
+					.value_parser(["Strict", "Lax", "None"]),
+			)
+			.arg(
+				Arg::new("auth-cookie-secure")
+					.env("AUTH_COOKIE_SECURE")
+					.long("auth-cookie-secure")
+					.required(false)
+					.takes_value(false)
+					.help("Whether the session and CSRF cookies are only sent over HTTPS"),
+			)
+			.arg(
+				Arg::new("public-ns")
+					.env("PUBLIC_NS")
+					.long("public-ns")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.requires("public-db")
+					.help("The namespace used for read-only unauthenticated requests"),
+			)
+			.arg(
+				Arg::new("public-db")
+					.env("PUBLIC_DB")
+					.long("public-db")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.requires("public-ns")
+					.help("The database used for read-only unauthenticated requests"),
+			)
+			.arg(
+				Arg::new("max-body-size")
+					.env("MAX_BODY_SIZE")
+					.long("max-body-size")
+					.takes_value(true)
+					.default_value("1048576")
+					.forbid_empty_values(true)
+					.validator(size_valid)
+					.help("The maximum size in bytes of query request bodies and RPC messages"),
+			)
+			.arg(
+				Arg::new("max-query-length")
+					.env("MAX_QUERY_LENGTH")
+					.long("max-query-length")
+					.takes_value(true)
+					.default_value("1048576")
+					.forbid_empty_values(true)
+					.validator(size_valid)
+					.help("The maximum length in bytes of a SQL query before it is parsed"),
+			)
+			.arg(
+				Arg::new("compression-threshold")
+					.env("COMPRESSION_THRESHOLD")
+					.long("compression-threshold")
+					.takes_value(true)
+					.default_value("1024")
+					.forbid_empty_values(true)
+					.validator(size_valid)
+					.help("The minimum response size in bytes before responses are compressed"),
+			)
+			.arg(
+				Arg::new("json-safe-integers")
+					.env("JSON_SAFE_INTEGERS")
+					.long("json-safe-integers")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to output integers which can not be represented exactly in JavaScript as JSON strings"),
+			)
+			.arg(
+				Arg::new("ws-ping-interval")
+					.env("WS_PING_INTERVAL")
+					.long("ws-ping-interval")
+					.takes_value(true)
+					.default_value("30")
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The interval in seconds between WebSocket ping messages"),
+			)
+			.arg(
+				Arg::new("ws-pong-wait")
+					.env("WS_PONG_WAIT")
+					.long("ws-pong-wait")
+					.takes_value(true)
+					.default_value("10")
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The time in seconds to wait for a WebSocket pong before closing the connection"),
+			)
+			.arg(
+				Arg::new("ws-idle-timeout")
+					.env("WS_IDLE_TIMEOUT")
+					.long("ws-idle-timeout")
+					.takes_value(true)
+					.default_value("3600")
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The time in seconds after which an inactive WebSocket connection is closed"),
+			)
+			.arg(
+				Arg::new("ws-max-message-size")
+					.env("WS_MAX_MESSAGE_SIZE")
+					.long("ws-max-message-size")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(size_valid)
+					.help("The maximum size in bytes of WebSocket messages, defaulting to the maximum body size"),
+			)
+			.arg(
+				Arg::new("ws-max-frame-size")
+					.env("WS_MAX_FRAME_SIZE")
+					.long("ws-max-frame-size")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(size_valid)
+					.help("The maximum size in bytes of each frame of a fragmented WebSocket message"),
+			)
+			.arg(
+				Arg::new("ws-resume-grace")
+					.env("WS_RESUME_GRACE")
+					.long("ws-resume-grace")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The time in seconds for which a closed WebSocket session can be resumed"),
+			)
+			.arg(
+				Arg::new("request-timeout")
+					.env("REQUEST_TIMEOUT")
+					.long("request-timeout")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The time in seconds after which an unfinished HTTP request is cancelled"),
+			)
+			.arg(
+				Arg::new("shutdown-grace")
+					.env("SHUTDOWN_GRACE")
+					.long("shutdown-grace")
+					.takes_value(true)
+					.default_value("10")
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The time in seconds to wait for in-flight requests to finish when shutting down"),
+			)
+			.arg(
+				Arg::new("ws-max-concurrent")
+					.env("WS_MAX_CONCURRENT")
+					.long("ws-max-concurrent")
+					.takes_value(true)
+					.default_value("24")
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of concurrent RPC calls on each WebSocket connection"),
+			)
+			.arg(
+				Arg::new("ws-reject-concurrent")
+					.env("WS_REJECT_CONCURRENT")
+					.long("ws-reject-concurrent")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to reject, rather than queue, RPC calls above the concurrency limit"),
+			)
+			.arg(
+				Arg::new("ws-allow-origin")
+					.env("WS_ALLOW_ORIGIN")
+					.long("ws-allow-origin")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.validator(origin_valid)
+					.help("The origins which are allowed to open WebSocket connections"),
+			)
+			.arg(
+				Arg::new("ws-log-upgrade")
+					.env("WS_LOG_UPGRADE")
+					.long("ws-log-upgrade")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to log the origin and protocol of WebSocket upgrade requests"),
+			)
+			.arg(
+				Arg::new("ws-debug-log")
+					.env("WS_DEBUG_LOG")
+					.long("ws-debug-log")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to log every request on each WebSocket connection"),
+			)
+			.arg(
+				Arg::new("ws-max-connections")
+					.env("WS_MAX_CONNECTIONS")
+					.long("ws-max-connections")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of open WebSocket connections"),
+			)
+			.arg(
+				Arg::new("ws-max-connections-per-ip")
+					.env("WS_MAX_CONNECTIONS_PER_IP")
+					.long("ws-max-connections-per-ip")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of open WebSocket connections from each client address"),
+			)
+			.arg(
+				Arg::new("rate-limit")
+					.env("RATE_LIMIT")
+					.long("rate-limit")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of requests per second for each namespace and database"),
+			)
+			.arg(
+				Arg::new("rate-limit-burst")
+					.env("RATE_LIMIT_BURST")
+					.long("rate-limit-burst")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of requests which can be made at once before requests are rate limited"),
+			)
+			.arg(
+				Arg::new("rate-limit-ns")
+					.env("RATE_LIMIT_NS")
+					.long("rate-limit-ns")
+					.number_of_values(1)
+					.forbid_empty_values(true)
+					.multiple_occurrences(true)
+					.validator(rate_valid)
+					.help("The rate limit for a specific namespace, in the form <namespace>:<rate>"),
+			)
 			.arg(
 				Arg::new("strict")
 					.short('s')
@@ -228,6 +771,122 @@ pub fn init() {
 					.takes_value(false)
 					.help("Whether strict mode is enabled on this database instance"),
 			)
+			.arg(
+				Arg::new("depth")
+					.env("DEPTH")
+					.long("depth")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(depth_valid)
+					.help(
+						"The maximum number of graph edges which can be traversed in an expression",
+					),
+			)
+			.arg(
+				Arg::new("max-loop-iterations")
+					.env("MAX_LOOP_ITERATIONS")
+					.long("max-loop-iterations")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of times the body of a FOR loop can run in a query"),
+			)
+			.arg(
+				Arg::new("max-query-complexity")
+					.env("MAX_QUERY_COMPLEXITY")
+					.long("max-query-complexity")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum nesting and graph traversal complexity of a query"),
+			)
+			.arg(
+				Arg::new("max-query-results")
+					.env("MAX_QUERY_RESULTS")
+					.long("max-query-results")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of records returned by each statement of a query"),
+			)
+			.arg(
+				Arg::new("max-query-statements")
+					.env("MAX_QUERY_STATEMENTS")
+					.long("max-query-statements")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("The maximum number of statements in a single query, unless using root authentication"),
+			)
+			.arg(
+				Arg::new("max-tx-retries")
+					.env("MAX_TX_RETRIES")
+					.long("max-tx-retries")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(number_valid)
+					.help("The maximum number of times a statement is retried when its transaction conflicts"),
+			)
+			.arg(
+				Arg::new("panic-recovery")
+					.env("PANIC_RECOVERY")
+					.long("panic-recovery")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to recover from panics during query execution, returning an internal error"),
+			)
+			.arg(
+				Arg::new("case-insensitive-names")
+					.env("CASE_INSENSITIVE_NAMES")
+					.long("case-insensitive-names")
+					.required(false)
+					.takes_value(false)
+					.help("Whether namespace and database names are matched case-insensitively"),
+			)
+			.arg(
+				Arg::new("default-ordering")
+					.env("DEFAULT_ORDERING")
+					.long("default-ordering")
+					.required(false)
+					.takes_value(false)
+					.help("Whether query results without an ORDER BY clause are ordered by record id"),
+			)
+			.arg(
+				Arg::new("reap-interval")
+					.env("REAP_INTERVAL")
+					.long("reap-interval")
+					.takes_value(true)
+					.default_value("60")
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("The interval in seconds at which expired records and change feed events are deleted"),
+			)
+			.arg(
+				Arg::new("query-cache-ttl")
+					.env("QUERY_CACHE_TTL")
+					.long("query-cache-ttl")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(secs_valid)
+					.help("Cache the results of read only queries for the specified number of seconds"),
+			)
+			.arg(
+				Arg::new("slow-query-threshold")
+					.env("SLOW_QUERY_THRESHOLD")
+					.long("slow-query-threshold")
+					.takes_value(true)
+					.forbid_empty_values(true)
+					.validator(count_valid)
+					.help("Log any queries which take longer than the specified number of milliseconds"),
+			)
+			.arg(
+				Arg::new("tracing")
+					.env("TRACING")
+					.long("tracing")
+					.required(false)
+					.takes_value(false)
+					.help("Whether to export OpenTelemetry traces to the configured OTLP endpoint"),
+			)
 			.arg(
 				Arg::new("log")
 					.short('l')
@@ -464,3 +1123,88 @@ pub fn init() {
 		error!(target: LOG, "{}", e);
 	}
 }
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn secs_valid_duration() {
+		assert!(secs_valid("1").is_ok());
+		assert!(secs_valid("3600").is_ok());
+	}
+
+	#[test]
+	fn secs_invalid_duration() {
+		assert!(secs_valid("0").is_err());
+		assert!(secs_valid("-1").is_err());
+		assert!(secs_valid("1.5").is_err());
+		assert!(secs_valid("").is_err());
+	}
+	#[test]
+	fn size_valid_bytes() {
+		assert!(size_valid("0").is_ok());
+		assert!(size_valid("4194304").is_ok());
+	}
+
+	#[test]
+	fn size_invalid_bytes() {
+		assert!(size_valid("-1").is_err());
+		assert!(size_valid("4MB").is_err());
+		assert!(size_valid("").is_err());
+	}
+	#[test]
+	fn count_valid_number() {
+		assert!(count_valid("1").is_ok());
+		assert!(count_valid("24").is_ok());
+	}
+
+	#[test]
+	fn count_invalid_number() {
+		assert!(count_valid("0").is_err());
+		assert!(count_valid("-1").is_err());
+		assert!(count_valid("many").is_err());
+	}
+	#[test]
+	fn rate_valid_namespace() {
+		assert!(rate_valid("test:100").is_ok());
+		assert!(rate_valid("a:b:10").is_ok());
+	}
+
+	#[test]
+	fn rate_invalid_namespace() {
+		assert!(rate_valid("test").is_err());
+		assert!(rate_valid(":100").is_err());
+		assert!(rate_valid("test:0").is_err());
+		assert!(rate_valid("test:fast").is_err());
+	}
+	#[test]
+	fn tls_cipher_valid_suite() {
+		assert!(tls_cipher_valid("TLS13_AES_256_GCM_SHA384").is_ok());
+		assert!(tls_cipher_valid("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256").is_ok());
+	}
+
+	#[test]
+	fn tls_cipher_invalid_suite() {
+		assert!(tls_cipher_valid("tls13_aes_256_gcm_sha384").is_err());
+		assert!(tls_cipher_valid("TLS_RSA_WITH_RC4_128_MD5").is_err());
+		assert!(tls_cipher_valid("").is_err());
+	}
+	#[test]
+	fn origin_valid_origin() {
+		assert!(origin_valid("*").is_ok());
+		assert!(origin_valid("https://example.com").is_ok());
+		assert!(origin_valid("http://localhost:3000").is_ok());
+		assert!(origin_valid("https://*.example.com").is_ok());
+	}
+
+	#[test]
+	fn origin_invalid_origin() {
+		assert!(origin_valid("example.com").is_err());
+		assert!(origin_valid("ws://example.com").is_err());
+		assert!(origin_valid("https://*").is_err());
+		assert!(origin_valid("https://app.*.example.com").is_err());
+		assert!(origin_valid("https://*.*.example.com").is_err());
+	}
+}
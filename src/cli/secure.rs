@@ -0,0 +1,162 @@
+use crate::cli::config::Config;
+use crate::cli::CF;
+use crate::cli::LOG;
+use crate::err::Error;
+
+// Passwords which are commonly left as the default
+const DEFAULT_PASSWORDS: [&str; 4] = ["root", "pass", "password", "admin"];
+
+pub fn init() -> Result<(), Error> {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Check the options for insecure settings
+	let problems = check(opt);
+	// Log each of the insecure settings
+	for v in problems.iter() {
+		warn!(target: LOG, "Insecure configuration: {}", v);
+	}
+	// Refuse to start if insecure settings are denied
+	match problems.first() {
+		Some(v) if opt.deny_insecure => Err(Error::InsecureConfig(v.to_string())),
+		_ => Ok(()),
+	}
+}
+
+// Find the combinations of options which are insecure
+fn check(opt: &Config) -> Vec<&'static str> {
+	let mut problems = Vec::new();
+	// Check if root authentication is enabled
+	if let Some(pass) = &opt.pass {
+		// Check for a default or easily guessed password
+		if pass == &opt.user || DEFAULT_PASSWORDS.contains(&pass.as_str()) {
+			problems.push("The root password is a default password, or the same as the username");
+		}
+		// Check for unencrypted connections from other hosts
+		if opt.crt.is_none() && !opt.bind.ip().is_loopback() {
+			problems.push("Root credentials are accepted on a public address without TLS");
+		}
+		// Check for connections from every network
+		if opt.bind.ip().is_unspecified() {
+			problems.push("Root credentials are accepted from any network address");
+		}
+	}
+	// Check for keys used for both kinds of encryption
+	if opt.keys.iter().any(|(_, k)| opt.fields.iter().any(|(_, f)| f == k)) {
+		problems.push("The same key is used for both storage encryption and field encryption");
+	}
+	// Check for keys used for more than one key version
+	if reused(&opt.keys) || reused(&opt.fields) {
+		problems.push("The same key is used for more than one key version");
+	}
+	// Check for keys which have been left as a placeholder
+	if opt.keys.iter().chain(opt.fields.iter()).any(|(_, k)| k.iter().all(|v| *v == k[0])) {
+		problems.push("An encryption key is a placeholder, with every byte the same");
+	}
+	problems
+}
+
+// Check if a key is used for more than one key version
+fn reused(keys: &[(u8, Vec<u8>)]) -> bool {
+	keys.iter().enumerate().any(|(i, (_, k))| keys[i + 1..].iter().any(|(_, v)| v == k))
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	fn secure() -> Config {
+		Config {
+			pass: Some(String::from("c0rr3ct-h0rse")),
+			keys: vec![(1, (64..96).collect()), (2, (0..32).collect())],
+			fields: vec![(1, (32..64).collect())],
+			..Default::default()
+		}
+	}
+
+	#[test]
+	fn secure_config() {
+		assert!(check(&secure()).is_empty());
+	}
+
+	#[test]
+	fn secure_config_without_credentials() {
+		let opt = Config {
+			bind: "0.0.0.0:8000".parse().unwrap(),
+			..Default::default()
+		};
+		assert!(check(&opt).is_empty());
+	}
+
+	#[test]
+	fn default_password() {
+		let opt = Config {
+			pass: Some(String::from("root")),
+			..secure()
+		};
+		assert_eq!(
+			check(&opt),
+			vec!["The root password is a default password, or the same as the username"]
+		);
+	}
+
+	#[test]
+	fn public_address_without_tls() {
+		let opt = Config {
+			bind: "10.0.0.1:8000".parse().unwrap(),
+			..secure()
+		};
+		assert_eq!(
+			check(&opt),
+			vec!["Root credentials are accepted on a public address without TLS"]
+		);
+	}
+
+	#[test]
+	fn any_network_address() {
+		let opt = Config {
+			bind: "0.0.0.0:8000".parse().unwrap(),
+			crt: Some(String::from("server.crt")),
+			..secure()
+		};
+		assert_eq!(check(&opt), vec!["Root credentials are accepted from any network address"]);
+		let opt = Config {
+			bind: "[::]:8000".parse().unwrap(),
+			crt: Some(String::from("server.crt")),
+			..secure()
+		};
+		assert_eq!(check(&opt), vec!["Root credentials are accepted from any network address"]);
+	}
+
+	#[test]
+	fn key_for_both_kinds_of_encryption() {
+		let opt = Config {
+			fields: vec![(1, (0..32).collect())],
+			..secure()
+		};
+		assert_eq!(
+			check(&opt),
+			vec!["The same key is used for both storage encryption and field encryption"]
+		);
+	}
+
+	#[test]
+	fn key_for_more_than_one_version() {
+		let opt = Config {
+			fields: vec![(1, (32..64).collect()), (2, (32..64).collect())],
+			..secure()
+		};
+		assert_eq!(check(&opt), vec!["The same key is used for more than one key version"]);
+	}
+
+	#[test]
+	fn placeholder_key() {
+		let opt = Config {
+			keys: vec![(1, vec![0; 32])],
+			..secure()
+		};
+		assert_eq!(
+			check(&opt),
+			vec!["An encryption key is a placeholder, with every byte the same"]
+		);
+	}
+}
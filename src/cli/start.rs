@@ -1,5 +1,7 @@
 use super::config;
 use super::log;
+use super::secure;
+use super::trace;
 use crate::cnf::LOGO;
 use crate::dbs;
 use crate::err::Error;
@@ -17,16 +19,22 @@ pub async fn init(matches: &clap::ArgMatches) -> Result<(), Error> {
 		Some("full") => log::init(4),
 		_ => unreachable!(),
 	};
+	// Setup the trace exporter
+	trace::init(matches.is_present("tracing"));
 	// Output SurrealDB logo
 	println!("{}", LOGO);
 	// Setup the cli options
-	config::init(matches);
+	config::init(matches)?;
+	// Check for insecure cli options
+	secure::init()?;
 	// Initiate master auth
 	iam::init().await?;
 	// Start the kvs server
 	dbs::init().await?;
 	// Start the web server
 	net::init().await?;
+	// Flush any remaining traces
+	trace::shutdown();
 	// All ok
 	Ok(())
 }
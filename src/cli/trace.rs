@@ -0,0 +1,24 @@
+use crate::cli::LOG;
+use opentelemetry::global;
+use opentelemetry::sdk::propagation::TraceContextPropagator;
+
+pub fn init(enabled: bool) {
+	// Extract trace context from incoming requests
+	global::set_text_map_propagator(TraceContextPropagator::new());
+	// Spans are discarded by the default no-op
+	// tracer unless an exporter is installed
+	if enabled {
+		let res = opentelemetry_otlp::new_pipeline()
+			.tracing()
+			.with_exporter(opentelemetry_otlp::new_exporter().tonic())
+			.install_batch(opentelemetry::runtime::Tokio);
+		match res {
+			Ok(_) => info!(target: LOG, "Exporting traces to the OTLP endpoint"),
+			Err(e) => warn!(target: LOG, "Unable to setup the trace exporter: {}", e),
+		}
+	}
+}
+
+pub fn shutdown() {
+	global::shutdown_tracer_provider();
+}
@@ -14,11 +14,39 @@ Y88b  d88P Y88b 888 888     888     Y8b.     888  888 888 888  .d88P 888   d88P
 pub const PKG_NAME: &str = env!("CARGO_PKG_NAME");
 pub const PKG_VERS: &str = env!("CARGO_PKG_VERSION");
 
+// The commit and compiler used for this build
+pub const PKG_COMMIT: &str = env!("SURREAL_BUILD_COMMIT");
+pub const PKG_RUSTC: &str = env!("SURREAL_BUILD_RUSTC");
+
 // The publicly visible name of the server
 pub const SERVER_NAME: &str = "SurrealDB";
 
+// The name of the tracer used for server trace spans
+pub const TRACER: &str = "surreal";
+
 // The public endpoint for the database administration interface
 pub const APP_ENDPOINT: &str = "https://surrealdb.com/app";
 
 // Specifies how many concurrent jobs can be buffered in the worker channel.
 pub const MAX_CONCURRENT_CALLS: usize = 24;
+
+// Specifies how many TLS handshakes can be in progress at once.
+pub const MAX_CONCURRENT_HANDSHAKES: usize = 256;
+
+// Specifies how long a client has to complete a TLS handshake.
+pub const HANDSHAKE_TIMEOUT: std::time::Duration = std::time::Duration::from_secs(10);
+
+// Specifies how long a JSON Web Key Set is cached when the response has no cache headers.
+pub const JWKS_CACHE_DURATION: std::time::Duration = std::time::Duration::from_secs(300);
+
+// Specifies how soon a JSON Web Key Set can be fetched again, when a token uses an unknown key.
+pub const JWKS_REFRESH_INTERVAL: std::time::Duration = std::time::Duration::from_secs(10);
+
+// Specifies how long to wait for a JSON Web Key Set to be fetched.
+pub const JWKS_FETCH_TIMEOUT: std::time::Duration = std::time::Duration::from_secs(5);
+
+// Specifies how many rows are committed together by default in a bulk import.
+pub const IMPORT_BATCH_SIZE: usize = 1000;
+
+// Specifies the largest number of rows which can be committed together in a bulk import.
+pub const MAX_IMPORT_BATCH_SIZE: usize = 10000;
@@ -1,6 +1,9 @@
 use crate::cli::CF;
 use crate::err::Error;
+use crate::net::signal;
 use once_cell::sync::OnceCell;
+use std::time::Duration;
+use surrealdb::Cipher;
 use surrealdb::Datastore;
 
 pub static DB: OnceCell<Datastore> = OnceCell::new();
@@ -17,8 +20,125 @@ pub async fn init() -> Result<(), Error> {
 	};
 	// Parse and setup the desired kv datastore
 	let dbs = Datastore::new(&opt.path).await?;
+	// Set the maximum graph traversal depth
+	let dbs = match opt.depth {
+		Some(v) => dbs.with_depth(v),
+		None => dbs,
+	};
+	// Set the maximum loop iterations
+	let dbs = match opt.iterations {
+		Some(v) => dbs.with_iterations(v),
+		None => dbs,
+	};
+	// Set the maximum query complexity
+	let dbs = match opt.complexity {
+		Some(v) => dbs.with_complexity(v),
+		None => dbs,
+	};
+	// Set the maximum statement results
+	let dbs = match opt.results {
+		Some(v) => dbs.with_max_results(v),
+		None => dbs,
+	};
+	// Set the maximum transaction retries
+	let dbs = match opt.retries {
+		Some(v) => dbs.with_max_retries(v),
+		None => dbs,
+	};
+	// Set whether to recover from panics
+	let dbs = match opt.recover {
+		true => {
+			info!(target: LOG, "Panic recovery is enabled");
+			dbs.with_panic_recovery(true)
+		}
+		false => dbs,
+	};
+	// Set whether names are case-insensitive
+	let dbs = match opt.fold {
+		true => {
+			info!(target: LOG, "Case-insensitive namespace and database names are enabled");
+			dbs.with_case_insensitive_names(true)
+		}
+		false => dbs,
+	};
+	// Set whether results are ordered by default
+	let dbs = match opt.ordered {
+		true => {
+			info!(target: LOG, "Default result ordering by record id is enabled");
+			dbs.with_default_ordering(true)
+		}
+		false => dbs,
+	};
+	// Set the maximum query statements
+	let dbs = match opt.statements {
+		Some(v) => dbs.with_max_statements(v),
+		None => dbs,
+	};
+	// Set the query result cache duration
+	let dbs = match opt.cache {
+		Some(v) => {
+			info!(target: LOG, "Query result caching is enabled");
+			dbs.with_query_cache(v)
+		}
+		None => dbs,
+	};
+	// Set the slow query log threshold
+	let dbs = match opt.slow {
+		Some(v) => {
+			info!(target: LOG, "Slow query logging is enabled");
+			dbs.with_slow_query_log(v)
+		}
+		None => dbs,
+	};
+	// Set the storage encryption keys
+	let dbs = match opt.keys.is_empty() {
+		true => dbs,
+		false => {
+			let mut cipher = Cipher::new();
+			for (n, k) in opt.keys.iter() {
+				cipher = cipher.with_key(*n, k)?;
+			}
+			info!(target: LOG, "Storage encryption is enabled");
+			dbs.with_encryption(cipher)
+		}
+	};
+	// Set the field encryption keys
+	let dbs = match opt.fields.is_empty() {
+		true => dbs,
+		false => {
+			let mut cipher = Cipher::new();
+			for (n, k) in opt.fields.iter() {
+				cipher = cipher.with_key(*n, k)?;
+			}
+			info!(target: LOG, "Field encryption is enabled");
+			dbs.with_field_encryption(cipher)
+		}
+	};
 	// Store database instance
 	let _ = DB.set(dbs);
+	// Start deleting expired records and change feed events
+	tokio::spawn(reap(opt.reap));
 	// All ok
 	Ok(())
 }
+
+async fn reap(every: Duration) {
+	// Get the datastore reference
+	let db = DB.get().unwrap();
+	// Check for expired records at each interval
+	let mut interval = tokio::time::interval(every);
+	loop {
+		tokio::select! {
+			_ = interval.tick() => {
+				if let Err(err) = db.reap().await {
+					warn!(target: LOG, "Unable to delete expired records: {}", err);
+				}
+				if let Err(err) = db.prune().await {
+					warn!(target: LOG, "Unable to prune change feed events: {}", err);
+				}
+			}
+			// Stop when the server is shutting down
+			_ = signal::shutdown() => break,
+		}
+	}
+}
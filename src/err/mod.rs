@@ -23,12 +23,48 @@ pub enum Error {
 	#[error("There was a problem with authentication")]
 	InvalidAuth,
 
+	#[error("The CSRF token is missing or does not match the session")]
+	InvalidCsrf,
+
+	#[error("The origin of the request is not allowed")]
+	InvalidOrigin,
+
 	#[error("The specified media type is unsupported")]
 	InvalidType,
 
 	#[error("There was a problem connecting with the storage engine")]
 	InvalidStorage,
 
+	#[error("The query exceeds the maximum allowed query length")]
+	QueryTooLarge,
+
+	#[error("A bare response can only be returned for a query with a single statement")]
+	BareMultiple,
+
+	#[error("There are too many concurrent queries on this connection")]
+	TooManyCalls,
+
+	#[error("There are too many open WebSocket connections")]
+	TooManyConnections,
+
+	#[error("The server configuration is invalid: {0}")]
+	InvalidConfig(String),
+
+	#[error("The server configuration is insecure: {0}")]
+	InsecureConfig(String),
+
+	#[error("There was a problem with the TLS configuration: {0}")]
+	Tls(String),
+
+	#[error("The request rate limit has been exceeded, retry after {0} seconds")]
+	TooManyRequests(u64),
+
+	#[error("The request did not complete within the request timeout")]
+	RequestTimeout,
+
+	#[error("There was a problem fetching the JSON Web Key Set: {0}")]
+	Jwks(String),
+
 	#[error("There was a problem with the database: {0}")]
 	Db(#[from] DbError),
 
@@ -50,6 +86,38 @@ pub enum Error {
 
 impl warp::reject::Reject for Error {}
 
+impl Error {
+	/// A stable code which identifies the kind of error
+	pub fn code(&self) -> &'static str {
+		match self {
+			Error::Request => "REQUEST_INVALID",
+			Error::NoNsHeader => "NS_HEADER_MISSING",
+			Error::NoDbHeader => "DB_HEADER_MISSING",
+			Error::InvalidAuth => "AUTH_INVALID",
+			Error::InvalidCsrf => "CSRF_INVALID",
+			Error::InvalidOrigin => "ORIGIN_NOT_ALLOWED",
+			Error::InvalidType => "MEDIA_TYPE_UNSUPPORTED",
+			Error::InvalidStorage => "STORAGE_UNAVAILABLE",
+			Error::QueryTooLarge => "QUERY_TOO_LARGE",
+			Error::BareMultiple => "BARE_MULTIPLE_STATEMENTS",
+			Error::TooManyCalls => "TOO_MANY_CALLS",
+			Error::TooManyConnections => "TOO_MANY_CONNECTIONS",
+			Error::InvalidConfig(_) => "INVALID_CONFIG",
+			Error::InsecureConfig(_) => "INSECURE_CONFIG",
+			Error::Tls(_) => "TLS",
+			Error::TooManyRequests(_) => "RATE_LIMITED",
+			Error::RequestTimeout => "REQUEST_TIMEOUT",
+			Error::Jwks(_) => "JWKS_UNAVAILABLE",
+			Error::Db(e) => e.code(),
+			Error::Io(_) => "IO",
+			Error::Json(_) => "JSON",
+			Error::Cbor(_) => "CBOR",
+			Error::Pack(_) => "MESSAGEPACK",
+			Error::Remote(_) => "REMOTE",
+		}
+	}
+}
+
 impl From<Error> for String {
 	fn from(e: Error) -> String {
 		e.to_string()
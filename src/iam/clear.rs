@@ -5,5 +5,6 @@ use surrealdb::Session;
 
 pub async fn clear(session: &mut Session) -> Result<(), Error> {
 	session.au = Arc::new(Auth::No);
+	session.rl = vec![];
 	Ok(())
 }
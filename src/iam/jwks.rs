@@ -0,0 +1,240 @@
+use crate::cnf::JWKS_CACHE_DURATION;
+use crate::cnf::JWKS_FETCH_TIMEOUT;
+use crate::cnf::JWKS_REFRESH_INTERVAL;
+use crate::err::Error;
+use crate::iam::LOG;
+use http::header::{HeaderMap, CACHE_CONTROL};
+use jsonwebtoken::jwk::{Jwk, JwkSet};
+use jsonwebtoken::{decode_header, Algorithm, DecodingKey, Validation};
+use once_cell::sync::Lazy;
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::{Duration, Instant};
+
+// The key sets which have been fetched from each JWKS endpoint
+static CACHE: Lazy<Mutex<HashMap<String, Entry>>> = Lazy::new(|| Mutex::new(HashMap::new()));
+
+struct Entry {
+	// The keys in the key set
+	keys: JwkSet,
+	// When the key set was fetched
+	fetched: Instant,
+	// How long the key set can be cached
+	ttl: Duration,
+}
+
+/// Select the key for verifying a token from a remote JSON Web Key Set
+///
+/// The key is selected using the `kid` in the token header. The key set is
+/// cached for as long as the endpoint allows, and is fetched again if the
+/// token uses a key which is not in the cached key set, for instance after
+/// the keys have been rotated.
+pub async fn config(url: &str, token: &str) -> Result<(DecodingKey, Validation), Error> {
+	// Decode the token header to find the key
+	let head = decode_header(token)?;
+	let kid = match head.kid {
+		Some(kid) => kid,
+		None => {
+			trace!(target: LOG, "The authentication token does not specify a 'kid' field");
+			return Err(Error::InvalidAuth);
+		}
+	};
+	// Check the cached key set for the key
+	let jwk = match lookup(url, &kid) {
+		(Some(jwk), false) => jwk,
+		// The key set needs to be fetched
+		(_, true) => match fetch(url).await?.find(&kid) {
+			Some(jwk) => jwk.clone(),
+			None => {
+				trace!(target: LOG, "The key '{}' was not found in the key set at {}", kid, url);
+				return Err(Error::InvalidAuth);
+			}
+		},
+		// The key set was fetched too recently
+		(None, false) => {
+			trace!(target: LOG, "The key '{}' was not found in the key set at {}", kid, url);
+			return Err(Error::InvalidAuth);
+		}
+	};
+	// Configure the key and the validation
+	key(&jwk, head.alg)
+}
+
+// Convert a key from a key set into a key for verifying a token
+fn key(jwk: &Jwk, alg: Algorithm) -> Result<(DecodingKey, Validation), Error> {
+	// Check the key can be used with the token algorithm
+	if let Some(v) = &jwk.common.algorithm {
+		if format!("{:?}", v) != format!("{:?}", alg) {
+			trace!(target: LOG, "The key can not be used with the '{:?}' algorithm", alg);
+			return Err(Error::InvalidAuth);
+		}
+	}
+	// Configure the key and the validation
+	Ok((DecodingKey::from_jwk(jwk)?, Validation::new(alg)))
+}
+
+// Find a key in the cached key set, and check if the key set should be fetched
+fn lookup(url: &str, kid: &str) -> (Option<Jwk>, bool) {
+	// Lock the cached key sets
+	let cache = CACHE.lock().unwrap();
+	// Check if the key set has been fetched
+	match cache.get(url) {
+		Some(v) => {
+			let age = v.fetched.elapsed();
+			let jwk = v.keys.find(kid).cloned();
+			// Fetch the key set when it has expired, or when the
+			// key is missing, unless it was only just fetched
+			let fetch = match jwk {
+				Some(_) => age >= v.ttl,
+				None => age >= JWKS_REFRESH_INTERVAL,
+			};
+			match fetch {
+				true => (None, true),
+				false => (jwk, false),
+			}
+		}
+		None => (None, true),
+	}
+}
+
+// Fetch a key set, and store it in the cache
+async fn fetch(url: &str) -> Result<JwkSet, Error> {
+	// Log the request
+	debug!(target: LOG, "Fetching the JSON Web Key Set at {}", url);
+	// Send the request to the endpoint
+	let res = reqwest::Client::new()
+		.get(url)
+		.timeout(JWKS_FETCH_TIMEOUT)
+		.send()
+		.await
+		.map_err(|e| Error::Jwks(e.to_string()))?;
+	// Check the response status
+	if !res.status().is_success() {
+		return Err(Error::Jwks(format!("The endpoint responded with {}", res.status())));
+	}
+	// Check how long the key set can be cached
+	let ttl = expiry(res.headers());
+	// Parse the key set
+	let body = res.bytes().await.map_err(|e| Error::Jwks(e.to_string()))?;
+	let keys = serde_json::from_slice::<JwkSet>(&body).map_err(|e| Error::Jwks(e.to_string()))?;
+	// Store the key set
+	CACHE.lock().unwrap().insert(
+		url.to_owned(),
+		Entry {
+			keys: keys.clone(),
+			fetched: Instant::now(),
+			ttl,
+		},
+	);
+	Ok(keys)
+}
+
+// Check how long a response can be cached from its Cache-Control header
+fn expiry(headers: &HeaderMap) -> Duration {
+	// Get the cache directives
+	let val = match headers.get(CACHE_CONTROL).and_then(|v| v.to_str().ok()) {
+		Some(v) => v.to_ascii_lowercase(),
+		None => return JWKS_CACHE_DURATION,
+	};
+	// Check each of the cache directives
+	let mut ttl = JWKS_CACHE_DURATION;
+	for v in val.split(',').map(str::trim) {
+		match v.split_once('=') {
+			Some(("max-age", v)) => match v.trim_matches('"').parse::<u64>() {
+				Ok(v) => ttl = Duration::from_secs(v),
+				Err(_) => return Duration::ZERO,
+			},
+			None if v == "no-store" || v == "no-cache" => return Duration::ZERO,
+			_ => (),
+		}
+	}
+	ttl
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use http::header::HeaderValue;
+
+	const JWKS: &str = r#"{
+		"keys": [
+			{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"kid": "2011-04-29",
+				"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				"e": "AQAB"
+			},
+			{
+				"kty": "RSA",
+				"kid": "any",
+				"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				"e": "AQAB"
+			}
+		]
+	}"#;
+
+	fn headers(val: &str) -> HeaderMap {
+		let mut headers = HeaderMap::new();
+		headers.insert(CACHE_CONTROL, HeaderValue::from_str(val).unwrap());
+		headers
+	}
+
+	#[test]
+	fn expiry_without_header() {
+		assert_eq!(expiry(&HeaderMap::new()), JWKS_CACHE_DURATION);
+	}
+
+	#[test]
+	fn expiry_with_max_age() {
+		assert_eq!(expiry(&headers("public, max-age=300")), Duration::from_secs(300));
+		assert_eq!(expiry(&headers("Max-Age=\"60\"")), Duration::from_secs(60));
+		assert_eq!(expiry(&headers("max-age=0")), Duration::ZERO);
+	}
+
+	#[test]
+	fn expiry_with_invalid_max_age() {
+		assert_eq!(expiry(&headers("max-age=soon")), Duration::ZERO);
+	}
+
+	#[test]
+	fn expiry_without_caching() {
+		assert_eq!(expiry(&headers("no-store")), Duration::ZERO);
+		assert_eq!(expiry(&headers("max-age=300, no-cache")), Duration::ZERO);
+	}
+
+	#[test]
+	fn expiry_with_other_directives() {
+		assert_eq!(expiry(&headers("public, must-revalidate")), JWKS_CACHE_DURATION);
+	}
+
+	#[test]
+	fn key_with_matching_algorithm() {
+		let set: JwkSet = serde_json::from_str(JWKS).unwrap();
+		let jwk = set.find("2011-04-29").unwrap();
+		let (_, val) = key(jwk, Algorithm::RS256).unwrap();
+		assert_eq!(val.algorithms, vec![Algorithm::RS256]);
+	}
+
+	#[test]
+	fn key_with_other_algorithm() {
+		let set: JwkSet = serde_json::from_str(JWKS).unwrap();
+		let jwk = set.find("2011-04-29").unwrap();
+		assert!(matches!(key(jwk, Algorithm::RS512), Err(Error::InvalidAuth)));
+	}
+
+	#[test]
+	fn key_without_algorithm() {
+		let set: JwkSet = serde_json::from_str(JWKS).unwrap();
+		let jwk = set.find("any").unwrap();
+		let (_, val) = key(jwk, Algorithm::RS384).unwrap();
+		assert_eq!(val.algorithms, vec![Algorithm::RS384]);
+	}
+
+	#[test]
+	fn key_not_in_set() {
+		let set: JwkSet = serde_json::from_str(JWKS).unwrap();
+		assert!(set.find("missing").is_none());
+	}
+}
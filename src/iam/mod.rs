@@ -1,4 +1,5 @@
 pub mod clear;
+pub mod jwks;
 pub mod parse;
 pub mod signin;
 pub mod signup;
@@ -106,6 +106,8 @@ pub async fn sc(
 ) -> Result<String, Error> {
 	// Get a database reference
 	let kvs = DB.get().unwrap();
+	// Get the names as they are stored
+	let (ns, db) = (kvs.name(&ns), kvs.name(&db));
 	// Get local copy of options
 	let opt = CF.get().unwrap();
 	// Create a new readonly transaction
@@ -129,16 +131,15 @@ pub async fn sc(
 								// Create the authentication key
 								let key = EncodingKey::from_secret(sv.code.as_ref());
 								// Create the authentication claim
+								let now = Utc::now();
 								let val = Claims {
 									iss: Some(SERVER_NAME.to_owned()),
-									iat: Some(Utc::now().timestamp()),
-									nbf: Some(Utc::now().timestamp()),
+									iat: Some(now.timestamp()),
+									nbf: Some(now.timestamp()),
 									exp: Some(
 										match sv.session {
-											Some(v) => {
-												Utc::now() + Duration::from_std(v.0).unwrap()
-											}
-											_ => Utc::now() + Duration::hours(1),
+											Some(v) => now + Duration::from_std(v.0).unwrap(),
+											_ => now + Duration::hours(1),
 										}
 										.timestamp(),
 									),
@@ -146,6 +147,10 @@ pub async fn sc(
 									db: Some(db.to_owned()),
 									sc: Some(sc.to_owned()),
 									id: Some(rid.to_raw()),
+									or: match opt.bind_origin {
+										true => session.or.clone(),
+										false => None,
+									},
 									..Claims::default()
 								};
 								// Create the authentication token
@@ -157,6 +162,7 @@ pub async fn sc(
 								session.sc = Some(sc.to_owned());
 								session.sd = Some(Value::from(rid));
 								session.au = Arc::new(Auth::Sc(ns, db, sc));
+								session.rl = vec![];
 								// Check the authentication token
 								match enc {
 									// The auth token was created successfully
@@ -190,6 +196,8 @@ pub async fn db(
 ) -> Result<String, Error> {
 	// Get a database reference
 	let kvs = DB.get().unwrap();
+	// Get the names as they are stored
+	let (ns, db) = (kvs.name(&ns), kvs.name(&db));
 	// Create a new readonly transaction
 	let mut tx = kvs.transaction(false, false).await?;
 	// Check if the supplied DB Login exists
@@ -220,6 +228,7 @@ pub async fn db(
 					session.ns = Some(ns.to_owned());
 					session.db = Some(db.to_owned());
 					session.au = Arc::new(Auth::Db(ns, db));
+					session.rl = dl.roles.clone();
 					// Check the authentication token
 					match enc {
 						// The auth token was created successfully
@@ -245,6 +254,8 @@ pub async fn ns(
 ) -> Result<String, Error> {
 	// Get a database reference
 	let kvs = DB.get().unwrap();
+	// Get the names as they are stored
+	let ns = kvs.name(&ns);
 	// Create a new readonly transaction
 	let mut tx = kvs.transaction(false, false).await?;
 	// Check if the supplied NS Login exists
@@ -273,6 +284,7 @@ pub async fn ns(
 					session.tk = Some(val.into());
 					session.ns = Some(ns.to_owned());
 					session.au = Arc::new(Auth::Ns(ns));
+					session.rl = nl.roles.clone();
 					// Check the authentication token
 					match enc {
 						// The auth token was created successfully
@@ -297,6 +309,7 @@ pub async fn su(session: &mut Session, user: String, pass: String) -> Result<Str
 	if let Some(root) = &opts.pass {
 		if user == opts.user && &pass == root {
 			session.au = Arc::new(Auth::Kv);
+			session.rl = vec![];
 			return Ok(String::from(""));
 		}
 	}
@@ -3,6 +3,7 @@ use crate::cnf::SERVER_NAME;
 use crate::dbs::DB;
 use crate::err::Error;
 use crate::iam::token::{Claims, HEADER};
+use crate::net::limit;
 use chrono::{Duration, Utc};
 use jsonwebtoken::{encode, EncodingKey};
 use std::sync::Arc;
@@ -41,6 +42,8 @@ pub async fn sc(
 ) -> Result<String, Error> {
 	// Get a database reference
 	let kvs = DB.get().unwrap();
+	// Get the names as they are stored
+	let (ns, db) = (kvs.name(&ns), kvs.name(&db));
 	// Get local copy of options
 	let opt = CF.get().unwrap();
 	// Create a new readonly transaction
@@ -51,10 +54,23 @@ pub async fn sc(
 			match sv.signup {
 				// This scope allows signin
 				Some(val) => {
+					// Check the signup rate limit for this client
+					if let Some((n, v)) = sv.limit {
+						limit::signup(session, &ns, &db, &sc, n, *v)?;
+					}
 					// Setup the query params
 					let vars = Some(vars.0);
 					// Setup the query session
 					let sess = Session::for_db(&ns, &db);
+					// Check the signup assertion with the params
+					if let Some(chk) = sv.assert {
+						match kvs.compute(chk, &sess, vars.clone(), opt.strict).await {
+							// The signup assertion passed
+							Ok(v) if v.is_truthy() => (),
+							// The signup assertion failed
+							_ => return Err(Error::InvalidAuth),
+						}
+					}
 					// Compute the value with the params
 					match kvs.compute(val, &sess, vars, opt.strict).await {
 						// The signin value succeeded
@@ -64,16 +80,15 @@ pub async fn sc(
 								// Create the authentication key
 								let key = EncodingKey::from_secret(sv.code.as_ref());
 								// Create the authentication claim
+								let now = Utc::now();
 								let val = Claims {
 									iss: Some(SERVER_NAME.to_owned()),
-									iat: Some(Utc::now().timestamp()),
-									nbf: Some(Utc::now().timestamp()),
+									iat: Some(now.timestamp()),
+									nbf: Some(now.timestamp()),
 									exp: Some(
 										match sv.session {
-											Some(v) => {
-												Utc::now() + Duration::from_std(v.0).unwrap()
-											}
-											_ => Utc::now() + Duration::hours(1),
+											Some(v) => now + Duration::from_std(v.0).unwrap(),
+											_ => now + Duration::hours(1),
 										}
 										.timestamp(),
 									),
@@ -81,6 +96,10 @@ pub async fn sc(
 									db: Some(db.to_owned()),
 									sc: Some(sc.to_owned()),
 									id: Some(rid.to_raw()),
+									or: match opt.bind_origin {
+										true => session.or.clone(),
+										false => None,
+									},
 									..Claims::default()
 								};
 								// Create the authentication token
@@ -92,6 +111,7 @@ pub async fn sc(
 								session.sc = Some(sc.to_owned());
 								session.sd = Some(Value::from(rid));
 								session.au = Arc::new(Auth::Sc(ns, db, sc));
+								session.rl = vec![];
 								// Create the authentication token
 								match enc {
 									// The auth token was created successfully
@@ -41,6 +41,11 @@ pub struct Claims {
 	#[serde(rename = "ID")]
 	#[serde(skip_serializing_if = "Option::is_none")]
 	pub id: Option<String>,
+	#[serde(alias = "or")]
+	#[serde(alias = "OR")]
+	#[serde(rename = "OR")]
+	#[serde(skip_serializing_if = "Option::is_none")]
+	pub or: Option<String>,
 }
 
 impl From<Claims> for Value {
@@ -83,6 +88,10 @@ impl From<Claims> for Value {
 		if let Some(id) = v.id {
 			out.insert("ID".to_string(), id.into());
 		}
+		// Add OR field if set
+		if let Some(or) = v.or {
+			out.insert("OR".to_string(), or.into());
+		}
 		// Return value
 		out.into()
 	}
@@ -1,6 +1,7 @@
 use crate::cli::CF;
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::iam::jwks;
 use crate::iam::token::Claims;
 use crate::iam::BASIC;
 use crate::iam::LOG;
@@ -8,15 +9,32 @@ use crate::iam::TOKEN;
 use argon2::password_hash::{PasswordHash, PasswordVerifier};
 use argon2::Argon2;
 use chrono::Utc;
-use jsonwebtoken::{decode, DecodingKey, Validation};
+use jsonwebtoken::{decode, decode_header, DecodingKey, Validation};
 use once_cell::sync::Lazy;
 use std::sync::Arc;
 use surrealdb::sql::Algorithm;
+use surrealdb::sql::Duration;
 use surrealdb::sql::Value;
 use surrealdb::Auth;
 use surrealdb::Session;
 
+fn allowed(list: &[String], auth: &str) -> Result<(), Error> {
+	// Decode the token header, which fails
+	// for tokens with an `alg` of `none`
+	let head = decode_header(auth)?;
+	let algo = format!("{:?}", head.alg).to_uppercase();
+	// Check the algorithm is in the allowlist
+	match list.iter().any(|v| v == &algo) {
+		true => Ok(()),
+		false => {
+			trace!(target: LOG, "The '{}' token signing algorithm is not allowed", algo);
+			Err(Error::InvalidAuth)
+		}
+	}
+}
+
 fn config(algo: Algorithm, code: String) -> Result<(DecodingKey, Validation), Error> {
+	// Configure the key and the validation
 	match algo {
 		Algorithm::Hs256 => Ok((
 			DecodingKey::from_secret(code.as_ref()),
@@ -70,6 +88,50 @@ fn config(algo: Algorithm, code: String) -> Result<(DecodingKey, Validation), Er
 			DecodingKey::from_rsa_pem(code.as_ref())?,
 			Validation::new(jsonwebtoken::Algorithm::RS512),
 		)),
+		// Remote key sets are fetched by `verify`
+		Algorithm::Jwks => Err(Error::InvalidAuth),
+	}
+}
+
+async fn verify(algo: Algorithm, code: String, auth: &str) -> Result<(), Error> {
+	// Get the config options
+	let opts = CF.get().unwrap();
+	// Check the token signing algorithm is allowed
+	allowed(&opts.algorithms, auth)?;
+	// Configure the key and the validation
+	let cf = match algo {
+		Algorithm::Jwks => jwks::config(&code, auth).await?,
+		algo => config(algo, code)?,
+	};
+	// Verify the token
+	decode::<Claims>(auth, &cf.0, &cf.1)?;
+	Ok(())
+}
+
+fn lifetime(max: Option<Duration>, iat: Option<i64>, exp: Option<i64>) -> Result<(), Error> {
+	// Check if the scope limits the session duration
+	let max = match max {
+		Some(v) => v.0.as_secs_f64().ceil() as i64,
+		None => return Ok(()),
+	};
+	// Check the token lifetime is within the limit
+	match (iat, exp) {
+		(Some(iat), Some(exp)) if exp - iat <= max => Ok(()),
+		_ => {
+			trace!(target: LOG, "The authentication token lifetime exceeds the scope session duration");
+			Err(Error::InvalidAuth)
+		}
+	}
+}
+
+fn origin(bound: Option<&str>, or: Option<&str>) -> Result<(), Error> {
+	// Check the request comes from the origin the token is bound to
+	match bound {
+		Some(v) if or != Some(v) => {
+			trace!(target: LOG, "The 'OR' field in the authentication token does not match the request origin");
+			Err(Error::InvalidAuth)
+		}
+		_ => Ok(()),
 	}
 }
 
@@ -92,6 +154,9 @@ pub async fn basic(session: &mut Session, auth: String) -> Result<(), Error> {
 	let kvs = DB.get().unwrap();
 	// Get the config options
 	let opts = CF.get().unwrap();
+	// Get the names as they are stored
+	session.ns = session.ns.as_deref().map(|v| kvs.name(v));
+	session.db = session.db.as_deref().map(|v| kvs.name(v));
 	// Decode the encoded auth data
 	let auth = base64::decode(auth)?;
 	// Convert the auth data to String
@@ -109,6 +174,7 @@ pub async fn basic(session: &mut Session, auth: String) -> Result<(), Error> {
 				debug!(target: LOG, "Authenticated as super user");
 				// Store the authentication data
 				session.au = Arc::new(Auth::Kv);
+				session.rl = vec![];
 				return Ok(());
 			}
 		}
@@ -125,6 +191,7 @@ pub async fn basic(session: &mut Session, auth: String) -> Result<(), Error> {
 					debug!(target: LOG, "Authenticated as namespace user: {}", user);
 					// Store the authentication data
 					session.au = Arc::new(Auth::Ns(ns.to_owned()));
+					session.rl = nl.roles.clone();
 					return Ok(());
 				}
 			};
@@ -139,6 +206,7 @@ pub async fn basic(session: &mut Session, auth: String) -> Result<(), Error> {
 						debug!(target: LOG, "Authenticated as database user: {}", user);
 						// Store the authentication data
 						session.au = Arc::new(Auth::Db(ns.to_owned(), db.to_owned()));
+						session.rl = dl.roles.clone();
 						return Ok(());
 					}
 				};
@@ -156,7 +224,8 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 	let auth = auth.trim_start_matches(TOKEN).trim();
 	// Get a database reference
 	let kvs = DB.get().unwrap();
-	// Decode the token without verifying
+	// Decode the token without verifying, which
+	// fails for tokens with an `alg` of `none`
 	let token = decode::<Claims>(auth, &KEY, &DUD)?;
 	// Parse the token and catch any errors
 	let value = super::parse::parse(auth)?;
@@ -174,16 +243,24 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			return Err(Error::InvalidAuth);
 		}
 	}
-	// Check the token authentication claims
-	match token.claims {
+	// Check if the auth token is bound to another origin
+	origin(token.claims.or.as_deref(), session.or.as_deref())?;
+	// Keep the token lifetime for checking scope sessions
+	let (iat, exp) = (token.claims.iat, token.claims.exp);
+	// Get the names as they are stored
+	let mut claims = token.claims;
+	claims.ns = claims.ns.map(|v| kvs.name(&v));
+	claims.db = claims.db.map(|v| kvs.name(&v));
+	// Check the token authentication claims, where each type
+	// of authentication only accepts its own set of claims
+	match target(claims)? {
 		// Check if this is scope token authentication
-		Claims {
-			ns: Some(ns),
-			db: Some(db),
-			sc: Some(sc),
-			tk: Some(tk),
+		Target::ScopeToken {
+			ns,
+			db,
+			sc,
+			tk,
 			id,
-			..
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to scope `{}` with token `{}`", sc, tk);
@@ -195,10 +272,12 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 				None => Value::None,
 			};
 			// Get the scope token
-			let de = tx.get_st(&ns, &db, &sc, &tk).await?;
-			let cf = config(de.kind, de.code)?;
+			let de = tx.get_st(&ns, &db, &sc, &tk).await.map_err(|_| Error::InvalidAuth)?;
 			// Verify the token
-			decode::<Claims>(auth, &cf.0, &cf.1)?;
+			verify(de.kind, de.code, auth).await?;
+			// Check the scope session duration
+			let sv = tx.get_sc(&ns, &db, &sc).await.map_err(|_| Error::InvalidAuth)?;
+			lifetime(sv.session, iat, exp)?;
 			// Log the success
 			debug!(target: LOG, "Authenticated to scope `{}` with token `{}`", sc, tk);
 			// Set the session
@@ -208,15 +287,15 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			session.db = Some(db.to_owned());
 			session.sc = Some(sc.to_owned());
 			session.au = Arc::new(Auth::Sc(ns, db, sc));
+			session.rl = vec![];
 			Ok(())
 		}
 		// Check if this is scope authentication
-		Claims {
-			ns: Some(ns),
-			db: Some(db),
-			sc: Some(sc),
-			id: Some(id),
-			..
+		Target::Scope {
+			ns,
+			db,
+			sc,
+			id,
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to scope `{}`", sc);
@@ -225,10 +304,12 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			// Parse the record id
 			let id = surrealdb::sql::thing(&id)?;
 			// Get the scope
-			let de = tx.get_sc(&ns, &db, &sc).await?;
+			let de = tx.get_sc(&ns, &db, &sc).await.map_err(|_| Error::InvalidAuth)?;
 			let cf = config(Algorithm::Hs512, de.code)?;
 			// Verify the token
 			decode::<Claims>(auth, &cf.0, &cf.1)?;
+			// Check the scope session duration
+			lifetime(de.session, iat, exp)?;
 			// Log the success
 			debug!(target: LOG, "Authenticated to scope `{}`", sc);
 			// Set the session
@@ -238,24 +319,23 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			session.sc = Some(sc.to_owned());
 			session.sd = Some(Value::from(id));
 			session.au = Arc::new(Auth::Sc(ns, db, sc));
+			session.rl = vec![];
 			Ok(())
 		}
 		// Check if this is database token authentication
-		Claims {
-			ns: Some(ns),
-			db: Some(db),
-			tk: Some(tk),
-			..
+		Target::DatabaseToken {
+			ns,
+			db,
+			tk,
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to database `{}` with token `{}`", db, tk);
 			// Create a new readonly transaction
 			let mut tx = kvs.transaction(false, false).await?;
 			// Get the database token
-			let de = tx.get_dt(&ns, &db, &tk).await?;
-			let cf = config(de.kind, de.code)?;
+			let de = tx.get_dt(&ns, &db, &tk).await.map_err(|_| Error::InvalidAuth)?;
 			// Verify the token
-			decode::<Claims>(auth, &cf.0, &cf.1)?;
+			verify(de.kind, de.code, auth).await?;
 			// Log the success
 			debug!(target: LOG, "Authenticated to database `{}` with token `{}`", db, tk);
 			// Set the session
@@ -263,21 +343,21 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			session.ns = Some(ns.to_owned());
 			session.db = Some(db.to_owned());
 			session.au = Arc::new(Auth::Db(ns, db));
+			session.rl = vec![];
 			Ok(())
 		}
 		// Check if this is database authentication
-		Claims {
-			ns: Some(ns),
-			db: Some(db),
-			id: Some(id),
-			..
+		Target::Database {
+			ns,
+			db,
+			id,
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to database `{}` with login `{}`", db, id);
 			// Create a new readonly transaction
 			let mut tx = kvs.transaction(false, false).await?;
 			// Get the database login
-			let de = tx.get_dl(&ns, &db, &id).await?;
+			let de = tx.get_dl(&ns, &db, &id).await.map_err(|_| Error::InvalidAuth)?;
 			let cf = config(Algorithm::Hs512, de.code)?;
 			// Verify the token
 			decode::<Claims>(auth, &cf.0, &cf.1)?;
@@ -288,43 +368,42 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			session.ns = Some(ns.to_owned());
 			session.db = Some(db.to_owned());
 			session.au = Arc::new(Auth::Db(ns, db));
+			session.rl = de.roles;
 			Ok(())
 		}
 		// Check if this is namespace token authentication
-		Claims {
-			ns: Some(ns),
-			tk: Some(tk),
-			..
+		Target::NamespaceToken {
+			ns,
+			tk,
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to namespace `{}` with token `{}`", ns, tk);
 			// Create a new readonly transaction
 			let mut tx = kvs.transaction(false, false).await?;
 			// Get the namespace token
-			let de = tx.get_nt(&ns, &tk).await?;
-			let cf = config(de.kind, de.code)?;
+			let de = tx.get_nt(&ns, &tk).await.map_err(|_| Error::InvalidAuth)?;
 			// Verify the token
-			decode::<Claims>(auth, &cf.0, &cf.1)?;
+			verify(de.kind, de.code, auth).await?;
 			// Log the success
 			trace!(target: LOG, "Authenticated to namespace `{}` with token `{}`", ns, tk);
 			// Set the session
 			session.tk = Some(value);
 			session.ns = Some(ns.to_owned());
 			session.au = Arc::new(Auth::Ns(ns));
+			session.rl = vec![];
 			Ok(())
 		}
 		// Check if this is namespace authentication
-		Claims {
-			ns: Some(ns),
-			id: Some(id),
-			..
+		Target::Namespace {
+			ns,
+			id,
 		} => {
 			// Log the decoded authentication claims
 			trace!(target: LOG, "Authenticating to namespace `{}` with login `{}`", ns, id);
 			// Create a new readonly transaction
 			let mut tx = kvs.transaction(false, false).await?;
 			// Get the namespace login
-			let de = tx.get_nl(&ns, &id).await?;
+			let de = tx.get_nl(&ns, &id).await.map_err(|_| Error::InvalidAuth)?;
 			let cf = config(Algorithm::Hs512, de.code)?;
 			// Verify the token
 			decode::<Claims>(auth, &cf.0, &cf.1)?;
@@ -334,9 +413,313 @@ pub async fn token(session: &mut Session, auth: String) -> Result<(), Error> {
 			session.tk = Some(value);
 			session.ns = Some(ns.to_owned());
 			session.au = Arc::new(Auth::Ns(ns));
+			session.rl = de.roles;
 			Ok(())
 		}
-		// There was an auth error
-		_ => Err(Error::InvalidAuth),
+	}
+}
+
+// The type of authentication which a token is for
+enum Target {
+	ScopeToken {
+		ns: String,
+		db: String,
+		sc: String,
+		tk: String,
+		id: Option<String>,
+	},
+	Scope {
+		ns: String,
+		db: String,
+		sc: String,
+		id: String,
+	},
+	DatabaseToken {
+		ns: String,
+		db: String,
+		tk: String,
+	},
+	Database {
+		ns: String,
+		db: String,
+		id: String,
+	},
+	NamespaceToken {
+		ns: String,
+		tk: String,
+	},
+	Namespace {
+		ns: String,
+		id: String,
+	},
+}
+
+// Find the type of authentication which the token claims are for,
+// where each type of authentication only accepts its own set of claims
+fn target(claims: Claims) -> Result<Target, Error> {
+	match claims {
+		Claims {
+			ns: Some(ns),
+			db: Some(db),
+			sc: Some(sc),
+			tk: Some(tk),
+			id,
+			..
+		} => Ok(Target::ScopeToken {
+			ns,
+			db,
+			sc,
+			tk,
+			id,
+		}),
+		Claims {
+			ns: Some(ns),
+			db: Some(db),
+			sc: Some(sc),
+			tk: None,
+			id: Some(id),
+			..
+		} => Ok(Target::Scope {
+			ns,
+			db,
+			sc,
+			id,
+		}),
+		Claims {
+			ns: Some(ns),
+			db: Some(db),
+			sc: None,
+			tk: Some(tk),
+			id: None,
+			..
+		} => Ok(Target::DatabaseToken {
+			ns,
+			db,
+			tk,
+		}),
+		Claims {
+			ns: Some(ns),
+			db: Some(db),
+			sc: None,
+			tk: None,
+			id: Some(id),
+			..
+		} => Ok(Target::Database {
+			ns,
+			db,
+			id,
+		}),
+		Claims {
+			ns: Some(ns),
+			db: None,
+			sc: None,
+			tk: Some(tk),
+			id: None,
+			..
+		} => Ok(Target::NamespaceToken {
+			ns,
+			tk,
+		}),
+		Claims {
+			ns: Some(ns),
+			db: None,
+			sc: None,
+			tk: None,
+			id: Some(id),
+			..
+		} => Ok(Target::Namespace {
+			ns,
+			id,
+		}),
+		// The claims do not match exactly one type of authentication
+		_ => {
+			trace!(target: LOG, "The authentication token claims do not match any type of authentication");
+			Err(Error::InvalidAuth)
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use jsonwebtoken::{encode, EncodingKey, Header};
+
+	fn token(algo: jsonwebtoken::Algorithm, secret: &str) -> String {
+		let claims = Claims {
+			exp: Some(Utc::now().timestamp() + 60),
+			..Claims::default()
+		};
+		encode(&Header::new(algo), &claims, &EncodingKey::from_secret(secret.as_ref())).unwrap()
+	}
+
+	#[test]
+	fn allowed_algorithm_none() {
+		let head = base64::encode_config(r#"{"alg":"none","typ":"JWT"}"#, base64::URL_SAFE_NO_PAD);
+		let body = base64::encode_config(r#"{"NS":"test"}"#, base64::URL_SAFE_NO_PAD);
+		let auth = format!("{}.{}.", head, body);
+		let list = vec!["HS256".to_string(), "HS512".to_string()];
+		assert!(allowed(&list, &auth).is_err());
+	}
+
+	#[test]
+	fn allowed_algorithm_unlisted() {
+		let auth = token(jsonwebtoken::Algorithm::HS256, "secret");
+		// The token signature is valid
+		let cf = config(Algorithm::Hs256, "secret".to_string()).unwrap();
+		assert!(decode::<Claims>(&auth, &cf.0, &cf.1).is_ok());
+		// The token algorithm is not allowed
+		let list = vec!["RS256".to_string(), "HS512".to_string()];
+		assert!(allowed(&list, &auth).is_err());
+	}
+
+	#[test]
+	fn allowed_algorithm_listed() {
+		let auth = token(jsonwebtoken::Algorithm::HS256, "secret");
+		let list = vec!["RS256".to_string(), "HS256".to_string()];
+		assert!(allowed(&list, &auth).is_ok());
+	}
+
+	#[test]
+	fn config_internal_algorithm() {
+		// Tokens issued by the server are verified without the allowlist
+		let auth = token(jsonwebtoken::Algorithm::HS512, "secret");
+		let cf = config(Algorithm::Hs512, "secret".to_string()).unwrap();
+		assert!(decode::<Claims>(&auth, &cf.0, &cf.1).is_ok());
+	}
+
+	#[test]
+	fn lifetime_without_session_duration() {
+		assert!(lifetime(None, None, None).is_ok());
+		assert!(lifetime(None, Some(0), Some(i64::MAX)).is_ok());
+	}
+
+	#[test]
+	fn lifetime_within_session_duration() {
+		let max = Some(Duration(std::time::Duration::from_secs(60)));
+		assert!(lifetime(max.clone(), Some(1000), Some(1059)).is_ok());
+		assert!(lifetime(max, Some(1000), Some(1060)).is_ok());
+	}
+
+	#[test]
+	fn lifetime_exceeds_session_duration() {
+		let max = Some(Duration(std::time::Duration::from_secs(60)));
+		assert!(lifetime(max, Some(1000), Some(1061)).is_err());
+	}
+
+	#[test]
+	fn lifetime_with_partial_seconds() {
+		// Partial seconds are rounded up to the next second
+		let max = Some(Duration(std::time::Duration::from_millis(1500)));
+		assert!(lifetime(max.clone(), Some(1000), Some(1002)).is_ok());
+		assert!(lifetime(max, Some(1000), Some(1003)).is_err());
+	}
+
+	#[test]
+	fn lifetime_without_timestamps() {
+		let max = Some(Duration(std::time::Duration::from_secs(60)));
+		assert!(lifetime(max.clone(), None, Some(1000)).is_err());
+		assert!(lifetime(max.clone(), Some(1000), None).is_err());
+		assert!(lifetime(max, None, None).is_err());
+	}
+
+	fn claims(ns: bool, db: bool, sc: bool, tk: bool, id: bool) -> Claims {
+		Claims {
+			ns: ns.then(|| String::from("test")),
+			db: db.then(|| String::from("test")),
+			sc: sc.then(|| String::from("test")),
+			tk: tk.then(|| String::from("test")),
+			id: id.then(|| String::from("user:test")),
+			..Claims::default()
+		}
+	}
+
+	#[test]
+	fn target_scope_token() {
+		let res = target(claims(true, true, true, true, false));
+		assert!(matches!(
+			res,
+			Ok(Target::ScopeToken {
+				id: None,
+				..
+			})
+		));
+		let res = target(claims(true, true, true, true, true));
+		assert!(matches!(
+			res,
+			Ok(Target::ScopeToken {
+				id: Some(_),
+				..
+			})
+		));
+	}
+
+	#[test]
+	fn target_scope() {
+		let res = target(claims(true, true, true, false, true));
+		assert!(matches!(res, Ok(Target::Scope { .. })));
+	}
+
+	#[test]
+	fn target_database() {
+		let res = target(claims(true, true, false, true, false));
+		assert!(matches!(res, Ok(Target::DatabaseToken { .. })));
+		let res = target(claims(true, true, false, false, true));
+		assert!(matches!(res, Ok(Target::Database { .. })));
+	}
+
+	#[test]
+	fn target_namespace() {
+		let res = target(claims(true, false, false, true, false));
+		assert!(matches!(res, Ok(Target::NamespaceToken { .. })));
+		let res = target(claims(true, false, false, false, true));
+		assert!(matches!(res, Ok(Target::Namespace { .. })));
+	}
+
+	#[test]
+	fn target_with_mixed_claims() {
+		// Token and login claims together
+		assert!(target(claims(true, true, false, true, true)).is_err());
+		assert!(target(claims(true, false, false, true, true)).is_err());
+		// Scope claims without a database
+		assert!(target(claims(true, false, true, true, false)).is_err());
+		assert!(target(claims(true, false, true, false, true)).is_err());
+	}
+
+	#[test]
+	fn target_with_missing_claims() {
+		assert!(target(Claims::default()).is_err());
+		// A namespace or database without a token or login
+		assert!(target(claims(true, false, false, false, false)).is_err());
+		assert!(target(claims(true, true, false, false, false)).is_err());
+		assert!(target(claims(true, true, true, false, false)).is_err());
+		// A token or login without a namespace
+		assert!(target(claims(false, true, false, true, false)).is_err());
+		assert!(target(claims(false, false, false, false, true)).is_err());
+	}
+
+	#[test]
+	fn origin_unbound() {
+		assert!(origin(None, None).is_ok());
+		assert!(origin(None, Some("https://example.com")).is_ok());
+	}
+
+	#[test]
+	fn origin_bound_to_request_origin() {
+		let or = Some("https://example.com");
+		assert!(origin(or, Some("https://example.com")).is_ok());
+	}
+
+	#[test]
+	fn origin_bound_to_other_origin() {
+		let or = Some("https://example.com");
+		assert!(origin(or, Some("https://other.com")).is_err());
+		assert!(origin(or, Some("https://example.com:8000")).is_err());
+		assert!(origin(or, Some("http://example.com")).is_err());
+	}
+
+	#[test]
+	fn origin_bound_without_request_origin() {
+		assert!(origin(Some("https://example.com"), None).is_err());
 	}
 }
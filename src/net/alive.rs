@@ -0,0 +1,105 @@
+use std::time::Duration;
+use tokio::time::Instant;
+
+/// The keepalive deadlines of a WebSocket connection
+pub struct Alive {
+	// The time to wait for a pong after a ping
+	wait: Duration,
+	// The time after which an inactive connection is closed
+	idle: Duration,
+	// The deadline for the next pong from the client
+	pong: Option<Instant>,
+	// The time of the last message from the client
+	last: Instant,
+}
+
+impl Alive {
+	pub fn new(wait: Duration, idle: Duration, now: Instant) -> Alive {
+		Alive {
+			wait,
+			idle,
+			pong: None,
+			last: now,
+		}
+	}
+	/// Start waiting for a pong, unless a ping is already unanswered
+	pub fn ping(&mut self, now: Instant) {
+		if self.pong.is_none() {
+			self.pong = Some(now + self.wait);
+		}
+	}
+	/// Record a message from the client, where pongs are not activity
+	pub fn received(&mut self, pong: bool, now: Instant) {
+		// Any message shows the connection is alive
+		self.pong = None;
+		// Reset the idle timeout
+		if !pong {
+			self.last = now;
+		}
+	}
+	/// The time by which the client must respond to a ping
+	pub fn pong(&self) -> Option<Instant> {
+		self.pong
+	}
+	/// The time at which the connection becomes idle
+	pub fn idle(&self) -> Instant {
+		self.last + self.idle
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	fn alive(now: Instant) -> Alive {
+		Alive::new(Duration::from_secs(10), Duration::from_secs(60), now)
+	}
+
+	#[test]
+	fn ping_waits_for_pong() {
+		let now = Instant::now();
+		let mut v = alive(now);
+		assert_eq!(v.pong(), None);
+		v.ping(now);
+		assert_eq!(v.pong(), Some(now + Duration::from_secs(10)));
+	}
+
+	#[test]
+	fn ping_keeps_first_deadline() {
+		let now = Instant::now();
+		let mut v = alive(now);
+		v.ping(now);
+		v.ping(now + Duration::from_secs(5));
+		assert_eq!(v.pong(), Some(now + Duration::from_secs(10)));
+	}
+
+	#[test]
+	fn message_answers_ping() {
+		let now = Instant::now();
+		let mut v = alive(now);
+		v.ping(now);
+		v.received(false, now + Duration::from_secs(1));
+		assert_eq!(v.pong(), None);
+		v.ping(now);
+		v.received(true, now + Duration::from_secs(1));
+		assert_eq!(v.pong(), None);
+	}
+
+	#[test]
+	fn message_resets_idle() {
+		let now = Instant::now();
+		let mut v = alive(now);
+		assert_eq!(v.idle(), now + Duration::from_secs(60));
+		v.received(false, now + Duration::from_secs(30));
+		assert_eq!(v.idle(), now + Duration::from_secs(90));
+	}
+
+	#[test]
+	fn pong_does_not_reset_idle() {
+		let now = Instant::now();
+		let mut v = alive(now);
+		v.received(true, now + Duration::from_secs(30));
+		assert_eq!(v.idle(), now + Duration::from_secs(60));
+	}
+}
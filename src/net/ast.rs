@@ -4,13 +4,15 @@ use warp::Filter;
 use surrealdb::Session;
 use surrealdb::sql::serde::{beg_internal_serialization, end_internal_serialization};
 
+use crate::cli::CF;
 use crate::err::Error;
+use crate::net::length;
 use crate::net::output;
 use crate::net::session;
 
-const MAX: u64 = 1024 * 1024; // 1 MiB
-
 pub fn config() -> impl Filter<Extract=impl warp::Reply, Error=warp::Rejection> + Clone {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
 	// Set base path
 	let base = warp::path("ast").and(warp::path::end());
 	// Set opts method
@@ -20,7 +22,7 @@ pub fn config() -> impl Filter<Extract=impl warp::Reply, Error=warp::Rejection>
 		.and(warp::post())
 		.and(session::build())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
-		.and(warp::body::content_length_limit(MAX))
+		.and(warp::body::content_length_limit(opt.max_body))
 		.and(warp::body::bytes())
 		.and_then(handler);
 	// Specify route
@@ -37,6 +39,8 @@ async fn handler(
 		true => {
 			// Convert the received sql query
 			let sql = std::str::from_utf8(&sql).unwrap();
+			// Check the length of the sql query
+			length::check(sql).map_err(warp::reject::custom)?;
 			// Get the AST of the query
 			let ast = surrealdb::sql::parse(&sql);
 			// Handle our error if we have one
@@ -0,0 +1,65 @@
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::output;
+use crate::net::session;
+use serde::Deserialize;
+use surrealdb::Session;
+use warp::http;
+use warp::Filter;
+
+#[derive(Default, Deserialize, Debug, Clone)]
+struct Query {
+	pub since: Option<u64>,
+	pub limit: Option<u32>,
+}
+
+pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejection> + Clone {
+	warp::path("changes")
+		.and(warp::path::end())
+		.and(warp::get())
+		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
+		.and(warp::query())
+		.and(session::build())
+		.and_then(handler)
+}
+
+async fn handler(
+	output: String,
+	query: Query,
+	session: Session,
+) -> Result<impl warp::Reply, warp::Rejection> {
+	// Check the permissions
+	match session.au.is_db() {
+		true => {
+			// Get the datastore reference
+			let db = DB.get().unwrap();
+			// Extract the NS header value
+			let nsv = match session.ns {
+				Some(ns) => ns,
+				None => return Err(warp::reject::custom(Error::NoNsHeader)),
+			};
+			// Extract the DB header value
+			let dbv = match session.db {
+				Some(db) => db,
+				None => return Err(warp::reject::custom(Error::NoDbHeader)),
+			};
+			// Get the requested sequence range
+			let since = query.since.unwrap_or(1);
+			let limit = query.limit.unwrap_or(100);
+			// Fetch the change feed events
+			match db.changes(nsv, dbv, since, limit).await {
+				Ok(res) => match output.as_ref() {
+					"application/json" => Ok(output::json(&res)),
+					"application/cbor" => Ok(output::cbor(&res)),
+					"application/msgpack" => Ok(output::pack(&res)),
+					// An incorrect content-type was requested
+					_ => Err(warp::reject::custom(Error::InvalidType)),
+				},
+				// There was an error when fetching the changes
+				Err(err) => Err(warp::reject::custom(Error::from(err))),
+			}
+		}
+		// There was an error with permissions
+		_ => Err(warp::reject::custom(Error::InvalidAuth)),
+	}
+}
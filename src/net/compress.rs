@@ -0,0 +1,211 @@
+use crate::cli::CF;
+use bytes::Bytes;
+use flate2::write::{DeflateEncoder, GzEncoder};
+use flate2::Compression;
+use http::header::{HeaderValue, CONTENT_ENCODING, CONTENT_LENGTH, CONTENT_TYPE, VARY};
+use hyper::body::{Body, HttpBody};
+use std::io::Write;
+use warp::reply::Response;
+use warp::Reply;
+
+// Content types which should never be compressed
+const SKIP: [&str; 8] = [
+	"application/x-ndjson",
+	"application/gzip",
+	"application/zip",
+	"application/octet-stream",
+	"image/",
+	"video/",
+	"audio/",
+	"font/",
+];
+
+enum Encoding {
+	Gzip,
+	Deflate,
+}
+
+impl Encoding {
+	fn as_str(&self) -> &'static str {
+		match self {
+			Encoding::Gzip => "gzip",
+			Encoding::Deflate => "deflate",
+		}
+	}
+}
+
+fn negotiate(accept: &str) -> Option<Encoding> {
+	// Parse the accepted encodings and their weights
+	let encs = accept
+		.split(',')
+		.filter_map(|v| {
+			let mut v = v.split(';').map(|v| v.trim());
+			let name = v.next()?.to_ascii_lowercase();
+			let q = match v.find_map(|v| v.split_once('=').filter(|(k, _)| k.trim() == "q")) {
+				Some((_, q)) => q.trim().parse::<f32>().ok()?,
+				None => 1.0,
+			};
+			Some((name, q))
+		})
+		.collect::<Vec<_>>();
+	// Get the weight of an encoding, falling back to any wildcard
+	let weight = |name: &str| {
+		let find = |name: &str| encs.iter().find(|(v, _)| v == name).map(|(_, q)| *q);
+		find(name).or_else(|| find("*")).unwrap_or(0.0)
+	};
+	// Prefer gzip over deflate, unless deflate has a higher weight
+	let gzip = weight("gzip");
+	let deflate = weight("deflate");
+	if gzip > 0.0 && gzip >= deflate {
+		Some(Encoding::Gzip)
+	} else if deflate > 0.0 {
+		Some(Encoding::Deflate)
+	} else {
+		None
+	}
+}
+
+fn encode(enc: &Encoding, val: &[u8]) -> std::io::Result<Vec<u8>> {
+	match enc {
+		Encoding::Gzip => {
+			let mut e = GzEncoder::new(Vec::new(), Compression::default());
+			e.write_all(val)?;
+			e.finish()
+		}
+		Encoding::Deflate => {
+			let mut e = DeflateEncoder::new(Vec::new(), Compression::default());
+			e.write_all(val)?;
+			e.finish()
+		}
+	}
+}
+
+pub async fn reply(accept: Option<String>, res: impl Reply) -> Response {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Compress the response
+	compress(opt.compression, accept, res).await
+}
+
+async fn compress(threshold: usize, accept: Option<String>, res: impl Reply) -> Response {
+	// Convert the reply into a response
+	let res = res.into_response();
+	// Check if the client accepts compression
+	let enc = match accept.as_deref().and_then(negotiate) {
+		Some(v) => v,
+		None => return res,
+	};
+	// Skip responses which are already encoded
+	if res.headers().contains_key(CONTENT_ENCODING) {
+		return res;
+	}
+	// Skip responses which are streamed or already compressed
+	if let Some(v) = res.headers().get(CONTENT_TYPE).and_then(|v| v.to_str().ok()) {
+		if SKIP.iter().any(|s| v.starts_with(s)) {
+			return res;
+		}
+	}
+	// Skip responses which are too small or of unknown length
+	match res.body().size_hint().exact() {
+		Some(v) if v as usize >= threshold => (),
+		_ => return res,
+	}
+	// Retrieve the full response body
+	let (mut head, body) = res.into_parts();
+	let body = match hyper::body::to_bytes(body).await {
+		Ok(v) => v,
+		Err(_) => Bytes::new(),
+	};
+	// Compress the response body
+	match encode(&enc, &body) {
+		Ok(v) => {
+			head.headers.remove(CONTENT_LENGTH);
+			head.headers.insert(CONTENT_ENCODING, HeaderValue::from_static(enc.as_str()));
+			head.headers.append(VARY, HeaderValue::from_static("accept-encoding"));
+			Response::from_parts(head, Body::from(v))
+		}
+		Err(_) => Response::from_parts(head, Body::from(body)),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use flate2::read::GzDecoder;
+	use std::io::Read;
+
+	fn name(accept: &str) -> Option<&'static str> {
+		negotiate(accept).map(|v| v.as_str())
+	}
+
+	#[test]
+	fn negotiate_encodings() {
+		assert_eq!(name("gzip"), Some("gzip"));
+		assert_eq!(name("deflate"), Some("deflate"));
+		assert_eq!(name("deflate, gzip"), Some("gzip"));
+		assert_eq!(name("GZIP"), Some("gzip"));
+		assert_eq!(name("br, identity"), None);
+		assert_eq!(name(""), None);
+	}
+
+	#[test]
+	fn negotiate_refused_encodings() {
+		assert_eq!(name("gzip;q=0"), None);
+		assert_eq!(name("gzip; q=0"), None);
+		assert_eq!(name("gzip ; q = 0.0"), None);
+		assert_eq!(name("gzip; q=0, deflate"), Some("deflate"));
+		assert_eq!(name("gzip; q=0.000, deflate; q=0"), None);
+	}
+
+	#[test]
+	fn negotiate_weighted_encodings() {
+		assert_eq!(name("gzip; q=0.5, deflate; q=0.8"), Some("deflate"));
+		assert_eq!(name("gzip; q=0.8, deflate; q=0.5"), Some("gzip"));
+		assert_eq!(name("gzip; q=0.5, deflate; q=0.5"), Some("gzip"));
+		assert_eq!(name("gzip; q=invalid, deflate"), Some("deflate"));
+	}
+
+	#[test]
+	fn negotiate_wildcard_encodings() {
+		assert_eq!(name("*"), Some("gzip"));
+		assert_eq!(name("*; q=0"), None);
+		assert_eq!(name("gzip; q=0, *"), Some("deflate"));
+		assert_eq!(name("*; q=0, deflate"), Some("deflate"));
+		assert_eq!(name("br, *; q=0.1"), Some("gzip"));
+	}
+
+	#[tokio::test]
+	async fn compress_small_body() {
+		let res = compress(1024, Some("gzip".into()), "a".repeat(1023)).await;
+		assert!(res.headers().get(CONTENT_ENCODING).is_none());
+		let body = hyper::body::to_bytes(res.into_body()).await.unwrap();
+		assert_eq!(body, "a".repeat(1023));
+	}
+
+	#[tokio::test]
+	async fn compress_large_body() {
+		let res = compress(1024, Some("gzip".into()), "a".repeat(1024)).await;
+		assert_eq!(res.headers().get(CONTENT_ENCODING).unwrap(), "gzip");
+		assert_eq!(res.headers().get(VARY).unwrap(), "accept-encoding");
+		assert!(res.headers().get(CONTENT_LENGTH).is_none());
+		let body = hyper::body::to_bytes(res.into_body()).await.unwrap();
+		let mut out = String::new();
+		GzDecoder::new(&body[..]).read_to_string(&mut out).unwrap();
+		assert_eq!(out, "a".repeat(1024));
+	}
+
+	#[tokio::test]
+	async fn compress_without_accept_encoding() {
+		let res = compress(1024, None, "a".repeat(2048)).await;
+		assert!(res.headers().get(CONTENT_ENCODING).is_none());
+		let res = compress(1024, Some("gzip; q=0".into()), "a".repeat(2048)).await;
+		assert!(res.headers().get(CONTENT_ENCODING).is_none());
+	}
+
+	#[tokio::test]
+	async fn compress_skipped_content_type() {
+		let res = warp::reply::with_header("a".repeat(2048), CONTENT_TYPE, "application/x-ndjson");
+		let res = compress(1024, Some("gzip".into()), res).await;
+		assert!(res.headers().get(CONTENT_ENCODING).is_none());
+	}
+}
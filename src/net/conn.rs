@@ -0,0 +1,139 @@
+use crate::cli::CF;
+use crate::err::Error;
+use crate::net::LOG;
+use once_cell::sync::Lazy;
+use std::collections::HashMap;
+use std::sync::Mutex;
+
+// The open WebSocket connections, in total and for each address
+static CONNS: Lazy<Mutex<Conns>> = Lazy::new(|| Mutex::new(Conns::default()));
+
+#[derive(Default)]
+struct Conns {
+	// The total number of open connections
+	total: usize,
+	// The number of open connections from each address
+	addrs: HashMap<String, usize>,
+}
+
+impl Conns {
+	// Take a connection slot, if the connection limits allow it
+	fn open(
+		&mut self,
+		ip: Option<&str>,
+		max: Option<usize>,
+		max_ip: Option<usize>,
+	) -> Result<(), Error> {
+		// Check the total connection limit
+		if let Some(max) = max {
+			if self.total >= max {
+				warn!(target: LOG, "Rejected WebSocket connection, the limit of {} connections has been reached", max);
+				return Err(Error::TooManyConnections);
+			}
+		}
+		// Check the connection limit for this address
+		if let (Some(max), Some(ip)) = (max_ip, ip) {
+			if self.addrs.get(ip).copied().unwrap_or(0) >= max {
+				warn!(target: LOG, "Rejected WebSocket connection from {}, the limit of {} connections has been reached", ip, max);
+				return Err(Error::TooManyConnections);
+			}
+		}
+		// Take the connection slot
+		self.total += 1;
+		if let Some(ip) = ip {
+			*self.addrs.entry(ip.to_owned()).or_insert(0) += 1;
+		}
+		Ok(())
+	}
+	// Release a connection slot
+	fn close(&mut self, ip: Option<&str>) {
+		self.total -= 1;
+		if let Some(ip) = ip {
+			if let Some(v) = self.addrs.get_mut(ip) {
+				*v -= 1;
+				// Remove addresses with no open connections
+				if *v == 0 {
+					self.addrs.remove(ip);
+				}
+			}
+		}
+	}
+}
+
+/// A guard which holds a WebSocket connection slot until it is dropped
+pub struct Conn {
+	ip: Option<String>,
+}
+
+impl Conn {
+	/// Take a connection slot, if the connection limits allow it
+	pub fn open(ip: Option<String>) -> Result<Conn, Error> {
+		// Get local copy of options
+		let opt = CF.get().unwrap();
+		// Take the connection slot
+		CONNS.lock().unwrap().open(ip.as_deref(), opt.ws_conns, opt.ws_conns_ip)?;
+		Ok(Conn {
+			ip,
+		})
+	}
+}
+
+impl Drop for Conn {
+	fn drop(&mut self) {
+		// Release the connection slot
+		CONNS.lock().unwrap().close(self.ip.as_deref());
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	const ONE: Option<&str> = Some("10.0.0.1");
+	const TWO: Option<&str> = Some("10.0.0.2");
+
+	#[test]
+	fn open_without_limits() {
+		let mut conns = Conns::default();
+		for _ in 0..100 {
+			assert!(conns.open(ONE, None, None).is_ok());
+		}
+		assert_eq!(conns.total, 100);
+		assert_eq!(conns.addrs.get("10.0.0.1"), Some(&100));
+	}
+
+	#[test]
+	fn open_above_total_limit() {
+		let mut conns = Conns::default();
+		assert!(conns.open(ONE, Some(2), None).is_ok());
+		assert!(conns.open(TWO, Some(2), None).is_ok());
+		assert!(matches!(conns.open(None, Some(2), None), Err(Error::TooManyConnections)));
+		// A closed connection frees its slot
+		conns.close(ONE);
+		assert!(conns.open(None, Some(2), None).is_ok());
+	}
+
+	#[test]
+	fn open_above_address_limit() {
+		let mut conns = Conns::default();
+		assert!(conns.open(ONE, None, Some(1)).is_ok());
+		assert!(matches!(conns.open(ONE, None, Some(1)), Err(Error::TooManyConnections)));
+		assert!(conns.open(TWO, None, Some(1)).is_ok());
+		// Connections without an address are only limited in total
+		assert!(conns.open(None, None, Some(1)).is_ok());
+		assert!(conns.open(None, None, Some(1)).is_ok());
+	}
+
+	#[test]
+	fn close_removes_address() {
+		let mut conns = Conns::default();
+		assert!(conns.open(ONE, None, None).is_ok());
+		assert!(conns.open(ONE, None, None).is_ok());
+		conns.close(ONE);
+		assert_eq!(conns.addrs.get("10.0.0.1"), Some(&1));
+		conns.close(ONE);
+		assert_eq!(conns.total, 0);
+		assert!(conns.addrs.is_empty());
+	}
+}
@@ -0,0 +1,178 @@
+use crate::cli::Config;
+use crate::cli::CF;
+use crate::err::Error;
+use http::header::{HeaderValue, SET_COOKIE};
+use http::Method;
+use surrealdb::Auth;
+use surrealdb::Session;
+use warp::Reply;
+
+// The name of the signed session cookie
+pub const SESSION: &str = "surreal_session";
+// The name of the CSRF token cookie
+pub const CSRF: &str = "surreal_csrf";
+// The header used to send the CSRF token
+pub const CSRF_HEADER: &str = "x-csrf-token";
+
+// Build the session and CSRF cookies for a scope user. The
+// session cookie holds the signed token, which is verified
+// again on each request, so no state is kept on the server.
+pub fn jar(session: &Session, token: &str) -> Vec<String> {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Build the cookies
+	cookies(opt, session, token)
+}
+
+fn cookies(opt: &Config, session: &Session, token: &str) -> Vec<String> {
+	// Only set cookies if they are enabled
+	if !opt.cookies {
+		return vec![];
+	}
+	// Only set cookies for scope users
+	if !matches!(*session.au, Auth::Sc(..)) {
+		return vec![];
+	}
+	// Specify the shared cookie attributes
+	let mut attr = format!("Path=/; SameSite={}", opt.cookie_same_site);
+	if opt.cookie_secure {
+		attr.push_str("; Secure");
+	}
+	// Generate a random CSRF token
+	let csrf = uuid::Uuid::new_v4().simple().to_string();
+	// Return the cookies
+	vec![
+		format!("{}={}; HttpOnly; {}", SESSION, token, attr),
+		format!("{}={}; {}", CSRF, csrf, attr),
+	]
+}
+
+// Add the cookies to the response
+pub fn attach(res: impl Reply, jar: Vec<String>) -> warp::reply::Response {
+	let mut res = res.into_response();
+	for v in jar {
+		if let Ok(v) = HeaderValue::from_str(&v) {
+			res.headers_mut().append(SET_COOKIE, v);
+		}
+	}
+	res
+}
+
+// Check that a cookie authenticated request which changes
+// data also sends the CSRF token from the CSRF cookie in
+// the CSRF header, which other origins are unable to read.
+pub fn csrf(method: &Method, cookie: Option<String>, header: Option<String>) -> Result<(), Error> {
+	match *method {
+		// Safe methods do not need a CSRF token
+		Method::GET | Method::HEAD | Method::OPTIONS => Ok(()),
+		// Unsafe methods need a matching CSRF token
+		_ => match (cookie, header) {
+			(Some(c), Some(h)) if !c.is_empty() && c == h => Ok(()),
+			_ => Err(Error::InvalidCsrf),
+		},
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use warp::http::StatusCode;
+
+	fn config() -> Config {
+		Config {
+			cookies: true,
+			cookie_same_site: String::from("Strict"),
+			cookie_secure: true,
+			..Default::default()
+		}
+	}
+
+	#[test]
+	fn cookies_for_scope_users() {
+		let ses = Session::for_sc("test", "test", "user");
+		let jar = cookies(&config(), &ses, "token");
+		assert_eq!(jar.len(), 2);
+		assert_eq!(jar[0], "surreal_session=token; HttpOnly; Path=/; SameSite=Strict; Secure");
+		assert!(jar[1].starts_with("surreal_csrf="));
+		assert!(jar[1].ends_with("; Path=/; SameSite=Strict; Secure"));
+		assert!(!jar[1].contains("HttpOnly"));
+	}
+
+	#[test]
+	fn cookies_with_random_csrf_token() {
+		let ses = Session::for_sc("test", "test", "user");
+		let one = cookies(&config(), &ses, "token");
+		let two = cookies(&config(), &ses, "token");
+		assert_ne!(one[1], two[1]);
+	}
+
+	#[test]
+	fn cookies_without_secure() {
+		let opt = Config {
+			cookie_secure: false,
+			cookie_same_site: String::from("Lax"),
+			..config()
+		};
+		let ses = Session::for_sc("test", "test", "user");
+		let jar = cookies(&opt, &ses, "token");
+		assert_eq!(jar[0], "surreal_session=token; HttpOnly; Path=/; SameSite=Lax");
+	}
+
+	#[test]
+	fn cookies_disabled() {
+		let opt = Config {
+			cookies: false,
+			..config()
+		};
+		let ses = Session::for_sc("test", "test", "user");
+		assert!(cookies(&opt, &ses, "token").is_empty());
+	}
+
+	#[test]
+	fn cookies_for_other_users() {
+		assert!(cookies(&config(), &Session::for_kv(), "token").is_empty());
+		assert!(cookies(&config(), &Session::for_ns("test"), "token").is_empty());
+		assert!(cookies(&config(), &Session::for_db("test", "test"), "token").is_empty());
+	}
+
+	#[test]
+	fn attach_cookies() {
+		let jar = vec![String::from("one=1; Path=/"), String::from("two=2; Path=/")];
+		let res = attach(StatusCode::OK, jar);
+		let val: Vec<_> = res.headers().get_all(SET_COOKIE).iter().collect();
+		assert_eq!(val, vec!["one=1; Path=/", "two=2; Path=/"]);
+	}
+
+	#[test]
+	fn attach_invalid_cookie() {
+		let jar = vec![String::from("one=1\n"), String::from("two=2")];
+		let res = attach(StatusCode::OK, jar);
+		let val: Vec<_> = res.headers().get_all(SET_COOKIE).iter().collect();
+		assert_eq!(val, vec!["two=2"]);
+	}
+
+	#[test]
+	fn csrf_safe_methods() {
+		assert!(csrf(&Method::GET, None, None).is_ok());
+		assert!(csrf(&Method::HEAD, None, None).is_ok());
+		assert!(csrf(&Method::OPTIONS, None, None).is_ok());
+	}
+
+	#[test]
+	fn csrf_matching_token() {
+		let tok = Some(String::from("abc"));
+		assert!(csrf(&Method::POST, tok.clone(), tok.clone()).is_ok());
+		assert!(csrf(&Method::DELETE, tok.clone(), tok).is_ok());
+	}
+
+	#[test]
+	fn csrf_invalid_token() {
+		let tok = Some(String::from("abc"));
+		let other = Some(String::from("xyz"));
+		let empty = Some(String::new());
+		assert!(matches!(csrf(&Method::POST, tok.clone(), other), Err(Error::InvalidCsrf)));
+		assert!(matches!(csrf(&Method::POST, tok.clone(), None), Err(Error::InvalidCsrf)));
+		assert!(matches!(csrf(&Method::POST, None, tok), Err(Error::InvalidCsrf)));
+		assert!(matches!(csrf(&Method::PUT, empty.clone(), empty), Err(Error::InvalidCsrf)));
+	}
+}
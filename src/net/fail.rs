@@ -1,54 +1,175 @@
 use crate::err::Error;
 use serde::Serialize;
+use std::convert::Infallible;
+use surrealdb::Error as DbError;
+use warp::http::header::{HeaderValue, RETRY_AFTER};
 use warp::http::StatusCode;
+use warp::Reply;
 
 #[derive(Serialize)]
 struct Message {
 	code: u16,
+	error: &'static str,
 	#[serde(skip_serializing_if = "Option::is_none")]
 	details: Option<String>,
 	#[serde(skip_serializing_if = "Option::is_none")]
 	description: Option<String>,
 	#[serde(skip_serializing_if = "Option::is_none")]
 	information: Option<String>,
+	request: String,
 }
 
-pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rejection> {
+pub async fn recover(err: warp::Rejection, id: String) -> Result<impl warp::Reply, Infallible> {
+	// Check if the request was rate limited
+	let retry = match err.find::<Error>() {
+		Some(Error::TooManyRequests(v)) => Some(*v),
+		_ => None,
+	};
+	// Convert the error into a response
+	let mut res = match message(err, id) {
+		Ok(v) => v.into_response(),
+		Err(e) => match e {},
+	};
+	// Specify when a rate limited request can be retried
+	if let Some(v) = retry {
+		res.headers_mut().insert(RETRY_AFTER, HeaderValue::from(v));
+	}
+	// Return the response
+	Ok(res)
+}
+
+fn message(err: warp::Rejection, id: String) -> Result<impl warp::Reply, Infallible> {
 	if let Some(err) = err.find::<Error>() {
 		match err {
 			Error::InvalidAuth => Ok(warp::reply::with_status(
 				warp::reply::json(&Message {
 					code: 403,
+					error: err.code(),
 					details: Some("Authentication failed".to_string()),
 					description: Some("Your authentication details are invalid. Reauthenticate using valid authentication parameters.".to_string()),
 					information: Some(err.to_string()),
+					request: id.clone(),
 				}),
 				StatusCode::FORBIDDEN,
 			)),
+			Error::InvalidCsrf => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 403,
+					error: err.code(),
+					details: Some("CSRF check failed".to_string()),
+					description: Some("Requests authenticated with a session cookie need to send the CSRF token, from the CSRF cookie, in the X-CSRF-Token header.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::FORBIDDEN,
+			)),
+			Error::InvalidOrigin => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 403,
+					error: err.code(),
+					details: Some("Origin not allowed".to_string()),
+					description: Some("Requests and WebSocket connections are only accepted from the configured origins. Connect from an allowed origin.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::FORBIDDEN,
+			)),
+			Error::Jwks(_) => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 502,
+					error: err.code(),
+					details: Some("Key set unavailable".to_string()),
+					description: Some("The keys for verifying the authentication token could not be fetched from the configured JSON Web Key Set endpoint. Retry the request later.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::BAD_GATEWAY,
+			)),
 			Error::InvalidType => Ok(warp::reply::with_status(
 				warp::reply::json(&Message {
 					code: 415,
+					error: err.code(),
 					details: Some("Unsupported media type".to_string()),
 					description: Some("The request needs to adhere to certain constraints. Refer to the documentation for supported content types.".to_string()),
 					information: None,
+					request: id.clone(),
 				}),
 				StatusCode::UNSUPPORTED_MEDIA_TYPE,
 			)),
 			Error::InvalidStorage => Ok(warp::reply::with_status(
 				warp::reply::json(&Message {
 					code: 500,
+					error: err.code(),
 					details: Some("Health check failed".to_string()),
 					description: Some("The database health check for this instance failed. There was an issue with the underlying storage engine.".to_string()),
 					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::INTERNAL_SERVER_ERROR,
+			)),
+			Error::QueryTooLarge => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 413,
+					error: err.code(),
+					details: Some("Payload too large".to_string()),
+					description: Some("The query has exceeded the maximum query length. Refer to the documentation for the request limitations.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::PAYLOAD_TOO_LARGE,
+			)),
+			Error::TooManyConnections => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 503,
+					error: err.code(),
+					details: Some("Too many connections".to_string()),
+					description: Some("The maximum number of WebSocket connections, in total or from this address, are already open. Close an open connection, or retry the connection later.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::SERVICE_UNAVAILABLE,
+			)),
+			Error::TooManyRequests(_) => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 429,
+					error: err.code(),
+					details: Some("Too many requests".to_string()),
+					description: Some("The request rate limit for this namespace and database has been exceeded. Retry the request after the specified delay.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::TOO_MANY_REQUESTS,
+			)),
+			Error::RequestTimeout => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 503,
+					error: err.code(),
+					details: Some("Request timed out".to_string()),
+					description: Some("The request did not complete within the request timeout, and any changes it made have been cancelled. Retry the request, or reduce the work done in a single request.".to_string()),
+					information: Some(err.to_string()),
+					request: id.clone(),
+				}),
+				StatusCode::SERVICE_UNAVAILABLE,
+			)),
+			Error::Db(DbError::Panic(_)) => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 500,
+					error: err.code(),
+					details: Some("Internal server error".to_string()),
+					description: Some("The query encountered an unexpected internal error, and any changes it made have been rolled back. Refer to the server logs using the request id for further information.".to_string()),
+					information: None,
+					request: id.clone(),
 				}),
 				StatusCode::INTERNAL_SERVER_ERROR,
 			)),
 			_ => Ok(warp::reply::with_status(
 				warp::reply::json(&Message {
 					code: 400,
+					error: err.code(),
 					details: Some("Request problems detected".to_string()),
 					description: Some("There is a problem with your request. Refer to the documentation for further information.".to_string()),
 					information: Some(err.to_string()),
+					request: id.clone(),
 				}),
 				StatusCode::BAD_REQUEST,
 			))
@@ -57,9 +178,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 404,
+				error: "NOT_FOUND",
 				details: Some("Requested resource not found".to_string()),
 				description: Some("The requested resource does not exist. Check that you have entered the url correctly.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::NOT_FOUND,
 		))
@@ -67,9 +190,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 412,
+				error: "HEADER_MISSING",
 				details: Some("Request problems detected".to_string()),
 				description: Some("The request appears to be missing a required header. Refer to the documentation for request requirements.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::PRECONDITION_FAILED,
 		))
@@ -77,9 +202,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 413,
+				error: "PAYLOAD_TOO_LARGE",
 				details: Some("Payload too large".to_string()),
 				description: Some("The request has exceeded the maximum payload size. Refer to the documentation for the request limitations.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::PAYLOAD_TOO_LARGE,
 		))
@@ -87,9 +214,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 501,
+				error: "QUERY_STRING_INVALID",
 				details: Some("Not implemented".to_string()),
 				description: Some("The server either does not recognize the query, or it lacks the ability to fulfill the request.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::NOT_IMPLEMENTED,
 		))
@@ -97,9 +226,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 501,
+				error: "HEADER_INVALID",
 				details: Some("Not implemented".to_string()),
 				description: Some("The server either does not recognize a request header, or it lacks the ability to fulfill the request.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::NOT_IMPLEMENTED,
 		))
@@ -107,9 +238,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 405,
+				error: "METHOD_NOT_ALLOWED",
 				details: Some("Requested method not allowed".to_string()),
 				description: Some("The requested http method is not allowed for this resource. Refer to the documentation for allowed methods.".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::METHOD_NOT_ALLOWED,
 		))
@@ -117,9 +250,11 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 		Ok(warp::reply::with_status(
 			warp::reply::json(&Message {
 				code: 500,
+				error: "INTERNAL_ERROR",
 				details: Some("Internal server error".to_string()),
 				description: Some("There was a problem with our servers, and we have been notified. Refer to the documentation for further information".to_string()),
 				information: None,
+				request: id.clone(),
 			}),
 			StatusCode::INTERNAL_SERVER_ERROR,
 		))
@@ -1,6 +1,11 @@
+use crate::cli::CF;
 use crate::cnf::PKG_NAME;
 use crate::cnf::PKG_VERS;
 use crate::cnf::SERVER_NAME;
+use crate::err::Error;
+use crate::net::origin;
+use crate::net::request::REQUEST_ID;
+use warp::Filter;
 
 const ID: &str = "ID";
 const NS: &str = "NS";
@@ -18,24 +23,47 @@ pub fn server() -> warp::filters::reply::WithHeader {
 }
 
 pub fn cors() -> warp::filters::cors::Builder {
-	warp::cors()
-		.max_age(86400)
-		.allow_any_origin()
-		.allow_methods(vec![
-			http::Method::GET,
-			http::Method::PUT,
-			http::Method::POST,
-			http::Method::PATCH,
-			http::Method::DELETE,
-			http::Method::OPTIONS,
-		])
-		.allow_headers(vec![
-			http::header::ACCEPT,
-			http::header::AUTHORIZATION,
-			http::header::CONTENT_TYPE,
-			http::header::ORIGIN,
-			NS.parse().unwrap(),
-			DB.parse().unwrap(),
-			ID.parse().unwrap(),
-		])
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Specify the allowed methods
+	let cors = warp::cors().max_age(86400).allow_methods(opt.methods.iter().map(|v| v.as_str()));
+	// Specify the allowed origins, where wildcard
+	// origins are checked by the `origin` filter
+	let cors = match opt.origins.iter().any(|v| v.contains('*')) {
+		true => cors.allow_any_origin(),
+		false => cors.allow_origins(opt.origins.iter().map(|v| v.as_str())),
+	};
+	// Specify whether credentials are allowed
+	let cors = cors.allow_credentials(opt.credentials);
+	// Specify the allowed headers, which always
+	// include the custom database headers
+	cors.allow_headers(vec![
+		http::header::ACCEPT.as_str(),
+		http::header::AUTHORIZATION.as_str(),
+		http::header::CONTENT_TYPE.as_str(),
+		http::header::ORIGIN.as_str(),
+		NS,
+		DB,
+		ID,
+		REQUEST_ID,
+	])
+	.allow_headers(opt.headers.iter().map(|v| v.as_str()))
+	.expose_headers(vec![REQUEST_ID])
+}
+
+/// Reject requests from an origin which is not allowed
+pub fn origin() -> impl Filter<Extract = (), Error = warp::Rejection> + Clone {
+	warp::header::optional::<String>(http::header::ORIGIN.as_str()).and_then(check).untuple_one()
+}
+
+async fn check(origin: Option<String>) -> Result<(), warp::Rejection> {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Check the origin against the allowed origins
+	match origin {
+		Some(v) if !origin::allowed(&opt.origins, &v) => {
+			Err(warp::reject::custom(Error::InvalidOrigin))
+		}
+		_ => Ok(()),
+	}
 }
@@ -0,0 +1,40 @@
+use crate::cli::CF;
+use crate::err::Error;
+
+/// Check a query against the configured maximum query length
+pub fn check(sql: &str) -> Result<(), Error> {
+	within(sql, CF.get().unwrap().max_query)
+}
+
+// Check a query against a maximum length in bytes
+fn within(sql: &str, max: usize) -> Result<(), Error> {
+	match sql.len() > max {
+		true => Err(Error::QueryTooLarge),
+		false => Ok(()),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn within_limit() {
+		assert!(within("", 0).is_ok());
+		assert!(within("INFO FOR KV;", 12).is_ok());
+		assert!(within("INFO FOR KV;", 100).is_ok());
+	}
+
+	#[test]
+	fn exceeds_limit() {
+		assert!(matches!(within("INFO FOR KV;", 11), Err(Error::QueryTooLarge)));
+		assert!(matches!(within("x", 0), Err(Error::QueryTooLarge)));
+	}
+
+	#[test]
+	fn counts_bytes() {
+		assert!(within("ü", 2).is_ok());
+		assert!(within("ü", 1).is_err());
+	}
+}
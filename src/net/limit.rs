@@ -0,0 +1,258 @@
+use crate::cli::Config;
+use crate::cli::CF;
+use crate::err::Error;
+use once_cell::sync::Lazy;
+use std::collections::HashMap;
+use std::hash::Hash;
+use std::net::SocketAddr;
+use std::sync::Mutex;
+use std::time::Duration;
+use std::time::Instant;
+use surrealdb::Session;
+
+// The number of buckets above which refilled buckets are removed
+const MAX_BUCKETS: usize = 10_000;
+
+// The rate limit buckets for each namespace and database
+static BUCKETS: Lazy<Mutex<HashMap<(String, String), Bucket>>> =
+	Lazy::new(|| Mutex::new(HashMap::new()));
+
+// The signup rate limit buckets for each scope and client IP
+static SIGNUPS: Lazy<Mutex<HashMap<(String, String, String, String), Bucket>>> =
+	Lazy::new(|| Mutex::new(HashMap::new()));
+
+struct Bucket {
+	// The number of requests which can currently be made
+	tokens: f64,
+	// The time at which the bucket was last refilled
+	last: Instant,
+	// The time at which the bucket will be completely refilled
+	full: Instant,
+}
+
+/// Take a request from the rate limit of the selected namespace and database
+pub fn check(session: &Session) -> Result<(), Error> {
+	// Root users are never rate limited
+	if session.au.is_kv() {
+		return Ok(());
+	}
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Get the selected namespace and database
+	let ns = session.ns.clone().unwrap_or_default();
+	let db = session.db.clone().unwrap_or_default();
+	// Get the rate limit for this namespace
+	let rate = match rate(opt, &ns) {
+		Some(v) => v,
+		None => return Ok(()),
+	};
+	// Calculate the bucket size and refill rate
+	let size = opt.burst.unwrap_or(rate) as f64;
+	let rate = rate as f64;
+	// Lock the rate limit buckets
+	let mut buckets = BUCKETS.lock().unwrap();
+	// Take a request from the bucket
+	take(&mut buckets, (ns, db), size, rate, Instant::now())
+}
+
+// Get the rate limit of a namespace, falling back to the default rate limit
+fn rate(opt: &Config, ns: &str) -> Option<usize> {
+	match opt.rates.iter().find(|(v, _)| v == ns) {
+		Some((_, v)) => Some(*v),
+		None => opt.rate,
+	}
+}
+
+/// Take a signup from the signup rate limit of a scope for a client IP
+///
+/// The rate limit allows `count` signups in each `every` period, from
+/// each client IP address, and is configured with the scope definition.
+pub fn signup(
+	session: &Session,
+	ns: &str,
+	db: &str,
+	sc: &str,
+	count: u64,
+	every: Duration,
+) -> Result<(), Error> {
+	// Get the client IP address, without the port
+	let ip = host(session.ip.as_deref());
+	// Signups are disabled without an allowance
+	if count == 0 || every.is_zero() {
+		return Err(Error::TooManyRequests(every.as_secs().max(1)));
+	}
+	// Calculate the bucket size and refill rate
+	let size = count as f64;
+	let rate = size / every.as_secs_f64();
+	// Lock the signup rate limit buckets
+	let mut buckets = SIGNUPS.lock().unwrap();
+	// Take a signup from the bucket
+	let key = (ns.to_owned(), db.to_owned(), sc.to_owned(), ip);
+	take(&mut buckets, key, size, rate, Instant::now())
+}
+
+// Get the IP address of a client socket address
+fn host(addr: Option<&str>) -> String {
+	match addr {
+		Some(v) => match v.parse::<SocketAddr>() {
+			Ok(v) => v.ip().to_string(),
+			Err(_) => v.to_owned(),
+		},
+		None => String::new(),
+	}
+}
+
+// Take a request from a rate limit bucket, refilling it for the elapsed time
+fn take<K>(
+	buckets: &mut HashMap<K, Bucket>,
+	key: K,
+	size: f64,
+	rate: f64,
+	now: Instant,
+) -> Result<(), Error>
+where
+	K: Eq + Hash,
+{
+	// Remove any buckets which have completely refilled
+	if buckets.len() >= MAX_BUCKETS {
+		buckets.retain(|_, v| v.full > now);
+	}
+	// Fetch the bucket for this key
+	let bucket = buckets.entry(key).or_insert(Bucket {
+		tokens: size,
+		last: now,
+		full: now,
+	});
+	// Refill the bucket for the elapsed time
+	let elapsed = now.duration_since(bucket.last).as_secs_f64();
+	bucket.tokens = (bucket.tokens + elapsed * rate).min(size);
+	bucket.last = now;
+	// Check if a request can be made
+	if bucket.tokens >= 1.0 {
+		bucket.tokens -= 1.0;
+		bucket.full = now + Duration::from_secs_f64((size - bucket.tokens) / rate);
+		Ok(())
+	} else {
+		let wait = ((1.0 - bucket.tokens) / rate).ceil() as u64;
+		Err(Error::TooManyRequests(wait.max(1)))
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	const SEC: Duration = Duration::from_secs(1);
+
+	#[test]
+	fn rate_for_namespace() {
+		let opt = Config {
+			rate: Some(10),
+			rates: vec![(String::from("test"), 100)],
+			..Default::default()
+		};
+		assert_eq!(rate(&opt, "test"), Some(100));
+		assert_eq!(rate(&opt, "other"), Some(10));
+		assert_eq!(rate(&Config::default(), "test"), None);
+	}
+
+	#[test]
+	fn take_within_burst() {
+		let now = Instant::now();
+		let mut buckets = HashMap::new();
+		for _ in 0..5 {
+			assert!(take(&mut buckets, "test", 5.0, 1.0, now).is_ok());
+		}
+		assert!(matches!(
+			take(&mut buckets, "test", 5.0, 1.0, now),
+			Err(Error::TooManyRequests(1))
+		));
+	}
+
+	#[test]
+	fn take_reports_wait() {
+		let now = Instant::now();
+		let mut buckets = HashMap::new();
+		assert!(take(&mut buckets, "test", 1.0, 0.1, now).is_ok());
+		assert!(matches!(
+			take(&mut buckets, "test", 1.0, 0.1, now),
+			Err(Error::TooManyRequests(10))
+		));
+	}
+
+	#[test]
+	fn take_after_refill() {
+		let now = Instant::now();
+		let mut buckets = HashMap::new();
+		assert!(take(&mut buckets, "test", 2.0, 1.0, now).is_ok());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, now).is_ok());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, now).is_err());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, now + SEC).is_ok());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, now + SEC).is_err());
+		// The bucket never fills above its size
+		let later = now + SEC * 60;
+		assert!(take(&mut buckets, "test", 2.0, 1.0, later).is_ok());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, later).is_ok());
+		assert!(take(&mut buckets, "test", 2.0, 1.0, later).is_err());
+	}
+
+	#[test]
+	fn take_for_each_key() {
+		let now = Instant::now();
+		let mut buckets = HashMap::new();
+		assert!(take(&mut buckets, "one", 1.0, 1.0, now).is_ok());
+		assert!(take(&mut buckets, "one", 1.0, 1.0, now).is_err());
+		assert!(take(&mut buckets, "two", 1.0, 1.0, now).is_ok());
+	}
+
+	#[test]
+	fn take_removes_full_buckets() {
+		let now = Instant::now();
+		let mut buckets = HashMap::new();
+		for i in 0..MAX_BUCKETS {
+			assert!(take(&mut buckets, i, 1.0, 1.0, now).is_ok());
+		}
+		assert_eq!(buckets.len(), MAX_BUCKETS);
+		assert!(take(&mut buckets, MAX_BUCKETS, 1.0, 1.0, now + SEC).is_ok());
+		assert_eq!(buckets.len(), 1);
+	}
+
+	#[test]
+	fn signup_without_allowance() {
+		let ses = Session::for_sc("test", "test", "user");
+		assert!(matches!(
+			signup(&ses, "test", "test", "user", 0, SEC * 60),
+			Err(Error::TooManyRequests(60))
+		));
+		assert!(matches!(
+			signup(&ses, "test", "test", "user", 5, Duration::ZERO),
+			Err(Error::TooManyRequests(1))
+		));
+	}
+	#[test]
+	fn host_without_port() {
+		assert_eq!(host(Some("10.0.0.1:54321")), "10.0.0.1");
+		assert_eq!(host(Some("[::1]:54321")), "::1");
+		assert_eq!(host(Some("10.0.0.1")), "10.0.0.1");
+		assert_eq!(host(None), "");
+	}
+
+	#[test]
+	fn signup_from_each_address() {
+		let ses = |ip: &str| Session {
+			ip: Some(ip.to_owned()),
+			..Default::default()
+		};
+		let every = SEC * 60;
+		// Each new connection uses a different source port
+		assert!(signup(&ses("10.0.0.1:50001"), "test", "test", "ports", 2, every).is_ok());
+		assert!(signup(&ses("10.0.0.1:50002"), "test", "test", "ports", 2, every).is_ok());
+		assert!(matches!(
+			signup(&ses("10.0.0.1:50003"), "test", "test", "ports", 2, every),
+			Err(Error::TooManyRequests(_))
+		));
+		// Other addresses are not limited
+		assert!(signup(&ses("10.0.0.2:50001"), "test", "test", "ports", 2, every).is_ok());
+	}
+}
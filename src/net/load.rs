@@ -0,0 +1,124 @@
+use crate::cli::CF;
+use crate::cnf::IMPORT_BATCH_SIZE;
+use crate::cnf::MAX_IMPORT_BATCH_SIZE;
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::session;
+use crate::net::LOG;
+use bytes::Buf;
+use bytes::Bytes;
+use futures::Stream;
+use futures::StreamExt;
+use hyper::body::Body;
+use hyper::body::Sender;
+use serde::Deserialize;
+use surrealdb::load::Batch;
+use surrealdb::load::Format;
+use surrealdb::sql::Value;
+use surrealdb::Session;
+use warp::http;
+use warp::Filter;
+
+#[derive(Default, Deserialize, Debug, Clone)]
+struct Query {
+	pub batch: Option<usize>,
+}
+
+pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejection> + Clone {
+	warp::path("import")
+		.and(warp::path::param::<String>())
+		.and(warp::path::end())
+		.and(warp::post())
+		.and(warp::header::optional::<String>(http::header::CONTENT_TYPE.as_str()))
+		.and(warp::query())
+		.and(warp::body::stream())
+		.and(session::build())
+		.and_then(handler)
+}
+
+async fn handler(
+	table: String,
+	kind: Option<String>,
+	query: Query,
+	body: impl Stream<Item = Result<impl Buf, warp::Error>> + Send + 'static,
+	session: Session,
+) -> Result<impl warp::Reply, warp::Rejection> {
+	// Check the permissions
+	match session.au.is_db() {
+		true => {
+			// Get the datastore reference
+			let db = DB.get().unwrap();
+			// Get local copy of options
+			let opt = CF.get().unwrap();
+			// Check the format of the rows
+			let fmt = match kind.as_deref() {
+				Some("application/x-ndjson") => Format::Ndjson,
+				Some("application/ndjson") => Format::Ndjson,
+				Some("text/csv") => Format::Csv,
+				// An incorrect content-type was specified
+				_ => return Err(warp::reject::custom(Error::InvalidType)),
+			};
+			// Get the number of rows in each batch
+			let size = query.batch.unwrap_or(IMPORT_BATCH_SIZE).clamp(1, MAX_IMPORT_BATCH_SIZE);
+			// Create a chunked response
+			let (mut chn, bdy) = Body::channel();
+			// Import the rows as they are received
+			tokio::spawn(async move {
+				let mut ldr = db.loader(&session, &table, fmt, size, opt.strict);
+				let mut buf: Vec<u8> = vec![];
+				let mut body = Box::pin(body);
+				while let Some(chunk) = body.next().await {
+					match chunk {
+						Ok(mut chunk) => {
+							// Store the received data
+							while chunk.has_remaining() {
+								let len = chunk.chunk().len();
+								buf.extend_from_slice(chunk.chunk());
+								chunk.advance(len);
+							}
+							// Process each complete line
+							while let Some(pos) = buf.iter().position(|&v| v == b'\n') {
+								let line: Vec<u8> = buf.drain(..=pos).collect();
+								let line = String::from_utf8_lossy(&line[..pos]);
+								if let Some(v) = ldr.push(&line).await {
+									report(&mut chn, v).await;
+								}
+							}
+						}
+						Err(e) => {
+							// The incomplete batch is never imported
+							warn!(target: LOG, "Bulk import request failed: {}", e);
+							return;
+						}
+					}
+				}
+				// Process any final line
+				if !buf.is_empty() {
+					if let Some(v) = ldr.push(&String::from_utf8_lossy(&buf)).await {
+						report(&mut chn, v).await;
+					}
+				}
+				// Process any remaining rows
+				if let Some(v) = ldr.finish().await {
+					report(&mut chn, v).await;
+				}
+			});
+			// Return the chunked body
+			let mut res = warp::reply::Response::new(bdy);
+			res.headers_mut().insert(
+				http::header::CONTENT_TYPE,
+				http::HeaderValue::from_static("application/x-ndjson"),
+			);
+			Ok(res)
+		}
+		// There was an error with permissions
+		_ => Err(warp::reject::custom(Error::InvalidAuth)),
+	}
+}
+
+// Send the result of a batch as a line of JSON
+async fn report(chn: &mut Sender, v: Batch) {
+	if let Ok(v) = serde_json::to_string(&Value::from(v)) {
+		let _ = chn.send_data(Bytes::from(format!("{}\n", v))).await;
+	}
+}
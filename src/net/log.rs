@@ -1,5 +1,8 @@
+use http::{Method, StatusCode, Version};
 use log::Level;
 use std::fmt;
+use std::net::SocketAddr;
+use std::time::Duration;
 
 struct OptFmt<T>(Option<T>);
 
@@ -15,19 +18,26 @@ impl<T: fmt::Display> fmt::Display for OptFmt<T> {
 
 const NAME: &str = "surreal::web";
 
-pub fn write() -> warp::filters::log::Log<impl Fn(warp::filters::log::Info) + Copy> {
-	warp::log::custom(|info| {
-		log!(
-			target: NAME,
-			Level::Info,
-			"{} {} {} {:?} {} \"{}\" {:?}",
-			OptFmt(info.remote_addr()),
-			info.method(),
-			info.path(),
-			info.version(),
-			info.status().as_u16(),
-			OptFmt(info.user_agent()),
-			info.elapsed(),
-		);
-	})
+/// Log a request to the console
+pub fn line(
+	addr: Option<SocketAddr>,
+	method: &Method,
+	path: &str,
+	version: Version,
+	status: StatusCode,
+	agent: Option<&str>,
+	elapsed: Duration,
+) {
+	log!(
+		target: NAME,
+		Level::Info,
+		"{} {} {} {:?} {} \"{}\" {:?}",
+		OptFmt(addr),
+		method,
+		path,
+		version,
+		status.as_u16(),
+		OptFmt(agent),
+		elapsed,
+	);
 }
@@ -0,0 +1,140 @@
+use crate::cli::Config;
+use crate::cli::CF;
+use crate::err::Error;
+use crate::net::remote;
+use crate::net::LOG;
+use std::net::SocketAddr;
+use warp::http;
+use warp::Filter;
+
+/// Log and check the origin of a WebSocket upgrade request
+pub fn check() -> impl Filter<Extract = (), Error = warp::Rejection> + Clone {
+	warp::header::optional::<String>(http::header::ORIGIN.as_str())
+		.and(warp::header::optional::<String>(http::header::SEC_WEBSOCKET_PROTOCOL.as_str()))
+		.and(remote::addr())
+		.and_then(verify)
+		.untuple_one()
+}
+
+async fn verify(
+	origin: Option<String>,
+	protocol: Option<String>,
+	addr: Option<SocketAddr>,
+) -> Result<(), warp::Rejection> {
+	// Format the upgrade details for logging
+	let addr = addr.map_or_else(|| String::from("-"), |v| v.to_string());
+	let origin = origin.unwrap_or_default();
+	let protocol = protocol.unwrap_or_default();
+	// Check the upgrade with the configured options
+	permit(CF.get().unwrap(), &addr, &origin, &protocol).map_err(warp::reject::custom)
+}
+
+// Log the upgrade if enabled, and check the origin against the allowlist
+fn permit(opt: &Config, addr: &str, origin: &str, protocol: &str) -> Result<(), Error> {
+	// Log the upgrade if enabled
+	if opt.ws_log {
+		info!(target: LOG, "WebSocket upgrade from {} with origin '{}' and protocol '{}'", addr, origin, protocol);
+	}
+	// Check the origin against the allowlist
+	match allowed(&opt.ws_origins, origin) {
+		true => Ok(()),
+		false => {
+			warn!(target: LOG, "Rejected WebSocket upgrade from {}, the origin '{}' is not allowed", addr, origin);
+			Err(Error::InvalidOrigin)
+		}
+	}
+}
+
+// Check if an origin is allowed, where an empty allowlist allows any origin
+pub fn allowed(origins: &[String], origin: &str) -> bool {
+	origins.is_empty() || origins.iter().any(|v| matches(v, origin))
+}
+
+// Check if an origin matches an allowed origin, where a wildcard
+// such as https://*.example.com matches any subdomain of the host
+fn matches(allowed: &str, origin: &str) -> bool {
+	match allowed.split_once("://*.") {
+		Some((scheme, host)) => match origin.split_once("://") {
+			Some((s, h)) if s.eq_ignore_ascii_case(scheme) => {
+				let h = h.to_ascii_lowercase();
+				let host = host.to_ascii_lowercase();
+				match h.strip_suffix(&host).and_then(|v| v.strip_suffix('.')) {
+					Some(v) => !v.is_empty(),
+					None => false,
+				}
+			}
+			_ => false,
+		},
+		None => allowed == "*" || allowed.eq_ignore_ascii_case(origin),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	fn list(v: &[&str]) -> Vec<String> {
+		v.iter().map(|v| v.to_string()).collect()
+	}
+
+	#[test]
+	fn allowed_exact_origin() {
+		let origins = list(&["https://example.com", "http://localhost:3000"]);
+		assert!(allowed(&origins, "https://example.com"));
+		assert!(allowed(&origins, "HTTPS://EXAMPLE.COM"));
+		assert!(allowed(&origins, "http://localhost:3000"));
+	}
+
+	#[test]
+	fn allowed_any_origin() {
+		assert!(allowed(&list(&["*"]), "https://example.com"));
+		assert!(allowed(&list(&[]), "https://example.com"));
+	}
+
+	#[test]
+	fn rejected_origin() {
+		let origins = list(&["https://example.com", "http://localhost:3000"]);
+		assert!(!allowed(&origins, "https://other.com"));
+		assert!(!allowed(&origins, "http://example.com"));
+		assert!(!allowed(&origins, "https://example.com.other.com"));
+		assert!(!allowed(&origins, "http://localhost:3001"));
+		assert!(!allowed(&origins, ""));
+	}
+
+	#[test]
+	fn allowed_wildcard_origin() {
+		let origins = list(&["https://*.example.com"]);
+		assert!(allowed(&origins, "https://app.example.com"));
+		assert!(allowed(&origins, "https://a.b.example.com"));
+		assert!(allowed(&origins, "https://App.Example.com"));
+	}
+
+	#[test]
+	fn rejected_wildcard_origin() {
+		let origins = list(&["https://*.example.com"]);
+		assert!(!allowed(&origins, "https://example.com"));
+		assert!(!allowed(&origins, "https://.example.com"));
+		assert!(!allowed(&origins, "https://badexample.com"));
+		assert!(!allowed(&origins, "https://app.example.com.other.com"));
+		assert!(!allowed(&origins, "http://app.example.com"));
+	}
+	#[test]
+	fn permit_any_origin() {
+		let opt = Config::default();
+		assert!(permit(&opt, "127.0.0.1:1234", "", "").is_ok());
+		assert!(permit(&opt, "127.0.0.1:1234", "https://example.com", "json").is_ok());
+	}
+
+	#[test]
+	fn permit_allowed_origin() {
+		let opt = Config {
+			ws_origins: list(&["https://example.com"]),
+			ws_log: true,
+			..Default::default()
+		};
+		assert!(permit(&opt, "-", "https://example.com", "").is_ok());
+		assert!(matches!(permit(&opt, "-", "https://other.com", ""), Err(Error::InvalidOrigin)));
+		assert!(matches!(permit(&opt, "-", "", ""), Err(Error::InvalidOrigin)));
+	}
+}
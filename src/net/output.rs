@@ -1,6 +1,11 @@
+use crate::cli::CF;
+use bytes::Bytes;
 use http::header::{HeaderValue, CONTENT_TYPE};
 use http::StatusCode;
+use hyper::body::Body;
 use serde::Serialize;
+use surrealdb::channel::Receiver;
+use surrealdb::sql::serde::{beg_safe_integer_serialization, end_safe_integer_serialization};
 
 pub enum Output {
 	None,
@@ -9,6 +14,7 @@ pub enum Output {
 	Json(Vec<u8>), // JSON
 	Cbor(Vec<u8>), // CBOR
 	Pack(Vec<u8>), // MessagePack
+	Ndjson(Body),  // Newline delimited JSON
 }
 
 pub fn none() -> Output {
@@ -23,7 +29,7 @@ pub fn json<T>(val: &T) -> Output
 where
 	T: Serialize,
 {
-	match serde_json::to_vec(val) {
+	match encode(val) {
 		Ok(v) => Output::Json(v),
 		Err(_) => Output::Fail,
 	}
@@ -49,6 +55,45 @@ where
 	}
 }
 
+pub fn ndjson<T>(rcv: Receiver<T>) -> Output
+where
+	T: Serialize + Send + 'static,
+{
+	// Create a chunked response
+	let (mut chn, bdy) = Body::channel();
+	// Write each value as a separate line
+	tokio::spawn(async move {
+		while let Ok(v) = rcv.recv().await {
+			let mut v = match encode(&v) {
+				Ok(v) => v,
+				Err(_) => break,
+			};
+			v.push(b'\n');
+			if chn.send_data(Bytes::from(v)).await.is_err() {
+				break;
+			}
+		}
+	});
+	// Return the chunked body
+	Output::Ndjson(bdy)
+}
+
+/// Serialize a value to JSON, with large integers as strings if enabled
+pub fn encode<T>(val: &T) -> Result<Vec<u8>, serde_json::Error>
+where
+	T: Serialize,
+{
+	match CF.get().unwrap().safe_integers {
+		true => {
+			beg_safe_integer_serialization();
+			let res = serde_json::to_vec(val);
+			end_safe_integer_serialization();
+			res
+		}
+		false => serde_json::to_vec(val),
+	}
+}
+
 impl warp::Reply for Output {
 	fn into_response(self) -> warp::reply::Response {
 		match self {
@@ -76,6 +121,12 @@ impl warp::Reply for Output {
 				res.headers_mut().insert(CONTENT_TYPE, con);
 				res
 			}
+			Output::Ndjson(v) => {
+				let mut res = warp::reply::Response::new(v);
+				let con = HeaderValue::from_static("application/x-ndjson");
+				res.headers_mut().insert(CONTENT_TYPE, con);
+				res
+			}
 			Output::None => StatusCode::OK.into_response(),
 			Output::Fail => StatusCode::INTERNAL_SERVER_ERROR.into_response(),
 		}
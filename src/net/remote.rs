@@ -0,0 +1,14 @@
+use std::convert::Infallible;
+use std::net::SocketAddr;
+use warp::Filter;
+
+/// The client address of a connection which is accepted outside of warp
+#[derive(Clone, Copy, Debug)]
+pub struct Remote(pub SocketAddr);
+
+/// Extract the client address of the request
+pub fn addr() -> impl Filter<Extract = (Option<SocketAddr>,), Error = Infallible> + Clone {
+	warp::filters::addr::remote()
+		.and(warp::ext::optional::<Remote>())
+		.map(|addr: Option<SocketAddr>, remote: Option<Remote>| addr.or(remote.map(|v| v.0)))
+}
@@ -0,0 +1,96 @@
+use crate::net::fail;
+use http::header::HeaderValue;
+use uuid::Uuid;
+use warp::reply::Response;
+use warp::Filter;
+use warp::Reply;
+
+// The header used for propagating request ids
+pub const REQUEST_ID: &str = "x-request-id";
+
+// The maximum length of a client provided request id
+const MAX_LENGTH: usize = 128;
+
+pub fn id() -> impl Filter<Extract = (String,), Error = std::convert::Infallible> + Clone {
+	warp::header::optional::<String>(REQUEST_ID).map(|id: Option<String>| pick(id.as_deref()))
+}
+
+/// Select the id for a request from its request id header
+pub fn pick(id: Option<&str>) -> String {
+	match id {
+		// Use the request id provided by the client
+		Some(v) if valid(v) => v.to_owned(),
+		// Otherwise generate a new request id
+		_ => Uuid::new_v4().to_string(),
+	}
+}
+
+pub async fn reply(id: String, res: Result<impl Reply, warp::Rejection>) -> Response {
+	// Catch any request errors
+	let mut res = match res {
+		Ok(v) => v.into_response(),
+		Err(e) => match fail::recover(e, id.clone()).await {
+			Ok(v) => v.into_response(),
+			Err(e) => match e {},
+		},
+	};
+	// Echo the request id in the response
+	if let Ok(v) = HeaderValue::from_str(&id) {
+		res.headers_mut().insert(REQUEST_ID, v);
+	}
+	// Return the response
+	res
+}
+
+fn valid(id: &str) -> bool {
+	!id.is_empty()
+		&& id.len() <= MAX_LENGTH
+		&& id.chars().all(|c| c.is_ascii_alphanumeric() || c == '-' || c == '_' || c == '.')
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use warp::http::StatusCode;
+
+	#[test]
+	fn pick_client_request_id() {
+		assert_eq!(pick(Some("abc-123_def.456")), "abc-123_def.456");
+		let id = "a".repeat(MAX_LENGTH);
+		assert_eq!(pick(Some(&id)), id);
+	}
+
+	#[test]
+	fn pick_generated_request_id() {
+		let id = pick(None);
+		assert!(Uuid::parse_str(&id).is_ok());
+		assert!(valid(&id));
+		assert_ne!(pick(None), id);
+	}
+
+	#[test]
+	fn pick_invalid_request_id() {
+		let long = "a".repeat(MAX_LENGTH + 1);
+		for id in ["", "with space", "new\nline", "quote\"", "ünicode", long.as_str()] {
+			let res = pick(Some(id));
+			assert_ne!(res, id);
+			assert!(Uuid::parse_str(&res).is_ok());
+		}
+	}
+
+	#[tokio::test]
+	async fn reply_with_request_id() {
+		let res = reply(String::from("abc"), Ok(StatusCode::OK)).await;
+		assert_eq!(res.status(), StatusCode::OK);
+		assert_eq!(res.headers().get(REQUEST_ID).unwrap(), "abc");
+	}
+
+	#[tokio::test]
+	async fn request_id_filter() {
+		let filter = id();
+		let res = warp::test::request().header(REQUEST_ID, "abc").filter(&filter).await.unwrap();
+		assert_eq!(res, "abc");
+		let res = warp::test::request().filter(&filter).await.unwrap();
+		assert!(Uuid::parse_str(&res).is_ok());
+	}
+}
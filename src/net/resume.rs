@@ -0,0 +1,79 @@
+use once_cell::sync::Lazy;
+use rand::distributions::Alphanumeric;
+use rand::Rng;
+use std::collections::BTreeMap;
+use std::collections::HashMap;
+use std::sync::Mutex;
+use surrealdb::sql::Value;
+use surrealdb::Session;
+
+// The state of closed connections which can still be resumed
+static PARKED: Lazy<Mutex<HashMap<String, Parked>>> = Lazy::new(|| Mutex::new(HashMap::new()));
+
+/// The state of a closed WebSocket connection
+pub struct Parked {
+	pub session: Session,
+	pub vars: BTreeMap<String, Value>,
+	pub lives: Vec<Value>,
+}
+
+/// Generate a new resumption token for a connection
+pub fn token() -> String {
+	rand::thread_rng().sample_iter(&Alphanumeric).take(64).map(char::from).collect()
+}
+
+/// Store the state of a closed connection until it is resumed
+pub fn park(token: &str, state: Parked) {
+	PARKED.lock().unwrap().insert(token.to_owned(), state);
+}
+
+/// Take the state of a closed connection, so that it can only be resumed once
+pub fn take(token: &str) -> Option<Parked> {
+	PARKED.lock().unwrap().remove(token)
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	fn parked() -> Parked {
+		Parked {
+			session: Session::for_kv().with_ns("test").with_db("test"),
+			vars: map! { String::from("name") => Value::from("test") },
+			lives: vec![],
+		}
+	}
+
+	#[test]
+	fn token_is_random() {
+		let one = token();
+		let two = token();
+		assert_eq!(one.len(), 64);
+		assert!(one.chars().all(|c| c.is_ascii_alphanumeric()));
+		assert_ne!(one, two);
+	}
+
+	#[test]
+	fn take_parked_state() {
+		let token = token();
+		park(&token, parked());
+		let v = take(&token).unwrap();
+		assert_eq!(v.session.ns.as_deref(), Some("test"));
+		assert_eq!(v.vars.get("name"), Some(&Value::from("test")));
+	}
+
+	#[test]
+	fn take_parked_state_once() {
+		let token = token();
+		park(&token, parked());
+		assert!(take(&token).is_some());
+		assert!(take(&token).is_none());
+	}
+
+	#[test]
+	fn take_unknown_token() {
+		assert!(take(&token()).is_none());
+		assert!(take("").is_none());
+	}
+}
@@ -2,22 +2,40 @@ use crate::cli::CF;
 use crate::cnf::MAX_CONCURRENT_CALLS;
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::alive::Alive;
+use crate::net::conn::Conn;
+use crate::net::length;
+use crate::net::limit;
+use crate::net::origin;
+use crate::net::remote;
+use crate::net::resume;
 use crate::net::session;
+use crate::net::signal;
+use crate::net::size;
+use crate::net::version;
 use crate::net::LOG;
 use crate::rpc::args::Take;
+use crate::rpc::debug;
 use crate::rpc::paths::{ID, METHOD, PARAMS};
 use crate::rpc::res::Failure;
 use crate::rpc::res::Response;
 use futures::{SinkExt, StreamExt};
 use std::collections::BTreeMap;
+use std::net::SocketAddr;
 use std::sync::Arc;
+use std::time::Duration;
 use surrealdb::channel;
 use surrealdb::channel::Sender;
+use surrealdb::sql::Array;
 use surrealdb::sql::Object;
 use surrealdb::sql::Strand;
 use surrealdb::sql::Value;
+use surrealdb::Auth;
+use surrealdb::Error as DbError;
 use surrealdb::Session;
 use tokio::sync::RwLock;
+use tokio::sync::{OwnedSemaphorePermit, Semaphore};
+use tokio::time::{interval_at, sleep_until, Instant};
 use warp::ws::{Message, WebSocket, Ws};
 use warp::Filter;
 
@@ -25,11 +43,26 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 	warp::path("rpc")
 		.and(warp::path::end())
 		.and(warp::ws())
+		.and(origin::check())
 		.and(session::build())
-		.map(|ws: Ws, session: Session| ws.on_upgrade(move |ws| socket(ws, session)))
+		.and(remote::addr())
+		.and_then(upgrade)
 }
 
-async fn socket(ws: WebSocket, session: Session) {
+async fn upgrade(
+	ws: Ws,
+	session: Session,
+	addr: Option<SocketAddr>,
+) -> Result<impl warp::Reply, warp::Rejection> {
+	// Take a connection slot before upgrading
+	let conn = Conn::open(addr.map(|v| v.ip().to_string()))?;
+	// Upgrade the connection to a WebSocket
+	Ok(size::limit(ws).on_upgrade(move |ws| socket(ws, session, conn)))
+}
+
+async fn socket(ws: WebSocket, session: Session, conn: Conn) {
+	// Hold the connection slot until the socket is closed
+	let _conn = conn;
 	let rpc = Rpc::new(session);
 	Rpc::serve(rpc, ws).await
 }
@@ -37,6 +70,9 @@ async fn socket(ws: WebSocket, session: Session) {
 pub struct Rpc {
 	session: Session,
 	vars: BTreeMap<String, Value>,
+	lives: Vec<Value>,
+	token: String,
+	debug: bool,
 }
 
 impl Rpc {
@@ -44,12 +80,21 @@ impl Rpc {
 	pub fn new(mut session: Session) -> Arc<RwLock<Rpc>> {
 		// Create a new RPC variables store
 		let vars = BTreeMap::new();
+		// Create a new RPC live queries store
+		let lives = Vec::new();
+		// Create a new RPC resumption token
+		let token = resume::token();
+		// Log requests if enabled for all connections
+		let debug = CF.get().unwrap().ws_debug;
 		// Enable real-time live queries
 		session.rt = true;
 		// Create and store the Rpc connection
 		Arc::new(RwLock::new(Rpc {
 			session,
 			vars,
+			lives,
+			token,
+			debug,
 		}))
 	}
 
@@ -59,8 +104,12 @@ impl Rpc {
 		let (chn, mut rcv) = channel::new(MAX_CONCURRENT_CALLS);
 		// Split the socket into send and recv
 		let (mut wtx, mut wrx) = ws.split();
+		// Keep the server running until the socket is closed
+		let guard = signal::Socket::open();
 		// Send messages to the client
 		tokio::task::spawn(async move {
+			// Release the server once all messages are sent
+			let _guard = guard;
 			// Wait for the next message to send
 			while let Some(res) = rcv.next().await {
 				// Send the message to the client
@@ -74,28 +123,132 @@ impl Rpc {
 				}
 			}
 		});
+		// Get local copy of options
+		let opt = CF.get().unwrap();
+		// Limit the number of concurrent calls
+		let calls = Arc::new(Semaphore::new(opt.ws_calls));
+		// Send pings to the client at a regular interval
+		let mut ping = interval_at(Instant::now() + opt.ws_ping, opt.ws_ping);
+		// Store the pong and idle deadlines of the connection
+		let mut alive = Alive::new(opt.ws_pong, opt.ws_idle, Instant::now());
+		// Store the message used to close the connection
+		let mut close = Message::close();
+		// Store whether the server is shutting down
+		let mut stopping = false;
 		// Get messages from the client
-		while let Some(msg) = wrx.next().await {
-			match msg {
-				// We've received a message from the client
-				Ok(msg) => {
-					if msg.is_text() {
-						tokio::task::spawn(Rpc::call(rpc.clone(), msg, chn.clone()));
-					}
+		loop {
+			tokio::select! {
+				// It's time to ping the client
+				_ = ping.tick() => {
+					alive.ping(Instant::now());
+					let _ = chn.send(Message::ping(vec![])).await;
 				}
-				// There was an error receiving the message
-				Err(err) => {
-					// Output the WebSocket error to the logs
-					trace!(target: LOG, "WebSocket error: {:?}", err);
-					// Exit out of the loop
+				// The client did not respond to the ping in time
+				_ = sleep_until(alive.pong().unwrap_or_else(Instant::now)), if alive.pong().is_some() => {
+					trace!(target: LOG, "WebSocket connection missed a pong, closing connection");
+					break;
+				}
+				// The client has not sent any messages for too long
+				_ = sleep_until(alive.idle()) => {
+					trace!(target: LOG, "WebSocket connection is idle, closing connection");
 					break;
 				}
+				// The server is shutting down
+				_ = signal::shutdown() => {
+					trace!(target: LOG, "Server is shutting down, closing connection");
+					// Wait for any in-flight calls to finish
+					let calls = calls.acquire_many(opt.ws_calls as u32);
+					let _ = tokio::time::timeout(opt.shutdown_grace, calls).await;
+					// Tell the client that the server is going away
+					close = Message::close_with(1001u16, "Server shutting down");
+					// Sessions can not be resumed once the server stops
+					stopping = true;
+					break;
+				}
+				// We've received a message from the client
+				msg = wrx.next() => match msg {
+					Some(Ok(msg)) => {
+						// Any message shows the connection is alive
+						alive.received(msg.is_pong(), Instant::now());
+						// Pongs need no further processing
+						if msg.is_pong() {
+							continue;
+						}
+						// Process the RPC request
+						if msg.is_text() {
+							tokio::task::spawn(Rpc::call(rpc.clone(), msg, chn.clone(), calls.clone()));
+						}
+						// The client closed the connection
+						if msg.is_close() {
+							break;
+						}
+					}
+					// There was an error receiving the message
+					Some(Err(err)) => {
+						// Output the WebSocket error to the logs
+						trace!(target: LOG, "WebSocket error: {:?}", err);
+						// Tell the client if the message was too large
+						if let Some(msg) = size::exceeded(&err) {
+							close = msg;
+						}
+						// Exit out of the loop
+						break;
+					}
+					// The connection has been closed
+					None => break,
+				},
+			}
+		}
+		// Close the connection to the client
+		let _ = chn.send(close).await;
+		// Keep the session so that the client can resume it
+		if let (Some(grace), false) = (opt.ws_resume, stopping) {
+			return rpc.write().await.park(grace);
+		}
+		// Kill any live queries on this connection
+		rpc.write().await.cleanup().await;
+	}
+
+	// Keep the session state until the resumption grace period ends
+	fn park(&mut self, grace: Duration) {
+		// Store the session state against the resumption token
+		let token = self.token.clone();
+		resume::park(
+			&token,
+			resume::Parked {
+				session: self.session.clone(),
+				vars: std::mem::take(&mut self.vars),
+				lives: std::mem::take(&mut self.lives),
+			},
+		);
+		// Discard the session if it is not resumed in time
+		tokio::task::spawn(async move {
+			tokio::time::sleep(grace).await;
+			if let Some(v) = resume::take(&token) {
+				trace!(target: LOG, "WebSocket session was not resumed, discarding session");
+				let mut rpc = Rpc {
+					session: v.session,
+					vars: v.vars,
+					lives: v.lives,
+					token,
+					debug: false,
+				};
+				rpc.cleanup().await;
+			}
+		});
+	}
+
+	// Kill all live queries started on this connection
+	async fn cleanup(&mut self) {
+		for id in std::mem::take(&mut self.lives) {
+			if let Err(e) = self.kill(id).await {
+				trace!(target: LOG, "Unable to kill live query: {}", e);
 			}
 		}
 	}
 
 	// Call RPC methods from the WebSocket
-	async fn call(rpc: Arc<RwLock<Rpc>>, msg: Message, chn: Sender<Message>) {
+	async fn call(rpc: Arc<RwLock<Rpc>>, msg: Message, chn: Sender<Message>, lim: Arc<Semaphore>) {
 		// Clone the RPC
 		let rpc = rpc.clone();
 		// Convert the message
@@ -124,9 +277,34 @@ impl Rpc {
 			Value::Array(v) => v,
 			_ => return Response::failure(id, Failure::INVALID_REQUEST).send(chn).await,
 		};
+		// Wait for a free call slot, or reject the call
+		let _slot = match slot(lim, CF.get().unwrap().ws_reject).await {
+			Ok(v) => v,
+			Err(err) => return Response::failure(id, err).send(chn).await,
+		};
+		// Check the request rate limit
+		if let Err(e) = limit::check(&rpc.read().await.session) {
+			return Response::failure(id, Failure::from(e)).send(chn).await;
+		}
+		// Describe the request if debug logging is enabled
+		let log = rpc.read().await.debug.then(|| debug::request(&method, &params));
 		// Match the method to a function
 		let res = match &method[..] {
 			"ping" => Ok(Value::True),
+			"version" => match params.len() {
+				0 => Ok(version::info()),
+				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
+			},
+			"resume" => match params.take_one() {
+				Value::None => rpc.write().await.resume(None).await,
+				Value::Strand(v) => rpc.write().await.resume(Some(v)).await,
+				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
+			},
+			"debug" => match params.take_one() {
+				Value::True => rpc.write().await.debug(true).await,
+				Value::False => rpc.write().await.debug(false).await,
+				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
+			},
 			"info" => match params.len() {
 				0 => rpc.read().await.info().await,
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
@@ -153,11 +331,11 @@ impl Rpc {
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
 			},
 			"kill" => match params.take_one() {
-				v if v.is_uuid() => rpc.read().await.kill(v).await,
+				v if v.is_uuid() => rpc.write().await.stop(v).await,
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
 			},
 			"live" => match params.take_one() {
-				v if v.is_strand() => rpc.read().await.live(v).await,
+				v if v.is_strand() => rpc.write().await.live(v).await,
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
 			},
 			"let" => match params.take_two() {
@@ -169,8 +347,14 @@ impl Rpc {
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
 			},
 			"query" => match params.take_two() {
-				(Value::Strand(s), o) if o.is_none() => rpc.read().await.query(s).await,
-				(Value::Strand(s), Value::Object(o)) => rpc.read().await.query_with(s, o).await,
+				(Value::Strand(s), o) if o.is_none() => Rpc::query(rpc, s).await,
+				(Value::Strand(s), Value::Object(o)) => Rpc::query_with(rpc, s, o).await,
+				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
+			},
+			"batch" => match params.take_two() {
+				(Value::Array(v), o) if o.is_none() => rpc.read().await.batch(v, false).await,
+				(Value::Array(v), Value::True) => rpc.read().await.batch(v, true).await,
+				(Value::Array(v), Value::False) => rpc.read().await.batch(v, false).await,
 				_ => return Response::failure(id, Failure::INVALID_PARAMS).send(chn).await,
 			},
 			"select" => match params.take_one() {
@@ -211,10 +395,20 @@ impl Rpc {
 			},
 			_ => return Response::failure(id, Failure::METHOD_NOT_FOUND).send(chn).await,
 		};
+		// Log the request and its result status
+		if let Some(req) = log {
+			let rpc = rpc.read().await;
+			let ip = rpc.session.ip.as_deref().unwrap_or("-");
+			let sid = rpc.session.id.as_deref().unwrap_or("-");
+			match &res {
+				Ok(_) => info!(target: LOG, "{} {} {} OK", ip, sid, req),
+				Err(e) => info!(target: LOG, "{} {} {} ERR {}", ip, sid, req, e.code()),
+			}
+		}
 		// Return the final response
 		match res {
 			Ok(v) => Response::success(id, v).send(chn).await,
-			Err(e) => Response::failure(id, Failure::custom(e.to_string())).send(chn).await,
+			Err(e) => Response::failure(id, Failure::from(e)).send(chn).await,
 		}
 	}
 
@@ -223,8 +417,34 @@ impl Rpc {
 	// ------------------------------
 
 	async fn yuse(&mut self, ns: Strand, db: Strand) -> Result<Value, Error> {
-		self.session.ns = Some(ns.0);
-		self.session.db = Some(db.0);
+		// Get a database reference
+		let kvs = DB.get().unwrap();
+		// Get the names as they are stored
+		let ns = kvs.name(&ns);
+		let db = kvs.name(&db);
+		// Check the namespace is allowed
+		match &*self.session.au {
+			Auth::No | Auth::Kv => (),
+			Auth::Ns(v) | Auth::Db(v, _) | Auth::Sc(v, _, _) if kvs.name(v) == ns => (),
+			_ => {
+				return Err(Error::from(DbError::NsNotAllowed {
+					ns,
+				}))
+			}
+		}
+		// Check the database is allowed
+		match &*self.session.au {
+			Auth::No | Auth::Kv | Auth::Ns(_) => (),
+			Auth::Db(_, v) | Auth::Sc(_, v, _) if kvs.name(v) == db => (),
+			_ => {
+				return Err(Error::from(DbError::DbNotAllowed {
+					db,
+				}))
+			}
+		}
+		// Update the selected namespace and database
+		self.session.ns = Some(ns);
+		self.session.db = Some(db);
 		Ok(Value::None)
 	}
 
@@ -244,6 +464,8 @@ impl Rpc {
 
 	async fn invalidate(&mut self) -> Result<Value, Error> {
 		crate::iam::clear::clear(&mut self.session).await?;
+		// Clear the connection variables
+		self.vars.clear();
 		Ok(Value::None)
 	}
 
@@ -252,6 +474,53 @@ impl Rpc {
 		Ok(Value::None)
 	}
 
+	// ------------------------------
+	// Methods for resumption
+	// ------------------------------
+
+	async fn resume(&mut self, token: Option<Strand>) -> Result<Value, Error> {
+		// Restore the state of a previous session
+		let resumed = match token.and_then(|v| resume::take(&v.0)) {
+			Some(v) => {
+				// Keep the details of this connection
+				self.session = Session {
+					ip: self.session.ip.take(),
+					or: self.session.or.take(),
+					id: self.session.id.take(),
+					..v.session
+				};
+				// Restore the connection variables
+				self.vars.extend(v.vars);
+				// Restore the live queries
+				self.lives.extend(v.lives);
+				true
+			}
+			// Unknown or expired tokens start a new session
+			None => false,
+		};
+		// Return the token for resuming this connection
+		Ok(Value::from(map! {
+			String::from("resumed") => Value::from(resumed),
+			String::from("token") => Value::from(self.token.clone()),
+		}))
+	}
+
+	// ------------------------------
+	// Methods for debugging
+	// ------------------------------
+
+	async fn debug(&mut self, enabled: bool) -> Result<Value, Error> {
+		// Only root users can change the debug log
+		if !self.session.au.is_kv() {
+			return Err(Error::from(DbError::QueryPermissions));
+		}
+		// Log the change of setting
+		info!(target: LOG, "WebSocket debug log {}", if enabled { "enabled" } else { "disabled" });
+		// Store the setting for this connection
+		self.debug = enabled;
+		Ok(Value::None)
+	}
+
 	// ------------------------------
 	// Methods for identification
 	// ------------------------------
@@ -314,7 +583,16 @@ impl Rpc {
 		Ok(res)
 	}
 
-	async fn live(&self, tb: Value) -> Result<Value, Error> {
+	async fn stop(&mut self, id: Value) -> Result<Value, Error> {
+		// Kill the live query
+		let res = self.kill(id.clone()).await?;
+		// Stop tracking the live query
+		self.lives.retain(|v| v != &id);
+		// Return the result to the client
+		Ok(res)
+	}
+
+	async fn live(&mut self, tb: Value) -> Result<Value, Error> {
 		// Get a database reference
 		let kvs = DB.get().unwrap();
 		// Get local copy of options
@@ -330,6 +608,8 @@ impl Rpc {
 		let mut res = kvs.execute(sql, &self.session, var, opt.strict).await?;
 		// Extract the first query result
 		let res = res.remove(0).result?;
+		// Track the live query on this connection
+		self.lives.push(res.clone());
 		// Return the result to the client
 		Ok(res)
 	}
@@ -338,36 +618,106 @@ impl Rpc {
 	// Methods for querying
 	// ------------------------------
 
-	async fn query(&self, sql: Strand) -> Result<Value, Error> {
+	async fn query(rpc: Arc<RwLock<Rpc>>, sql: Strand) -> Result<Value, Error> {
 		// Get a database reference
 		let kvs = DB.get().unwrap();
 		// Get local copy of options
 		let opt = CF.get().unwrap();
-		// Specify the query parameters
-		let var = Some(self.vars.clone());
+		// Check the length of the sql query
+		length::check(&sql)?;
+		// Get the connection session and variables
+		let (mut ses, var) = {
+			let rpc = rpc.read().await;
+			(rpc.session.clone(), rpc.vars.clone())
+		};
 		// Execute the query on the database
-		let res = kvs.execute(&sql, &self.session, var, opt.strict).await?;
+		let (res, var) = kvs.execute_with_session(&sql, &mut ses, Some(var), opt.strict).await?;
+		// Store any params and selection on the connection
+		let mut rpc = rpc.write().await;
+		rpc.vars.extend(var);
+		rpc.session.ns = ses.ns;
+		rpc.session.db = ses.db;
 		// Extract the first query result
 		let res = res.into_iter().collect::<Vec<Value>>().into();
 		// Return the result to the client
 		Ok(res)
 	}
 
-	async fn query_with(&self, sql: Strand, mut vars: Object) -> Result<Value, Error> {
+	async fn query_with(rpc: Arc<RwLock<Rpc>>, sql: Strand, vars: Object) -> Result<Value, Error> {
 		// Get a database reference
 		let kvs = DB.get().unwrap();
 		// Get local copy of options
 		let opt = CF.get().unwrap();
-		// Specify the query parameters
-		let var = Some(mrg! { vars.0, &self.vars });
+		// Check the length of the sql query
+		length::check(&sql)?;
+		// Get the connection session and variables
+		let (mut ses, mut var) = {
+			let rpc = rpc.read().await;
+			(rpc.session.clone(), rpc.vars.clone())
+		};
+		// Client variables shadow connection variables
+		let var = Some(mrg! { var, vars.0 });
 		// Execute the query on the database
-		let res = kvs.execute(&sql, &self.session, var, opt.strict).await?;
+		let (res, var) = kvs.execute_with_session(&sql, &mut ses, var, opt.strict).await?;
+		// Store any params and selection on the connection
+		let mut rpc = rpc.write().await;
+		rpc.vars.extend(var);
+		rpc.session.ns = ses.ns;
+		rpc.session.db = ses.db;
 		// Extract the first query result
 		let res = res.into_iter().collect::<Vec<Value>>().into();
 		// Return the result to the client
 		Ok(res)
 	}
 
+	// ------------------------------
+	// Methods for batching
+	// ------------------------------
+
+	async fn batch(&self, items: Array, atomic: bool) -> Result<Value, Error> {
+		// Get a database reference
+		let kvs = DB.get().unwrap();
+		// Get local copy of options
+		let opt = CF.get().unwrap();
+		// Specify the queries and parameters
+		let mut qry = Vec::with_capacity(items.len());
+		for item in items.into_iter() {
+			match item {
+				Value::Object(mut v) => match (v.remove("sql"), v.remove("vars")) {
+					(Some(Value::Strand(sql)), _) if length::check(&sql).is_err() => {
+						return Err(Error::QueryTooLarge)
+					}
+					(Some(Value::Strand(sql)), None) => {
+						qry.push((sql.0, Some(self.vars.clone())));
+					}
+					(Some(Value::Strand(sql)), Some(Value::Object(vars))) => {
+						// Client variables shadow connection variables
+						let mut var = self.vars.clone();
+						qry.push((sql.0, Some(mrg! { var, vars.0 })));
+					}
+					_ => return Err(Error::Request),
+				},
+				_ => return Err(Error::Request),
+			}
+		}
+		// Execute the queries on the database
+		let res = kvs.batch(qry, &self.session, opt.strict, atomic).await?;
+		// Extract the results for each query
+		let res = res
+			.into_iter()
+			.map(|v| match v {
+				Ok(v) => v.into_iter().collect::<Vec<Value>>().into(),
+				Err(e) => Value::Object(Object(map! {
+					String::from("status") => Value::from("ERR"),
+					String::from("detail") => Value::from(e.to_string()),
+				})),
+			})
+			.collect::<Vec<Value>>()
+			.into();
+		// Return the result to the client
+		Ok(res)
+	}
+
 	// ------------------------------
 	// Methods for selecting
 	// ------------------------------
@@ -516,3 +866,208 @@ impl Rpc {
 		Ok(res)
 	}
 }
+
+// Take a call slot, waiting for one to be free unless calls are rejected
+async fn slot(lim: Arc<Semaphore>, reject: bool) -> Result<OwnedSemaphorePermit, Failure> {
+	match reject {
+		true => lim.try_acquire_owned().map_err(|_| Failure::from(Error::TooManyCalls)),
+		false => lim.acquire_owned().await.map_err(|_| Failure::INTERNAL_ERROR),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::cli::Config;
+	use surrealdb::Datastore;
+
+	// Set up the options and datastore shared by the tests
+	async fn init() {
+		CF.get_or_init(Config::default);
+		if DB.get().is_none() {
+			let ds = Datastore::new("memory").await.unwrap().with_case_insensitive_names(true);
+			let _ = DB.set(ds);
+		}
+	}
+
+	// Create an RPC connection with the specified session
+	async fn rpc(session: Session) -> Rpc {
+		init().await;
+		Rpc {
+			session,
+			vars: BTreeMap::new(),
+			lives: Vec::new(),
+			token: resume::token(),
+			debug: false,
+		}
+	}
+
+	#[tokio::test]
+	async fn slot_rejects_above_limit() {
+		let lim = Arc::new(Semaphore::new(2));
+		let one = slot(lim.clone(), true).await;
+		let two = slot(lim.clone(), true).await;
+		assert!(one.is_ok());
+		assert!(two.is_ok());
+		assert!(slot(lim.clone(), true).await.is_err());
+		// A finished call frees its slot
+		drop(one);
+		assert!(slot(lim.clone(), true).await.is_ok());
+	}
+
+	#[tokio::test]
+	async fn slot_waits_above_limit() {
+		let lim = Arc::new(Semaphore::new(1));
+		let one = slot(lim.clone(), false).await;
+		assert!(one.is_ok());
+		// The next call waits while the slot is taken
+		let two = tokio::spawn(slot(lim.clone(), false));
+		tokio::time::sleep(Duration::from_millis(50)).await;
+		assert!(!two.is_finished());
+		// The next call runs once the slot is free
+		drop(one);
+		assert!(two.await.unwrap().is_ok());
+	}
+
+	#[tokio::test]
+	async fn slot_released_on_panic() {
+		let lim = Arc::new(Semaphore::new(1));
+		let res = tokio::spawn({
+			let lim = lim.clone();
+			async move {
+				let _slot = slot(lim, true).await;
+				panic!("Simulated panic");
+			}
+		});
+		assert!(res.await.is_err());
+		assert_eq!(lim.available_permits(), 1);
+	}
+	#[tokio::test]
+	async fn yuse_folds_names() {
+		let mut rpc = rpc(Session::for_kv()).await;
+		rpc.yuse(Strand::from("MyNS"), Strand::from("MyDB")).await.unwrap();
+		assert_eq!(rpc.session.ns.as_deref(), Some("myns"));
+		assert_eq!(rpc.session.db.as_deref(), Some("mydb"));
+	}
+
+	#[tokio::test]
+	async fn yuse_allowed_in_mixed_case() {
+		let mut rpc = rpc(Session::for_db("test", "test")).await;
+		rpc.yuse(Strand::from("Test"), Strand::from("TEST")).await.unwrap();
+		assert_eq!(rpc.session.ns.as_deref(), Some("test"));
+		assert_eq!(rpc.session.db.as_deref(), Some("test"));
+	}
+
+	#[tokio::test]
+	async fn yuse_not_allowed() {
+		let mut rpc = rpc(Session::for_db("test", "test")).await;
+		let res = rpc.yuse(Strand::from("Other"), Strand::from("test")).await;
+		assert!(matches!(res, Err(Error::Db(DbError::NsNotAllowed { .. }))));
+		let res = rpc.yuse(Strand::from("test"), Strand::from("Other")).await;
+		assert!(matches!(res, Err(Error::Db(DbError::DbNotAllowed { .. }))));
+	}
+	// The session of a connection from the specified address
+	fn connection(ip: &str) -> Session {
+		Session {
+			ip: Some(ip.to_owned()),
+			or: Some(format!("http://{}", ip)),
+			id: Some(format!("id-{}", ip)),
+			..Default::default()
+		}
+	}
+
+	// The result of a call to resume a session
+	fn resumed(resumed: bool, token: &str) -> Value {
+		Value::from(map! {
+			String::from("resumed") => Value::from(resumed),
+			String::from("token") => Value::from(token),
+		})
+	}
+
+	#[tokio::test]
+	async fn resume_parked_session() {
+		// Close a connection with an authenticated session
+		let mut old = rpc(Session {
+			ip: Some(String::from("10.0.0.1")),
+			..Session::for_sc("test", "test", "user")
+		})
+		.await;
+		old.vars.insert(String::from("name"), Value::from("test"));
+		old.lives.push(Value::from("live"));
+		old.park(Duration::from_secs(60));
+		// Resume the session on a new connection
+		let mut new = rpc(connection("10.0.0.2")).await;
+		let res = new.resume(Some(Strand::from(old.token.as_str()))).await.unwrap();
+		assert_eq!(res, resumed(true, &new.token));
+		// The authentication, variables, and live queries are restored
+		assert_eq!(*new.session.au, Auth::Sc("test".into(), "test".into(), "user".into()));
+		assert_eq!(new.session.ns.as_deref(), Some("test"));
+		assert_eq!(new.session.db.as_deref(), Some("test"));
+		assert_eq!(new.vars.get("name"), Some(&Value::from("test")));
+		assert_eq!(new.lives, vec![Value::from("live")]);
+		// The details of the new connection are kept
+		assert_eq!(new.session.ip.as_deref(), Some("10.0.0.2"));
+		assert_eq!(new.session.or.as_deref(), Some("http://10.0.0.2"));
+		assert_eq!(new.session.id.as_deref(), Some("id-10.0.0.2"));
+		// The session can only be resumed once
+		let mut other = rpc(connection("10.0.0.3")).await;
+		let res = other.resume(Some(Strand::from(old.token.as_str()))).await.unwrap();
+		assert_eq!(res, resumed(false, &other.token));
+	}
+
+	#[tokio::test]
+	async fn resume_unknown_session() {
+		let mut rpc = rpc(connection("10.0.0.1")).await;
+		let res = rpc.resume(Some(Strand::from("unknown"))).await.unwrap();
+		assert_eq!(res, resumed(false, &rpc.token));
+		assert_eq!(rpc.session, connection("10.0.0.1"));
+		assert!(rpc.vars.is_empty());
+		assert!(rpc.lives.is_empty());
+		let res = rpc.resume(None).await.unwrap();
+		assert_eq!(res, resumed(false, &rpc.token));
+	}
+
+	#[tokio::test]
+	async fn resume_expired_session() {
+		let mut old = rpc(Session {
+			ip: Some(String::from("10.0.0.1")),
+			..Session::for_kv()
+		})
+		.await;
+		old.vars.insert(String::from("name"), Value::from("test"));
+		old.park(Duration::from_millis(10));
+		tokio::time::sleep(Duration::from_millis(100)).await;
+		// The session was discarded after the grace period
+		let mut new = rpc(connection("10.0.0.2")).await;
+		let res = new.resume(Some(Strand::from(old.token.as_str()))).await.unwrap();
+		assert_eq!(res, resumed(false, &new.token));
+		assert_eq!(new.session, connection("10.0.0.2"));
+		assert!(new.vars.is_empty());
+	}
+	#[tokio::test]
+	async fn debug_disabled_by_default() {
+		init().await;
+		let rpc = Rpc::new(Session::for_kv());
+		assert!(!rpc.read().await.debug);
+	}
+
+	#[tokio::test]
+	async fn debug_enabled_for_connection() {
+		let mut one = rpc(Session::for_kv()).await;
+		let two = rpc(Session::for_kv()).await;
+		one.debug(true).await.unwrap();
+		assert!(one.debug);
+		assert!(!two.debug);
+		one.debug(false).await.unwrap();
+		assert!(!one.debug);
+	}
+
+	#[tokio::test]
+	async fn debug_enabled_by_root_only() {
+		let mut rpc = rpc(Session::for_db("test", "test")).await;
+		let res = rpc.debug(true).await;
+		assert!(matches!(res, Err(Error::Db(DbError::QueryPermissions))));
+		assert!(!rpc.debug);
+	}
+}
@@ -1,8 +1,19 @@
+use crate::cli::CF;
+use crate::cnf::TRACER;
 use crate::err::Error;
 use crate::iam::verify::{basic, token};
 use crate::iam::BASIC;
 use crate::iam::TOKEN;
+use crate::net::cookie;
+use crate::net::limit;
+use crate::net::remote;
+use crate::net::trace;
+use http::Method;
+use opentelemetry::global;
+use opentelemetry::trace::{FutureExt, TraceContextExt, Tracer};
+use opentelemetry::Context;
 use std::net::SocketAddr;
+use surrealdb::Auth;
 use surrealdb::Session;
 use warp::Filter;
 
@@ -10,7 +21,7 @@ pub fn build() -> impl Filter<Extract = (Session,), Error = warp::Rejection> + C
 	// Enable on any path
 	let conf = warp::any();
 	// Add remote ip address
-	let conf = conf.and(warp::filters::addr::remote());
+	let conf = conf.and(remote::addr());
 	// Add remote ip address
 	let conf = conf.map(|addr: Option<SocketAddr>| addr.map(|v| v.to_string()));
 	// Add authorization header
@@ -23,6 +34,16 @@ pub fn build() -> impl Filter<Extract = (Session,), Error = warp::Rejection> + C
 	let conf = conf.and(warp::header::optional::<String>("ns"));
 	// Add database header
 	let conf = conf.and(warp::header::optional::<String>("db"));
+	// Add session cookie
+	let conf = conf.and(warp::cookie::optional::<String>(cookie::SESSION));
+	// Add csrf token cookie
+	let conf = conf.and(warp::cookie::optional::<String>(cookie::CSRF));
+	// Add csrf token header
+	let conf = conf.and(warp::header::optional::<String>(cookie::CSRF_HEADER));
+	// Add request method
+	let conf = conf.and(warp::method());
+	// Add trace context headers
+	let conf = conf.and(trace::context());
 	// Process all headers
 	conf.and_then(process)
 }
@@ -34,21 +55,55 @@ async fn process(
 	id: Option<String>,
 	ns: Option<String>,
 	db: Option<String>,
+	sc: Option<String>,
+	ct: Option<String>,
+	ch: Option<String>,
+	mt: Method,
+	cx: Context,
 ) -> Result<Session, warp::Rejection> {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
 	// Create session
 	#[rustfmt::skip]
 	let mut session = Session { ip, or, id, ns, db, ..Default::default() };
+	// Start a trace span for the authentication
+	let span = global::tracer(TRACER).start_with_context("authenticate", &cx);
+	let cx = cx.with_span(span);
 	// Parse the authentication header
-	match au {
-		// Basic authentication data was supplied
-		Some(auth) if auth.starts_with(BASIC) => basic(&mut session, auth).await,
-		// Token authentication data was supplied
-		Some(auth) if auth.starts_with(TOKEN) => token(&mut session, auth).await,
-		// Wrong authentication data was supplied
-		Some(_) => Err(Error::InvalidAuth),
-		// No authentication data was supplied
-		None => Ok(()),
-	}?;
+	let res = async {
+		match au {
+			// Basic authentication data was supplied
+			Some(auth) if auth.starts_with(BASIC) => basic(&mut session, auth).await,
+			// Token authentication data was supplied
+			Some(auth) if auth.starts_with(TOKEN) => token(&mut session, auth).await,
+			// Wrong authentication data was supplied
+			Some(_) => Err(Error::InvalidAuth),
+			// A session cookie was supplied
+			None if opt.cookies && sc.is_some() => match cookie::csrf(&mt, ct, ch) {
+				Ok(_) => token(&mut session, format!("{}{}", TOKEN, sc.unwrap())).await,
+				Err(e) => Err(e),
+			},
+			// No authentication data was supplied
+			None => Ok(()),
+		}
+	}
+	.with_context(cx.clone())
+	.await;
+	// Record any authentication failure
+	if let Err(e) = &res {
+		cx.span().record_error(e);
+	}
+	cx.span().end();
+	// Check the authentication result
+	res?;
+	// Limit unauthenticated requests to the public database
+	if let (Auth::No, Some(ns), Some(db)) = (&*session.au, &opt.public_ns, &opt.public_db) {
+		session.ns = Some(ns.to_owned());
+		session.db = Some(db.to_owned());
+		session.ro = true;
+	}
+	// Check the request rate limit
+	limit::check(&session)?;
 	// Pass the authenticated session through
 	Ok(session)
 }
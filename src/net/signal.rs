@@ -0,0 +1,112 @@
+use crate::net::LOG;
+use once_cell::sync::Lazy;
+use tokio::sync::watch;
+
+// The shutdown state, which is true once the server is shutting down
+static SHUTDOWN: Lazy<watch::Sender<bool>> = Lazy::new(|| watch::channel(false).0);
+
+/// Listen for a shutdown signal, and start shutting down the server
+pub async fn listen() {
+	// Wait for a SIGINT or SIGTERM signal
+	#[cfg(unix)]
+	{
+		use tokio::signal::unix::{signal, SignalKind};
+		let mut term =
+			signal(SignalKind::terminate()).expect("Failed to listen to shutdown signal");
+		tokio::select! {
+			_ = tokio::signal::ctrl_c() => (),
+			_ = term.recv() => (),
+		}
+	}
+	// Wait for a Ctrl-C signal
+	#[cfg(not(unix))]
+	{
+		tokio::signal::ctrl_c().await.expect("Failed to listen to shutdown signal");
+	}
+	// Start shutting down
+	info!(target: LOG, "Received shutdown signal, draining connections");
+	let _ = SHUTDOWN.send(true);
+}
+
+/// Wait until the server has started shutting down
+pub async fn shutdown() {
+	wait(SHUTDOWN.subscribe(), |v| *v).await
+}
+
+// The number of open WebSocket connections
+static SOCKETS: Lazy<watch::Sender<usize>> = Lazy::new(|| watch::channel(0).0);
+
+/// A guard which keeps the server running while a WebSocket connection is open
+pub struct Socket(());
+
+impl Socket {
+	pub fn open() -> Socket {
+		SOCKETS.send_modify(|v| *v += 1);
+		Socket(())
+	}
+}
+
+impl Drop for Socket {
+	fn drop(&mut self) {
+		SOCKETS.send_modify(|v| *v -= 1);
+	}
+}
+
+/// Wait until all WebSocket connections have been closed
+pub async fn closed() {
+	wait(SOCKETS.subscribe(), |v| *v == 0).await
+}
+
+// Wait until a watched value is done, or the sender is dropped
+async fn wait<T>(mut rx: watch::Receiver<T>, done: impl Fn(&T) -> bool) {
+	while !done(&rx.borrow()) {
+		if rx.changed().await.is_err() {
+			break;
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use std::time::Duration;
+	use tokio::time::timeout;
+
+	const WAIT: Duration = Duration::from_millis(50);
+
+	#[tokio::test]
+	async fn wait_until_done() {
+		let (tx, rx) = watch::channel(false);
+		let res = tokio::spawn(wait(rx, |v| *v));
+		tokio::time::sleep(WAIT).await;
+		assert!(!res.is_finished());
+		tx.send(true).unwrap();
+		assert!(timeout(WAIT, res).await.is_ok());
+	}
+
+	#[tokio::test]
+	async fn wait_when_already_done() {
+		let (_tx, rx) = watch::channel(true);
+		assert!(timeout(WAIT, wait(rx, |v| *v)).await.is_ok());
+	}
+
+	#[tokio::test]
+	async fn wait_when_sender_dropped() {
+		let (tx, rx) = watch::channel(false);
+		drop(tx);
+		assert!(timeout(WAIT, wait(rx, |v| *v)).await.is_ok());
+	}
+
+	#[tokio::test]
+	async fn closed_after_sockets_dropped() {
+		let one = Socket::open();
+		let two = Socket::open();
+		let res = tokio::spawn(closed());
+		drop(one);
+		tokio::time::sleep(WAIT).await;
+		assert!(!res.is_finished());
+		drop(two);
+		assert!(timeout(WAIT, res).await.is_ok());
+	}
+}
@@ -1,4 +1,5 @@
 use crate::err::Error;
+use crate::net::cookie;
 use crate::net::output;
 use crate::net::session;
 use bytes::Bytes;
@@ -56,15 +57,21 @@ async fn handler(
 		// The provided value was an object
 		Ok(Value::Object(vars)) => match crate::iam::signup::signup(&mut session, vars).await {
 			// Authentication was successful
-			Ok(v) => match output.as_deref() {
-				Some("application/json") => Ok(output::json(&Success::new(v))),
-				Some("application/cbor") => Ok(output::cbor(&Success::new(v))),
-				Some("application/msgpack") => Ok(output::pack(&Success::new(v))),
-				Some("text/plain") => Ok(output::text(v)),
-				None => Ok(output::text(v)),
-				// An incorrect content-type was requested
-				_ => Err(warp::reject::custom(Error::InvalidType)),
-			},
+			Ok(v) => {
+				// Build any session cookies
+				let jar = cookie::jar(&session, &v);
+				// Output the authentication token
+				match output.as_deref() {
+					Some("application/json") => Ok(output::json(&Success::new(v))),
+					Some("application/cbor") => Ok(output::cbor(&Success::new(v))),
+					Some("application/msgpack") => Ok(output::pack(&Success::new(v))),
+					Some("text/plain") => Ok(output::text(v)),
+					None => Ok(output::text(v)),
+					// An incorrect content-type was requested
+					_ => Err(warp::reject::custom(Error::InvalidType)),
+				}
+				.map(|res| cookie::attach(res, jar))
+			}
 			// There was an error with authentication
 			Err(e) => Err(warp::reject::custom(e)),
 		},
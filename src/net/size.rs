@@ -0,0 +1,57 @@
+use crate::cli::CF;
+use std::error::Error;
+use warp::ws::{Message, Ws};
+
+/// Apply the configured message and frame size limits to a WebSocket
+///
+/// Fragmented messages are reassembled before they are received, so the
+/// message size limit applies to the whole message, and the frame size
+/// limit applies to each of its fragments.
+pub fn limit(ws: Ws) -> Ws {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Limit the size of each message
+	let ws = ws.max_message_size(opt.ws_message);
+	// Limit the size of each frame
+	match opt.ws_frame {
+		Some(v) => ws.max_frame_size(v),
+		None => ws,
+	}
+}
+
+/// Get the close message to send when a client exceeds the size limits
+pub fn exceeded(err: &warp::Error) -> Option<Message> {
+	err.source().and_then(|e| e.downcast_ref::<tungstenite::Error>()).and_then(capacity)
+}
+
+// Get the close message for a WebSocket capacity error
+fn capacity(err: &tungstenite::Error) -> Option<Message> {
+	match err {
+		tungstenite::Error::Capacity(e) => Some(Message::close_with(1009u16, e.to_string())),
+		_ => None,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use tungstenite::error::CapacityError;
+
+	#[test]
+	fn capacity_exceeded() {
+		let err = tungstenite::Error::Capacity(CapacityError::MessageTooLong {
+			size: 2048,
+			max_size: 1024,
+		});
+		let msg = capacity(&err).unwrap();
+		assert!(msg.is_close());
+		assert_eq!(msg.close_frame().map(|(c, _)| c), Some(1009));
+	}
+
+	#[test]
+	fn capacity_not_exceeded() {
+		assert!(capacity(&tungstenite::Error::ConnectionClosed).is_none());
+		assert!(capacity(&tungstenite::Error::AlreadyClosed).is_none());
+	}
+}
@@ -1,17 +1,50 @@
 use crate::cli::CF;
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::length;
+use crate::net::limit;
+use crate::net::origin;
 use crate::net::output;
 use crate::net::session;
+use crate::net::size;
+use crate::net::trace;
 use bytes::Bytes;
 use futures::{SinkExt, StreamExt};
+use opentelemetry::trace::FutureExt;
+use opentelemetry::Context;
+use serde::Deserialize;
+use std::time::Duration;
+use surrealdb::sql::Value;
+use surrealdb::Chunk;
+use surrealdb::Response;
 use surrealdb::Session;
 use warp::ws::{Message, WebSocket, Ws};
 use warp::Filter;
 
-const MAX: u64 = 1024 * 1024; // 1 MiB
+#[derive(Deserialize, Debug, Clone, Copy, Eq, PartialEq)]
+#[serde(rename_all = "lowercase")]
+enum Envelope {
+	/// Each statement response with its status and time
+	Full,
+	/// Only the result of a single statement
+	Bare,
+}
+
+impl Default for Envelope {
+	fn default() -> Self {
+		Envelope::Full
+	}
+}
+
+#[derive(Default, Deserialize, Debug, Clone)]
+struct Query {
+	#[serde(default)]
+	pub envelope: Envelope,
+}
 
 pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejection> + Clone {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
 	// Set base path
 	let base = warp::path("sql").and(warp::path::end());
 	// Set opts method
@@ -20,23 +53,27 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 	let post = base
 		.and(warp::post())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
-		.and(warp::body::content_length_limit(MAX))
+		.and(warp::query())
+		.and(warp::body::content_length_limit(opt.max_body))
 		.and(warp::body::bytes())
 		.and(session::build())
+		.and(trace::context())
 		.and_then(handler);
 	// Set sock method
-	let sock = base
-		.and(warp::ws())
-		.and(session::build())
-		.map(|ws: Ws, session: Session| ws.on_upgrade(move |ws| socket(ws, session)));
+	let sock =
+		base.and(warp::ws()).and(origin::check()).and(session::build()).map(
+			|ws: Ws, session: Session| size::limit(ws).on_upgrade(move |ws| socket(ws, session)),
+		);
 	// Specify route
 	opts.or(post).or(sock)
 }
 
 async fn handler(
 	output: String,
+	query: Query,
 	sql: Bytes,
 	session: Session,
+	cx: Context,
 ) -> Result<impl warp::Reply, warp::Rejection> {
 	// Get a database reference
 	let db = DB.get().unwrap();
@@ -44,8 +81,25 @@ async fn handler(
 	let opt = CF.get().unwrap();
 	// Convert the received sql query
 	let sql = std::str::from_utf8(&sql).unwrap();
+	// Check the length of the sql query
+	length::check(sql).map_err(warp::reject::custom)?;
+	// Stream the responses if requested
+	if output == "application/x-ndjson" {
+		return Ok(stream(sql.to_owned(), session, cx));
+	}
 	// Execute the received sql query
-	match db.execute(sql, &session, None, opt.strict).await {
+	match db.execute(sql, &session, None, opt.strict).with_context(cx).await {
+		// Return only the result of the statement
+		Ok(res) if query.envelope == Envelope::Bare => {
+			let res = bare(res)?;
+			match output.as_ref() {
+				"application/json" => Ok(output::json(&res)),
+				"application/cbor" => Ok(output::cbor(&res)),
+				"application/msgpack" => Ok(output::pack(&res)),
+				// An incorrect content-type was requested
+				_ => Err(warp::reject::custom(Error::InvalidType)),
+			}
+		}
 		// Convert the response to JSON
 		Ok(res) => match output.as_ref() {
 			"application/json" => Ok(output::json(&res)),
@@ -59,23 +113,81 @@ async fn handler(
 	}
 }
 
+fn bare(mut res: Vec<Response>) -> Result<Value, warp::Rejection> {
+	// Bare results are only unambiguous for a single statement
+	if res.len() != 1 {
+		return Err(warp::reject::custom(Error::BareMultiple));
+	}
+	// Return the result, or the statement error
+	match res.remove(0).result {
+		Ok(v) => Ok(v),
+		Err(e) => Err(warp::reject::custom(Error::from(e))),
+	}
+}
+
+fn stream(sql: String, session: Session, cx: Context) -> output::Output {
+	// Get a database reference
+	let db = DB.get().unwrap();
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Create a new bounded channel
+	let (snd, rcv) = surrealdb::channel::new(1);
+	// Spawn a new query execution
+	tokio::spawn(async move {
+		// Keep a sender for reporting errors
+		let err = snd.clone();
+		// Execute the received sql query
+		if let Err(e) = db.stream(&sql, &session, None, opt.strict, snd).with_context(cx).await {
+			// Signal the error as the last line
+			let _ = err
+				.send(Chunk::Response(Response {
+					sql: None,
+					time: Duration::default(),
+					result: Err(e),
+					cursor: None,
+					truncated: false,
+				}))
+				.await;
+		}
+	});
+	// Return the streamed records and responses
+	output::ndjson(rcv)
+}
+
 async fn socket(ws: WebSocket, session: Session) {
 	// Split the WebSocket connection
 	let (mut tx, mut rx) = ws.split();
 	// Wait to receive the next message
 	while let Some(res) = rx.next().await {
+		// Close the connection if the message was too large
+		if let Err(err) = &res {
+			if let Some(msg) = size::exceeded(err) {
+				let _ = tx.send(msg).await;
+				break;
+			}
+		}
 		if let Ok(msg) = res {
 			if let Ok(sql) = msg.to_str() {
 				// Get a database reference
 				let db = DB.get().unwrap();
 				// Get local copy of options
 				let opt = CF.get().unwrap();
+				// Check the length of the sql query
+				if let Err(e) = length::check(sql) {
+					let _ = tx.send(Message::text(e)).await;
+					continue;
+				}
+				// Check the request rate limit
+				if let Err(e) = limit::check(&session) {
+					let _ = tx.send(Message::text(e)).await;
+					continue;
+				}
 				// Execute the received sql query
 				let _ = match db.execute(sql, &session, None, opt.strict).await {
 					// Convert the response to JSON
-					Ok(v) => match serde_json::to_string(&v) {
+					Ok(v) => match output::encode(&v) {
 						// Send the JSON response to the client
-						Ok(v) => tx.send(Message::text(v)).await,
+						Ok(v) => tx.send(Message::text(String::from_utf8(v).unwrap())).await,
 						// There was an error converting to JSON
 						Err(e) => tx.send(Message::text(Error::from(e))).await,
 					},
@@ -86,3 +198,53 @@ async fn socket(ws: WebSocket, session: Session) {
 		}
 	}
 }
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use surrealdb::Error as DbError;
+
+	fn response(result: Result<Value, DbError>) -> Response {
+		Response {
+			sql: None,
+			time: Duration::default(),
+			result,
+			cursor: None,
+			truncated: false,
+		}
+	}
+
+	#[test]
+	fn bare_single_result() {
+		let res = bare(vec![response(Ok(Value::from("test")))]);
+		assert_eq!(res.unwrap(), Value::from("test"));
+	}
+
+	#[test]
+	fn bare_single_error() {
+		let res = bare(vec![response(Err(DbError::QueryPermissions))]);
+		let err = res.unwrap_err();
+		assert!(matches!(err.find::<Error>(), Some(Error::Db(DbError::QueryPermissions))));
+	}
+
+	#[test]
+	fn bare_multiple_results() {
+		let res = bare(vec![response(Ok(Value::None)), response(Ok(Value::None))]);
+		assert!(matches!(res.unwrap_err().find::<Error>(), Some(Error::BareMultiple)));
+		let res = bare(vec![]);
+		assert!(matches!(res.unwrap_err().find::<Error>(), Some(Error::BareMultiple)));
+	}
+
+	#[test]
+	fn query_envelope() {
+		assert_eq!(Query::default().envelope, Envelope::Full);
+		let query: Query = serde_json::from_str("{}").unwrap();
+		assert_eq!(query.envelope, Envelope::Full);
+		let query: Query = serde_json::from_str(r#"{ "envelope": "bare" }"#).unwrap();
+		assert_eq!(query.envelope, Envelope::Bare);
+		let query: Query = serde_json::from_str(r#"{ "envelope": "full" }"#).unwrap();
+		assert_eq!(query.envelope, Envelope::Full);
+		assert!(serde_json::from_str::<Query>(r#"{ "envelope": "other" }"#).is_err());
+	}
+}
@@ -0,0 +1,13 @@
+use http::HeaderMap;
+use opentelemetry::global;
+use opentelemetry::Context;
+use opentelemetry_http::HeaderExtractor;
+use std::convert::Infallible;
+use warp::Filter;
+
+pub fn context() -> impl Filter<Extract = (Context,), Error = Infallible> + Clone {
+	// Extract any traceparent headers
+	warp::header::headers_cloned().map(|headers: HeaderMap| {
+		global::get_text_map_propagator(|p| p.extract(&HeaderExtractor(&headers)))
+	})
+}
@@ -1,13 +1,62 @@
+use crate::cli::CF;
+use crate::cnf::PKG_COMMIT;
 use crate::cnf::PKG_NAME;
+use crate::cnf::PKG_RUSTC;
 use crate::cnf::PKG_VERS;
-use warp::http;
+use crate::net::output;
+use surrealdb::sql::Value;
 use warp::Filter;
 
 pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejection> + Clone {
-	warp::path("version").and(warp::path::end()).and(warp::get()).and_then(handler)
+	warp::path("version")
+		.and(warp::path::end())
+		.and(warp::get())
+		.and(warp::header::optional::<String>(http::header::ACCEPT.as_str()))
+		.and_then(handler)
 }
 
-pub async fn handler() -> Result<impl warp::Reply, warp::Rejection> {
-	let val = format!("{}-{}", PKG_NAME, PKG_VERS);
-	Ok(warp::reply::with_status(val, http::StatusCode::OK))
+pub async fn handler(output: Option<String>) -> Result<impl warp::Reply, warp::Rejection> {
+	match output.as_deref() {
+		// Output the full build information
+		Some("application/json") => Ok(output::json(&info())),
+		Some("application/cbor") => Ok(output::cbor(&info())),
+		Some("application/msgpack") => Ok(output::pack(&info())),
+		// Output the plain name and version
+		_ => Ok(output::text(format!("{}-{}", PKG_NAME, PKG_VERS))),
+	}
+}
+
+// Build the server version and feature information. This
+// only reports whether each option is enabled, and never
+// includes any configured values such as keys or secrets.
+pub fn info() -> Value {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Specify the compiled and configured features
+	let features = map! {
+		String::from("storage-rocksdb") => Value::from(cfg!(feature = "storage-rocksdb")),
+		String::from("storage-tikv") => Value::from(cfg!(feature = "storage-tikv")),
+		String::from("storage-fdb") => Value::from(cfg!(feature = "storage-fdb")),
+		String::from("scripting") => Value::from(cfg!(feature = "scripting")),
+		String::from("http") => Value::from(cfg!(feature = "http")),
+		String::from("strict") => Value::from(opt.strict),
+		String::from("tracing") => Value::from(opt.tracing),
+		String::from("tls") => Value::from(opt.crt.is_some() && opt.key.is_some()),
+		String::from("encryption") => Value::from(!opt.keys.is_empty()),
+		String::from("field-encryption") => Value::from(!opt.fields.is_empty()),
+		String::from("rate-limit") => Value::from(opt.rate.is_some() || !opt.rates.is_empty()),
+		String::from("query-cache") => Value::from(opt.cache.is_some()),
+		String::from("json-safe-integers") => Value::from(opt.safe_integers),
+		String::from("public-access") => Value::from(opt.public_ns.is_some()),
+		String::from("ws-origin-allowlist") => Value::from(!opt.ws_origins.is_empty()),
+		String::from("ws-resume") => Value::from(opt.ws_resume.is_some()),
+	};
+	// Return the build information
+	Value::from(map! {
+		String::from("name") => Value::from(PKG_NAME),
+		String::from("version") => Value::from(PKG_VERS),
+		String::from("commit") => Value::from(PKG_COMMIT),
+		String::from("rustc") => Value::from(PKG_RUSTC),
+		String::from("features") => Value::from(features),
+	})
 }
@@ -0,0 +1,87 @@
+use surrealdb::sql::Array;
+use surrealdb::sql::Value;
+
+// The methods with parameters which contain credentials or tokens
+const REDACTED: [&str; 4] = ["signin", "signup", "authenticate", "resume"];
+
+/// Describe an RPC request for the connection debug log
+///
+/// The parameters of authentication requests are redacted, and only the
+/// names of any variables are included, as their values may be sensitive.
+pub fn request(method: &str, params: &Array) -> String {
+	match method {
+		// Authentication parameters are never logged
+		m if REDACTED.contains(&m) => format!("{} [REDACTED]", method),
+		// Only the name of the variable is logged
+		"let" | "set" => match params.first() {
+			Some(v) => format!("{} {}", method, v),
+			None => method.to_owned(),
+		},
+		// Only the query and the names of the variables are logged
+		"query" => match (params.get(0), params.get(1)) {
+			(Some(sql), Some(Value::Object(vars))) => {
+				let vars = vars.keys().map(String::as_str).collect::<Vec<_>>().join(", ");
+				format!("{} {} with variables {}", method, sql, vars)
+			}
+			(Some(sql), _) => format!("{} {}", method, sql),
+			_ => method.to_owned(),
+		},
+		// Otherwise log all of the parameters
+		_ => format!("{} {}", method, params),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use surrealdb::sql::json;
+
+	fn params(v: &str) -> Array {
+		match json(v).unwrap() {
+			Value::Array(v) => v,
+			_ => unreachable!(),
+		}
+	}
+
+	#[test]
+	fn request_redacts_credentials() {
+		let creds = params(r#"[{ "user": "root", "pass": "secret" }]"#);
+		for method in ["signin", "signup"] {
+			let log = request(method, &creds);
+			assert_eq!(log, format!("{} [REDACTED]", method));
+		}
+		let token = params(r#"["secret"]"#);
+		for method in ["authenticate", "resume"] {
+			let log = request(method, &token);
+			assert_eq!(log, format!("{} [REDACTED]", method));
+		}
+	}
+
+	#[test]
+	fn request_logs_variable_names() {
+		let vars = params(r#"["name", "secret"]"#);
+		for method in ["let", "set"] {
+			let log = request(method, &vars);
+			assert!(log.starts_with(method));
+			assert!(log.contains("name"));
+			assert!(!log.contains("secret"));
+		}
+	}
+
+	#[test]
+	fn request_logs_query_variable_names() {
+		let query = params(r#"["SELECT * FROM $user", { "user": "secret", "pass": "secret" }]"#);
+		let log = request("query", &query);
+		assert!(log.contains("SELECT * FROM $user"));
+		assert!(log.contains("pass, user"));
+		assert!(!log.contains("secret"));
+	}
+
+	#[test]
+	fn request_logs_other_params() {
+		let log = request("select", &params(r#"["person"]"#));
+		assert!(log.starts_with("select"));
+		assert!(log.contains("person"));
+	}
+}
@@ -1,3 +1,4 @@
 pub mod args;
+pub mod debug;
 pub mod paths;
 pub mod res;
@@ -1,3 +1,5 @@
+use crate::err::Error;
+use crate::net::output;
 use serde::Serialize;
 use std::borrow::Cow;
 use surrealdb::channel::Sender;
@@ -22,8 +24,8 @@ pub struct Response {
 impl Response {
 	// Send the response to the channel
 	pub async fn send(self, chn: Sender<Message>) {
-		let res = serde_json::to_string(&self).unwrap();
-		let res = Message::text(res);
+		let res = output::encode(&self).unwrap();
+		let res = Message::text(String::from_utf8(res).unwrap());
 		let _ = chn.send(res).await;
 	}
 	// Create a JSON RPC result response
@@ -46,41 +48,48 @@ impl Response {
 pub struct Failure {
 	code: i64,
 	message: Cow<'static, str>,
+	#[serde(skip_serializing_if = "Option::is_none")]
+	data: Option<&'static str>,
 }
 
 impl Failure {
 	pub const PARSE_ERROR: Failure = Failure {
 		code: -32700,
 		message: Cow::Borrowed("Parse error"),
+		data: None,
 	};
 
 	pub const INVALID_REQUEST: Failure = Failure {
 		code: -32600,
 		message: Cow::Borrowed("Invalid Request"),
+		data: None,
 	};
 
 	pub const METHOD_NOT_FOUND: Failure = Failure {
 		code: -32601,
 		message: Cow::Borrowed("Method not found"),
+		data: None,
 	};
 
 	pub const INVALID_PARAMS: Failure = Failure {
 		code: -32602,
 		message: Cow::Borrowed("Invalid params"),
+		data: None,
 	};
 
 	pub const INTERNAL_ERROR: Failure = Failure {
 		code: -32603,
 		message: Cow::Borrowed("Internal error"),
+		data: None,
 	};
+}
 
-	pub fn custom<S>(message: S) -> Failure
-	where
-		Cow<'static, str>: From<S>,
-	{
+impl From<Error> for Failure {
+	fn from(e: Error) -> Failure {
 		Failure {
 			code: -32000,
-			message: message.into(),
+			message: e.to_string().into(),
+			data: Some(e.code()),
 		}
 	}
 }